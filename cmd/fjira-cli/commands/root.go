@@ -21,7 +21,10 @@ const (
 	CtxWorkspaceSettings CtxVarWorkspaceSettings = "workspace-settings"
 )
 
-var ErrInvalidIssueKeyFormat = errors.New("invalid issue key format")
+var (
+	ErrInvalidIssueKeyFormat = errors.New("invalid issue key format")
+	ErrRecordAndReplay       = errors.New("--record and --replay cannot be used together")
+)
 
 // shouldSkipWorkspaceInitialization determines if a command should skip workspace initialization.
 func shouldSkipWorkspaceInitialization(cmd *cobra.Command) bool {
@@ -62,10 +65,20 @@ Say goodbye to manual searching and hello to increased productivity with fjira.`
 				return nil
 			}
 			// it's initializing fjira before every command
-			s, err := fjira.Install("")
+			install := fjira.Install
+			if demo, _ := cmd.Flags().GetBool("demo"); demo {
+				install = func(string) (*workspaces.WorkspaceSettings, error) { return fjira.InstallDemo() }
+			}
+			s, err := install("")
 			if err != nil {
 				return err
 			}
+			s.Offline, _ = cmd.Flags().GetBool("offline")
+			s.Record, _ = cmd.Flags().GetString("record")
+			s.Replay, _ = cmd.Flags().GetString("replay")
+			if s.Record != "" && s.Replay != "" {
+				return ErrRecordAndReplay
+			}
 			cmd.SetContext(context.WithValue(cmd.Context(), CtxWorkspaceSettings, s))
 			return nil
 		},
@@ -99,6 +112,10 @@ Say goodbye to manual searching and hello to increased productivity with fjira.`
 	cmd.AddCommand(&cobra.Command{Use: "", Short: "Open a fuzzy finder for projects as a default action"})
 	cmd.Flags().StringP("project", "p", "", "Open a project directly from CLI")
 	cmd.Flags().Int("board", 0, "Open a board directly from CLI (by board id)")
+	cmd.PersistentFlags().Bool("offline", false, "Work on the local snapshot of the workspace, without Jira. Every online session keeps that snapshot (~/.fjira/offline/<workspace>) up to date. Changes are queued and sent on the next online start")
+	cmd.PersistentFlags().String("record", "", "Record every Jira request and response (credentials redacted) into the given directory, e.g. to attach to a bug report")
+	cmd.PersistentFlags().String("replay", "", "Serve Jira responses from a directory recorded with --record, instead of calling Jira")
+	cmd.PersistentFlags().Bool("demo", false, "Run against a built-in fake Jira with sample projects, boards and issues. Nothing is sent anywhere or saved")
 	cmd.Flags().Bool("debug-pprof", false, "Start a pprof HTTP server on 127.0.0.1 (auto-picked port, printed to stderr). For debugging only.")
 	return cmd
 }
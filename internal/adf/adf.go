@@ -0,0 +1,98 @@
+// Package adf reads Atlassian Document Format, the JSON documents Jira Cloud
+// returns for rich text (descriptions, comment bodies) from its v3 api, and
+// renders them as styled terminal text.
+//
+// https://developer.atlassian.com/cloud/jira/platform/apis/document/structure/
+package adf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/richtext"
+)
+
+var ErrNotAdf = errors.New("not an Atlassian Document Format document")
+
+// Node is a node of the document tree: a block (paragraph, table, ...), an
+// inline node (text, mention, ...) or the doc itself. Unknown node types are
+// kept, and render as their content.
+type Node struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Marks   []Mark         `json:"marks,omitempty"`
+	Content []*Node        `json:"content,omitempty"`
+}
+
+// Mark styles a text node: strong, em, code, link, ...
+type Mark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// Parse reads a document. Anything but a JSON object with a type, e.g. the
+// plain string the v2 api returns, is ErrNotAdf.
+func Parse(data []byte) (*Node, error) {
+	var doc Node
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Type == "" {
+		return nil, ErrNotAdf
+	}
+	return &doc, nil
+}
+
+// IsDocument tells whether data looks like a document rather than a string
+// or null, without parsing it all.
+func IsDocument(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return strings.HasPrefix(trimmed, "{")
+}
+
+// PlainText flattens a document into text, the way it reads unwrapped.
+func PlainText(doc *Node) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(richtext.String(RenderWithTheme(doc, 0, richtext.Theme{})))
+}
+
+// attr reads a string attribute; numbers are formatted, anything else is "".
+func (n *Node) attr(key string) string {
+	switch v := n.Attrs[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (m Mark) attr(key string) string {
+	if v, ok := m.Attrs[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// text joins the text of all descendants, e.g. the code in a codeBlock.
+func (n *Node) text() string {
+	if n.Type == "text" {
+		return n.Text
+	}
+	if n.Type == "hardBreak" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, c := range n.Content {
+		b.WriteString(c.text())
+	}
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/richtext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTheme = richtext.Theme{
+	Code:    tcell.StyleDefault.Background(tcell.ColorGray),
+	Link:    tcell.StyleDefault.Foreground(tcell.ColorBlue).Underline(true),
+	Mention: tcell.StyleDefault.Foreground(tcell.ColorGreen),
+	Panels:  map[string]tcell.Style{"warning": tcell.StyleDefault.Foreground(tcell.ColorYellow)},
+}
+
+func render(t *testing.T, doc string, width int) []richtext.Line {
+	t.Helper()
+	node, err := Parse([]byte(doc))
+	assert.Nil(t, err)
+	return RenderWithTheme(node, width, testTheme)
+}
+
+func Test_Render(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		width int
+		want  string
+	}{
+		{"should separate paragraphs",
+			`{"type":"doc","version":1,"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"one"}]},
+				{"type":"paragraph","content":[{"type":"text","text":"two"},{"type":"hardBreak"},{"type":"text","text":"three"}]}]}`,
+			40, "one\n\ntwo\nthree"},
+		{"should wrap paragraphs",
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"the quick brown fox"}]}]}`,
+			10, "the quick\nbrown fox"},
+		{"should render headings",
+			`{"type":"doc","content":[{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Steps"}]}]}`,
+			40, "Steps"},
+		{"should render nested bullet lists",
+			`{"type":"doc","content":[{"type":"bulletList","content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]},
+					{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"nested"}]}]}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]}]}]}]}`,
+			40, "• one\n  ◦ nested\n• two"},
+		{"should number ordered lists from their order",
+			`{"type":"doc","content":[{"type":"orderedList","attrs":{"order":9},"content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"nine"}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"ten and more"}]}]}]}]}`,
+			12, " 9. nine\n10. ten and\n    more"},
+		{"should render task lists",
+			`{"type":"doc","content":[{"type":"taskList","content":[
+				{"type":"taskItem","attrs":{"state":"DONE"},"content":[{"type":"text","text":"done"}]},
+				{"type":"taskItem","attrs":{"state":"TODO"},"content":[{"type":"text","text":"todo"}]}]}]}`,
+			40, "☑ done\n☐ todo"},
+		{"should keep code block lines",
+			`{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"func main() {\n\tfmt.Println()\n}"}]}]}`,
+			20, " go\n func main() {\n \tfmt.Println()\n }"},
+		{"should render tables",
+			`{"type":"doc","content":[{"type":"table","content":[
+				{"type":"tableRow","content":[
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Key"}]}]},
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Status"}]}]}]},
+				{"type":"tableRow","content":[
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"FJ-1"}]}]},
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"Done"}]}]}]}]}]}`,
+			40, "Key  │ Status\n─────┼───────\nFJ-1 │ Done"},
+		{"should shrink tables to the width",
+			`{"type":"doc","content":[{"type":"table","content":[
+				{"type":"tableRow","content":[
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]},
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"long long text"}]}]}]}]}]}`,
+			14, "a │ long long\n  │ text"},
+		{"should render mentions, emoji and links",
+			`{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"mention","attrs":{"id":"712020:alice","text":"@Alice"}},
+				{"type":"text","text":" "},
+				{"type":"emoji","attrs":{"shortName":":thumbsup:"}},
+				{"type":"text","text":" "},
+				{"type":"emoji","attrs":{"shortName":":custom:"}},
+				{"type":"text","text":" see "},
+				{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]},
+				{"type":"text","text":" "},
+				{"type":"inlineCard","attrs":{"url":"https://example.com/FJ-1"}}]}]}`,
+			80, "@Alice 👍 :custom: see docs <https://example.com> https://example.com/FJ-1"},
+		{"should render panels",
+			`{"type":"doc","content":[{"type":"panel","attrs":{"panelType":"warning"},"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"careful now"}]}]}]}`,
+			12, "▌⚠  careful\n▌   now"},
+		{"should render quotes and rules",
+			`{"type":"doc","content":[
+				{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"quoted"}]}]},
+				{"type":"rule"}]}`,
+			8, "│ quoted\n\n────────"},
+		{"should render status and dates",
+			`{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"status","attrs":{"text":"in progress"}},{"type":"text","text":" until "},{"type":"date","attrs":{"timestamp":"1767225600000"}}]}]}`,
+			40, " IN PROGRESS  until 1 Jan 2026"},
+		{"should render unknown nodes as their content",
+			`{"type":"doc","content":[{"type":"somethingNew","content":[{"type":"paragraph","content":[{"type":"text","text":"still here"}]}]}]}`,
+			40, "still here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, tt.doc, tt.width)
+
+			assert.Equal(t, tt.want, richtext.String(got))
+		})
+	}
+}
+
+func Test_Render_should_style_marks(t *testing.T) {
+	// given
+	doc := `{"type":"doc","content":[{"type":"paragraph","content":[
+		{"type":"text","text":"b","marks":[{"type":"strong"}]},
+		{"type":"text","text":"i","marks":[{"type":"em"},{"type":"strike"}]},
+		{"type":"text","text":"c","marks":[{"type":"code"}]},
+		{"type":"mention","attrs":{"text":"@Bob"}}]}]}`
+
+	// when
+	lines := render(t, doc, 40)
+
+	// then
+	assert.Len(t, lines, 1)
+	spans := lines[0]
+	assert.Equal(t, tcell.StyleDefault.Bold(true), spans[0].Style)
+	assert.Equal(t, tcell.StyleDefault.Italic(true).StrikeThrough(true), spans[1].Style)
+	assert.Equal(t, testTheme.Code, spans[2].Style)
+	assert.Equal(t, testTheme.Mention, spans[3].Style)
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"should parse documents", `{"type":"doc","version":1,"content":[]}`, false},
+		{"should reject strings", `"plain text"`, true},
+		{"should reject objects without type", `{"content":[]}`, true},
+		{"should reject invalid json", `{"type":`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_PlainText(t *testing.T) {
+	// given
+	doc, _ := Parse([]byte(`{"type":"doc","content":[
+		{"type":"paragraph","content":[{"type":"text","text":"Hello "},{"type":"mention","attrs":{"text":"@Alice"}}]},
+		{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"a long item that is not wrapped"}]}]}]}]}`))
+
+	// when
+	text := PlainText(doc)
+
+	// then
+	assert.Equal(t, "Hello @Alice\n\n• a long item that is not wrapped", text)
+}
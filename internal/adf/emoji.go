@@ -0,0 +1,82 @@
+package adf
+
+// shortcodes covers the emoji people actually use in Jira, for emoji nodes
+// that come without their text (custom and older ones don't have it).
+var shortcodes = map[string]string{
+	":smile:":            "😄",
+	":smiley:":           "😃",
+	":slight_smile:":     "🙂",
+	":grinning:":         "😀",
+	":laughing:":         "😆",
+	":joy:":              "😂",
+	":wink:":             "😉",
+	":blush:":            "😊",
+	":thinking:":         "🤔",
+	":neutral_face:":     "😐",
+	":confused:":         "😕",
+	":disappointed:":     "😞",
+	":cry:":              "😢",
+	":sweat_smile:":      "😅",
+	":stuck_out_tongue:": "😛",
+	":sunglasses:":       "😎",
+	":scream:":           "😱",
+	":thumbsup:":         "👍",
+	":+1:":               "👍",
+	":thumbsdown:":       "👎",
+	":-1:":               "👎",
+	":clap:":             "👏",
+	":pray:":             "🙏",
+	":wave:":             "👋",
+	":muscle:":           "💪",
+	":eyes:":             "👀",
+	":heart:":            "❤️",
+	":broken_heart:":     "💔",
+	":fire:":             "🔥",
+	":tada:":             "🎉",
+	":rocket:":           "🚀",
+	":star:":             "⭐",
+	":sparkles:":         "✨",
+	":bulb:":             "💡",
+	":bug:":              "🐛",
+	":lock:":             "🔒",
+	":key:":              "🔑",
+	":warning:":          "⚠️",
+	":construction:":     "🚧",
+	":no_entry:":         "⛔",
+	":question:":         "❓",
+	":exclamation:":      "❗",
+	":white_check_mark:": "✅",
+	":check_mark:":       "✔️",
+	":heavy_check_mark:": "✔️",
+	":x:":                "❌",
+	":cross_mark:":       "❌",
+	":100:":              "💯",
+	":hourglass:":        "⌛",
+	":calendar:":         "📅",
+	":memo:":             "📝",
+	":link:":             "🔗",
+	":coffee:":           "☕",
+	":beer:":             "🍺",
+	":info:":             "ℹ️",
+	":light_bulb_on:":    "💡",
+	":light_bulb_off:":   "💡",
+	":yellow_star:":      "⭐",
+	":red_star:":         "⭐",
+	":green_star:":       "⭐",
+	":blue_star:":        "⭐",
+	":plus:":             "➕",
+	":minus:":            "➖",
+}
+
+// emoji is the text of an emoji node: its own, the known one for its short
+// name, or else the short name itself.
+func emoji(n *Node) string {
+	if text := n.attr("text"); text != "" {
+		return text
+	}
+	shortName := n.attr("shortName")
+	if e, ok := shortcodes[shortName]; ok {
+		return e
+	}
+	return shortName
+}
@@ -0,0 +1,258 @@
+package adf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/richtext"
+	"github.com/rivo/uniseg"
+)
+
+var (
+	bullets     = []string{"• ", "◦ ", "▪ "}
+	statusStyle = tcell.StyleDefault.Reverse(true)
+)
+
+// Render lays the document out for a width of width cells, styled with the
+// color scheme. A width <= 0 doesn't wrap.
+func Render(doc *Node, width int) []richtext.Line {
+	return RenderWithTheme(doc, width, richtext.DefaultTheme())
+}
+
+// RenderWithTheme is Render with the given styles.
+func RenderWithTheme(doc *Node, width int, theme richtext.Theme) []richtext.Line {
+	if doc == nil {
+		return nil
+	}
+	r := renderer{theme: theme}
+	if doc.Type != "doc" {
+		return r.block(doc, width, 0)
+	}
+	return r.blocks(doc.Content, width, 0, true)
+}
+
+type renderer struct {
+	theme richtext.Theme
+}
+
+// blocks renders block nodes one below the other; loose puts a blank line
+// between them, like between paragraphs - list items are tight.
+func (r renderer) blocks(nodes []*Node, width int, depth int, loose bool) []richtext.Line {
+	var lines []richtext.Line
+	for i, n := range nodes {
+		block := r.block(n, width, depth)
+		if len(block) == 0 {
+			continue
+		}
+		if i > 0 && loose && len(lines) > 0 {
+			lines = append(lines, richtext.Line{})
+		}
+		lines = append(lines, block...)
+	}
+	return lines
+}
+
+func (r renderer) block(n *Node, width int, depth int) []richtext.Line {
+	switch n.Type {
+	case "paragraph":
+		return richtext.Wrap(r.inline(n.Content, r.theme.Text), width)
+	case "heading":
+		style := r.theme.Heading
+		if level, _ := strconv.Atoi(n.attr("level")); level <= 1 {
+			style = style.Underline(true)
+		}
+		return richtext.Wrap(r.inline(n.Content, style), width)
+	case "bulletList":
+		return r.list(n, width, depth, func(int) string { return bullets[depth%len(bullets)] })
+	case "orderedList":
+		start := 1
+		if order, err := strconv.Atoi(n.attr("order")); err == nil {
+			start = order
+		}
+		numberWidth := len(strconv.Itoa(start + len(n.Content) - 1))
+		return r.list(n, width, depth, func(i int) string {
+			return fmt.Sprintf("%*d. ", numberWidth, start+i)
+		})
+	case "taskList", "decisionList":
+		return r.list(n, width, depth, func(i int) string { return itemMark(n.Content[i]) })
+	case "taskItem", "decisionItem":
+		// an item outside of a list
+		return richtext.Wrap(r.inline(n.Content, r.theme.Text), width)
+	case "codeBlock":
+		return richtext.CodeBlock(n.text(), n.attr("language"), width, r.theme)
+	case "blockquote":
+		return richtext.Quote(r.blocks(n.Content, width-richtext.QuoteInset, depth, true), r.theme)
+	case "panel":
+		content := r.blocks(n.Content, width-richtext.PanelInset, depth, true)
+		return richtext.Panel(content, n.attr("panelType"), "", r.theme)
+	case "rule":
+		return richtext.Rule(width, r.theme)
+	case "table":
+		return r.table(n, width)
+	case "expand", "nestedExpand":
+		title := n.attr("title")
+		lines := []richtext.Line{{{Text: "▾ " + title, Style: r.theme.Text.Bold(true)}}}
+		indent := richtext.Span{Text: "  ", Style: r.theme.Text}
+		return append(lines, richtext.Indent(r.blocks(n.Content, width-2, depth, true), indent, indent)...)
+	case "mediaSingle", "mediaGroup":
+		return r.blocks(n.Content, width, depth, false)
+	case "media":
+		return richtext.Wrap([]richtext.Span{r.media(n)}, width)
+	case "blockCard", "embedCard":
+		url := n.attr("url")
+		return richtext.Wrap([]richtext.Span{{Text: url, Style: r.theme.Link}}, width)
+	case "text", "hardBreak", "mention", "emoji", "inlineCard", "status", "date", "mediaInline", "placeholder":
+		// inline nodes where a block was expected
+		return richtext.Wrap(r.inline([]*Node{n}, r.theme.Text), width)
+	}
+	return r.blocks(n.Content, width, depth, true)
+}
+
+// list renders items with their marks hanging in front of them.
+func (r renderer) list(n *Node, width int, depth int, mark func(i int) string) []richtext.Line {
+	var lines []richtext.Line
+	for i, item := range n.Content {
+		m := mark(i)
+		markWidth := uniseg.StringWidth(m)
+		var content []richtext.Line
+		switch item.Type {
+		case "taskItem", "decisionItem":
+			content = richtext.Wrap(r.inline(item.Content, r.theme.Text), width-markWidth)
+			if item.Type == "taskItem" && item.attr("state") == "DONE" {
+				content = richtext.Restyle(content, func(s tcell.Style) tcell.Style { return s.StrikeThrough(true) })
+			}
+		default:
+			content = r.blocks(item.Content, width-markWidth, depth+1, false)
+		}
+		if len(content) == 0 {
+			content = []richtext.Line{{}}
+		}
+		lines = append(lines, richtext.Indent(content,
+			richtext.Span{Text: m, Style: r.theme.Text},
+			richtext.Span{Text: strings.Repeat(" ", markWidth), Style: r.theme.Text})...)
+	}
+	return lines
+}
+
+func itemMark(item *Node) string {
+	switch {
+	case item.Type == "decisionItem":
+		return "◆ "
+	case item.attr("state") == "DONE":
+		return "☑ "
+	default:
+		return "☐ "
+	}
+}
+
+// table lays the rows out with their header cells marked.
+func (r renderer) table(n *Node, width int) []richtext.Line {
+	var rows [][]richtext.Cell
+	for _, row := range n.Content {
+		if row.Type != "tableRow" {
+			continue
+		}
+		cells := make([]richtext.Cell, 0, len(row.Content))
+		for _, cell := range row.Content {
+			cells = append(cells, richtext.Cell{
+				Header: cell.Type == "tableHeader",
+				Render: func(width int) []richtext.Line { return r.blocks(cell.Content, width, 0, false) },
+			})
+		}
+		rows = append(rows, cells)
+	}
+	return richtext.Table(rows, width, r.theme)
+}
+
+// inline turns inline nodes into spans, base being the style of the block
+// they're in.
+func (r renderer) inline(nodes []*Node, base tcell.Style) []richtext.Span {
+	var spans []richtext.Span
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			spans = append(spans, r.text(n, base)...)
+		case "hardBreak":
+			spans = append(spans, richtext.Span{Text: "\n", Style: base})
+		case "mention":
+			text := n.attr("text")
+			if text == "" {
+				text = n.attr("id")
+			}
+			if !strings.HasPrefix(text, "@") {
+				text = "@" + text
+			}
+			spans = append(spans, richtext.Span{Text: text, Style: r.theme.Mention})
+		case "emoji":
+			spans = append(spans, richtext.Span{Text: emoji(n), Style: base})
+		case "inlineCard":
+			spans = append(spans, richtext.Span{Text: n.attr("url"), Style: r.theme.Link})
+		case "status":
+			spans = append(spans, richtext.Span{Text: " " + strings.ToUpper(n.attr("text")) + " ", Style: statusStyle})
+		case "date":
+			spans = append(spans, richtext.Span{Text: formatDate(n.attr("timestamp")), Style: base.Bold(true)})
+		case "mediaInline":
+			spans = append(spans, r.media(n))
+		case "placeholder":
+		default:
+			spans = append(spans, r.inline(n.Content, base)...)
+		}
+	}
+	return spans
+}
+
+// text applies the marks of a text node. A link shows its url after the
+// text, unless the text is the url.
+func (r renderer) text(n *Node, base tcell.Style) []richtext.Span {
+	style := base
+	href := ""
+	for _, m := range n.Marks {
+		switch m.Type {
+		case "strong":
+			style = style.Bold(true)
+		case "em":
+			style = style.Italic(true)
+		case "underline":
+			style = style.Underline(true)
+		case "strike":
+			style = style.StrikeThrough(true)
+		case "code":
+			style = r.theme.Code
+		case "link":
+			href = m.attr("href")
+			style = r.theme.Linked(style)
+		case "textColor":
+			if color := m.attr("color"); color != "" {
+				style = style.Foreground(tcell.GetColor(color))
+			}
+		}
+	}
+	spans := []richtext.Span{{Text: n.Text, Style: style}}
+	if href != "" && href != n.Text {
+		spans = append(spans, richtext.Span{Text: " <" + href + ">", Style: r.theme.Dim})
+	}
+	return spans
+}
+
+func (r renderer) media(n *Node) richtext.Span {
+	name := n.attr("alt")
+	if name == "" {
+		name = n.attr("type")
+	}
+	if name == "" {
+		name = "attachment"
+	}
+	return richtext.Span{Text: "[" + name + "]", Style: r.theme.Dim}
+}
+
+// formatDate formats the epoch milliseconds of a date node.
+func formatDate(timestamp string) string {
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return timestamp
+	}
+	return time.UnixMilli(ms).UTC().Format("2 Jan 2006")
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -44,9 +45,19 @@ type App struct {
 	loading         bool
 	closed          bool
 	runOnAppRoutine []func()
+	runMutex        sync.Mutex
 	spinner         *SpinnerTCell
 	view            View
 	style           tcell.Style
+	// loadingCtx is handed out by LoadingContext while the spinner is shown;
+	// Esc cancels it (see CancelLoading) so a hung request doesn't pin the
+	// spinner forever.
+	loadingCtx    context.Context
+	loadingCancel context.CancelFunc
+	loadingMutex  sync.Mutex
+	// globalKeys are handled before the current view sees the key event.
+	globalKeys      map[tcell.Key]func()
+	globalKeysMutex sync.Mutex
 }
 
 const (
@@ -133,19 +144,18 @@ func (a *App) Start() {
 			return
 		}
 		a.Render()
-		if len(a.runOnAppRoutine) == 0 {
+		// funcs queued while these run are left for the next frame
+		a.runMutex.Lock()
+		funcsToRun := a.runOnAppRoutine
+		a.runOnAppRoutine = nil
+		a.runMutex.Unlock()
+		if len(funcsToRun) == 0 {
 			time.Sleep(FPSSleep)
 			continue
 		}
-		funcsToRun := len(a.runOnAppRoutine) - 1
-		for i := funcsToRun; i >= 0; i-- {
-			a.runOnAppRoutine[i]()
-		}
-		if len(a.runOnAppRoutine) > funcsToRun {
-			a.runOnAppRoutine = a.runOnAppRoutine[funcsToRun+1:]
-			continue
+		for i := len(funcsToRun) - 1; i >= 0; i-- {
+			funcsToRun[i]()
 		}
-		a.runOnAppRoutine = nil
 	}
 }
 
@@ -190,7 +200,7 @@ func (a *App) Close() {
 
 func (a *App) Loading(flag bool) {
 	a.spinner.text = "Fetching"
-	a.loading = flag
+	a.setLoading(flag)
 	a.setDirty()
 }
 
@@ -204,7 +214,50 @@ func (a *App) IsQuit() bool {
 
 func (a *App) LoadingWithText(flag bool, text string) {
 	a.spinner.text = text
-	a.loading = flag
+	a.setLoading(flag)
+}
+
+// LoadingContext returns the context bound to the current loading spinner.
+// Requests issued under it are cancelled when the user presses Esc while the
+// spinner is shown. Hiding the spinner detaches the context, so the next
+// loading phase gets a fresh one.
+func (a *App) LoadingContext() context.Context {
+	a.loadingMutex.Lock()
+	defer a.loadingMutex.Unlock()
+	if a.loadingCtx == nil {
+		a.loadingCtx, a.loadingCancel = context.WithCancel(context.Background())
+	}
+	return a.loadingCtx
+}
+
+// LoadingContextFrom derives a context from parent that is additionally
+// cancelled by Esc while the spinner is shown (see LoadingContext). Used where
+// the caller already has its own cancellation, e.g. a fuzzy-find query.
+func (a *App) LoadingContextFrom(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(a.LoadingContext(), cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// CancelLoading cancels the context handed out by LoadingContext and hides the
+// spinner. It returns false when nothing is loading under a context, so the
+// caller can fall back to regular key handling.
+func (a *App) CancelLoading() bool {
+	a.loadingMutex.Lock()
+	cancel := a.loadingCancel
+	if !a.loading || cancel == nil {
+		a.loadingMutex.Unlock()
+		return false
+	}
+	a.loadingCtx = nil
+	a.loadingCancel = nil
+	a.loadingMutex.Unlock()
+	cancel()
+	a.setLoading(false)
+	return true
 }
 
 func (a *App) SetView(view View) {
@@ -332,7 +385,11 @@ func (a *App) ClearNow() {
 	a.screen.HideCursor()
 }
 
+// RunOnAppRoutine queues f to run on the app routine, between two frames.
+// Safe to call from any goroutine.
 func (a *App) RunOnAppRoutine(f func()) {
+	a.runMutex.Lock()
+	defer a.runMutex.Unlock()
 	a.runOnAppRoutine = append(a.runOnAppRoutine, f)
 }
 
@@ -365,6 +422,18 @@ func (a *App) PanicRecover() {
 	}
 }
 
+func (a *App) setLoading(flag bool) {
+	a.loading = flag
+	if flag {
+		return
+	}
+	// detach, don't cancel - the caller may still be reading the response
+	a.loadingMutex.Lock()
+	a.loadingCtx = nil
+	a.loadingCancel = nil
+	a.loadingMutex.Unlock()
+}
+
 func (a *App) setDirty() {
 	a.dirty = true
 	a.RunOnAppRoutine(func() {
@@ -416,6 +485,14 @@ func (a *App) processTerminalEvents() {
 				a.Quit()
 				return
 			}
+			// Esc while a request is in flight aborts the request only; the
+			// view underneath must not also treat it as "go back".
+			if ev.Key() == tcell.KeyEscape && a.CancelLoading() {
+				continue
+			}
+			if a.handleGlobalKey(ev.Key()) {
+				continue
+			}
 			if len(a.systems) == 0 && ev.Key() == tcell.KeyEscape {
 				a.quit = true
 			}
@@ -431,6 +508,31 @@ func (a *App) processTerminalEvents() {
 	}
 }
 
+// SetGlobalKey makes key run f on any screen, instead of reaching the
+// current view. A nil f removes the binding.
+func (a *App) SetGlobalKey(key tcell.Key, f func()) {
+	a.globalKeysMutex.Lock()
+	defer a.globalKeysMutex.Unlock()
+	if a.globalKeys == nil {
+		a.globalKeys = make(map[tcell.Key]func())
+	}
+	if f == nil {
+		delete(a.globalKeys, key)
+		return
+	}
+	a.globalKeys[key] = f
+}
+
+func (a *App) handleGlobalKey(key tcell.Key) bool {
+	a.globalKeysMutex.Lock()
+	f, ok := a.globalKeys[key]
+	a.globalKeysMutex.Unlock()
+	if ok {
+		f()
+	}
+	return ok
+}
+
 func (a *App) processOsSignals() {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT)
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"context"
 	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -125,3 +127,91 @@ func Test_App_processTerminalEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_CancelLoading(t *testing.T) {
+	tests := []struct {
+		name          string
+		loading       bool
+		withContext   bool
+		wantCancelled bool
+	}{
+		{"should cancel loading context when loading", true, true, true},
+		{"should ignore cancel when nothing is loading", false, true, false},
+		{"should ignore cancel when loading without context", true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			a := &App{spinner: NewSimpleSpinner()}
+			var ctx = context.Background()
+			if tt.withContext {
+				ctx = a.LoadingContext()
+			}
+			a.LoadingWithText(tt.loading, "test")
+
+			// when
+			cancelled := a.CancelLoading()
+
+			// then
+			assert.Equal(t, tt.wantCancelled, cancelled)
+			assert.Equal(t, tt.wantCancelled, ctx.Err() != nil)
+			if tt.wantCancelled {
+				assert.False(t, a.IsLoading())
+				assert.NotEqual(t, ctx, a.LoadingContext())
+			}
+		})
+	}
+}
+
+func TestApp_SetGlobalKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          tcell.Key
+		wantHandled  bool
+		wantListener bool
+	}{
+		{"should run global key handler instead of the view", tcell.KeyF5, true, false},
+		{"should pass other keys to the view", tcell.KeyF6, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			screen := tcell.NewSimulationScreen("utf-8")
+			_ = screen.Init() //nolint:errcheck
+			a := &App{
+				screen:          screen,
+				keyEvent:        make(chan *tcell.EventKey),
+				runOnAppRoutine: make([]func(), 0, 64),
+				drawables:       make([]Drawable, 0, 256),
+				systems:         make([]System, 0, 128),
+				flash:           make([]Drawable, 0, 5),
+				keepAlive:       make(map[interface{}]bool),
+				dirty:           true,
+			}
+			handled := make(chan bool, 1)
+			a.SetGlobalKey(tcell.KeyF5, func() { handled <- true })
+			listener := &keyListenerMock{}
+			a.AddSystem(listener)
+			go a.processTerminalEvents()
+			defer a.Quit()
+
+			// when
+			screen.InjectKey(tt.key, 0, tcell.ModNone)
+			<-time.NewTimer(100 * time.Millisecond).C
+
+			// then
+			assert.Equal(t, tt.wantHandled, len(handled) == 1)
+			assert.Equal(t, tt.wantListener, listener.received.Load())
+		})
+	}
+}
+
+type keyListenerMock struct {
+	received atomic.Bool
+}
+
+func (k *keyListenerMock) Update() {}
+
+func (k *keyListenerMock) HandleKeyEvent(_ *tcell.EventKey) {
+	k.received.Store(true)
+}
@@ -27,12 +27,16 @@ func Color(c string) tcell.Color {
 func MustLoadColorScheme() map[string]interface{} {
 	d := os2.MustGetFjiraHomeDir()
 	p := fmt.Sprintf("%s/colors.yml", d)
+	defaults := parseYMLStr(defaultColorsYML())
 	b, err := os.ReadFile(p)
 	if err != nil {
-		schemeMap = parseYMLStr(defaultColorsYML())
+		schemeMap = defaults
 	} else {
 		schemeMap = parseYMLStr(string(b))
 	}
+	// the defaults go first, so a scheme written for an older fjira still
+	// has the colors added since
+	colorsMap = parseYamlToDotNotationMap("", defaults, colorsMap)
 	colorsMap = parseYamlToDotNotationMap("", schemeMap, colorsMap)
 	return schemeMap
 }
@@ -120,5 +124,20 @@ alerts:
   error:
     background: "#F5F5F5"
     foreground: "#8B0000"
+
+richtext:
+  heading: "#E9CE58"
+  link: "#87AFD7"
+  mention: "#90EE90"
+  quote: "#8A8A8A"
+  code:
+    background: "#262626"
+    foreground: "#D7AF87"
+  panel:
+    info: "#5F87AF"
+    note: "#AF87D7"
+    success: "#5F875f"
+    warning: "#E9CE58"
+    error: "#D75F5F"
 `
 }
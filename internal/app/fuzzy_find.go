@@ -2,12 +2,14 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/bep/debounce"
 	"github.com/gdamore/tcell/v2"
 	"github.com/sahilm/fuzzy"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
@@ -17,7 +19,7 @@ type FuzzyFind struct {
 	MarginBottom      int
 	Complete          chan FuzzyFindResult
 	records           []string
-	recordsProvider   func(query string) []string
+	recordsProvider   contextRecordsProvider
 	query             string
 	fuzzyStatus       string
 	title             string
@@ -33,6 +35,18 @@ type FuzzyFind struct {
 	disableFuzzyMatch bool
 	clearOnEsc        bool // when true, Esc with non-empty query clears it instead of completing with -1
 
+	// queryCancel cancels the context of the provider call for the previous
+	// query. Debounced provider calls run on their own goroutines, so a slow
+	// one could otherwise outlive (and overwrite) the results of a newer query.
+	queryCancel context.CancelFunc
+	queryMutex  sync.Mutex
+
+	// recordsMutex guards records, what's derived from them (matchesAll,
+	// matchTargets, targetMaps, dimmed) and dirty: providers and
+	// SetRangeRecords replace them from their own goroutines while the app
+	// routine matches and draws them.
+	recordsMutex sync.Mutex
+
 	// rangesProvider, when set, scopes fuzzy matching and highlighting to
 	// declared byte ranges of each record (see rangesProvider / MatchRange).
 	// matchTargets holds the projected match strings and targetMaps maps each
@@ -78,7 +92,11 @@ type MatchRange struct {
 // Returning them from a single call keeps everything strictly parallel, so
 // match.Index stays a valid row identity for selection. Dimmed records are drawn
 // in a muted style and sorted last (see the post-match partition in Update).
-type rangesProvider func(query string) (records []string, ranges [][]MatchRange, dimmed []bool)
+type rangesProvider func(ctx context.Context, query string) (records []string, ranges [][]MatchRange, dimmed []bool)
+
+// contextRecordsProvider is a records supplier whose context is cancelled as
+// soon as a newer query supersedes it.
+type contextRecordsProvider func(ctx context.Context, query string) []string
 
 // buildMatchTarget projects the matchable ranges of a display record into a
 // single string used for fuzzy matching, and returns a mapping from each byte
@@ -155,6 +173,19 @@ func NewFuzzyFind(title string, records []string) *FuzzyFind {
 }
 
 func NewFuzzyFindWithProvider(title string, recordsProvider func(query string) []string) *FuzzyFind {
+	if recordsProvider == nil {
+		return NewFuzzyFindWithContextProvider(title, nil)
+	}
+	return NewFuzzyFindWithContextProvider(title, func(_ context.Context, query string) []string {
+		return recordsProvider(query)
+	})
+}
+
+// NewFuzzyFindWithContextProvider is like NewFuzzyFindWithProvider, but the
+// provider receives a context that is cancelled when a newer query supersedes
+// the one being supplied. Providers that call the Jira API should thread it
+// through so stale searches are aborted instead of piling up.
+func NewFuzzyFindWithContextProvider(title string, recordsProvider func(ctx context.Context, query string) []string) *FuzzyFind {
 	highlightDefaultStyle := DefaultStyle().Foreground(Color("finder.highlight.foreground")).Background(Color("finder.highlight.background"))
 	return &FuzzyFind{
 		Complete:          make(chan FuzzyFindResult),
@@ -188,8 +219,16 @@ func NewFuzzyFindWithProvider(title string, recordsProvider func(query string) [
 // provider also returns, per record, the byte ranges that fuzzy matching and
 // highlighting are restricted to. Records and ranges come from one call so they
 // stay strictly parallel and match.Index remains a valid row identity.
-func NewFuzzyFindWithRangeProvider(title string, provider rangesProvider) *FuzzyFind {
-	f := NewFuzzyFindWithProvider(title, nil)
+func NewFuzzyFindWithRangeProvider(title string, provider func(query string) (records []string, ranges [][]MatchRange, dimmed []bool)) *FuzzyFind {
+	return NewFuzzyFindWithRangeContextProvider(title, func(_ context.Context, query string) ([]string, [][]MatchRange, []bool) {
+		return provider(query)
+	})
+}
+
+// NewFuzzyFindWithRangeContextProvider is the context-aware variant of
+// NewFuzzyFindWithRangeProvider; see NewFuzzyFindWithContextProvider.
+func NewFuzzyFindWithRangeContextProvider(title string, provider rangesProvider) *FuzzyFind {
+	f := NewFuzzyFindWithContextProvider(title, nil)
 	f.rangesProvider = provider
 	return f
 }
@@ -211,7 +250,10 @@ func (f *FuzzyFind) Draw(screen tcell.Screen) {
 }
 
 func (f *FuzzyFind) Update() {
-	if !f.dirty {
+	f.recordsMutex.Lock()
+	dirty := f.dirty
+	f.recordsMutex.Unlock()
+	if !dirty {
 		return
 	}
 	buff := f.buffer.String()
@@ -221,10 +263,14 @@ func (f *FuzzyFind) Update() {
 			f.updateRecordsFromSupplier()
 		} else {
 			f.supplierDebounce(f.updateRecordsFromSupplier)
+			f.recordsMutex.Lock()
 			f.dirty = false
+			f.recordsMutex.Unlock()
 			return
 		}
 	}
+	f.recordsMutex.Lock()
+	defer f.recordsMutex.Unlock()
 	f.query = buff
 	if len(f.query) == 0 || f.disableFuzzyMatch {
 		f.matches = f.matchesAll
@@ -296,7 +342,9 @@ func (f *FuzzyFind) HandleKeyEvent(ev *tcell.EventKey) {
 		f.Update()
 		if len(f.matches) > 0 && f.selected >= 0 {
 			match := f.matches[f.selected].Str
+			f.recordsMutex.Lock()
 			index := findSelectedRecord(match, f.records)
+			f.recordsMutex.Unlock()
 			f.Complete <- FuzzyFindResult{Index: index, Match: match}
 		} else {
 			f.Complete <- FuzzyFindResult{Index: -1, Match: ""}
@@ -353,6 +401,8 @@ func (f *FuzzyFind) AlwaysShowAllResults() {
 }
 
 func (f *FuzzyFind) GetSelectedItem() string {
+	f.recordsMutex.Lock()
+	defer f.recordsMutex.Unlock()
 	if len(f.records) == 0 {
 		return ""
 	}
@@ -396,6 +446,8 @@ func (f *FuzzyFind) remapMatchToDisplay(m fuzzy.Match) fuzzy.Match {
 }
 
 func (f *FuzzyFind) drawRecords(screen tcell.Screen) {
+	f.recordsMutex.Lock()
+	defer f.recordsMutex.Unlock()
 	matchesLen := f.matches.Len()
 	if matchesLen == 0 {
 		return
@@ -430,26 +482,70 @@ func (f *FuzzyFind) drawRecords(screen tcell.Screen) {
 	}
 }
 
+// beginQuery cancels the provider call for the previous query (if still in
+// flight) and returns the context for the next one.
+func (f *FuzzyFind) beginQuery() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f.queryMutex.Lock()
+	if f.queryCancel != nil {
+		f.queryCancel()
+	}
+	f.queryCancel = cancel
+	f.queryMutex.Unlock()
+	return ctx, cancel
+}
+
 func (f *FuzzyFind) updateRecordsFromSupplier() {
+	ctx, cancel := f.beginQuery()
+	defer cancel()
+	query := f.query
 	if f.rangesProvider != nil {
-		records, ranges, dimmed := f.rangesProvider(f.query)
-		f.records = records
-		f.dimmed = dimmed
-		f.matchTargets = make([]string, len(records))
-		f.targetMaps = make([][]int, len(records))
-		for i, record := range records {
-			var rr []MatchRange
-			if i < len(ranges) {
-				rr = ranges[i]
-			}
-			f.matchTargets[i], f.targetMaps[i] = buildMatchTarget(record, rr)
+		records, ranges, dimmed := f.rangesProvider(ctx, query)
+		if ctx.Err() != nil {
+			// superseded by a newer query - its results win
+			return
 		}
+		f.SetRangeRecords(records, ranges, dimmed)
 	} else {
-		f.records = f.recordsProvider(f.query)
+		records := f.recordsProvider(ctx, query)
+		if ctx.Err() != nil {
+			return
+		}
+		f.recordsMutex.Lock()
+		f.records = records
 		f.matchTargets = nil
 		f.targetMaps = nil
 		f.dimmed = nil
+		f.resetMatches()
+		f.recordsMutex.Unlock()
+		f.redraw()
 	}
+}
+
+// SetRangeRecords replaces the records of a range-provider finder without
+// calling the provider, e.g. when a provider keeps streaming results in after
+// it has returned the first page. The current query is re-applied on the next
+// Update. Safe to call from any goroutine.
+func (f *FuzzyFind) SetRangeRecords(records []string, ranges [][]MatchRange, dimmed []bool) {
+	f.recordsMutex.Lock()
+	defer f.redraw()
+	defer f.recordsMutex.Unlock()
+	f.records = records
+	f.dimmed = dimmed
+	f.matchTargets = make([]string, len(records))
+	f.targetMaps = make([][]int, len(records))
+	for i, record := range records {
+		var rr []MatchRange
+		if i < len(ranges) {
+			rr = ranges[i]
+		}
+		f.matchTargets[i], f.targetMaps[i] = buildMatchTarget(record, rr)
+	}
+	f.resetMatches()
+}
+
+// resetMatches must be called with recordsMutex held.
+func (f *FuzzyFind) resetMatches() {
 	f.matchesAll = nil
 	for i, record := range f.records {
 		f.matchesAll = append(f.matchesAll, fuzzy.Match{
@@ -457,7 +553,7 @@ func (f *FuzzyFind) updateRecordsFromSupplier() {
 			Index: i,
 		})
 	}
-	f.markAsDirty()
+	f.dirty = true
 }
 
 func (f *FuzzyFind) isEventWritable(ev *tcell.EventKey) bool {
@@ -468,7 +564,13 @@ func (f *FuzzyFind) isEventWritable(ev *tcell.EventKey) bool {
 }
 
 func (f *FuzzyFind) markAsDirty() {
+	f.recordsMutex.Lock()
 	f.dirty = true
+	f.recordsMutex.Unlock()
+	f.redraw()
+}
+
+func (f *FuzzyFind) redraw() {
 	// it couples fuzzyFinder with app ... which is not nice,
 	// but it's the easy way to make sure that app is re-rendered
 	// whenever fuzzy search is updated. Another solution would be to
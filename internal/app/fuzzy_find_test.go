@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
 	"strings"
@@ -104,3 +105,67 @@ func TestFuzzyFind_HandleKeyEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestFuzzyFind_newer_query_cancels_previous_provider_call(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	CreateNewAppWithScreen(screen)
+
+	// given
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	fuzzyFind := NewFuzzyFindWithContextProvider("test", func(ctx context.Context, query string) []string {
+		if query == "slow" {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return []string{"stale"}
+		}
+		return []string{"fresh"}
+	})
+	fuzzyFind.SetDebounceDisabled(true)
+
+	// when
+	fuzzyFind.SetQuery("slow")
+	go fuzzyFind.Update()
+	<-started
+	fuzzyFind.query = "fast"
+	fuzzyFind.updateRecordsFromSupplier()
+	<-cancelled
+
+	// then
+	assert.Equal(t, []string{"fresh"}, fuzzyFind.records)
+}
+
+func TestFuzzyFind_SetRangeRecords_while_updating(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	CreateNewAppWithScreen(screen)
+
+	// given
+	fuzzyFind := NewFuzzyFindWithRangeContextProvider("test", func(_ context.Context, _ string) ([]string, [][]MatchRange, []bool) {
+		return []string{"ABC-1 first"}, nil, nil
+	})
+	fuzzyFind.SetDebounceDisabled(true)
+	done := make(chan struct{})
+
+	// when
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			fuzzyFind.SetRangeRecords([]string{"ABC-1 first", "ABC-2 second"}, nil, []bool{false, true})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		fuzzyFind.ForceUpdate()
+		fuzzyFind.Draw(screen)
+	}
+	<-done
+	fuzzyFind.ForceUpdate()
+
+	// then
+	assert.Equal(t, "ABC-1 first", fuzzyFind.GetSelectedItem())
+	assert.Len(t, fuzzyFind.Matches(), 2)
+}
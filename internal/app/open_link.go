@@ -14,6 +14,11 @@ func OpenLink(url string) {
 	}
 }
 
+// TryOpenLink is OpenLink for when a missing browser isn't fatal.
+func TryOpenLink(url string) error {
+	return openLinkForSystem(runtime.GOOS, url)
+}
+
 func openLinkForSystem(system string, url string) error {
 	switch system {
 	case "linux":
@@ -7,6 +7,13 @@ type View interface {
 	Destroy()
 }
 
+// Reloadable is implemented by views F5 can open again with everything on
+// them fetched fresh. Views holding what the user is typing (forms, the text
+// writer) leave it out, so F5 doesn't throw it away.
+type Reloadable interface {
+	Reload()
+}
+
 type Drawable interface {
 	Draw(screen tcell.Screen)
 }
@@ -1,6 +1,8 @@
 package boards
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -193,6 +195,12 @@ func (b *boardView) Resize(screenX, screenY int) {
 func (b *boardView) Init() {
 	app.GetApp().Loading(true)
 	fetched, err := b.fetchIssues()
+	if errors.Is(err, context.Canceled) {
+		// loading aborted with Esc - keep the (empty) board navigable so the
+		// user can still back out of it
+		go b.handleActions()
+		return
+	}
 	if err != nil {
 		app.GetApp().Loading(false)
 		return
@@ -200,7 +208,15 @@ func (b *boardView) Init() {
 	if fetched == nil {
 		fetched = []jira.Issue{}
 	}
-	b.allIssues = fetched
+	b.showIssues(fetched)
+	app.GetApp().Loading(false)
+	go b.handleActions()
+}
+
+// showIssues lays out the given issues on the board. Called once per fetched
+// page while the board is loading, and with the full set at the end.
+func (b *boardView) showIssues(issues []jira.Issue) {
+	b.allIssues = issues
 	if b.assigneeFilter != nil {
 		// applyAssigneeFilter populates b.issues from b.allIssues and calls
 		// the refresh helpers itself.
@@ -213,8 +229,7 @@ func (b *boardView) Init() {
 		b.setInitialCursorX()
 		b.refreshHighlightedIssue()
 	}
-	app.GetApp().Loading(false)
-	go b.handleActions()
+	app.GetApp().SetDirty()
 }
 
 func (b *boardView) Destroy() {
@@ -302,13 +317,15 @@ func (b *boardView) SetSprints(sprints []jira.SprintItem) {
 
 func (b *boardView) fetchIssues() ([]jira.Issue, error) {
 	app.GetApp().Loading(true)
+	// Esc while the board is loading aborts the remaining pages
+	api := b.api.WithContext(app.GetApp().LoadingContext())
 	issues := make([]jira.Issue, 0, maxIssuesNumber)
 	page := int32(0)
 	var iss []jira.Issue
 	var total int32
 	var err error
 	if b.activeSprint == nil && b.filterJQL == "" && b.boardConfiguration != nil && b.boardConfiguration.Filter.Id != "" {
-		filter, ferr := b.api.GetFilter(b.boardConfiguration.Filter.Id)
+		filter, ferr := api.GetFilter(b.boardConfiguration.Filter.Id)
 		if ferr != nil {
 			app.GetApp().Loading(false)
 			app.Error(ferr.Error())
@@ -316,11 +333,32 @@ func (b *boardView) fetchIssues() ([]jira.Issue, error) {
 		}
 		b.filterJQL = filter.JQL
 	}
-	for len(b.issues) < maxIssuesNumber {
-		if b.activeSprint == nil {
-			iss, total, _, err = b.api.SearchJqlPageable(b.filterJQL, page, issueFetchBatchSize)
-		} else {
-			iss, total, _, err = b.api.GetBoardSprintIssues(b.boardConfiguration.Id, b.activeSprint.Id, page, issueFetchBatchSize)
+	if b.activeSprint == nil {
+		// stream the board filter page by page, so columns fill in while the
+		// rest is still loading
+		for iss, err = range api.SearchJqlPages(b.filterJQL, issueFetchBatchSize) {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			if err != nil {
+				app.GetApp().Loading(false)
+				app.Error(err.Error())
+				return nil, err
+			}
+			issues = append(issues, iss...)
+			if len(issues) >= maxIssuesNumber {
+				issues = issues[:maxIssuesNumber]
+				break
+			}
+			b.showIssues(issues)
+		}
+		app.GetApp().Loading(false)
+		return issues, nil
+	}
+	for len(issues) < maxIssuesNumber {
+		iss, total, _, err = api.GetBoardSprintIssues(b.boardConfiguration.Id, b.activeSprint.Id, page, issueFetchBatchSize)
+		if errors.Is(err, context.Canceled) {
+			return nil, err
 		}
 		if err != nil {
 			app.GetApp().Loading(false)
@@ -469,15 +507,12 @@ func (b *boardView) handleActions() {
 				b.runSelectAssigneeFilter()
 				return
 			case ui.ActionCreateIssue:
-				projectId := ""
+				projectKey := ""
 				if b.project != nil {
-					projectId = b.project.Id
-				}
-				boardId := 0
-				if b.boardConfiguration != nil {
-					boardId = b.boardConfiguration.Id
+					projectKey = b.project.Key
 				}
-				ui.OpenCreateIssueInBrowser(b.api, projectId, boardId)
+				app.GoTo("issue-create", projectKey, b.reopen, b.api)
+				return
 			case ui.ActionCancel:
 				if b.goBackFn != nil {
 					b.goBackFn()
@@ -498,6 +533,12 @@ func (b *boardView) handleActions() {
 	}
 }
 
+// Reload opens the board again, fetching its configuration and issues fresh.
+func (b *boardView) Reload() {
+	board := &jira.BoardItem{Id: b.boardConfiguration.Id, Name: b.boardConfiguration.Name, Type: b.boardConfiguration.Type}
+	app.GoTo("boards", b.project, board, b.goBackFn, b.api)
+}
+
 func (b *boardView) reopen() {
 	app.GetApp().SetView(b)
 }
@@ -600,7 +641,15 @@ func (b *boardView) moveIssue(issue *jira.Issue, direction int) {
 		app.Error(ui.MessageCannotFindStatusForColumn)
 		return
 	}
-	err = b.api.DoTransition(issue.Id, targetTransition)
+	if targetTransition.RequiresInput() {
+		// e.g. a resolution for Done - asked for on the transition's screen,
+		// the board is fetched again when it's back
+		app.GetApp().Loading(false)
+		b.issueSelected = false
+		app.GoTo("issue-transition", issue, targetTransition, b.reopen, b.api)
+		return
+	}
+	err = b.api.DoTransition(issue.Id, targetTransition, nil)
 	if err != nil {
 		app.GetApp().Loading(false)
 		app.Error(err.Error())
@@ -503,3 +503,28 @@ func Test_boardView_fetchIssues_WithActiveSprint_UsesSprintIssues(t *testing.T)
 	assert.Equal(t, "S1", issues[0].Key)
 	assert.Equal(t, "S2", issues[1].Key)
 }
+
+func Test_boardView_fetchIssues_WithoutSprint_StreamsAllPages(t *testing.T) {
+	app.InitTestApp(nil)
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		if r.URL.Query().Get("nextPageToken") == "" {
+			_, _ = w.Write([]byte(`{"issues":[{"id":"10001","key":"K1"}],"nextPageToken":"p2","isLast":false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"issues":[{"id":"10002","key":"K2"}],"isLast":true}`))
+	})
+
+	view := NewBoardView(&jira.Project{}, &jira.BoardConfiguration{}, "project = GEN", api).(*boardView)
+
+	// when
+	issues, err := view.fetchIssues()
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "K2", issues[1].Key)
+	// pages are laid out on the board as they arrive, before Init gets the result
+	assert.Len(t, view.issues, 2)
+	assert.Equal(t, 1, view.issuesRow["10002"]-view.issuesRow["10001"])
+}
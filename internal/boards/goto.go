@@ -24,8 +24,9 @@ func RegisterGoTo() {
 
 		defer app.GetApp().PanicRecover()
 		app.GetApp().Loading(true)
+		loadingApi := api.WithContext(app.GetApp().LoadingContext())
 		if boardConfig == nil {
-			bc, err := api.GetBoardConfiguration(board.Id)
+			bc, err := loadingApi.GetBoardConfiguration(board.Id)
 			if err != nil {
 				app.GetApp().Loading(false)
 				app.Error(err.Error())
@@ -35,7 +36,7 @@ func RegisterGoTo() {
 		}
 		var sprints []jira.SprintItem
 		if boardConfig.Type == "scrum" {
-			s, err := api.GetBoardSprints(boardConfig.Id)
+			s, err := loadingApi.GetBoardSprints(boardConfig.Id)
 			if err != nil {
 				app.GetApp().Loading(false)
 				app.Error(err.Error())
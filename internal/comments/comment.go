@@ -1,7 +1,11 @@
 package comments
 
+import "github.com/mk-5/fjira/internal/richtext"
+
 type Comment struct {
 	Body  string
 	Title string
 	Lines int
+	// Rich is the laid out body, see RenderBody.
+	Rich []richtext.Line
 }
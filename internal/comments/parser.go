@@ -1,36 +1,62 @@
 package comments
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
+	"github.com/mk-5/fjira/internal/adf"
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/richtext"
+	"github.com/mk-5/fjira/internal/wiki"
 	"time"
 )
 
-// TODO - could be optimized a bit
-func ParseCommentsFromIssue(issue *jira.Issue, limitX, limitY int) []Comment {
+// ParseCommentsFromIssue lays the comments out for limitX cells. Their links
+// are numbered by renderer, on from the ones of the description.
+func ParseCommentsFromIssue(issue *jira.Issue, limitX int, renderer *wiki.Renderer) []Comment {
 	cs := make([]Comment, 0, 100)
-	var commentsBuffer bytes.Buffer
 	now := time.Now()
-	if len(issue.Fields.Comment.Comments) > 0 {
-		for _, comment := range issue.Fields.Comment.Comments {
-			// Prefer a friendly relative time ("2 hours ago"); fall back to the
-			// raw timestamp if it can't be parsed so the date is never blank.
-			created := app.FormatRelativeTime(comment.Created, now)
-			if created == "" {
-				created = comment.Created
-			}
-			title := fmt.Sprintf("%s, %s", created, comment.Author.DisplayName)
-			body := fmt.Sprintf("\n%s", comment.Body)
-			lines := app.DrawTextLimited(nil, 0, 0, limitX, limitY, app.DefaultStyle(), comment.Body) + 2
-			cs = append(cs, Comment{
-				Title: title,
-				Body:  body,
-				Lines: lines,
-			})
-			commentsBuffer.Reset()
-		}
+	for _, comment := range issue.Fields.Comment.Comments {
+		rich := RenderBody(comment.Body, comment.BodyAdf, limitX, renderer)
+		cs = append(cs, Comment{
+			Title: Title(comment, now),
+			Body:  fmt.Sprintf("\n%s", comment.Body),
+			// one blank line above the body
+			Lines: len(rich) + 1,
+			Rich:  rich,
+		})
 	}
 	return cs
 }
+
+// Title is "2 hours ago, Alice", followed by who sees the comment when it's
+// restricted to a role or group.
+func Title(comment jira.Comment, now time.Time) string {
+	// Prefer a friendly relative time ("2 hours ago"); fall back to the
+	// raw timestamp if it can't be parsed so the date is never blank.
+	created := app.FormatRelativeTime(comment.Created, now)
+	if created == "" {
+		created = comment.Created
+	}
+	title := fmt.Sprintf("%s, %s", created, comment.Author.DisplayName)
+	if comment.Visibility != nil {
+		title = fmt.Sprintf("%s (visible to %s)", title, comment.Visibility.Value)
+	}
+	return title
+}
+
+// RenderBody lays out a description or comment: the document when it was sent
+// as one (Cloud), the wiki markup of text otherwise. Never empty, so there's
+// always a line to draw.
+func RenderBody(text string, doc json.RawMessage, width int, renderer *wiki.Renderer) []richtext.Line {
+	var lines []richtext.Line
+	if node, err := adf.Parse(doc); len(doc) > 0 && err == nil {
+		lines = adf.Render(node, width)
+	} else {
+		lines = renderer.Render(text, width)
+	}
+	if len(lines) == 0 {
+		lines = []richtext.Line{{}}
+	}
+	return lines
+}
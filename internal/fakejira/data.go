@@ -0,0 +1,458 @@
+package fakejira
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Status categories, as in Jira's statusCategory.key.
+const (
+	CategoryNew        = "new"
+	CategoryInProgress = "indeterminate"
+	CategoryDone       = "done"
+)
+
+const (
+	sprintStateActive     = "active"
+	sprintStateFuture     = "future"
+	sprintStateClosed     = "closed"
+	boardTypeScrum        = "scrum"
+	boardTypeKanban       = "kanban"
+	defaultPriority       = "Medium"
+	defaultIssueType      = "Task"
+	subtaskIssueType      = "Sub-task"
+	epicIssueType         = "Epic"
+	firstIssueId          = 10000
+	firstCommentId        = 20000
+	firstWorklogId        = 30000
+	firstAttachmentId     = 40000
+	firstLinkId           = 50000
+	demoCurrentUser       = "712020:alice"
+	demoProjectKey        = "FJ"
+	demoOpsProjectKey     = "OPS"
+	demoScrumBoardId      = 1
+	demoKanbanBoardId     = 2
+	demoActiveSprintId    = 11
+	demoFutureSprintId    = 12
+	demoKanbanFilterId    = "10100"
+	demoOpenBugsFilterId  = "10101"
+	demoMyIssuesFilterId  = "10102"
+	demoSprintDays        = 14
+	demoSprintStartedDays = 4
+)
+
+type User struct {
+	// AccountId identifies users on Cloud, Name (and Key) on Server/DC.
+	AccountId   string
+	Name        string
+	DisplayName string
+	Email       string
+}
+
+type Project struct {
+	Id   string
+	Key  string
+	Name string
+}
+
+type Status struct {
+	Id       string
+	Name     string
+	Category string
+}
+
+// Transition moves an issue to the To status; every transition whose target
+// isn't the current status is available, like a simple "all to all" workflow.
+type Transition struct {
+	Id   string
+	Name string
+	To   string
+	// Resolution is set for transitions whose screen requires a resolution,
+	// like Done on strict workflows.
+	Resolution bool
+}
+
+type Comment struct {
+	Id      string
+	Author  string
+	Body    string
+	Created time.Time
+	// Visibility restricts the comment to a role or group; nil for everyone.
+	Visibility *Visibility
+}
+
+// Visibility is a project role - Type "role" - or a group - Type "group" -
+// by name.
+type Visibility struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Worklog is time logged on an issue; Author is an account id.
+type Worklog struct {
+	Id               string
+	Author           string
+	TimeSpentSeconds int
+	Started          time.Time
+}
+
+// Attachment is a file attached to an issue; Author is an account id.
+type Attachment struct {
+	Id       string
+	Filename string
+	Author   string
+	MimeType string
+	Content  []byte
+	Created  time.Time
+}
+
+// LinkType is a kind of link between issues, like Blocks: the inward issue
+// blocks the outward one, which is blocked by it.
+type LinkType struct {
+	Id      string
+	Name    string
+	Inward  string
+	Outward string
+}
+
+// Link links the issues with the keys Inward and Outward, see LinkType.
+type Link struct {
+	Id      string
+	Type    string
+	Inward  string
+	Outward string
+}
+
+type Issue struct {
+	Id          string
+	Key         string
+	ProjectKey  string
+	Type        string
+	Summary     string
+	Description string
+	Priority    string
+	// DueDate is a date like "2024-05-31", empty when unset.
+	DueDate string
+	// Status is a status id; Assignee and Reporter are account ids.
+	Status   string
+	Assignee string
+	Reporter string
+	Labels   []string
+	// Resolution is the name of the resolution, set by transitions that ask
+	// for it.
+	Resolution string
+	// Parent is the key of the epic or of the parent of a sub-task.
+	Parent      string
+	Sprint      int
+	Created     time.Time
+	Updated     time.Time
+	Comments    []Comment
+	Worklogs    []Worklog
+	Attachments []Attachment
+}
+
+type Column struct {
+	Name     string
+	Statuses []string
+}
+
+type Board struct {
+	Id         int
+	Name       string
+	Type       string
+	ProjectKey string
+	FilterId   string
+	Columns    []Column
+}
+
+type Sprint struct {
+	Id      int
+	BoardId int
+	Name    string
+	State   string
+	Goal    string
+	Start   *time.Time
+	End     *time.Time
+}
+
+type Filter struct {
+	Id        string
+	Name      string
+	Jql       string
+	Favourite bool
+}
+
+// Data is everything the fake Jira knows. Issues are kept in rank order.
+type Data struct {
+	Users      []User
+	Projects   []Project
+	Statuses   []Status
+	Workflow   []Transition
+	Priorities []string
+	IssueTypes []string
+	Issues     []*Issue
+	Boards     []Board
+	Sprints    []Sprint
+	Filters    []Filter
+	LinkTypes  []LinkType
+	Links      []Link
+	// Roles are the project roles, the same in every project.
+	Roles  []string
+	Groups []string
+	// CurrentUser is the account id of the user the requests are made as.
+	CurrentUser string
+}
+
+// DemoData seeds two projects with a handful of people, an epic with its
+// stories and sub-tasks, a scrum board with an active sprint, a kanban board
+// and a few filters - enough to click through every fjira screen.
+func DemoData(now time.Time) *Data {
+	d := &Data{
+		Users: []User{
+			{AccountId: demoCurrentUser, Name: "alice", DisplayName: "Alice Andersen", Email: "alice@example.com"},
+			{AccountId: "712020:bob", Name: "bob", DisplayName: "Bob Baker", Email: "bob@example.com"},
+			{AccountId: "712020:carol", Name: "carol", DisplayName: "Carol Chen", Email: "carol@example.com"},
+			{AccountId: "712020:dave", Name: "dave", DisplayName: "Dave Dvořák", Email: "dave@example.com"},
+		},
+		Projects: []Project{
+			{Id: "10000", Key: demoProjectKey, Name: "Fjira Demo"},
+			{Id: "10001", Key: demoOpsProjectKey, Name: "Operations"},
+		},
+		Statuses: []Status{
+			{Id: "1", Name: "To Do", Category: CategoryNew},
+			{Id: "3", Name: "In Progress", Category: CategoryInProgress},
+			{Id: "4", Name: "In Review", Category: CategoryInProgress},
+			{Id: "5", Name: "Done", Category: CategoryDone},
+		},
+		Workflow: []Transition{
+			{Id: "11", Name: "To Do", To: "1"},
+			{Id: "21", Name: "Start progress", To: "3"},
+			{Id: "31", Name: "Ready for review", To: "4"},
+			{Id: "41", Name: "Done", To: "5", Resolution: true},
+		},
+		Priorities: []string{"Highest", "High", defaultPriority, "Low", "Lowest"},
+		IssueTypes: []string{defaultIssueType, "Story", "Bug", epicIssueType, subtaskIssueType},
+		LinkTypes: []LinkType{
+			{Id: "10000", Name: "Blocks", Inward: "is blocked by", Outward: "blocks"},
+			{Id: "10001", Name: "Cloners", Inward: "is cloned by", Outward: "clones"},
+			{Id: "10002", Name: "Duplicate", Inward: "is duplicated by", Outward: "duplicates"},
+			{Id: "10003", Name: "Relates", Inward: "relates to", Outward: "relates to"},
+		},
+		Roles:       []string{"Administrators", "Developers", "Service Desk Team"},
+		Groups:      []string{"jira-administrators", "jira-software-users", "support"},
+		CurrentUser: demoCurrentUser,
+	}
+	ago := func(days int) time.Time {
+		return now.Add(-time.Duration(days) * 24 * time.Hour)
+	}
+	add := func(project string, issue Issue) *Issue {
+		issue.ProjectKey = project
+		if issue.Type == "" {
+			issue.Type = defaultIssueType
+		}
+		if issue.Priority == "" {
+			issue.Priority = defaultPriority
+		}
+		if issue.Reporter == "" {
+			issue.Reporter = demoCurrentUser
+		}
+		if issue.Updated.IsZero() {
+			issue.Updated = issue.Created
+		}
+		return d.addIssue(issue)
+	}
+	epic := add(demoProjectKey, Issue{Type: epicIssueType, Summary: "Offline-first issue browsing", Status: "3", Assignee: demoCurrentUser,
+		Description: "Everything needed to use fjira on a plane.", Created: ago(40), Updated: ago(2)})
+	add(demoProjectKey, Issue{Type: "Story", Summary: "Keep a local snapshot of opened issues", Status: "5", Assignee: demoCurrentUser, Parent: epic.Key,
+		Sprint: demoActiveSprintId, Labels: []string{"offline"}, Created: ago(30), Updated: ago(3),
+		Comments: []Comment{{Author: "712020:bob", Body: "Snapshot file is readable by the owner only, nice.", Created: ago(3)}},
+		Worklogs: []Worklog{{Author: demoCurrentUser, TimeSpentSeconds: 3 * 3600, Started: ago(5)}, {Author: "712020:bob", TimeSpentSeconds: 5400, Started: ago(3)}}})
+	story := add(demoProjectKey, Issue{Type: "Story", Summary: "Queue comments made while offline", Status: "3", Assignee: "712020:bob", Parent: epic.Key,
+		Sprint: demoActiveSprintId, Labels: []string{"offline"}, Priority: "High", Created: ago(28), Updated: ago(1),
+		Description: "Comments and transitions made offline are replayed on the next online start."})
+	add(demoProjectKey, Issue{Type: subtaskIssueType, Summary: "Report conflicts on replay", Status: "1", Assignee: "712020:bob", Parent: story.Key,
+		Sprint: demoActiveSprintId, Created: ago(20)})
+	add(demoProjectKey, Issue{Type: subtaskIssueType, Summary: "Write conflicts next to the snapshot", Status: "4", Assignee: "712020:carol", Parent: story.Key,
+		Sprint: demoActiveSprintId, Created: ago(20), Updated: ago(1)})
+	add(demoProjectKey, Issue{Type: "Bug", Summary: "Board columns flicker while streaming", Status: "4", Assignee: "712020:carol", Priority: "Highest",
+		Sprint: demoActiveSprintId, Labels: []string{"ui", "boards"}, Created: ago(12), Updated: ago(0),
+		Description: "Steps to reproduce:\n1. Open a board with 500 issues\n2. Watch the first column",
+		Comments: []Comment{
+			{Author: demoCurrentUser, Body: "I can reproduce it on a slow connection.", Created: ago(10)},
+			{Author: "712020:carol", Body: "Fix is up for review.", Created: ago(1)},
+			{Author: demoCurrentUser, Body: "Customer confirmed the fix on staging.", Created: ago(0),
+				Visibility: &Visibility{Type: "role", Value: "Service Desk Team"}},
+		},
+		Attachments: []Attachment{{Filename: "board.log", Author: "712020:carol", MimeType: "text/plain", Created: ago(10),
+			Content: []byte("12:00:01 render column 1\n12:00:01 render column 1\n12:00:02 render column 1\n")}}})
+	add(demoProjectKey, Issue{Type: "Bug", Summary: "Esc doesn't cancel a slow search", Status: "5", Assignee: "712020:dave", Priority: "High",
+		Sprint: demoActiveSprintId, Labels: []string{"ui"}, Created: ago(15), Updated: ago(6)})
+	add(demoProjectKey, Issue{Summary: "Retry rate-limited requests", Status: "1", Sprint: demoActiveSprintId, Labels: []string{"api"}, Created: ago(9)})
+	add(demoProjectKey, Issue{Summary: "Detect Jira Server vs Cloud", Status: "1", Assignee: "712020:dave", Sprint: demoFutureSprintId,
+		Labels: []string{"api"}, Created: ago(8)})
+	add(demoProjectKey, Issue{Type: "Story", Summary: "Render Atlassian Document Format", Status: "1", Sprint: demoFutureSprintId, Created: ago(7)})
+	add(demoProjectKey, Issue{Type: "Story", Summary: "Edit issues in $EDITOR", Status: "1", Priority: "Low", Created: ago(6)})
+	add(demoProjectKey, Issue{Type: "Bug", Summary: "Long summaries overflow the top bar", Status: "1", Priority: "Lowest", Labels: []string{"ui"}, Created: ago(5)})
+	add(demoProjectKey, Issue{Summary: "Document the --demo mode", Status: "3", Assignee: demoCurrentUser, Labels: []string{"docs"}, Created: ago(2), Updated: ago(0)})
+	add(demoOpsProjectKey, Issue{Summary: "Rotate the staging API tokens", Status: "1", Assignee: "712020:dave", Priority: "High", Labels: []string{"security"}, Created: ago(4)})
+	add(demoOpsProjectKey, Issue{Type: "Bug", Summary: "Nightly backup job times out", Status: "3", Assignee: demoCurrentUser, Priority: "Highest", Created: ago(3), Updated: ago(0)})
+	add(demoOpsProjectKey, Issue{Summary: "Upgrade the Jira test instance", Status: "5", Assignee: "712020:bob", Created: ago(25), Updated: ago(21)})
+	add(demoOpsProjectKey, Issue{Summary: "Move CI runners to arm64", Status: "4", Assignee: "712020:carol", Created: ago(11), Updated: ago(2)})
+	d.addLink("Blocks", "FJ-9", "FJ-10")
+	d.addLink("Relates", "FJ-7", "FJ-6")
+
+	sprintStart := ago(demoSprintStartedDays)
+	sprintEnd := sprintStart.Add(demoSprintDays * 24 * time.Hour)
+	nextEnd := sprintEnd.Add(demoSprintDays * 24 * time.Hour)
+	d.Sprints = []Sprint{
+		{Id: demoActiveSprintId, BoardId: demoScrumBoardId, Name: "FJ Sprint 7", State: sprintStateActive, Goal: "Ship offline mode", Start: &sprintStart, End: &sprintEnd},
+		{Id: demoFutureSprintId, BoardId: demoScrumBoardId, Name: "FJ Sprint 8", State: sprintStateFuture, Start: &sprintEnd, End: &nextEnd},
+	}
+	columns := []Column{
+		{Name: "To Do", Statuses: []string{"1"}},
+		{Name: "In Progress", Statuses: []string{"3"}},
+		{Name: "Review", Statuses: []string{"4"}},
+		{Name: "Done", Statuses: []string{"5"}},
+	}
+	d.Boards = []Board{
+		{Id: demoScrumBoardId, Name: "FJ Scrum", Type: boardTypeScrum, ProjectKey: demoProjectKey, FilterId: demoOpenBugsFilterId, Columns: columns},
+		{Id: demoKanbanBoardId, Name: "OPS Kanban", Type: boardTypeKanban, ProjectKey: demoOpsProjectKey, FilterId: demoKanbanFilterId, Columns: columns},
+	}
+	d.Filters = []Filter{
+		{Id: demoKanbanFilterId, Name: "OPS board", Jql: fmt.Sprintf("project = %s ORDER BY rank", demoOpsProjectKey)},
+		{Id: demoOpenBugsFilterId, Name: "Open bugs", Jql: "type = Bug AND statusCategory != Done ORDER BY priority", Favourite: true},
+		{Id: demoMyIssuesFilterId, Name: "My open issues", Jql: "assignee = currentUser() AND resolution = Unresolved ORDER BY updated DESC", Favourite: true},
+	}
+	return d
+}
+
+// addIssue assigns the next id and project key number.
+func (d *Data) addIssue(issue Issue) *Issue {
+	issue.Id = fmt.Sprint(firstIssueId + len(d.Issues))
+	number := 1
+	for _, other := range d.Issues {
+		if other.ProjectKey == issue.ProjectKey {
+			number++
+		}
+	}
+	issue.Key = fmt.Sprintf("%s-%d", issue.ProjectKey, number)
+	for i := range issue.Comments {
+		issue.Comments[i].Id = fmt.Sprint(firstCommentId + i + 100*len(d.Issues))
+	}
+	for i := range issue.Worklogs {
+		issue.Worklogs[i].Id = fmt.Sprint(firstWorklogId + i + 100*len(d.Issues))
+	}
+	for i := range issue.Attachments {
+		issue.Attachments[i].Id = fmt.Sprint(firstAttachmentId + i + 100*len(d.Issues))
+	}
+	d.Issues = append(d.Issues, &issue)
+	return &issue
+}
+
+// addLink links the issues with the next link id; ids of removed links
+// aren't reused.
+func (d *Data) addLink(linkType string, inward string, outward string) *Link {
+	id := firstLinkId
+	for _, link := range d.Links {
+		if n, err := strconv.Atoi(link.Id); err == nil && n >= id {
+			id = n + 1
+		}
+	}
+	d.Links = append(d.Links, Link{Id: strconv.Itoa(id), Type: linkType, Inward: inward, Outward: outward})
+	return &d.Links[len(d.Links)-1]
+}
+
+func (d *Data) linkType(name string) *LinkType {
+	for i := range d.LinkTypes {
+		if d.LinkTypes[i].Name == name || d.LinkTypes[i].Id == name {
+			return &d.LinkTypes[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) issue(keyOrId string) *Issue {
+	for _, issue := range d.Issues {
+		if issue.Key == keyOrId || issue.Id == keyOrId {
+			return issue
+		}
+	}
+	return nil
+}
+
+func (d *Data) attachment(id string) *Attachment {
+	for _, issue := range d.Issues {
+		for i := range issue.Attachments {
+			if issue.Attachments[i].Id == id {
+				return &issue.Attachments[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Data) project(keyOrId string) *Project {
+	for i, project := range d.Projects {
+		if project.Key == keyOrId || project.Id == keyOrId {
+			return &d.Projects[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) status(id string) *Status {
+	for i, status := range d.Statuses {
+		if status.Id == id {
+			return &d.Statuses[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) user(accountIdOrName string) *User {
+	for i, user := range d.Users {
+		if user.AccountId == accountIdOrName || user.Name == accountIdOrName {
+			return &d.Users[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) board(id int) *Board {
+	for i, board := range d.Boards {
+		if board.Id == id {
+			return &d.Boards[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) filter(id string) *Filter {
+	for i, filter := range d.Filters {
+		if filter.Id == id {
+			return &d.Filters[i]
+		}
+	}
+	return nil
+}
+
+func (d *Data) sprint(id int) *Sprint {
+	for i, sprint := range d.Sprints {
+		if sprint.Id == id {
+			return &d.Sprints[i]
+		}
+	}
+	return nil
+}
+
+// transitions are the workflow transitions leading away from the issue's status.
+func (d *Data) transitions(issue *Issue) []Transition {
+	transitions := make([]Transition, 0, len(d.Workflow))
+	for _, t := range d.Workflow {
+		if t.To != issue.Status {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions
+}
@@ -0,0 +1,1599 @@
+// Package fakejira is an in-process Jira speaking the REST endpoints fjira
+// uses, backed by an in-memory Data set. It's what `fjira --demo` runs
+// against, and what tests use when a mocked single response isn't enough.
+package fakejira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mk-5/fjira/internal/adf"
+	"github.com/mk-5/fjira/internal/jira"
+)
+
+const (
+	jiraTimeFormat    = "2006-01-02T15:04:05.000-0700"
+	defaultMaxResults = 50
+	rankCustomFieldId = 10019
+	epicLinkField     = "customfield_10014"
+)
+
+// Server is safe for concurrent use; every request sees and changes the
+// same Data, so a comment posted through it shows up on the next fetch.
+type Server struct {
+	mu         sync.Mutex
+	data       *Data
+	deployment jira.DeploymentType
+	now        func() time.Time
+	mux        *http.ServeMux
+	http       *http.Server
+	// mediaUrl is where attachment downloads are redirected, see
+	// RedirectAttachments.
+	mediaUrl string
+}
+
+// New serves data as a Jira of the given deployment - users, search and
+// assignee endpoints differ between Cloud and Server/DC.
+func New(data *Data, deployment jira.DeploymentType) *Server {
+	s := &Server{data: data, deployment: deployment, now: time.Now, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+// NewDemo serves DemoData as Jira Cloud.
+func NewDemo() *Server {
+	return New(DemoData(time.Now()), jira.DeploymentCloud)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /rest/api/2/serverInfo", s.serverInfo)
+	if s.isServer() {
+		s.mux.HandleFunc("GET /rest/api/2/search", s.searchStartAt)
+	} else {
+		s.mux.HandleFunc("GET /rest/api/3/search/jql", s.searchNextPageToken)
+		s.mux.HandleFunc("GET /rest/api/2/search", s.searchRemoved)
+		s.mux.HandleFunc("GET /rest/api/3/issue/{key}", s.getIssue)
+		s.mux.HandleFunc("PUT /rest/api/3/issue/{key}", s.editIssue)
+		s.mux.HandleFunc("POST /rest/api/3/issue/{key}/comment", s.addComment)
+		s.mux.HandleFunc("PUT /rest/api/3/issue/{key}/comment/{id}", s.updateComment)
+	}
+	s.mux.HandleFunc("GET /rest/api/2/issue/createmeta", s.getCreateMeta)
+	s.mux.HandleFunc("POST /rest/api/2/issue", s.createIssue)
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}", s.getIssue)
+	s.mux.HandleFunc("PUT /rest/api/2/issue/{key}", s.editIssue)
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}/editmeta", s.getEditMeta)
+	s.mux.HandleFunc("PUT /rest/api/2/issue/{key}/assignee", s.assignIssue)
+	s.mux.HandleFunc("POST /rest/api/2/issue/{key}/comment", s.addComment)
+	s.mux.HandleFunc("PUT /rest/api/2/issue/{key}/comment/{id}", s.updateComment)
+	s.mux.HandleFunc("DELETE /rest/api/2/issue/{key}/comment/{id}", s.deleteComment)
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}/transitions", s.getTransitions)
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}/worklog", s.getWorklogs)
+	s.mux.HandleFunc("POST /rest/api/2/issue/{key}/worklog", s.addWorklog)
+	s.mux.HandleFunc("POST /rest/api/2/issue/{key}/attachments", s.addAttachments)
+	s.mux.HandleFunc("GET /rest/api/2/attachment/content/{id}", s.getAttachmentContent)
+	s.mux.HandleFunc("GET /rest/api/2/issueLinkType", s.getLinkTypes)
+	s.mux.HandleFunc("POST /rest/api/2/issueLink", s.addLink)
+	s.mux.HandleFunc("DELETE /rest/api/2/issueLink/{id}", s.deleteLink)
+	s.mux.HandleFunc("POST /rest/api/2/issue/{key}/transitions", s.doTransition)
+	s.mux.HandleFunc("GET /rest/api/3/project/search", s.searchProjects)
+	s.mux.HandleFunc("GET /rest/api/3/project/{key}", s.getProject)
+	s.mux.HandleFunc("GET /rest/api/2/project/{key}/statuses", s.getProjectStatuses)
+	s.mux.HandleFunc("GET /rest/api/2/project/{key}/role", s.getProjectRoles)
+	s.mux.HandleFunc("GET /rest/api/2/user/assignable/search", s.findAssignableUsers)
+	s.mux.HandleFunc("GET /rest/api/2/myself", s.getMyself)
+	s.mux.HandleFunc("GET /rest/api/2/groups/picker", s.findGroups)
+	s.mux.HandleFunc("GET /rest/api/1.0/labels/suggest", s.suggestLabels)
+	s.mux.HandleFunc("GET /rest/api/1.0/labels/{id}/suggest", s.suggestLabels)
+	s.mux.HandleFunc("GET /rest/agile/1.0/board", s.findBoards)
+	s.mux.HandleFunc("GET /rest/agile/1.0/board/{id}/configuration", s.getBoardConfiguration)
+	s.mux.HandleFunc("GET /rest/agile/1.0/board/{id}/sprint", s.getBoardSprints)
+	s.mux.HandleFunc("GET /rest/agile/1.0/board/{id}/sprint/{sprintId}/issue", s.getSprintIssues)
+	s.mux.HandleFunc("GET /rest/agile/1.0/board/{id}/project", s.getBoardProjects)
+	s.mux.HandleFunc("GET /rest/api/2/filter/my", s.getMyFilters)
+	s.mux.HandleFunc("GET /rest/api/2/filter/favourite", s.getFavouriteFilters)
+	s.mux.HandleFunc("GET /rest/api/2/filter/{id}", s.getFilter)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start serves on a random local port and returns the url to point fjira at.
+func (s *Server) Start() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.http = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = s.http.Serve(listener)
+	}()
+	return "http://" + listener.Addr().String(), nil
+}
+
+func (s *Server) Close() error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Close()
+}
+
+func (s *Server) isServer() bool {
+	return s.deployment == jira.DeploymentServer || s.deployment == jira.DeploymentDataCenter
+}
+
+func (s *Server) serverInfo(w http.ResponseWriter, r *http.Request) {
+	info := jira.ServerInfo{
+		BaseUrl:        baseUrl(r),
+		Version:        "1001.0.0-SNAPSHOT",
+		VersionNumbers: []int{1001, 0, 0},
+		DeploymentType: jira.DeploymentCloud,
+		BuildNumber:    100279,
+		ServerTitle:    "Fjira Demo",
+	}
+	if s.isServer() {
+		info.Version = "9.12.2"
+		info.VersionNumbers = []int{9, 12, 2}
+		info.DeploymentType = s.deployment
+		info.BuildNumber = 9120002
+	}
+	writeJson(w, http.StatusOK, info)
+}
+
+func (s *Server) searchStartAt(w http.ResponseWriter, r *http.Request) {
+	issues, err := s.search(r.URL.Query().Get("jql"))
+	if err != nil {
+		writeJqlError(w, err)
+		return
+	}
+	startAt := intParam(r, "startAt", 0)
+	maxResults := intParam(r, "maxResults", defaultMaxResults)
+	page := pageOf(issues, startAt, maxResults)
+	writeJson(w, http.StatusOK, map[string]any{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(issues),
+		"issues":     s.renderIssues(r, page),
+	})
+}
+
+// searchNextPageToken pages Cloud style; the token is simply the offset.
+func (s *Server) searchNextPageToken(w http.ResponseWriter, r *http.Request) {
+	jql := r.URL.Query().Get("jql")
+	if query, err := parseJql(jql); err == nil && query.where == nil {
+		writeError(w, http.StatusBadRequest, "Unbounded JQL queries are not allowed here. Please add a search restriction to your query.")
+		return
+	}
+	issues, err := s.search(jql)
+	if err != nil {
+		writeJqlError(w, err)
+		return
+	}
+	startAt := 0
+	if token := r.URL.Query().Get("nextPageToken"); token != "" {
+		if startAt, err = strconv.Atoi(token); err != nil {
+			writeError(w, http.StatusBadRequest, "The provided next page token is invalid or expired.")
+			return
+		}
+	}
+	maxResults := intParam(r, "maxResults", defaultMaxResults)
+	page := pageOf(issues, startAt, maxResults)
+	response := map[string]any{
+		"issues": s.renderIssues(r, page),
+		"isLast": startAt+len(page) >= len(issues),
+	}
+	if startAt+len(page) < len(issues) {
+		response["nextPageToken"] = strconv.Itoa(startAt + len(page))
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) searchRemoved(w http.ResponseWriter, _ *http.Request) {
+	writeError(w, http.StatusGone, "The requested API has been removed. Please migrate to the /rest/api/3/search/jql API.")
+}
+
+func (s *Server) search(jql string) ([]*Issue, error) {
+	query, err := parseJql(jql)
+	if err != nil {
+		return nil, err
+	}
+	e := &evaluator{data: s.data, now: s.now()}
+	issues := make([]*Issue, 0, len(s.data.Issues))
+	for _, issue := range s.data.Issues {
+		matches := query.where == nil
+		if !matches {
+			if matches, err = query.where.match(e, issue); err != nil {
+				return nil, err
+			}
+		}
+		if matches {
+			issues = append(issues, issue)
+		}
+	}
+	if err := e.sort(issues, query.orderBy); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	writeJson(w, http.StatusOK, s.renderIssue(r, issue, true))
+}
+
+// getCreateMeta describes the create screens of the issue types: sub-tasks
+// need a parent, the other types (but epics) may have an epic - the parent
+// field on Cloud, the Epic Link custom field on Server.
+func (s *Server) getCreateMeta(w http.ResponseWriter, r *http.Request) {
+	priorities := s.renderPriorities()
+	projects := make([]map[string]any, 0)
+	keys := strings.Split(r.URL.Query().Get("projectKeys"), ",")
+	for _, project := range s.data.Projects {
+		if !slices.Contains(keys, project.Key) {
+			continue
+		}
+		types := make([]map[string]any, 0, len(s.data.IssueTypes))
+		for i, name := range s.data.IssueTypes {
+			fields := map[string]any{
+				"summary":     field("Summary", true, map[string]string{"type": "string", "system": "summary"}),
+				"description": field("Description", false, map[string]string{"type": "string", "system": "description"}),
+				"assignee":    field("Assignee", false, map[string]string{"type": "user", "system": "assignee"}),
+				"labels":      field("Labels", false, map[string]string{"type": "array", "items": "string", "system": "labels"}),
+			}
+			priority := field("Priority", false, map[string]string{"type": "priority", "system": "priority"})
+			priority["hasDefaultValue"] = true
+			priority["allowedValues"] = priorities
+			fields["priority"] = priority
+			switch {
+			case name == subtaskIssueType:
+				fields["parent"] = field("Parent", true, map[string]string{"type": "issuelink", "system": "parent"})
+			case name == epicIssueType:
+			case s.isServer():
+				fields[epicLinkField] = field("Epic Link", false, map[string]string{"type": "any", "custom": "com.pyxis.greenhopper.jira:gh-epic-link"})
+			default:
+				fields["parent"] = field("Parent", false, map[string]string{"type": "issuelink", "system": "parent"})
+			}
+			types = append(types, map[string]any{"id": strconv.Itoa(i + 1), "name": name, "subtask": name == subtaskIssueType, "fields": fields})
+		}
+		projects = append(projects, map[string]any{"id": project.Id, "key": project.Key, "name": project.Name, "issuetypes": types})
+	}
+	writeJson(w, http.StatusOK, map[string]any{"projects": projects})
+}
+
+func (s *Server) getEditMeta(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	fields := map[string]any{
+		"summary":     field("Summary", true, map[string]string{"type": "string", "system": "summary"}),
+		"description": field("Description", false, map[string]string{"type": "string", "system": "description"}),
+		"assignee":    field("Assignee", false, map[string]string{"type": "user", "system": "assignee"}),
+		"duedate":     field("Due date", false, map[string]string{"type": "date", "system": "duedate"}),
+		"labels":      field("Labels", false, map[string]string{"type": "array", "items": "string", "system": "labels"}),
+	}
+	priority := field("Priority", false, map[string]string{"type": "priority", "system": "priority"})
+	priority["allowedValues"] = s.renderPriorities()
+	fields["priority"] = priority
+	for id, f := range fields {
+		f.(map[string]any)["operations"] = []string{"set"}
+		if id == "labels" {
+			f.(map[string]any)["operations"] = []string{"add", "set", "remove"}
+		}
+	}
+	writeJson(w, http.StatusOK, map[string]any{"fields": fields})
+}
+
+// field is a field of a create or edit screen.
+func field(name string, required bool, schema map[string]string) map[string]any {
+	return map[string]any{"name": name, "required": required, "hasDefaultValue": false, "schema": schema}
+}
+
+func (s *Server) renderPriorities() []map[string]string {
+	priorities := make([]map[string]string, 0, len(s.data.Priorities))
+	for i, name := range s.data.Priorities {
+		priorities = append(priorities, map[string]string{"id": strconv.Itoa(i + 1), "name": name})
+	}
+	return priorities
+}
+
+// priority is the name of the priority the request refers to, empty when
+// there's no such priority.
+func (s *Server) priority(ref idOrKey) string {
+	for i, name := range s.data.Priorities {
+		if ref.Id == strconv.Itoa(i+1) || ref.Name == name {
+			return name
+		}
+	}
+	return ""
+}
+
+type createIssueRequest struct {
+	Fields struct {
+		Project     idOrKey  `json:"project"`
+		IssueType   idOrKey  `json:"issuetype"`
+		Summary     string   `json:"summary"`
+		Description richText `json:"description"`
+		Priority    idOrKey  `json:"priority"`
+		Assignee    *struct {
+			AccountId string `json:"accountId"`
+			Name      string `json:"name"`
+		} `json:"assignee"`
+		Labels   []string `json:"labels"`
+		Parent   idOrKey  `json:"parent"`
+		EpicLink string   `json:"customfield_10014"`
+	} `json:"fields"`
+}
+
+// idOrKey is how the api refers to projects, issue types, ...: {"id": "1"},
+// {"key": "FJ"} or {"name": "Task"}.
+type idOrKey struct {
+	Id   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	var request createIssueRequest
+	if !readJson(w, r, &request) {
+		return
+	}
+	fields := request.Fields
+	project := s.data.project(fields.Project.Key + fields.Project.Id)
+	if project == nil {
+		writeFieldError(w, "project", "Specify a valid project ID or key")
+		return
+	}
+	issueType := ""
+	for i, name := range s.data.IssueTypes {
+		if fields.IssueType.Id == strconv.Itoa(i+1) || fields.IssueType.Name == name {
+			issueType = name
+		}
+	}
+	if issueType == "" {
+		writeFieldError(w, "issuetype", "Specify an issue type")
+		return
+	}
+	if strings.TrimSpace(fields.Summary) == "" {
+		writeFieldError(w, "summary", "You must specify a summary of the issue.")
+		return
+	}
+	issue := Issue{
+		Type:        issueType,
+		Summary:     fields.Summary,
+		Description: string(fields.Description),
+		Priority:    defaultPriority,
+		Status:      s.data.Statuses[0].Id,
+		Reporter:    s.data.CurrentUser,
+		Labels:      fields.Labels,
+		Created:     s.now(),
+		Updated:     s.now(),
+	}
+	if priority := s.priority(fields.Priority); priority != "" {
+		issue.Priority = priority
+	}
+	if fields.Assignee != nil && !s.assign(w, &issue, fields.Assignee.AccountId+fields.Assignee.Name) {
+		return
+	}
+	if parent := fields.Parent.Key + fields.Parent.Id + fields.EpicLink; parent != "" {
+		found := s.data.issue(parent)
+		if found == nil || found.ProjectKey != project.Key {
+			writeFieldError(w, "parent", fmt.Sprintf("Issue '%s' does not exist or you do not have permission to see it.", parent))
+			return
+		}
+		issue.Parent = found.Key
+	} else if issueType == subtaskIssueType {
+		writeFieldError(w, "parent", "Sub-tasks must be created with a parent.")
+		return
+	}
+	issue.ProjectKey = project.Key
+	created := s.data.addIssue(issue)
+	writeJson(w, http.StatusCreated, map[string]any{
+		"id":   created.Id,
+		"key":  created.Key,
+		"self": baseUrl(r) + "/rest/api/2/issue/" + created.Id,
+	})
+}
+
+type editIssueRequest struct {
+	Fields struct {
+		Summary     *string   `json:"summary"`
+		Description *richText `json:"description"`
+		Assignee    *struct {
+			AccountId string `json:"accountId"`
+			Name      string `json:"name"`
+		} `json:"assignee"`
+		Priority *idOrKey `json:"priority"`
+		// DueDate is null when the request clears it, and empty when it's not there
+		DueDate json.RawMessage `json:"duedate"`
+		Labels  *[]string       `json:"labels"`
+	} `json:"fields"`
+	Update struct {
+		Labels []struct {
+			Add    string `json:"add"`
+			Remove string `json:"remove"`
+		} `json:"labels"`
+	} `json:"update"`
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	var request editIssueRequest
+	if !readJson(w, r, &request) {
+		return
+	}
+	if request.Fields.Assignee != nil {
+		if !s.assign(w, issue, request.Fields.Assignee.AccountId+request.Fields.Assignee.Name) {
+			return
+		}
+	}
+	if request.Fields.Summary != nil {
+		if strings.TrimSpace(*request.Fields.Summary) == "" {
+			writeFieldError(w, "summary", "You must specify a summary of the issue.")
+			return
+		}
+		issue.Summary = *request.Fields.Summary
+	}
+	if request.Fields.Description != nil {
+		issue.Description = string(*request.Fields.Description)
+	}
+	if request.Fields.Priority != nil {
+		priority := s.priority(*request.Fields.Priority)
+		if priority == "" {
+			writeFieldError(w, "priority", "Specify a valid 'id' or 'name' for Priority")
+			return
+		}
+		issue.Priority = priority
+	}
+	if len(request.Fields.DueDate) > 0 {
+		var dueDate string
+		_ = json.Unmarshal(request.Fields.DueDate, &dueDate)
+		if _, err := time.Parse(time.DateOnly, dueDate); dueDate != "" && err != nil {
+			writeFieldError(w, "duedate", "Error parsing date string: "+dueDate)
+			return
+		}
+		issue.DueDate = dueDate
+	}
+	if request.Fields.Labels != nil {
+		issue.Labels = append([]string{}, *request.Fields.Labels...)
+	}
+	for _, label := range request.Update.Labels {
+		if strings.ContainsRune(label.Add, ' ') {
+			writeFieldError(w, "labels", "The label '"+label.Add+"' contains spaces which is invalid.")
+			return
+		}
+		if label.Add != "" && !slices.Contains(issue.Labels, label.Add) {
+			issue.Labels = append(issue.Labels, label.Add)
+		}
+		if label.Remove != "" {
+			issue.Labels = slices.DeleteFunc(issue.Labels, func(l string) bool { return l == label.Remove })
+		}
+	}
+	issue.Updated = s.now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) assignIssue(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	var request struct {
+		AccountId *string `json:"accountId"`
+		Name      *string `json:"name"`
+	}
+	if !readJson(w, r, &request) {
+		return
+	}
+	user := ""
+	if request.AccountId != nil {
+		user = *request.AccountId
+	} else if request.Name != nil {
+		user = *request.Name
+	}
+	if !s.assign(w, issue, user) {
+		return
+	}
+	issue.Updated = s.now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// assign unassigns the issue for an empty user, "-1" means automatic.
+func (s *Server) assign(w http.ResponseWriter, issue *Issue, user string) bool {
+	switch user {
+	case "":
+		issue.Assignee = ""
+		return true
+	case "-1":
+		issue.Assignee = s.data.CurrentUser
+		return true
+	}
+	found := s.data.user(user)
+	if found == nil {
+		writeFieldError(w, "assignee", fmt.Sprintf("User '%s' cannot be assigned issues.", user))
+		return false
+	}
+	issue.Assignee = found.AccountId
+	return true
+}
+
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	request, ok := s.readComment(w, r)
+	if !ok {
+		return
+	}
+	comment := Comment{
+		Id:         strconv.Itoa(s.nextCommentId()),
+		Author:     s.data.CurrentUser,
+		Body:       string(request.Body),
+		Created:    s.now(),
+		Visibility: request.Visibility,
+	}
+	issue.Comments = append(issue.Comments, comment)
+	issue.Updated = comment.Created
+	writeJson(w, http.StatusCreated, s.renderComment(r, comment))
+}
+
+// updateComment replaces the body and the visibility of a comment - of the
+// current user only, like Jira without the "Edit All Comments" permission.
+func (s *Server) updateComment(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	comment := s.ownCommentOr4xx(w, r, issue)
+	if comment == nil {
+		return
+	}
+	request, ok := s.readComment(w, r)
+	if !ok {
+		return
+	}
+	comment.Body = string(request.Body)
+	comment.Visibility = request.Visibility
+	issue.Updated = s.now()
+	writeJson(w, http.StatusOK, s.renderComment(r, *comment))
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	comment := s.ownCommentOr4xx(w, r, issue)
+	if comment == nil {
+		return
+	}
+	issue.Comments = slices.DeleteFunc(issue.Comments, func(c Comment) bool { return c.Id == comment.Id })
+	issue.Updated = s.now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type commentRequest struct {
+	Body       richText    `json:"body"`
+	Visibility *Visibility `json:"visibility"`
+}
+
+// readComment reads the body of a comment request, which must not be empty,
+// and its visibility, which must name a known role or group.
+func (s *Server) readComment(w http.ResponseWriter, r *http.Request) (*commentRequest, bool) {
+	var request commentRequest
+	if !readJson(w, r, &request) {
+		return nil, false
+	}
+	if strings.TrimSpace(string(request.Body)) == "" {
+		writeFieldError(w, "comment", "Comment body can not be empty!")
+		return nil, false
+	}
+	if v := request.Visibility; v != nil {
+		known := (v.Type == "role" && slices.Contains(s.data.Roles, v.Value)) ||
+			(v.Type == "group" && slices.Contains(s.data.Groups, v.Value))
+		if !known {
+			writeFieldError(w, "commentLevel", fmt.Sprintf("You are currently not a member of the %s: %s.", v.Type, v.Value))
+			return nil, false
+		}
+	}
+	return &request, true
+}
+
+// ownCommentOr4xx finds the comment of the path on the issue, answering 404
+// when there's no such comment and 403 when it isn't the current user's.
+func (s *Server) ownCommentOr4xx(w http.ResponseWriter, r *http.Request, issue *Issue) *Comment {
+	for i := range issue.Comments {
+		if issue.Comments[i].Id != r.PathValue("id") {
+			continue
+		}
+		if issue.Comments[i].Author != s.data.CurrentUser {
+			writeError(w, http.StatusForbidden, "You do not have the permission to edit or delete this comment.")
+			return nil
+		}
+		return &issue.Comments[i]
+	}
+	writeError(w, http.StatusNotFound, "Can not find a comment for the id: "+r.PathValue("id")+".")
+	return nil
+}
+
+func (s *Server) nextCommentId() int {
+	id := firstCommentId
+	for _, issue := range s.data.Issues {
+		for _, c := range issue.Comments {
+			if n, err := strconv.Atoi(c.Id); err == nil && n >= id {
+				id = n + 1
+			}
+		}
+	}
+	return id
+}
+
+func (s *Server) getWorklogs(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	worklogs := make([]map[string]any, 0, len(issue.Worklogs))
+	for _, worklog := range issue.Worklogs {
+		worklogs = append(worklogs, s.renderWorklog(r, worklog))
+	}
+	writeJson(w, http.StatusOK, map[string]any{"startAt": 0, "maxResults": len(worklogs), "total": len(worklogs), "worklogs": worklogs})
+}
+
+func (s *Server) addWorklog(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	var request struct {
+		TimeSpent string `json:"timeSpent"`
+		Started   string `json:"started"`
+	}
+	if !readJson(w, r, &request) {
+		return
+	}
+	seconds, ok := timeSpentSeconds(request.TimeSpent)
+	if !ok {
+		writeFieldError(w, "timeLogged", "Invalid time duration entered.")
+		return
+	}
+	started, err := time.Parse(jiraTimeFormat, request.Started)
+	if err != nil {
+		writeFieldError(w, "started", "Invalid date format. Please enter the date in the format \"d/MMM/yy h:mm a\".")
+		return
+	}
+	worklog := Worklog{
+		Id:               strconv.Itoa(s.nextWorklogId()),
+		Author:           s.data.CurrentUser,
+		TimeSpentSeconds: seconds,
+		Started:          started,
+	}
+	issue.Worklogs = append(issue.Worklogs, worklog)
+	issue.Updated = s.now()
+	writeJson(w, http.StatusCreated, s.renderWorklog(r, worklog))
+}
+
+func (s *Server) nextWorklogId() int {
+	id := firstWorklogId
+	for _, issue := range s.data.Issues {
+		for _, worklog := range issue.Worklogs {
+			if n, err := strconv.Atoi(worklog.Id); err == nil && n >= id {
+				id = n + 1
+			}
+		}
+	}
+	return id
+}
+
+// timeSpentSeconds reads time spent like "1h 30m", with Jira's default of
+// 8 hour days and 5 day weeks.
+func timeSpentSeconds(timeSpent string) (int, bool) {
+	normalized, err := jira.NormalizeTimeSpent(timeSpent)
+	if err != nil {
+		return 0, false
+	}
+	units := map[byte]int{'w': 5 * 8 * 3600, 'd': 8 * 3600, 'h': 3600, 'm': 60}
+	seconds := 0
+	for _, part := range strings.Fields(normalized) {
+		n, _ := strconv.Atoi(part[:len(part)-1])
+		seconds += n * units[part[len(part)-1]]
+	}
+	return seconds, seconds > 0
+}
+
+// addAttachments takes the files of a multipart form - only with the
+// X-Atlassian-Token header, like Jira.
+func (s *Server) addAttachments(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	if r.Header.Get("X-Atlassian-Token") != "no-check" {
+		writeError(w, http.StatusForbidden, "XSRF check failed")
+		return
+	}
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, "Unexpected request body: "+err.Error())
+		return
+	}
+	added := make([]map[string]any, 0)
+	for _, header := range r.MultipartForm.File["file"] {
+		file, err := header.Open()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		content, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		attachment := Attachment{
+			Id:       strconv.Itoa(s.nextAttachmentId()),
+			Filename: header.Filename,
+			Author:   s.data.CurrentUser,
+			MimeType: http.DetectContentType(content),
+			Content:  content,
+			Created:  s.now(),
+		}
+		issue.Attachments = append(issue.Attachments, attachment)
+		issue.Updated = attachment.Created
+		added = append(added, s.renderAttachment(r, attachment))
+	}
+	writeJson(w, http.StatusOK, added)
+}
+
+// RedirectAttachments makes attachment downloads answer with a 303 to
+// mediaUrl + "/file/{id}", like Jira Cloud does to its media host.
+func (s *Server) RedirectAttachments(mediaUrl string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mediaUrl = mediaUrl
+}
+
+func (s *Server) getAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	attachment := s.data.attachment(r.PathValue("id"))
+	if attachment == nil {
+		writeError(w, http.StatusNotFound, "The attachment with id '"+r.PathValue("id")+"' does not exist")
+		return
+	}
+	if s.mediaUrl != "" {
+		http.Redirect(w, r, s.mediaUrl+"/file/"+attachment.Id, http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", attachment.MimeType)
+	_, _ = w.Write(attachment.Content)
+}
+
+func (s *Server) nextAttachmentId() int {
+	id := firstAttachmentId
+	for _, issue := range s.data.Issues {
+		for _, attachment := range issue.Attachments {
+			if n, err := strconv.Atoi(attachment.Id); err == nil && n >= id {
+				id = n + 1
+			}
+		}
+	}
+	return id
+}
+
+func (s *Server) getLinkTypes(w http.ResponseWriter, r *http.Request) {
+	linkTypes := make([]map[string]any, 0, len(s.data.LinkTypes))
+	for _, linkType := range s.data.LinkTypes {
+		linkTypes = append(linkTypes, renderLinkType(r, linkType))
+	}
+	writeJson(w, http.StatusOK, map[string]any{"issueLinkTypes": linkTypes})
+}
+
+func (s *Server) addLink(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Type struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"type"`
+		InwardIssue struct {
+			Key string `json:"key"`
+		} `json:"inwardIssue"`
+		OutwardIssue struct {
+			Key string `json:"key"`
+		} `json:"outwardIssue"`
+	}
+	if !readJson(w, r, &request) {
+		return
+	}
+	linkType := s.data.linkType(request.Type.Name)
+	if linkType == nil {
+		linkType = s.data.linkType(request.Type.Id)
+	}
+	if linkType == nil {
+		writeError(w, http.StatusNotFound, "No issue link type with name '"+request.Type.Name+"' found.")
+		return
+	}
+	inward, outward := s.data.issue(request.InwardIssue.Key), s.data.issue(request.OutwardIssue.Key)
+	if inward == nil || outward == nil {
+		writeError(w, http.StatusNotFound, "Issue Does Not Exist")
+		return
+	}
+	if inward == outward {
+		writeError(w, http.StatusBadRequest, "You cannot link an issue to itself.")
+		return
+	}
+	s.data.addLink(linkType.Name, inward.Key, outward.Key)
+	inward.Updated = s.now()
+	outward.Updated = inward.Updated
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteLink(w http.ResponseWriter, r *http.Request) {
+	for i, link := range s.data.Links {
+		if link.Id != r.PathValue("id") {
+			continue
+		}
+		s.data.Links = append(s.data.Links[:i], s.data.Links[i+1:]...)
+		for _, key := range []string{link.Inward, link.Outward} {
+			if issue := s.data.issue(key); issue != nil {
+				issue.Updated = s.now()
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusNotFound, "No issue link with id '"+r.PathValue("id")+"' exists.")
+}
+
+func (s *Server) getTransitions(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	expandFields := strings.Contains(r.URL.Query().Get("expand"), "transitions.fields")
+	transitions := make([]map[string]any, 0, len(s.data.Workflow))
+	for _, t := range s.data.transitions(issue) {
+		transition := map[string]any{
+			"id":        t.Id,
+			"name":      t.Name,
+			"to":        s.renderStatus(r, t.To),
+			"hasScreen": t.Resolution,
+		}
+		if expandFields {
+			fields := map[string]any{}
+			if t.Resolution {
+				resolution := field("Resolution", true, map[string]string{"type": "resolution", "system": "resolution"})
+				resolution["operations"] = []string{"set"}
+				resolution["allowedValues"] = renderResolutions()
+				fields["resolution"] = resolution
+			}
+			transition["fields"] = fields
+		}
+		transitions = append(transitions, transition)
+	}
+	writeJson(w, http.StatusOK, map[string]any{"transitions": transitions})
+}
+
+func (s *Server) doTransition(w http.ResponseWriter, r *http.Request) {
+	issue := s.issueOr404(w, r)
+	if issue == nil {
+		return
+	}
+	// Jira takes {"transition":{"id":"21"}}, older clients send the bare id
+	var request struct {
+		Transition json.RawMessage `json:"transition"`
+		Fields     struct {
+			Resolution *idOrKey `json:"resolution"`
+		} `json:"fields"`
+		Update struct {
+			Comment []struct {
+				Add struct {
+					Body richText `json:"body"`
+				} `json:"add"`
+			} `json:"comment"`
+		} `json:"update"`
+	}
+	if !readJson(w, r, &request) {
+		return
+	}
+	var id string
+	if json.Unmarshal(request.Transition, &id) != nil {
+		var transition struct {
+			Id string `json:"id"`
+		}
+		_ = json.Unmarshal(request.Transition, &transition)
+		id = transition.Id
+	}
+	for _, t := range s.data.transitions(issue) {
+		if t.Id != id {
+			continue
+		}
+		resolution := ""
+		if request.Fields.Resolution != nil {
+			resolution = resolutionOf(*request.Fields.Resolution)
+		}
+		if t.Resolution && resolution == "" {
+			writeFieldError(w, "resolution", "Field 'resolution' is required")
+			return
+		}
+		issue.Status = t.To
+		issue.Resolution = resolution
+		issue.Updated = s.now()
+		for _, c := range request.Update.Comment {
+			issue.Comments = append(issue.Comments, Comment{
+				Id:      strconv.Itoa(s.nextCommentId()),
+				Author:  s.data.CurrentUser,
+				Body:    string(c.Add.Body),
+				Created: issue.Updated,
+			})
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("Transition id '%s' is not valid for this issue.", id))
+}
+
+// resolutions are those of every Jira instance, by id.
+var resolutions = []string{"Done", "Won't Do", "Duplicate"}
+
+func renderResolutions() []map[string]string {
+	rendered := make([]map[string]string, 0, len(resolutions))
+	for i, name := range resolutions {
+		rendered = append(rendered, map[string]string{"id": strconv.Itoa(i + 1), "name": name})
+	}
+	return rendered
+}
+
+// resolutionOf is the name of the resolution the request refers to, empty
+// when there's no such resolution.
+func resolutionOf(ref idOrKey) string {
+	for i, name := range resolutions {
+		if ref.Id == strconv.Itoa(i+1) || ref.Name == name {
+			return name
+		}
+	}
+	return ""
+}
+
+func (s *Server) searchProjects(w http.ResponseWriter, r *http.Request) {
+	startAt := intParam(r, "startAt", 0)
+	maxResults := intParam(r, "maxResults", defaultMaxResults)
+	projects := pageOf(s.data.Projects, startAt, maxResults)
+	values := make([]map[string]any, 0, len(projects))
+	for _, p := range projects {
+		values = append(values, s.renderProject(r, p))
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(s.data.Projects),
+		"isLast":     startAt+len(projects) >= len(s.data.Projects),
+		"values":     values,
+	})
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	project := s.data.project(r.PathValue("key"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No project could be found with key '%s'.", r.PathValue("key")))
+		return
+	}
+	writeJson(w, http.StatusOK, s.renderProject(r, *project))
+}
+
+// getProjectStatuses lists the statuses per issue type, as Jira does; all
+// types share one workflow here.
+func (s *Server) getProjectStatuses(w http.ResponseWriter, r *http.Request) {
+	if s.data.project(r.PathValue("key")) == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No project could be found with key '%s'.", r.PathValue("key")))
+		return
+	}
+	statuses := make([]map[string]any, 0, len(s.data.Statuses))
+	for _, status := range s.data.Statuses {
+		statuses = append(statuses, s.renderStatus(r, status.Id))
+	}
+	types := make([]map[string]any, 0)
+	for _, name := range s.data.IssueTypes {
+		types = append(types, map[string]any{"name": name, "subtask": name == subtaskIssueType, "statuses": statuses})
+	}
+	writeJson(w, http.StatusOK, types)
+}
+
+func (s *Server) findAssignableUsers(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query") + r.URL.Query().Get("username"))
+	users := make([]map[string]any, 0, len(s.data.Users))
+	for _, user := range s.data.Users {
+		searchable := strings.ToLower(strings.Join([]string{user.Name, user.DisplayName, user.Email}, " "))
+		if strings.Contains(searchable, query) {
+			users = append(users, s.renderUser(r, &user))
+		}
+	}
+	writeJson(w, http.StatusOK, users)
+}
+
+func (s *Server) getMyself(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, s.renderUser(r, s.data.user(s.data.CurrentUser)))
+}
+
+// getProjectRoles maps the role names to their urls, like Jira does.
+func (s *Server) getProjectRoles(w http.ResponseWriter, r *http.Request) {
+	project := s.data.project(r.PathValue("key"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No project could be found with key '%s'.", r.PathValue("key")))
+		return
+	}
+	roles := make(map[string]string, len(s.data.Roles))
+	for i, name := range s.data.Roles {
+		roles[name] = fmt.Sprintf("%s/rest/api/2/project/%s/role/%d", baseUrl(r), project.Id, 10002+i)
+	}
+	writeJson(w, http.StatusOK, roles)
+}
+
+func (s *Server) findGroups(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	groups := make([]map[string]any, 0, len(s.data.Groups))
+	for _, name := range s.data.Groups {
+		if strings.Contains(strings.ToLower(name), query) {
+			groups = append(groups, map[string]any{"name": name, "html": name})
+		}
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"header": fmt.Sprintf("Showing %d of %d matching groups", len(groups), len(groups)),
+		"total":  len(groups),
+		"groups": groups,
+	})
+}
+
+func (s *Server) suggestLabels(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	var exclude []string
+	if id := r.PathValue("id"); id != "" {
+		issue := s.data.issue(id)
+		if issue == nil {
+			writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+			return
+		}
+		exclude = issue.Labels
+	}
+	var labels []string
+	for _, issue := range s.data.Issues {
+		for _, label := range issue.Labels {
+			if strings.HasPrefix(strings.ToLower(label), query) && !slices.Contains(labels, label) && !slices.Contains(exclude, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	slices.Sort(labels)
+	suggestions := make([]map[string]string, 0, len(labels))
+	for _, label := range labels {
+		suggestions = append(suggestions, map[string]string{
+			"label": label,
+			"html":  "<b>" + label[:len(query)] + "</b>" + label[len(query):],
+		})
+	}
+	writeJson(w, http.StatusOK, map[string]any{"token": "", "suggestions": suggestions})
+}
+
+func (s *Server) findBoards(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("projectKeyOrId")
+	boards := make([]map[string]any, 0, len(s.data.Boards))
+	for _, board := range s.data.Boards {
+		if p := s.data.project(board.ProjectKey); project == "" || p != nil && (p.Key == project || p.Id == project) {
+			boards = append(boards, map[string]any{
+				"id":   board.Id,
+				"self": fmt.Sprintf("%s/rest/agile/1.0/board/%d", baseUrl(r), board.Id),
+				"name": board.Name,
+				"type": board.Type,
+			})
+		}
+	}
+	startAt := intParam(r, "startAt", 0)
+	page := pageOf(boards, startAt, defaultMaxResults)
+	writeJson(w, http.StatusOK, map[string]any{
+		"maxResults": defaultMaxResults,
+		"startAt":    startAt,
+		"total":      len(boards),
+		"isLast":     startAt+len(page) >= len(boards),
+		"values":     page,
+	})
+}
+
+func (s *Server) getBoardConfiguration(w http.ResponseWriter, r *http.Request) {
+	board := s.boardOr404(w, r)
+	if board == nil {
+		return
+	}
+	columns := make([]map[string]any, 0, len(board.Columns))
+	for _, column := range board.Columns {
+		statuses := make([]map[string]string, 0, len(column.Statuses))
+		for _, id := range column.Statuses {
+			statuses = append(statuses, map[string]string{"id": id, "self": baseUrl(r) + "/rest/api/2/status/" + id})
+		}
+		columns = append(columns, map[string]any{"name": column.Name, "statuses": statuses})
+	}
+	location := map[string]any{"type": "project"}
+	if project := s.data.project(board.ProjectKey); project != nil {
+		location["key"] = project.Key
+		location["id"] = project.Id
+		location["name"] = project.Name
+		location["self"] = baseUrl(r) + "/rest/api/2/project/" + project.Id
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"id":           board.Id,
+		"name":         board.Name,
+		"type":         board.Type,
+		"self":         fmt.Sprintf("%s/rest/agile/1.0/board/%d/configuration", baseUrl(r), board.Id),
+		"location":     location,
+		"filter":       map[string]string{"id": board.FilterId, "self": baseUrl(r) + "/rest/api/2/filter/" + board.FilterId},
+		"subQuery":     map[string]string{"query": ""},
+		"columnConfig": map[string]any{"columns": columns, "constraintType": "none"},
+		"ranking":      map[string]int{"rankCustomFieldId": rankCustomFieldId},
+	})
+}
+
+func (s *Server) getBoardSprints(w http.ResponseWriter, r *http.Request) {
+	board := s.boardOr404(w, r)
+	if board == nil {
+		return
+	}
+	if board.Type != boardTypeScrum {
+		writeError(w, http.StatusBadRequest, "The board does not support sprints")
+		return
+	}
+	states := strings.Split(r.URL.Query().Get("state"), ",")
+	sprints := make([]map[string]any, 0)
+	for _, sprint := range s.data.Sprints {
+		if sprint.BoardId != board.Id || states[0] != "" && !slices.Contains(states, sprint.State) {
+			continue
+		}
+		sprints = append(sprints, map[string]any{
+			"id":            sprint.Id,
+			"self":          fmt.Sprintf("%s/rest/agile/1.0/sprint/%d", baseUrl(r), sprint.Id),
+			"state":         sprint.State,
+			"name":          sprint.Name,
+			"startDate":     sprint.Start,
+			"endDate":       sprint.End,
+			"originBoardId": sprint.BoardId,
+			"goal":          sprint.Goal,
+		})
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"maxResults": defaultMaxResults,
+		"startAt":    0,
+		"total":      len(sprints),
+		"isLast":     true,
+		"values":     sprints,
+	})
+}
+
+func (s *Server) getSprintIssues(w http.ResponseWriter, r *http.Request) {
+	board := s.boardOr404(w, r)
+	if board == nil {
+		return
+	}
+	sprintId, _ := strconv.Atoi(r.PathValue("sprintId"))
+	if sprint := s.data.sprint(sprintId); sprint == nil || sprint.BoardId != board.Id {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Sprint with id %s does not exist or you do not have permission to see it.", r.PathValue("sprintId")))
+		return
+	}
+	var issues []*Issue
+	for _, issue := range s.data.Issues {
+		if issue.Sprint == sprintId {
+			issues = append(issues, issue)
+		}
+	}
+	startAt := intParam(r, "startAt", 0)
+	maxResults := intParam(r, "maxResults", defaultMaxResults)
+	page := pageOf(issues, startAt, maxResults)
+	writeJson(w, http.StatusOK, map[string]any{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(issues),
+		"isLast":     startAt+len(page) >= len(issues),
+		"issues":     s.renderIssues(r, page),
+	})
+}
+
+func (s *Server) getBoardProjects(w http.ResponseWriter, r *http.Request) {
+	board := s.boardOr404(w, r)
+	if board == nil {
+		return
+	}
+	values := make([]map[string]any, 0, 1)
+	if project := s.data.project(board.ProjectKey); project != nil {
+		values = append(values, s.renderProject(r, *project))
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"maxResults": defaultMaxResults,
+		"startAt":    0,
+		"total":      len(values),
+		"isLast":     true,
+		"values":     values,
+	})
+}
+
+func (s *Server) getFilter(w http.ResponseWriter, r *http.Request) {
+	filter := s.data.filter(r.PathValue("id"))
+	if filter == nil {
+		writeError(w, http.StatusBadRequest, "The selected filter is not available to you, perhaps it has been deleted or had its permissions changed.")
+		return
+	}
+	writeJson(w, http.StatusOK, s.renderFilter(r, filter))
+}
+
+func (s *Server) getMyFilters(w http.ResponseWriter, r *http.Request) {
+	s.writeFilters(w, r, func(Filter) bool { return true })
+}
+
+func (s *Server) getFavouriteFilters(w http.ResponseWriter, r *http.Request) {
+	s.writeFilters(w, r, func(f Filter) bool { return f.Favourite })
+}
+
+func (s *Server) writeFilters(w http.ResponseWriter, r *http.Request, include func(Filter) bool) {
+	filters := make([]map[string]any, 0, len(s.data.Filters))
+	for i, filter := range s.data.Filters {
+		if include(filter) {
+			filters = append(filters, s.renderFilter(r, &s.data.Filters[i]))
+		}
+	}
+	writeJson(w, http.StatusOK, filters)
+}
+
+func (s *Server) issueOr404(w http.ResponseWriter, r *http.Request) *Issue {
+	issue := s.data.issue(r.PathValue("key"))
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+	}
+	return issue
+}
+
+func (s *Server) boardOr404(w http.ResponseWriter, r *http.Request) *Board {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	board := s.data.board(id)
+	if board == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Board with id %s does not exist or you do not have permission to see it.", r.PathValue("id")))
+	}
+	return board
+}
+
+func (s *Server) renderIssues(r *http.Request, issues []*Issue) []map[string]any {
+	rendered := make([]map[string]any, 0, len(issues))
+	for _, issue := range issues {
+		rendered = append(rendered, s.renderIssue(r, issue, false))
+	}
+	return rendered
+}
+
+// renderIssue renders search results without the description and comments,
+// like the search fields fjira asks for; detailed adds everything.
+func (s *Server) renderIssue(r *http.Request, issue *Issue, detailed bool) map[string]any {
+	var project map[string]any
+	if p := s.data.project(issue.ProjectKey); p != nil {
+		project = s.renderProject(r, *p)
+	}
+	fields := map[string]any{
+		"summary":   issue.Summary,
+		"project":   project,
+		"reporter":  s.renderUser(r, s.data.user(issue.Reporter)),
+		"assignee":  s.renderUser(r, s.data.user(issue.Assignee)),
+		"issuetype": renderIssueType(issue.Type),
+		"status":    s.renderStatus(r, issue.Status),
+		"priority":  map[string]string{"id": strconv.Itoa(slices.Index(s.data.Priorities, issue.Priority) + 1), "name": issue.Priority},
+		"labels":    append([]string{}, issue.Labels...),
+		"created":   issue.Created.Format(jiraTimeFormat),
+		"updated":   issue.Updated.Format(jiraTimeFormat),
+	}
+	if parent := s.data.issue(issue.Parent); parent != nil {
+		fields["parent"] = s.renderIssueRef(r, parent)
+	}
+	if issue.DueDate != "" {
+		fields["duedate"] = issue.DueDate
+	}
+	if issue.Resolution != "" {
+		fields["resolution"] = map[string]string{"name": issue.Resolution}
+	}
+	if detailed {
+		var description any
+		if issue.Description != "" {
+			description = renderRichText(r, issue.Description)
+		}
+		comments := make([]map[string]any, 0, len(issue.Comments))
+		for _, c := range issue.Comments {
+			comments = append(comments, s.renderComment(r, c))
+		}
+		subtasks := make([]map[string]any, 0)
+		for _, other := range s.data.Issues {
+			if other.Parent == issue.Key && other.Type == subtaskIssueType {
+				subtasks = append(subtasks, s.renderIssueRef(r, other))
+			}
+		}
+		fields["description"] = description
+		fields["comment"] = map[string]any{
+			"comments":   comments,
+			"maxResults": len(comments),
+			"total":      len(comments),
+			"startAt":    0,
+		}
+		fields["subtasks"] = subtasks
+		attachments := make([]map[string]any, 0, len(issue.Attachments))
+		for _, attachment := range issue.Attachments {
+			attachments = append(attachments, s.renderAttachment(r, attachment))
+		}
+		fields["attachment"] = attachments
+		fields["issuelinks"] = s.renderIssueLinks(r, issue)
+	}
+	return map[string]any{
+		"id":     issue.Id,
+		"key":    issue.Key,
+		"self":   baseUrl(r) + "/rest/api/2/issue/" + issue.Id,
+		"fields": fields,
+	}
+}
+
+func (s *Server) renderIssueRef(r *http.Request, issue *Issue) map[string]any {
+	return map[string]any{
+		"id":   issue.Id,
+		"key":  issue.Key,
+		"self": baseUrl(r) + "/rest/api/2/issue/" + issue.Id,
+		"fields": map[string]any{
+			"summary":   issue.Summary,
+			"status":    s.renderStatus(r, issue.Status),
+			"issuetype": renderIssueType(issue.Type),
+		},
+	}
+}
+
+// renderIssueLinks renders the links of the issue from its side: the other
+// issue is outwardIssue when the issue is the inward one, see LinkType.
+func (s *Server) renderIssueLinks(r *http.Request, issue *Issue) []map[string]any {
+	links := make([]map[string]any, 0)
+	for _, link := range s.data.Links {
+		var side, other string
+		switch issue.Key {
+		case link.Inward:
+			side, other = "outwardIssue", link.Outward
+		case link.Outward:
+			side, other = "inwardIssue", link.Inward
+		default:
+			continue
+		}
+		otherIssue, linkType := s.data.issue(other), s.data.linkType(link.Type)
+		if otherIssue == nil || linkType == nil {
+			continue
+		}
+		links = append(links, map[string]any{
+			"id":   link.Id,
+			"self": baseUrl(r) + "/rest/api/2/issueLink/" + link.Id,
+			"type": renderLinkType(r, *linkType),
+			side:   s.renderIssueRef(r, otherIssue),
+		})
+	}
+	return links
+}
+
+func renderLinkType(r *http.Request, linkType LinkType) map[string]any {
+	return map[string]any{
+		"id":      linkType.Id,
+		"name":    linkType.Name,
+		"inward":  linkType.Inward,
+		"outward": linkType.Outward,
+		"self":    baseUrl(r) + "/rest/api/2/issueLinkType/" + linkType.Id,
+	}
+}
+
+func renderIssueType(name string) map[string]any {
+	return map[string]any{"name": name, "subtask": name == subtaskIssueType}
+}
+
+func (s *Server) renderProject(r *http.Request, project Project) map[string]any {
+	return map[string]any{
+		"id":   project.Id,
+		"key":  project.Key,
+		"name": project.Name,
+		"self": baseUrl(r) + "/rest/api/2/project/" + project.Id,
+	}
+}
+
+func (s *Server) renderStatus(r *http.Request, id string) map[string]any {
+	status := s.data.status(id)
+	if status == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":          status.Id,
+		"name":        status.Name,
+		"description": "",
+		"self":        baseUrl(r) + "/rest/api/2/status/" + status.Id,
+		"statusCategory": map[string]string{
+			"key":  status.Category,
+			"name": categoryName(status.Category),
+		},
+	}
+}
+
+// renderUser renders a Cloud user with an accountId, or a Server/DC user with
+// name and key; a nil user renders as JSON null.
+func (s *Server) renderUser(r *http.Request, user *User) map[string]any {
+	if user == nil {
+		return nil
+	}
+	rendered := map[string]any{
+		"displayName":  user.DisplayName,
+		"emailAddress": user.Email,
+		"active":       true,
+		"timeZone":     "Europe/Warsaw",
+	}
+	if s.isServer() {
+		rendered["name"] = user.Name
+		rendered["key"] = user.Name
+		rendered["self"] = baseUrl(r) + "/rest/api/2/user?username=" + user.Name
+	} else {
+		rendered["accountId"] = user.AccountId
+		rendered["accountType"] = "atlassian"
+		rendered["self"] = baseUrl(r) + "/rest/api/2/user?accountId=" + user.AccountId
+	}
+	return rendered
+}
+
+func (s *Server) renderComment(r *http.Request, comment Comment) map[string]any {
+	rendered := map[string]any{
+		"id":      comment.Id,
+		"author":  s.renderUser(r, s.data.user(comment.Author)),
+		"body":    renderRichText(r, comment.Body),
+		"created": comment.Created.Format(jiraTimeFormat),
+		"updated": comment.Created.Format(jiraTimeFormat),
+	}
+	if comment.Visibility != nil {
+		rendered["visibility"] = comment.Visibility
+	}
+	return rendered
+}
+
+func (s *Server) renderAttachment(r *http.Request, attachment Attachment) map[string]any {
+	return map[string]any{
+		"id":       attachment.Id,
+		"filename": attachment.Filename,
+		"author":   s.renderUser(r, s.data.user(attachment.Author)),
+		"created":  attachment.Created.Format(jiraTimeFormat),
+		"size":     len(attachment.Content),
+		"mimeType": attachment.MimeType,
+		"content":  baseUrl(r) + "/rest/api/2/attachment/content/" + attachment.Id,
+	}
+}
+
+func (s *Server) renderWorklog(r *http.Request, worklog Worklog) map[string]any {
+	return map[string]any{
+		"id":               worklog.Id,
+		"author":           s.renderUser(r, s.data.user(worklog.Author)),
+		"started":          worklog.Started.Format(jiraTimeFormat),
+		"timeSpent":        jira.FormatTimeSpent(time.Duration(worklog.TimeSpentSeconds) * time.Second),
+		"timeSpentSeconds": worklog.TimeSpentSeconds,
+	}
+}
+
+// renderRichText is the text itself for the v2 api, and an Atlassian Document
+// Format document for v3: a paragraph per blank-line separated block.
+func renderRichText(r *http.Request, text string) any {
+	if !strings.HasPrefix(r.URL.Path, "/rest/api/3/") {
+		return text
+	}
+	paragraphs := make([]map[string]any, 0)
+	for _, block := range strings.Split(text, "\n\n") {
+		content := make([]map[string]any, 0)
+		for i, line := range strings.Split(block, "\n") {
+			if i > 0 {
+				content = append(content, map[string]any{"type": "hardBreak"})
+			}
+			if line != "" {
+				content = append(content, map[string]any{"type": "text", "text": line})
+			}
+		}
+		paragraphs = append(paragraphs, map[string]any{"type": "paragraph", "content": content})
+	}
+	return map[string]any{"type": "doc", "version": 1, "content": paragraphs}
+}
+
+// richText is a description or a comment body as it's sent: the text itself
+// to the v2 api, an Atlassian Document Format document to v3. Documents are
+// kept as their plain text.
+type richText string
+
+func (t *richText) UnmarshalJSON(data []byte) error {
+	if !adf.IsDocument(data) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*t = richText(text)
+		return nil
+	}
+	doc, err := adf.Parse(data)
+	if err != nil {
+		return err
+	}
+	*t = richText(adf.PlainText(doc))
+	return nil
+}
+
+func (s *Server) renderFilter(r *http.Request, filter *Filter) map[string]any {
+	return map[string]any{
+		"id":        filter.Id,
+		"name":      filter.Name,
+		"jql":       filter.Jql,
+		"favourite": filter.Favourite,
+		"self":      baseUrl(r) + "/rest/api/2/filter/" + filter.Id,
+	}
+}
+
+func baseUrl(r *http.Request) string {
+	if r.Host == "" {
+		return ""
+	}
+	return "http://" + r.Host
+}
+
+func intParam(r *http.Request, name string, fallback int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+func pageOf[T any](items []T, startAt int, maxResults int) []T {
+	if startAt >= len(items) {
+		return []T{}
+	}
+	return items[startAt:min(startAt+maxResults, len(items))]
+}
+
+func readJson(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Unexpected character in the request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes Jira's error body, {"errorMessages":[...],"errors":{}}.
+func writeError(w http.ResponseWriter, status int, messages ...string) {
+	writeJson(w, status, map[string]any{"errorMessages": messages, "errors": map[string]string{}})
+}
+
+func writeFieldError(w http.ResponseWriter, field string, message string) {
+	writeJson(w, http.StatusBadRequest, map[string]any{"errorMessages": []string{}, "errors": map[string]string{field: message}})
+}
+
+func writeJqlError(w http.ResponseWriter, err error) {
+	var jqlErr *jqlError
+	if errors.As(err, &jqlErr) {
+		writeError(w, http.StatusBadRequest, jqlErr.message)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,359 @@
+package fakejira
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeApi(t *testing.T, deployment jira.DeploymentType) jira.Api {
+	t.Helper()
+	server := httptest.NewServer(New(DemoData(time.Now()), deployment))
+	t.Cleanup(server.Close)
+	tokenType := jira.ApiToken
+	if deployment != jira.DeploymentCloud {
+		tokenType = jira.PersonalToken
+	}
+	api, _ := jira.NewApiWithConfig(jira.ApiConfig{ApiUrl: server.URL, Username: "alice", Token: "demo", TokenType: tokenType})
+	return api
+}
+
+func Test_fakeJira_should_serve_every_api_call(t *testing.T) {
+	for _, deployment := range []jira.DeploymentType{jira.DeploymentCloud, jira.DeploymentServer} {
+		t.Run(string(deployment), func(t *testing.T) {
+			// given
+			api := newFakeApi(t, deployment)
+
+			// when
+			info, err := api.GetServerInfo()
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, deployment, info.DeploymentType)
+
+			projects, err := api.FindProjects()
+			assert.Nil(t, err)
+			assert.Len(t, projects, 2)
+			project, err := api.FindProject("FJ")
+			assert.Nil(t, err)
+			assert.Equal(t, "Fjira Demo", project.Name)
+			statuses, err := api.FindProjectStatuses("FJ")
+			assert.Nil(t, err)
+			assert.Len(t, statuses, 4)
+
+			issues, total, err := api.Search("Board columns")
+			assert.Nil(t, err)
+			assert.Equal(t, int32(1), total)
+			assert.Equal(t, "FJ-6", issues[0].Key)
+			assert.Equal(t, "In Review", issues[0].Fields.Status.Name)
+			var pages [][]jira.Issue
+			for page, err := range api.SearchJqlPages("project=FJ ORDER BY key", 5) {
+				assert.Nil(t, err)
+				pages = append(pages, page)
+			}
+			assert.Len(t, pages, 3)
+			assert.Equal(t, "FJ-1", pages[0][0].Key)
+			assert.Equal(t, "FJ-11", pages[2][0].Key)
+
+			users, err := api.FindUsersWithQuery("FJ", "car")
+			assert.Nil(t, err)
+			assert.Len(t, users, 1)
+			assert.Equal(t, "Carol Chen", users[0].DisplayName)
+			assert.Nil(t, api.DoAssignee("FJ-8", &users[0]))
+
+			transitions, err := api.FindTransitions("FJ-8")
+			assert.Nil(t, err)
+			assert.Len(t, transitions, 3)
+			assert.Nil(t, api.DoTransition("FJ-8", &transitions[0], nil))
+			assert.Nil(t, api.DoComment("FJ-8", "on it", nil))
+			assert.Nil(t, api.DoUpdateDescription("FJ-8", "Use Retry-After"))
+			assert.Nil(t, api.AddLabel("FJ-8", "retry"))
+			assert.Nil(t, api.AddWorklog("FJ-8", "1h 30m", time.Now()))
+			assert.NotNil(t, api.AddWorklog("FJ-8", "0m", time.Now()))
+			attached, err := api.AddAttachment("FJ-8", "retry.log", strings.NewReader("429 Too Many Requests"))
+			assert.Nil(t, err)
+			assert.Equal(t, "retry.log", attached[0].Filename)
+			var downloaded strings.Builder
+			assert.Nil(t, api.DownloadAttachment(&attached[0], &downloaded))
+			assert.Equal(t, "429 Too Many Requests", downloaded.String())
+			worklogs, err := api.FindWorklogs("FJ-8")
+			assert.Nil(t, err)
+			assert.Len(t, worklogs, 1)
+			assert.Equal(t, 5400, worklogs[0].TimeSpentSeconds)
+			assert.Equal(t, "Alice Andersen", worklogs[0].Author.DisplayName)
+			issue, err := api.GetIssueDetailed("FJ-8")
+			assert.Nil(t, err)
+			assert.Equal(t, "Carol Chen", issue.Fields.Assignee.DisplayName)
+			assert.Equal(t, transitions[0].To.Name, issue.Fields.Status.Name)
+			assert.Equal(t, "on it", issue.Fields.Comment.Comments[0].Body)
+			assert.Equal(t, "Use Retry-After", issue.Fields.Description)
+			assert.Equal(t, deployment == jira.DeploymentCloud, issue.Fields.DescriptionAdf != nil, "only Cloud should send documents")
+			assert.Equal(t, []string{"api", "retry"}, issue.Fields.Labels)
+			assert.Equal(t, int64(21), issue.Fields.Attachments[0].Size)
+			myself, err := api.GetMyself()
+			assert.Nil(t, err)
+			assert.True(t, myself.SameAs(&issue.Fields.Comment.Comments[0].Author))
+			roles, err := api.FindProjectRoles("FJ")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"Administrators", "Developers", "Service Desk Team"}, roles)
+			groups, err := api.FindGroups("sup")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"support"}, groups)
+			commentId := issue.Fields.Comment.Comments[0].Id
+			visibility := &jira.CommentVisibility{Type: jira.VisibilityRole, Value: "Developers"}
+			assert.Nil(t, api.UpdateComment("FJ-8", commentId, "on it, with retries", visibility))
+			assert.NotNil(t, api.UpdateComment("FJ-8", commentId, "on it", &jira.CommentVisibility{Type: jira.VisibilityGroup, Value: "nobody"}))
+			issue, _ = api.GetIssueDetailed("FJ-8")
+			assert.Equal(t, "on it, with retries", issue.Fields.Comment.Comments[0].Body)
+			assert.Equal(t, visibility, issue.Fields.Comment.Comments[0].Visibility)
+			assert.Nil(t, api.DeleteComment("FJ-8", commentId))
+			assert.NotNil(t, api.DeleteComment("FJ-8", commentId))
+			issue, _ = api.GetIssueDetailed("FJ-8")
+			assert.Empty(t, issue.Fields.Comment.Comments)
+			linkTypes, err := api.FindIssueLinkTypes()
+			assert.Nil(t, err)
+			assert.Equal(t, jira.IssueLinkType{Id: "10000", Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}, linkTypes[0])
+			assert.Nil(t, api.CreateIssueLink("Blocks", "FJ-8", "FJ-12"))
+			assert.NotNil(t, api.CreateIssueLink("Blocks", "FJ-8", "FJ-8"))
+			issue, _ = api.GetIssueDetailed("FJ-8")
+			assert.Len(t, issue.Fields.IssueLinks, 1)
+			assert.Equal(t, "blocks FJ-12", issue.Fields.IssueLinks[0].Line())
+			blocked, _ := api.GetIssueDetailed("FJ-12")
+			assert.Equal(t, "is blocked by FJ-8", blocked.Fields.IssueLinks[0].Line())
+			assert.Nil(t, api.DeleteIssueLink(issue.Fields.IssueLinks[0].Id))
+			assert.NotNil(t, api.DeleteIssueLink(issue.Fields.IssueLinks[0].Id))
+			issue, _ = api.GetIssueDetailed("FJ-8")
+			assert.Empty(t, issue.Fields.IssueLinks)
+			issueTypes, err := api.FindCreateMeta("FJ")
+			assert.Nil(t, err)
+			assert.Len(t, issueTypes, 5)
+			assert.True(t, issueTypes[4].Fields["parent"].Required, "sub-tasks need a parent")
+			created, err := api.CreateIssue(map[string]any{
+				"project":   map[string]string{"key": "FJ"},
+				"issuetype": map[string]string{"id": issueTypes[4].Id},
+				"summary":   "Retry on 503 too",
+				"parent":    map[string]string{"key": "FJ-8"},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, "FJ-14", created.Key)
+			subtask, err := api.GetIssueDetailed(created.Key)
+			assert.Nil(t, err)
+			assert.Equal(t, "FJ-8", subtask.Fields.Parent.Key)
+			editable, err := api.FindEditMeta(created.Key)
+			assert.Nil(t, err)
+			assert.Equal(t, "date", editable["duedate"].Schema.Type)
+			assert.Nil(t, api.UpdateIssueFields(created.Key, map[string]any{"priority": editable["priority"].AllowedValues[0], "duedate": "2024-05-31"}))
+			subtask, _ = api.GetIssueDetailed(created.Key)
+			assert.Equal(t, "Highest", subtask.Fields.Priority.Name)
+			assert.Equal(t, "2024-05-31", subtask.Fields.DueDate)
+			assert.NotNil(t, api.UpdateIssueFields(created.Key, map[string]any{"duedate": "tomorrow"}))
+			labels, err := api.FindLabels(nil, "re")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"retry"}, labels)
+
+			boards, err := api.FindBoards("FJ")
+			assert.Nil(t, err)
+			assert.Len(t, boards, 1)
+			configuration, err := api.GetBoardConfiguration(boards[0].Id)
+			assert.Nil(t, err)
+			assert.Len(t, configuration.ColumnConfig.Columns, 4)
+			sprints, err := api.GetBoardSprints(boards[0].Id)
+			assert.Nil(t, err)
+			assert.Len(t, sprints, 2)
+			sprintIssues, sprintTotal, _, err := api.GetBoardSprintIssues(boards[0].Id, sprints[0].Id, 0, 100)
+			assert.Nil(t, err)
+			assert.Equal(t, int32(len(sprintIssues)), sprintTotal)
+			boardProjects, err := api.GetBoardProjects(boards[0].Id)
+			assert.Nil(t, err)
+			assert.Equal(t, "FJ", boardProjects[0].Key)
+			filter, err := api.GetFilter(configuration.Filter.Id)
+			assert.Nil(t, err)
+			assert.Equal(t, "Open bugs", filter.Name)
+			filters, err := api.GetMyFilters()
+			assert.Nil(t, err)
+			assert.NotEmpty(t, filters)
+		})
+	}
+}
+
+func Test_fakeJira_should_read_documents_sent_to_cloud(t *testing.T) {
+	// given
+	api := newFakeApi(t, jira.DeploymentCloud)
+	doc := json.RawMessage(`{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"on it","marks":[{"type":"strong"}]}]}]}`)
+
+	// when
+	commentErr := api.DoCommentAdf("FJ-8", doc, nil)
+	descriptionErr := api.DoUpdateDescriptionAdf("FJ-8", doc)
+
+	// then
+	assert.Nil(t, commentErr)
+	assert.Nil(t, descriptionErr)
+	issue, err := api.GetIssueDetailed("FJ-8")
+	assert.Nil(t, err)
+	assert.Equal(t, "on it", issue.Fields.Comment.Comments[0].Body)
+	assert.Equal(t, "on it", issue.Fields.Description)
+	assert.Nil(t, api.UpdateCommentAdf("FJ-8", issue.Fields.Comment.Comments[0].Id, doc, nil))
+}
+
+func Test_fakeJira_should_render_users_per_deployment(t *testing.T) {
+	tests := []struct {
+		name          string
+		deployment    jira.DeploymentType
+		wantAccountId string
+		wantName      string
+	}{
+		{"should identify cloud users by account id", jira.DeploymentCloud, "712020:alice", ""},
+		{"should identify server users by name", jira.DeploymentServer, "", "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeApi(t, tt.deployment)
+
+			users, err := api.FindUsersWithQuery("FJ", "alice")
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantAccountId, users[0].AccountId)
+			assert.Equal(t, tt.wantName, users[0].Name)
+		})
+	}
+}
+
+func Test_fakeJira_should_answer_with_jira_errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(api jira.Api) error
+		wantStatus int
+		wantReason string
+	}{
+		{"should report missing issues", func(api jira.Api) error {
+			_, err := api.GetIssueDetailed("FJ-999")
+			return err
+		}, http.StatusNotFound, "Issue does not exist or you do not have permission to see it."},
+		{"should report invalid jql", func(api jira.Api) error {
+			_, err := api.SearchJql("sprint = openSprints() AND foo = bar")
+			return err
+		}, http.StatusBadRequest, "Field 'foo' does not exist or you do not have permission to view it."},
+		{"should refuse unbounded cloud searches", func(api jira.Api) error {
+			_, err := api.SearchJql("ORDER BY created")
+			return err
+		}, http.StatusBadRequest, "Unbounded JQL queries are not allowed here. Please add a search restriction to your query."},
+		{"should not have sprints on kanban boards", func(api jira.Api) error {
+			_, err := api.GetBoardSprints(2)
+			return err
+		}, http.StatusBadRequest, "The board does not support sprints"},
+		{"should reject transitions outside of the workflow", func(api jira.Api) error {
+			return api.DoTransition("FJ-1", &jira.IssueTransition{Id: "21"}, nil)
+		}, http.StatusBadRequest, "Transition id '21' is not valid for this issue."},
+		{"should require a resolution on done", func(api jira.Api) error {
+			return api.DoTransition("FJ-1", &jira.IssueTransition{Id: "41"}, nil)
+		}, http.StatusBadRequest, "Field 'resolution' is required"},
+		{"should not edit comments of others", func(api jira.Api) error {
+			issue, _ := api.GetIssueDetailed("FJ-6")
+			return api.UpdateComment("FJ-6", issue.Fields.Comment.Comments[1].Id, "LGTM", nil)
+		}, http.StatusForbidden, "You do not have the permission to edit or delete this comment."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeApi(t, jira.DeploymentCloud)
+
+			err := tt.call(api)
+
+			var jiraErr *jira.Error
+			assert.True(t, errors.As(err, &jiraErr))
+			assert.Equal(t, tt.wantStatus, jiraErr.StatusCode)
+			assert.Contains(t, jiraErr.Error(), tt.wantReason)
+		})
+	}
+}
+
+func Test_fakeJira_should_take_transition_screens(t *testing.T) {
+	// given
+	api := newFakeApi(t, jira.DeploymentServer)
+	transitions, err := api.FindTransitions("FJ-1")
+	assert.Nil(t, err)
+	var done *jira.IssueTransition
+	for i := range transitions {
+		if transitions[i].Id == "41" {
+			done = &transitions[i]
+		}
+	}
+	assert.NotNil(t, done)
+	assert.True(t, done.HasScreen)
+	assert.True(t, done.RequiresInput())
+	assert.Equal(t, "Won't Do", done.Fields["resolution"].AllowedValues[1].Label())
+
+	// when
+	err = api.DoTransition("FJ-1", done, &jira.TransitionInput{
+		Fields: map[string]any{"resolution": map[string]string{"id": "2"}},
+		Update: map[string]any{"comment": []any{map[string]any{"add": map[string]any{"body": "not needed"}}}},
+	})
+
+	// then
+	assert.Nil(t, err)
+	issue, err := api.GetIssueDetailed("FJ-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Done", issue.Fields.Status.Name)
+	comments := issue.Fields.Comment.Comments
+	assert.Equal(t, "not needed", comments[len(comments)-1].Body)
+}
+
+func Test_fakeJira_should_require_authorization(t *testing.T) {
+	// given
+	server := httptest.NewServer(NewDemo())
+	defer server.Close()
+
+	// when
+	response, err := http.Get(server.URL + "/rest/api/2/serverInfo")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func Test_Server_Start(t *testing.T) {
+	// given
+	server := NewDemo()
+	defer server.Close()
+
+	// when
+	url, err := server.Start()
+
+	// then
+	assert.Nil(t, err)
+	api, _ := jira.NewApi(url, "alice", "demo", jira.ApiToken)
+	info, err := api.GetServerInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, jira.DeploymentCloud, info.DeploymentType)
+}
+
+func Test_fakeJira_should_not_send_credentials_to_the_media_host(t *testing.T) {
+	// given
+	var authorization []string
+	media := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("12:00:01 render column 1")) //nolint:errcheck
+	}))
+	t.Cleanup(media.Close)
+	fake := New(DemoData(time.Now()), jira.DeploymentCloud)
+	fake.RedirectAttachments(media.URL)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	api, _ := jira.NewApiWithConfig(jira.ApiConfig{ApiUrl: server.URL, Username: "alice", Token: "demo", TokenType: jira.ApiToken})
+	issue, _ := api.GetIssueDetailed("FJ-6")
+
+	// when
+	var downloaded strings.Builder
+	err := api.DownloadAttachment(&issue.Fields.Attachments[0], &downloaded)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "12:00:01 render column 1", downloaded.String())
+	assert.Equal(t, []string{""}, authorization)
+}
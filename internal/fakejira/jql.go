@@ -0,0 +1,594 @@
+package fakejira
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The fake understands the subset of JQL fjira and its users write: AND/OR/NOT
+// and parentheses, =, !=, ~, !~, <, <=, >, >=, IN, NOT IN, IS [NOT] EMPTY,
+// relative dates like -30d, currentUser(), openSprints(),
+// subtaskIssueTypes() and ORDER BY.
+
+type jqlError struct {
+	message string
+}
+
+func (e *jqlError) Error() string {
+	return e.message
+}
+
+func jqlErrorf(format string, args ...any) error {
+	return &jqlError{message: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func tokenize(jql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(jql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenOpen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenClose, ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ","})
+			i++
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, jqlErrorf("Error in the JQL Query: The quoted string has not been completed.")
+			}
+			tokens = append(tokens, token{tokenString, value.String()})
+			i++
+		case strings.ContainsRune("=!~<>", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' || r == '!' && i+1 < len(runes) && runes[i+1] == '~' {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, jqlErrorf("Error in the JQL Query: The character '!' is a reserved JQL character.")
+			}
+			tokens = append(tokens, token{tokenOperator, op})
+			i += len(op)
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),\"'=!~<>", runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			// functions: currentUser(), openSprints(), ...
+			if i+1 < len(runes) && runes[i] == '(' && runes[i+1] == ')' && !strings.EqualFold(word, "in") {
+				word += "()"
+				i += 2
+			}
+			tokens = append(tokens, token{tokenWord, word})
+		}
+	}
+	return tokens, nil
+}
+
+type jqlExpr interface {
+	match(e *evaluator, issue *Issue) (bool, error)
+}
+
+type jqlAnd struct{ left, right jqlExpr }
+type jqlOr struct{ left, right jqlExpr }
+type jqlNot struct{ expr jqlExpr }
+
+type jqlClause struct {
+	field    string
+	operator string
+	values   []string
+}
+
+type jqlOrder struct {
+	field string
+	desc  bool
+}
+
+type jqlQuery struct {
+	where   jqlExpr
+	orderBy []jqlOrder
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parseJql(jql string) (*jqlQuery, error) {
+	tokens, err := tokenize(jql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	query := &jqlQuery{}
+	if !p.done() && !p.peek().is("order") {
+		if query.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.done() {
+		if !p.next().is("order") || p.done() || !p.next().is("by") {
+			return nil, jqlErrorf("Error in the JQL Query: Expecting either 'OR' or 'AND' but got '%s'.", p.tokens[p.pos-1].value)
+		}
+		for {
+			if p.done() {
+				return nil, jqlErrorf("Error in the JQL Query: Expecting a field name after ORDER BY.")
+			}
+			order := jqlOrder{field: strings.ToLower(p.next().value)}
+			if !p.done() && (p.peek().is("asc") || p.peek().is("desc")) {
+				order.desc = p.next().is("desc")
+			}
+			query.orderBy = append(query.orderBy, order)
+			if p.done() || p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if !p.done() {
+		return nil, jqlErrorf("Error in the JQL Query: Unexpected '%s'.", p.peek().value)
+	}
+	return query, nil
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *parser) parseOr() (jqlExpr, error) {
+	left, err := p.parseAnd()
+	for err == nil && !p.done() && p.peek().is("or") {
+		p.next()
+		var right jqlExpr
+		if right, err = p.parseAnd(); err == nil {
+			left = jqlOr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (jqlExpr, error) {
+	left, err := p.parseUnary()
+	for err == nil && !p.done() && p.peek().is("and") {
+		p.next()
+		var right jqlExpr
+		if right, err = p.parseUnary(); err == nil {
+			left = jqlAnd{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (jqlExpr, error) {
+	if p.done() {
+		return nil, jqlErrorf("Error in the JQL Query: Expecting a field name.")
+	}
+	switch t := p.peek(); {
+	case t.is("not"):
+		p.next()
+		expr, err := p.parseUnary()
+		return jqlNot{expr}, err
+	case t.kind == tokenOpen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.next().kind != tokenClose {
+			return nil, jqlErrorf("Error in the JQL Query: Expecting ')'.")
+		}
+		return expr, nil
+	}
+	return p.parseClause()
+}
+
+func (p *parser) parseClause() (jqlExpr, error) {
+	field := p.next()
+	if field.kind != tokenWord && field.kind != tokenString {
+		return nil, jqlErrorf("Error in the JQL Query: Expecting a field name but got '%s'.", field.value)
+	}
+	clause := jqlClause{field: strings.ToLower(field.value)}
+	if p.done() {
+		return nil, jqlErrorf("Error in the JQL Query: Expecting an operator after '%s'.", field.value)
+	}
+	switch op := p.next(); {
+	case op.kind == tokenOperator:
+		clause.operator = op.value
+	case op.is("in"):
+		clause.operator = "in"
+	case op.is("not") && !p.done() && p.peek().is("in"):
+		p.next()
+		clause.operator = "not in"
+	case op.is("is"):
+		clause.operator = "is"
+		if !p.done() && p.peek().is("not") {
+			p.next()
+			clause.operator = "is not"
+		}
+	default:
+		return nil, jqlErrorf("Error in the JQL Query: Expecting an operator but got '%s'.", op.value)
+	}
+	if clause.operator == "in" || clause.operator == "not in" {
+		// a function is a list on its own: sprint in openSprints()
+		if !p.done() && p.peek().kind == tokenWord && strings.HasSuffix(p.peek().value, "()") {
+			clause.values = []string{p.next().value}
+			return clause, nil
+		}
+		if p.done() || p.next().kind != tokenOpen {
+			return nil, jqlErrorf("Error in the JQL Query: Expecting '(' after IN.")
+		}
+		for !p.done() && p.peek().kind != tokenClose {
+			if t := p.next(); t.kind != tokenComma {
+				clause.values = append(clause.values, t.value)
+			}
+		}
+		if p.done() {
+			return nil, jqlErrorf("Error in the JQL Query: Expecting ')'.")
+		}
+		p.next()
+		return clause, nil
+	}
+	if p.done() {
+		return nil, jqlErrorf("Error in the JQL Query: Expecting a value after '%s %s'.", field.value, clause.operator)
+	}
+	value := p.next()
+	if (clause.operator == "is" || clause.operator == "is not") && !value.is("empty") && !value.is("null") {
+		return nil, jqlErrorf("Error in the JQL Query: Expecting EMPTY after IS but got '%s'.", value.value)
+	}
+	clause.values = []string{value.value}
+	return clause, nil
+}
+
+func (a jqlAnd) match(e *evaluator, issue *Issue) (bool, error) {
+	ok, err := a.left.match(e, issue)
+	if !ok || err != nil {
+		return false, err
+	}
+	return a.right.match(e, issue)
+}
+
+func (o jqlOr) match(e *evaluator, issue *Issue) (bool, error) {
+	ok, err := o.left.match(e, issue)
+	if ok || err != nil {
+		return ok, err
+	}
+	return o.right.match(e, issue)
+}
+
+func (n jqlNot) match(e *evaluator, issue *Issue) (bool, error) {
+	ok, err := n.expr.match(e, issue)
+	return !ok, err
+}
+
+// evaluator resolves field values and functions against the fake's data.
+type evaluator struct {
+	data *Data
+	now  time.Time
+}
+
+func (c jqlClause) match(e *evaluator, issue *Issue) (bool, error) {
+	switch c.field {
+	case "created", "updated":
+		return c.matchDate(e, issue)
+	case "resolution":
+		if len(c.values) == 1 && strings.EqualFold(c.values[0], "unresolved") {
+			c.operator = map[string]string{"=": "is", "!=": "is not"}[c.operator]
+		}
+	}
+	candidates, err := e.fieldValues(c.field, issue)
+	if err != nil {
+		return false, err
+	}
+	switch c.operator {
+	case "is":
+		return len(candidates) == 0, nil
+	case "is not":
+		return len(candidates) > 0, nil
+	case "=", "in":
+		return e.anyEqual(candidates, c.values), nil
+	case "!=", "not in":
+		return !e.anyEqual(candidates, c.values), nil
+	case "~", "!~":
+		contains := false
+		needle := strings.ToLower(strings.Trim(c.values[0], "*"))
+		for _, candidate := range candidates {
+			contains = contains || strings.Contains(strings.ToLower(candidate), needle)
+		}
+		return contains == (c.operator == "~"), nil
+	}
+	return false, jqlErrorf("The operator '%s' is not supported by the '%s' field.", c.operator, c.field)
+}
+
+func (e *evaluator) anyEqual(candidates []string, values []string) bool {
+	for _, value := range values {
+		for _, resolved := range e.resolve(value) {
+			for _, candidate := range candidates {
+				if strings.EqualFold(candidate, resolved) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// resolve expands functions into the values they stand for.
+func (e *evaluator) resolve(value string) []string {
+	switch strings.ToLower(value) {
+	case "currentuser()":
+		return []string{e.data.CurrentUser}
+	case "subtaskissuetypes()":
+		return []string{subtaskIssueType}
+	case "opensprints()", "closedsprints()":
+		var ids []string
+		for _, sprint := range e.data.Sprints {
+			if (sprint.State == sprintStateClosed) == (strings.ToLower(value) == "closedsprints()") {
+				ids = append(ids, strconv.Itoa(sprint.Id))
+			}
+		}
+		return ids
+	}
+	return []string{value}
+}
+
+// fieldValues lists everything a value of the field may be compared with -
+// e.g. a status matches both its id and its name. Empty means the field is
+// EMPTY.
+func (e *evaluator) fieldValues(field string, issue *Issue) ([]string, error) {
+	switch field {
+	case "project":
+		if project := e.data.project(issue.ProjectKey); project != nil {
+			return []string{project.Id, project.Key, project.Name}, nil
+		}
+		return []string{issue.ProjectKey}, nil
+	case "key", "issuekey", "id":
+		return []string{issue.Key, issue.Id}, nil
+	case "summary":
+		return []string{issue.Summary}, nil
+	case "description":
+		return nonEmpty(issue.Description), nil
+	case "text":
+		values := []string{issue.Summary, issue.Description}
+		for _, c := range issue.Comments {
+			values = append(values, c.Body)
+		}
+		return values, nil
+	case "comment":
+		var values []string
+		for _, c := range issue.Comments {
+			values = append(values, c.Body)
+		}
+		return values, nil
+	case "status":
+		if status := e.data.status(issue.Status); status != nil {
+			return []string{status.Id, status.Name}, nil
+		}
+		return []string{issue.Status}, nil
+	case "statuscategory":
+		if status := e.data.status(issue.Status); status != nil {
+			return []string{status.Category, categoryName(status.Category)}, nil
+		}
+		return nil, nil
+	case "resolution":
+		if status := e.data.status(issue.Status); status != nil && status.Category == CategoryDone {
+			return []string{"Done"}, nil
+		}
+		// unresolved issues are EMPTY, see jqlClause.match for "= Unresolved"
+		return nil, nil
+	case "assignee", "reporter":
+		accountId := issue.Assignee
+		if field == "reporter" {
+			accountId = issue.Reporter
+		}
+		if user := e.data.user(accountId); user != nil {
+			return []string{user.AccountId, user.Name, user.DisplayName, user.Email}, nil
+		}
+		return nonEmpty(accountId), nil
+	case "labels":
+		return issue.Labels, nil
+	case "type", "issuetype":
+		return []string{issue.Type}, nil
+	case "priority":
+		return []string{issue.Priority}, nil
+	case "parent":
+		if parent := e.data.issue(issue.Parent); parent != nil {
+			return []string{parent.Key, parent.Id}, nil
+		}
+		return nil, nil
+	case "sprint":
+		if sprint := e.data.sprint(issue.Sprint); sprint != nil {
+			return []string{strconv.Itoa(sprint.Id), sprint.Name}, nil
+		}
+		return nil, nil
+	}
+	return nil, jqlErrorf("Field '%s' does not exist or you do not have permission to view it.", field)
+}
+
+func (c jqlClause) matchDate(e *evaluator, issue *Issue) (bool, error) {
+	value := issue.Created
+	if c.field == "updated" {
+		value = issue.Updated
+	}
+	if len(c.values) != 1 {
+		return false, jqlErrorf("The operator '%s' is not supported by the '%s' field.", c.operator, c.field)
+	}
+	at, err := e.parseDate(c.field, c.values[0])
+	if err != nil {
+		return false, err
+	}
+	switch c.operator {
+	case ">=":
+		return !value.Before(at), nil
+	case ">":
+		return value.After(at), nil
+	case "<=":
+		return !value.After(at), nil
+	case "<":
+		return value.Before(at), nil
+	case "=":
+		return value.Equal(at), nil
+	case "!=":
+		return !value.Equal(at), nil
+	}
+	return false, jqlErrorf("The operator '%s' is not supported by the '%s' field.", c.operator, c.field)
+}
+
+// parseDate accepts what Jira does: "-30d", "2w", "-4h", "2024-01-31",
+// "2024-01-31 10:00", now() and startOfDay().
+func (e *evaluator) parseDate(field string, value string) (time.Time, error) {
+	switch strings.ToLower(value) {
+	case "now()":
+		return e.now, nil
+	case "startofday()":
+		y, m, d := e.now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, e.now.Location()), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006/01/02 15:04", "2006-01-02", "2006/01/02"} {
+		if at, err := time.ParseInLocation(layout, value, e.now.Location()); err == nil {
+			return at, nil
+		}
+	}
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(value) >= 2 {
+		if unit, ok := units[value[len(value)-1]]; ok {
+			if n, err := strconv.Atoi(value[:len(value)-1]); err == nil {
+				return e.now.Add(time.Duration(n) * unit), nil
+			}
+		}
+	}
+	return time.Time{}, jqlErrorf("Date value '%s' for field '%s' is invalid.", value, field)
+}
+
+// sort orders issues by the ORDER BY fields; with none, the newest first.
+func (e *evaluator) sort(issues []*Issue, orderBy []jqlOrder) error {
+	if len(orderBy) == 0 {
+		orderBy = []jqlOrder{{field: "created", desc: true}}
+	}
+	for _, order := range orderBy {
+		if !slices.Contains(sortableFields, order.field) {
+			return jqlErrorf("Field '%s' does not exist or you do not have permission to view it.", order.field)
+		}
+	}
+	rank := make(map[*Issue]int, len(e.data.Issues))
+	for i, issue := range e.data.Issues {
+		rank[issue] = i
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		for _, order := range orderBy {
+			c := e.compare(order.field, issues[i], issues[j])
+			if order.field == "rank" {
+				c = rank[issues[i]] - rank[issues[j]]
+			}
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != order.desc
+		}
+		return false
+	})
+	return nil
+}
+
+var sortableFields = []string{"created", "updated", "key", "issuekey", "id", "priority", "status", "summary", "rank", "assignee"}
+
+// compare returns <0, 0 or >0. Rank is the order of Data.Issues, handled by sort.
+func (e *evaluator) compare(field string, a, b *Issue) int {
+	statusIndex := func(issue *Issue) int {
+		return slices.IndexFunc(e.data.Statuses, func(s Status) bool { return s.Id == issue.Status })
+	}
+	keyNumber := func(issue *Issue) int {
+		_, n, _ := strings.Cut(issue.Key, "-")
+		number, _ := strconv.Atoi(n)
+		return number
+	}
+	switch field {
+	case "created":
+		return a.Created.Compare(b.Created)
+	case "updated":
+		return a.Updated.Compare(b.Updated)
+	case "key", "issuekey", "id":
+		if c := strings.Compare(a.ProjectKey, b.ProjectKey); c != 0 {
+			return c
+		}
+		return keyNumber(a) - keyNumber(b)
+	case "priority":
+		// Highest first when ascending, as Jira does
+		return slices.Index(e.data.Priorities, a.Priority) - slices.Index(e.data.Priorities, b.Priority)
+	case "status":
+		return statusIndex(a) - statusIndex(b)
+	case "summary":
+		return strings.Compare(strings.ToLower(a.Summary), strings.ToLower(b.Summary))
+	case "assignee":
+		return strings.Compare(a.Assignee, b.Assignee)
+	}
+	return 0
+}
+
+func categoryName(category string) string {
+	switch category {
+	case CategoryNew:
+		return "To Do"
+	case CategoryInProgress:
+		return "In Progress"
+	case CategoryDone:
+		return "Done"
+	}
+	return category
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
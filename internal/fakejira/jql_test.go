@@ -0,0 +1,72 @@
+package fakejira
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchKeys(t *testing.T, jql string) ([]string, error) {
+	t.Helper()
+	s := New(DemoData(time.Now()), "")
+	issues, err := s.search(jql)
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	return keys, err
+}
+
+func Test_search_jql(t *testing.T) {
+	tests := []struct {
+		name string
+		jql  string
+		want []string
+	}{
+		{"should match project by key", "project = OPS ORDER BY key", []string{"OPS-1", "OPS-2", "OPS-3", "OPS-4"}},
+		{"should match project by id", "project=10001 ORDER BY key DESC", []string{"OPS-4", "OPS-3", "OPS-2", "OPS-1"}},
+		{"should match summary prefix like fjira's search", `project=10000 AND summary~"retry*"`, []string{"FJ-8"}},
+		{"should match issue key", `summary~"FJ-3*" OR issuekey="FJ-3"`, []string{"FJ-3"}},
+		{"should match status by id or name", `project=OPS AND status IN (3, "In Review") ORDER BY key`, []string{"OPS-2", "OPS-4"}},
+		{"should match status category", "project=OPS AND statusCategory = Done", []string{"OPS-3"}},
+		{"should resolve currentUser", "project=OPS AND assignee = currentUser()", []string{"OPS-2"}},
+		{"should match unassigned issues", "project=FJ AND assignee IS EMPTY ORDER BY key", []string{"FJ-8", "FJ-10", "FJ-11", "FJ-12"}},
+		{"should match labels", "labels = boards", []string{"FJ-6"}},
+		{"should negate with NOT", "project=OPS AND NOT status = 1 AND priority != Highest ORDER BY key", []string{"OPS-3", "OPS-4"}},
+		{"should bind AND tighter than OR", "labels = docs OR project = OPS AND type = Bug ORDER BY key", []string{"FJ-13", "OPS-2"}},
+		{"should match parents", "parent = FJ-3 ORDER BY key", []string{"FJ-4", "FJ-5"}},
+		{"should match open sprints", "sprint in openSprints() AND type = Bug ORDER BY priority", []string{"FJ-6", "FJ-7"}},
+		{"should treat unresolved as not done", "project=OPS AND resolution = Unresolved AND status != 3 ORDER BY key", []string{"OPS-1", "OPS-4"}},
+		{"should compare relative dates", "project=OPS AND created >= -5d ORDER BY created", []string{"OPS-1", "OPS-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := searchKeys(t, tt.jql)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_search_invalid_jql(t *testing.T) {
+	tests := []struct {
+		name string
+		jql  string
+	}{
+		{"should fail for unknown fields", "foo = bar"},
+		{"should fail for unknown sort fields", "project = FJ ORDER BY foo"},
+		{"should fail for unclosed quotes", `summary ~ "abc`},
+		{"should fail for unclosed parentheses", "(project = FJ"},
+		{"should fail for missing values", "project ="},
+		{"should fail for invalid dates", "created > yesterday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := searchKeys(t, tt.jql)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -32,6 +32,10 @@ func (view *filtersSearchView) Destroy() {
 	view.bottomBar.Destroy()
 }
 
+func (view *filtersSearchView) Reload() {
+	app.GoTo("filters", view.api)
+}
+
 func (view *filtersSearchView) Draw(screen tcell.Screen) {
 	if view.fuzzyFind != nil {
 		view.fuzzyFind.Draw(screen)
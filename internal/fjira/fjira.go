@@ -1,12 +1,17 @@
 package fjira
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/boards"
 	"github.com/mk-5/fjira/internal/filters"
@@ -39,6 +44,13 @@ type Fjira struct {
 	api       jira.Api
 	jiraUrl   string
 	workspace string
+	settings  *workspaces.WorkspaceSettings
+	// cache is the caching decorator api is wrapped in; nil when disabled
+	cache *jira.CachingApi
+	// snapshots keeps what's been seen online for --offline, and the
+	// changes queued there
+	snapshots *jira.SnapshotStore
+	offline   bool
 }
 
 // CliArgs TODO - drop it, and use cobra directly
@@ -56,6 +68,9 @@ type CliArgs struct {
 var (
 	fjiraInstance *Fjira
 	fjiraOnce     sync.Once
+	// settingsMutex serializes changes to the session's workspace settings:
+	// rotated OAuth tokens come in on the transport's goroutine
+	settingsMutex sync.Mutex
 )
 
 func CreateNewFjira(settings *workspaces.WorkspaceSettings) *Fjira {
@@ -64,26 +79,149 @@ func CreateNewFjira(settings *workspaces.WorkspaceSettings) *Fjira {
 	}
 	fjiraOnce.Do(func() {
 		url := strings.TrimSuffix(settings.JiraRestUrl, "/")
-		api, err := jira.NewApi(url, settings.JiraUsername, settings.JiraToken, settings.JiraTokenType)
-		if err != nil {
-			app.Error(err.Error())
+		retry := jira.DefaultRetryPolicy()
+		if settings.Retry != nil {
+			retry = settings.Retry.WithDefaults()
+		}
+		cfg := jira.ApiConfig{
+			ApiUrl:        url,
+			Username:      settings.JiraUsername,
+			Token:         resolveToken(settings),
+			TokenType:     settings.JiraTokenType,
+			Retry:         retry,
+			Deployment:    settings.DeploymentType,
+			ServerVersion: settings.ServerVersion,
+			Transport:     newTransport(settings),
+			Connection:    connectionConfig(settings),
+		}
+		if settings.CustomFields != nil {
+			cfg.CustomFields = *settings.CustomFields
+		}
+		if settings.OAuth != nil && settings.OAuthTokens != nil {
+			cfg.OAuth = *settings.OAuth
+			cfg.OAuthTokens = *settings.OAuthTokens
+			cfg.OnOAuthRefresh = storeOAuthTokens(settings)
+		}
+		if settings.Record != "" || settings.Replay != "" {
+			// the session has to carry its own serverInfo, so it replays
+			// against the same endpoints it was recorded with
+			cfg.Deployment = ""
+			cfg.ServerVersion = ""
+		}
+		snapshots := jira.NewSnapshotStore(snapshotDir(settings))
+		var api jira.Api
+		var cache *jira.CachingApi
+		if settings.Offline {
+			api = jira.NewOfflineApi(snapshots, cfg)
+		} else {
+			online, err := jira.NewApiWithConfig(cfg)
+			if err != nil {
+				// a broken CA bundle or client certificate
+				log.Fatalln(err)
+			}
+			cache = newCache(online, settings)
+			if cache != nil {
+				online = cache
+			}
+			// every online session feeds the snapshot --offline works on,
+			// see the flag's help
+			api = jira.NewSnapshotApi(online, snapshots)
 		}
 		fjiraInstance = &Fjira{
 			app:       app.CreateNewApp(),
 			api:       api,
 			jiraUrl:   url,
 			workspace: settings.Workspace,
+			settings:  settings,
+			cache:     cache,
+			snapshots: snapshots,
+			offline:   settings.Offline,
 		}
 	})
 	return fjiraInstance
 }
 
+// resolveToken reads the token from the workspace's credential source. Offline
+// and replayed sessions never reach Jira, so they don't run token commands or
+// ask for a passphrase.
+func resolveToken(settings *workspaces.WorkspaceSettings) string {
+	if settings.Offline || settings.Replay != "" {
+		return settings.JiraToken
+	}
+	token, err := settings.ResolveToken()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return token
+}
+
+// newCache wraps api in the response cache configured for the workspace.
+// The disk cache lives in ~/.fjira/cache/<workspace>; settings coming from
+// env variables have no workspace, so they only get the in-memory one.
+func newCache(api jira.Api, settings *workspaces.WorkspaceSettings) *jira.CachingApi {
+	var cfg jira.CacheConfig
+	if settings.Cache != nil {
+		cfg = *settings.Cache
+	}
+	if cfg.Disabled {
+		return nil
+	}
+	if settings.Record != "" || settings.Replay != "" {
+		// disk hits would leave requests out of the recording
+		return jira.NewCachingApi(api, cfg)
+	}
+	if configDir, err := workspaces.NewUserHomeSettingsStorage().ConfigDir(); err == nil && settings.Workspace != "" {
+		cfg.Dir = filepath.Join(configDir, "cache", settings.Workspace)
+	}
+	return jira.NewCachingApi(api, cfg)
+}
+
+// snapshotDir is ~/.fjira/offline/<workspace>. Settings coming from env
+// variables have no workspace, so their snapshot isn't kept between runs;
+// neither is a replayed session's.
+func snapshotDir(settings *workspaces.WorkspaceSettings) string {
+	configDir, err := workspaces.NewUserHomeSettingsStorage().ConfigDir()
+	if err != nil || settings.Workspace == "" || settings.Replay != "" {
+		return ""
+	}
+	return filepath.Join(configDir, "offline", settings.Workspace)
+}
+
+// newTransport returns the transport for --record/--replay, or nil for the
+// default one. A recording that can't be opened is fatal - falling back to
+// the real Jira would be a surprise.
+func newTransport(settings *workspaces.WorkspaceSettings) http.RoundTripper {
+	var transport http.RoundTripper
+	var err error
+	switch {
+	case settings.Replay != "":
+		transport, err = jira.NewReplayTransport(settings.Replay)
+	case settings.Record != "":
+		var connection http.RoundTripper
+		if connection, err = jira.NewHttpTransport(connectionConfig(settings)); err == nil {
+			transport, err = jira.NewRecordingTransport(settings.Record, connection)
+		}
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return transport
+}
+
+func connectionConfig(settings *workspaces.WorkspaceSettings) jira.ConnectionConfig {
+	if settings.Connection == nil {
+		return jira.ConnectionConfig{}
+	}
+	return *settings.Connection
+}
+
 func (f *Fjira) Run(args *CliArgs) {
 	x := app.ClampInt(f.app.ScreenX/2-18, 0, f.app.ScreenX)
 	y := app.ClampInt(f.app.ScreenY/2-4, 0, f.app.ScreenY)
 	welcomeText := app.NewText(x, y, app.DefaultStyle(), WelcomeMessage)
 	f.app.AddDrawable(welcomeText)
 	f.registerGoTos()
+	f.app.SetGlobalKey(tcell.KeyF5, f.refresh)
 	go f.bootstrap(args)
 	f.app.Start()
 }
@@ -98,6 +236,7 @@ func (f *Fjira) Close() {
 func (f *Fjira) registerGoTos() {
 	projects.RegisterGoto()
 	issues.RegisterGoTo()
+	issues.UseWorkspace(f.settings)
 	users.RegisterGoTo()
 	statuses.RegisterGoTo()
 	labels.RegisterGoTo()
@@ -109,6 +248,16 @@ func (f *Fjira) registerGoTos() {
 
 func (f *Fjira) bootstrap(args *CliArgs) {
 	defer f.app.PanicRecover()
+	if connectionConfig(f.settings).InsecureSkipVerify && !f.offline {
+		app.Error(ui.MessageInsecureSkipVerify)
+	}
+	if f.offline {
+		app.Success(ui.MessageOfflineMode)
+	} else {
+		f.detectDeployment()
+		f.replayPending()
+	}
+	f.loadFields()
 	if args.BoardId != 0 {
 		f.openBoardDirect(args.BoardId, args.ProjectId)
 		return
@@ -144,6 +293,102 @@ func (f *Fjira) bootstrap(args *CliArgs) {
 	})
 }
 
+// refresh is bound to F5 on every screen: it drops the response cache and
+// opens the current view again, so everything on it is fetched fresh. Views
+// that aren't app.Reloadable are left alone.
+func (f *Fjira) refresh() {
+	view, ok := f.app.CurrentView().(app.Reloadable)
+	if !ok {
+		return
+	}
+	if f.cache != nil {
+		f.cache.Invalidate()
+	}
+	go view.Reload()
+}
+
+// storeOAuthTokens keeps the rotated tokens of a workspace - the refresh
+// token it was started with doesn't work anymore after a refresh.
+func storeOAuthTokens(settings *workspaces.WorkspaceSettings) func(jira.OAuthTokens) {
+	return func(tokens jira.OAuthTokens) {
+		settingsMutex.Lock()
+		defer settingsMutex.Unlock()
+		settings.OAuthTokens = &tokens
+		if settings.Workspace != "" {
+			_ = workspaces.NewUserHomeSettingsStorage().Write(settings.Workspace, settings)
+		}
+	}
+}
+
+// detectDeployment probes serverInfo once per workspace and caches the
+// result in the workspace settings, so later launches skip the request. On
+// failure the api keeps guessing from the token type, and we try again next
+// launch.
+func (f *Fjira) detectDeployment() {
+	if f.api.Capabilities().Detected {
+		return
+	}
+	app.GetApp().Loading(true)
+	info, err := f.api.WithContext(app.GetApp().LoadingContext()).GetServerInfo()
+	app.GetApp().Loading(false)
+	if err != nil || info.DeploymentType == "" {
+		return
+	}
+	if f.settings == nil || f.settings.Workspace == "" || f.settings.Replay != "" {
+		// settings from env variables - nothing to cache them in; a replayed
+		// session may come from a different Jira
+		return
+	}
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	f.settings.DeploymentType = info.DeploymentType
+	f.settings.ServerVersion = info.Version
+	_ = workspaces.NewUserHomeSettingsStorage().Write(f.settings.Workspace, f.settings)
+}
+
+// loadFields resolves the names of the custom fields configured for the
+// workspace. The fields rarely change, so they're cached for a day.
+func (f *Fjira) loadFields() {
+	if f.settings == nil || f.settings.CustomFields == nil || f.settings.CustomFields.IsEmpty() {
+		return
+	}
+	app.GetApp().Loading(true)
+	fields, err := f.api.WithContext(app.GetApp().LoadingContext()).FindFields()
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, jira.ErrOffline) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchFields, ui.JiraErrorReason(err)))
+		}
+		return
+	}
+	f.api.UseFields(fields)
+}
+
+// replayPending sends the changes queued in offline mode. Only the first
+// conflict fits into the flash; all of them are written next to the
+// snapshot.
+func (f *Fjira) replayPending() {
+	if len(f.snapshots.Pending()) == 0 {
+		return
+	}
+	app.GetApp().Loading(true)
+	result := f.snapshots.Replay(f.api.WithContext(app.GetApp().LoadingContext()))
+	app.GetApp().Loading(false)
+	switch {
+	case len(result.Conflicts) > 0:
+		conflict := result.Conflicts[0]
+		reason := conflict.Reason
+		if conflict.Err != nil {
+			reason = ui.JiraErrorReason(conflict.Err)
+		}
+		app.Error(fmt.Sprintf(ui.MessageOfflineReplayConflicts, len(result.Conflicts), conflict.Operation, reason, f.snapshots.ConflictsPath()))
+	case result.Remaining > 0:
+		app.Error(fmt.Sprintf(ui.MessageOfflineReplayRemaining, result.Remaining))
+	case result.Applied > 0:
+		app.Success(fmt.Sprintf(ui.MessageOfflineReplaySuccess, result.Applied))
+	}
+}
+
 // openBoardDirect opens a board view directly from CLI by board ID, resolving
 // its associated project automatically when not supplied. Every failure path
 // surfaces a flash error so the user isn't left staring at an empty screen.
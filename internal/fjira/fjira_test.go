@@ -3,11 +3,15 @@ package fjira
 import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/issues"
 	"github.com/mk-5/fjira/internal/jira"
 	os2 "github.com/mk-5/fjira/internal/os"
+	"github.com/mk-5/fjira/internal/ui"
 	"github.com/mk-5/fjira/internal/workspaces"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -108,3 +112,88 @@ func TestFjira_run_should_run_without_error(t *testing.T) {
 	// then
 	assert.False(t, app.GetApp().IsQuit())
 }
+
+func TestFjira_detectDeployment_should_cache_deployment_in_workspace(t *testing.T) {
+	// given
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	app.CreateNewAppWithScreen(screen)
+	storage := workspaces.NewUserHomeSettingsStorage()
+	settings := &workspaces.WorkspaceSettings{JiraRestUrl: "http://test", Workspace: "work"}
+	_ = storage.Write("work", settings)
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"deploymentType":"DataCenter","version":"9.12.0"}`)) //nolint:errcheck
+	})
+	fjira := &Fjira{api: api, settings: settings}
+
+	// when
+	fjira.detectDeployment()
+
+	// then
+	saved, err := storage.Read("work")
+	assert.Nil(t, err)
+	assert.Equal(t, jira.DeploymentDataCenter, saved.DeploymentType)
+	assert.Equal(t, "9.12.0", saved.ServerVersion)
+	assert.True(t, api.IsJiraServer())
+}
+
+func TestFjira_refresh(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+
+	tests := []struct {
+		name     string
+		view     func(api jira.Api) app.View
+		reloaded bool
+	}{
+		{"should reload the issue view", func(api jira.Api) app.View {
+			return issues.NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api)
+		}, true},
+		{"should keep the issue create form", func(api jira.Api) app.View {
+			return issues.NewCreateIssueView("ABC", nil, api)
+		}, false},
+		{"should keep the text writer", func(api jira.Api) app.View {
+			return ui.NewTextWriterView(&ui.TextWriterArgs{})
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			a := app.CreateNewAppWithScreen(screen)
+			var mutex sync.Mutex
+			var paths []string
+			api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				paths = append(paths, r.URL.Path)
+				mutex.Unlock()
+				w.WriteHeader(200)
+				w.Write([]byte("{}")) //nolint:errcheck
+			})
+			fjira := CreateNewFjira(&workspaces.WorkspaceSettings{})
+			fjira.registerGoTos()
+			fjira.app = a
+			fjira.api = api
+			a.SetView(tt.view(api))
+
+			// when
+			fjira.refresh()
+
+			// then
+			reloaded := func() bool {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return slices.Contains(paths, "/rest/api/2/issue/ABC-1")
+			}
+			if tt.reloaded {
+				assert.Eventually(t, reloaded, time.Second, 10*time.Millisecond)
+			} else {
+				assert.Never(t, reloaded, 200*time.Millisecond, 10*time.Millisecond)
+			}
+		})
+	}
+}
@@ -2,15 +2,20 @@ package fjira
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/fakejira"
 	"github.com/mk-5/fjira/internal/jira"
 	"github.com/mk-5/fjira/internal/ui"
 	"github.com/mk-5/fjira/internal/workspaces"
@@ -21,12 +26,30 @@ const (
 	JiraUsernameEnv = "FJIRA_USERNAME"
 	JiraTokenType   = "FJIRA_JIRA_TOKEN_TYPE"
 	JiraRestUrlEnv  = "FJIRA_REST_URL"
+	// oauthSignInTimeout is how long the browser sign-in is waited for
+	oauthSignInTimeout = 5 * time.Minute
+)
+
+// where the token is kept, as numbered in the install prompt
+const (
+	tokenInSettings = iota + 1
+	tokenFromCommand
+	tokenFromEnv
+	tokenInEncryptedFile
 )
 
 var (
 	ErrEnvironmentsMissing    = errors.New("cannot find " + JiraTokenEnv + " or " + JiraUsernameEnv + " or " + JiraRestUrlEnv + " environments. Please add them in order to use Jira REST API")
 	ErrWorkspaceFormatInvalid = errors.New("workspace name needs to match pattern [a-z0-9]{2,50}")
+	ErrTokenSourceEmpty       = errors.New("the token command, variable, file or passphrase cannot be empty")
+	ErrNoTokenToEncrypt       = errors.New("enter the token to encrypt it into a file")
 	workspaceRegExp           = regexp.MustCompile("^[a-z0-9]{2,50}$")
+	// openAuthorizeUrl sends the user to sign in; swapped in tests
+	openAuthorizeUrl = func(url string) {
+		fmt.Println(ui.MessageOAuthOpenBrowser)
+		fmt.Println(color.BlueString(url))
+		_ = app.TryOpenLink(url)
+	}
 )
 
 func Install(workspace string) (*workspaces.WorkspaceSettings, error) {
@@ -51,6 +74,22 @@ func Install(workspace string) (*workspaces.WorkspaceSettings, error) {
 	return s2, nil
 }
 
+// InstallDemo starts the fake Jira with its demo data in the background and
+// returns settings pointing at it. Nothing is stored - the server, and
+// everything changed on it, lives as long as the process.
+func InstallDemo() (*workspaces.WorkspaceSettings, error) {
+	url, err := fakejira.NewDemo().Start()
+	if err != nil {
+		return nil, err
+	}
+	return &workspaces.WorkspaceSettings{
+		JiraRestUrl:   url,
+		JiraUsername:  "demo",
+		JiraToken:     "demo",
+		JiraTokenType: jira.ApiToken,
+	}, nil
+}
+
 func EditWorkspaceAndReadSettings(input io.Reader, workspace string) (*workspaces.WorkspaceSettings, error) {
 	var settingsStorage = workspaces.NewUserHomeSettingsStorage()
 	settings, err := settingsStorage.Read(workspace)
@@ -151,7 +190,7 @@ func readFromUserInputAndStore(input io.Reader, workspace string, existingSettin
 	if err != nil {
 		return nil, err
 	}
-	tokenTypeOptions := []string{string(jira.ApiToken), string(jira.PersonalToken)}
+	tokenTypeOptions := []string{string(jira.ApiToken), string(jira.PersonalToken), string(jira.OAuthToken)}
 	fmt.Print(color.HiYellowString(ui.MessageQuestionMark))
 	fmt.Print(ui.MessageEnterJiraTokenType)
 	if existingSettings != nil && existingSettings.JiraTokenType != "" {
@@ -160,6 +199,7 @@ func readFromUserInputAndStore(input io.Reader, workspace string, existingSettin
 	fmt.Println("")
 	fmt.Println("1. api token")
 	fmt.Println("2. personal token")
+	fmt.Println("3. oauth (Jira Cloud, signs in with your browser)")
 	fmt.Println("")
 	var tokenOption int
 	for {
@@ -198,6 +238,24 @@ func readFromUserInputAndStore(input io.Reader, workspace string, existingSettin
 	if existingSettings != nil && settings.JiraRestUrl == "" {
 		settings.JiraRestUrl = existingSettings.JiraRestUrl
 	}
+	if existingSettings != nil {
+		settings.Retry = existingSettings.Retry
+		settings.Cache = existingSettings.Cache
+		settings.Connection = existingSettings.Connection
+		settings.CustomFields = existingSettings.CustomFields
+		settings.DownloadDir = existingSettings.DownloadDir
+	}
+	if existingSettings != nil && settings.JiraRestUrl == existingSettings.JiraRestUrl {
+		settings.DeploymentType = existingSettings.DeploymentType
+		settings.ServerVersion = existingSettings.ServerVersion
+	}
+	if settings.JiraTokenType == jira.OAuthToken {
+		if err := readOAuthAndSignIn(reader, settings, existingSettings); err != nil {
+			return nil, err
+		}
+	} else if err := readTokenStorage(reader, workspaceName, settings, existingSettings); err != nil {
+		return nil, err
+	}
 	var settingsStorage = workspaces.NewUserHomeSettingsStorage()
 	err = settingsStorage.Write(workspace, settings)
 	if err != nil {
@@ -207,6 +265,181 @@ func readFromUserInputAndStore(input io.Reader, workspace string, existingSettin
 	return settings, err
 }
 
+// readOAuthAndSignIn asks for the OAuth app, signs in with the browser and
+// picks the Jira site matching the entered url - or the only one, when the
+// url was left empty.
+func readOAuthAndSignIn(reader *bufio.Reader, settings *workspaces.WorkspaceSettings, existingSettings *workspaces.WorkspaceSettings) error {
+	cfg := jira.OAuthConfig{}
+	if existingSettings != nil && existingSettings.OAuth != nil {
+		cfg = *existingSettings.OAuth
+	}
+	fmt.Print(color.HiYellowString(ui.MessageQuestionMark))
+	fmt.Print(ui.MessageEnterOAuthClientId)
+	if cfg.ClientId != "" {
+		fmt.Print(color.BlueString("[%s] ", cfg.ClientId))
+	}
+	clientId, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	fmt.Print(color.HiYellowString(ui.MessageQuestionMark))
+	fmt.Print(ui.MessageEnterOAuthClientSecret)
+	clientSecret, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if strings.TrimSpace(clientId) != "" {
+		cfg.ClientId = strings.TrimSpace(clientId)
+	}
+	if strings.TrimSpace(clientSecret) != "" {
+		cfg.ClientSecret = strings.TrimSpace(clientSecret)
+	}
+	// signing in goes through the workspace's proxy and certificates too
+	transport, err := jira.NewHttpTransport(connectionConfig(settings))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), oauthSignInTimeout)
+	defer cancel()
+	tokens, err := jira.AuthorizeOAuth(ctx, transport, cfg, openAuthorizeUrl)
+	if err != nil {
+		return err
+	}
+	resources, err := jira.FindOAuthResources(ctx, transport, cfg, tokens.AccessToken)
+	if err != nil {
+		return err
+	}
+	site, err := jira.SelectOAuthResource(resources, settings.JiraRestUrl)
+	if err != nil {
+		return err
+	}
+	cfg.CloudId = site.Id
+	settings.JiraRestUrl = site.Url
+	settings.JiraToken = ""
+	settings.OAuth = &cfg
+	settings.OAuthTokens = tokens
+	settings.DeploymentType = jira.DeploymentCloud
+	return nil
+}
+
+// readTokenStorage asks where the token is kept: in fjira.yaml, or behind one
+// of the credential sources keeping it out of there. Picking the encrypted
+// file while the workspace has a plain token moves that token into the file.
+func readTokenStorage(reader *bufio.Reader, workspace string, settings *workspaces.WorkspaceSettings, existingSettings *workspaces.WorkspaceSettings) error {
+	current := tokenInSettings
+	if existingSettings != nil {
+		settings.JiraTokenCommand = existingSettings.JiraTokenCommand
+		settings.JiraTokenEnv = existingSettings.JiraTokenEnv
+		settings.JiraTokenFile = existingSettings.JiraTokenFile
+		switch {
+		case settings.JiraTokenCommand != "":
+			current = tokenFromCommand
+		case settings.JiraTokenEnv != "":
+			current = tokenFromEnv
+		case settings.JiraTokenFile != "":
+			current = tokenInEncryptedFile
+		}
+	}
+	fmt.Print(color.HiYellowString(ui.MessageQuestionMark))
+	fmt.Println(ui.MessageEnterTokenStorage)
+	fmt.Println("1. fjira.yaml (plain text)")
+	fmt.Println("2. a command printing it, like `pass show jira`")
+	fmt.Println("3. an environment variable")
+	fmt.Println("4. a file encrypted with a passphrase")
+	fmt.Println("")
+	option := current
+	for {
+		fmt.Printf(ui.MessageEnterTokenStorageNumber, current)
+		optionStr, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		optionStr = strings.TrimSpace(optionStr)
+		if n, convErr := strconv.Atoi(optionStr); convErr == nil && n >= tokenInSettings && n <= tokenInEncryptedFile {
+			option = n
+			break
+		}
+		if optionStr == "" || errors.Is(err, io.EOF) {
+			break
+		}
+		fmt.Println("")
+	}
+
+	token := settings.JiraToken
+	settings.JiraToken = ""
+	var err error
+	switch option {
+	case tokenInSettings:
+		settings.JiraToken = token
+		settings.JiraTokenCommand, settings.JiraTokenEnv, settings.JiraTokenFile = "", "", ""
+	case tokenFromCommand:
+		settings.JiraTokenCommand, err = readWithDefault(reader, ui.MessageEnterTokenCommand, settings.JiraTokenCommand)
+		settings.JiraTokenEnv, settings.JiraTokenFile = "", ""
+	case tokenFromEnv:
+		settings.JiraTokenEnv, err = readWithDefault(reader, ui.MessageEnterTokenEnv, settings.JiraTokenEnv)
+		settings.JiraTokenCommand, settings.JiraTokenFile = "", ""
+	case tokenInEncryptedFile:
+		err = readEncryptedTokenFile(reader, workspace, token, settings)
+		settings.JiraTokenCommand, settings.JiraTokenEnv = "", ""
+	}
+	return err
+}
+
+// readEncryptedTokenFile encrypts the token into a file, by default
+// ~/.fjira/tokens/<workspace>.token. Without a new token the file already
+// configured is kept.
+func readEncryptedTokenFile(reader *bufio.Reader, workspace string, token string, settings *workspaces.WorkspaceSettings) error {
+	path := settings.JiraTokenFile
+	if path == "" {
+		configDir, err := workspaces.NewUserHomeSettingsStorage().ConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(configDir, workspaces.TokensDirname, workspace+".token")
+	}
+	path, err := readWithDefault(reader, ui.MessageEnterTokenFile, path)
+	if err != nil {
+		return err
+	}
+	settings.JiraTokenFile = path
+	if token == "" {
+		if _, err := os.Stat(path); err != nil {
+			return ErrNoTokenToEncrypt
+		}
+		return nil
+	}
+	passphrase, err := workspaces.ReadPassphrase(ui.MessageEnterTokenPassphrase)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return ErrTokenSourceEmpty
+	}
+	return workspaces.WriteEncryptedToken(path, token, passphrase)
+}
+
+// readWithDefault reads one answer, falling back to the current value when
+// it's left empty.
+func readWithDefault(reader *bufio.Reader, message string, current string) (string, error) {
+	fmt.Print(color.HiYellowString(ui.MessageQuestionMark))
+	fmt.Print(message)
+	if current != "" {
+		fmt.Print(color.BlueString("[%s] ", current))
+	}
+	answer, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		answer = current
+	}
+	if answer == "" {
+		return "", ErrTokenSourceEmpty
+	}
+	return answer, nil
+}
+
 func validateWorkspaceName(workspace string) error {
 	if workspace != workspaces.EmptyWorkspace && !workspaceRegExp.MatchString(workspace) {
 		return ErrWorkspaceFormatInvalid
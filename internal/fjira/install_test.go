@@ -2,6 +2,9 @@ package fjira
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 	"time"
@@ -188,6 +191,51 @@ func Test_readFromUserInputAndWorkspaceEdit(t *testing.T) {
 	}
 }
 
+func Test_readFromUserInputAndStore_should_sign_in_with_oauth(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	_ = os.Mkdir(tempDir+"/.fjira", os.ModePerm) //nolint:errcheck
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authorize":
+			callback := r.URL.Query().Get("redirect_uri") + "?code=abc&state=" + url.QueryEscape(r.URL.Query().Get("state"))
+			http.Redirect(w, r, callback, http.StatusFound)
+		case "/oauth/token":
+			_, _ = w.Write([]byte(`{"access_token":"access-1","refresh_token":"refresh-1","expires_in":3600}`))
+		case "/resources":
+			_, _ = w.Write([]byte(`[{"id":"cloud-2","url":"https://other.atlassian.net"},{"id":"cloud-1","url":"https://fjira.atlassian.net"}]`))
+		}
+	}))
+	defer authServer.Close()
+	openAuthorizeUrl = func(authorizeUrl string) {
+		response, err := http.Get(authorizeUrl)
+		if err == nil {
+			_ = response.Body.Close()
+		}
+	}
+	existing := &workspaces.WorkspaceSettings{OAuth: &jira.OAuthConfig{
+		RedirectUrl:  "http://127.0.0.1:0/callback",
+		AuthorizeUrl: authServer.URL + "/authorize",
+		TokenUrl:     authServer.URL + "/oauth/token",
+		ResourcesUrl: authServer.URL + "/resources",
+	}}
+	stdin := bytes.NewBufferString("me@example.com\nhttps://fjira.atlassian.net/\n\n3\nclient-id\nclient-secret\n")
+
+	// when
+	settings, err := readFromUserInputAndStore(stdin, "oauth", existing)
+
+	// then
+	assert2.Nil(t, err)
+	assert2.Equal(t, jira.OAuthToken, settings.JiraTokenType)
+	assert2.Equal(t, "https://fjira.atlassian.net", settings.JiraRestUrl)
+	assert2.Equal(t, "cloud-1", settings.OAuth.CloudId)
+	assert2.Equal(t, "client-id", settings.OAuth.ClientId)
+	stored, _ := workspaces.NewUserHomeSettingsStorage().Read("oauth")
+	assert2.Equal(t, "refresh-1", stored.OAuthTokens.RefreshToken)
+	assert2.Equal(t, "client-secret", stored.OAuth.ClientSecret)
+}
+
 func Test_fjira_ValidateWorkspaceName(t *testing.T) {
 	type args struct {
 		workspace string
@@ -211,3 +259,72 @@ func Test_fjira_ValidateWorkspaceName(t *testing.T) {
 		})
 	}
 }
+
+func Test_EditWorkspaceAndReadSettings_should_move_plain_token_to_encrypted_file(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	_ = os.Mkdir(tempDir+"/.fjira", os.ModePerm) //nolint:errcheck
+	workspaces.ReadPassphrase = func(string) (string, error) { return "secret", nil }
+	storage := workspaces.NewUserHomeSettingsStorage()
+	_ = storage.Write("abc", &workspaces.WorkspaceSettings{JiraToken: "plain-token", JiraUsername: "test", JiraRestUrl: "https://test", JiraTokenType: jira.ApiToken})
+	stdin := bytes.NewBufferString("\n\n\n1\n4\n\n")
+
+	// when
+	settings, err := EditWorkspaceAndReadSettings(stdin, "abc")
+
+	// then
+	assert2.Nil(t, err)
+	assert2.Empty(t, settings.JiraToken)
+	assert2.Equal(t, tempDir+"/.fjira/tokens/abc.token", settings.JiraTokenFile)
+	yml, _ := os.ReadFile(tempDir + "/.fjira/fjira.yaml")
+	assert2.NotContains(t, string(yml), "plain-token")
+	stored, _ := storage.Read("abc")
+	token, err := stored.ResolveToken()
+	assert2.Nil(t, err)
+	assert2.Equal(t, "plain-token", token)
+}
+
+func Test_EditWorkspaceAndReadSettings_should_keep_workspace_config(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	_ = os.Mkdir(tempDir+"/.fjira", os.ModePerm) //nolint:errcheck
+	customFields := &jira.CustomFieldsConfig{Details: []string{"Team"}, Columns: []string{"Story Points"}, Filters: []string{"customfield_10020"}}
+	_ = workspaces.NewUserHomeSettingsStorage().Write("abc", &workspaces.WorkspaceSettings{
+		JiraToken:     "123",
+		JiraUsername:  "test@test.pl",
+		JiraRestUrl:   "https://test.atlassian.net",
+		JiraTokenType: jira.ApiToken,
+		CustomFields:  customFields,
+		DownloadDir:   "~/jira",
+	})
+
+	// when
+	settings, err := EditWorkspaceAndReadSettings(bytes.NewBufferString("\n\n\n\n"), "abc")
+
+	// then
+	assert2.Nil(t, err)
+	assert2.Equal(t, customFields, settings.CustomFields)
+	assert2.Equal(t, "~/jira", settings.DownloadDir)
+	stored, _ := workspaces.NewUserHomeSettingsStorage().Read("abc")
+	assert2.Equal(t, customFields, stored.CustomFields)
+	assert2.Equal(t, "~/jira", stored.DownloadDir)
+}
+
+func Test_readFromUserInputAndStore_should_keep_token_command(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	_ = os.Mkdir(tempDir+"/.fjira", os.ModePerm) //nolint:errcheck
+	stdin := bytes.NewBufferString("test\nhttps://test\n\n2\n2\npass show jira\n")
+
+	// when
+	settings, err := readFromUserInputAndStore(stdin, "abc", nil)
+
+	// then
+	assert2.Nil(t, err)
+	assert2.Equal(t, "pass show jira", settings.JiraTokenCommand)
+	assert2.Empty(t, settings.JiraToken)
+	assert2.Equal(t, jira.PersonalToken, settings.JiraTokenType)
+}
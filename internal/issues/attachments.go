@@ -0,0 +1,231 @@
+package issues
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/workspaces"
+)
+
+// openedAttachmentsDir is where attachments are downloaded to, in the temp
+// directory, to open them with the system handler.
+const openedAttachmentsDir = "fjira-attachments"
+
+// attachmentRows renders the Attachments box of the issue view, and the
+// attachments to choose from: "<filename>  <size>  <author>  <when>", lined
+// up.
+func attachmentRows(attachments []jira.Attachment, now time.Time) []string {
+	nameWidth, sizeWidth, authorWidth := 0, 0, 0
+	for _, a := range attachments {
+		nameWidth = max(nameWidth, len(a.Filename))
+		sizeWidth = max(sizeWidth, len(formatSize(a.Size)))
+		authorWidth = max(authorWidth, len(a.Author.DisplayName))
+	}
+	rows := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		rows = append(rows, strings.TrimRight(fmt.Sprintf("%-*s  %*s  %-*s  %s", nameWidth, a.Filename, sizeWidth, formatSize(a.Size),
+			authorWidth, a.Author.DisplayName, app.FormatRelativeTime(a.Created, now)), " "))
+	}
+	return rows
+}
+
+// formatSize formats a file size like "512 B", "12.5 KB" or "3.1 MB".
+func formatSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+}
+
+// runAttachments opens a fuzzy-find modal over the issue's attachments:
+// the selected one is downloaded or opened, see runAttachment. The last
+// option uploads a file picked with pickFile, starting in the working
+// directory.
+func (view *issueView) runAttachments() {
+	attachments := view.issue.Fields.Attachments
+	rows := append(attachmentRows(attachments, time.Now()), ui.MessageUploadAttachment)
+	view.fuzzyFind = app.NewFuzzyFind(ui.MessageAttachmentsFuzzyFind, rows)
+	if chosen := <-view.fuzzyFind.Complete; true {
+		view.fuzzyFind = nil
+		app.GetApp().ClearNow()
+		switch {
+		case chosen.Index == len(attachments):
+			dir, _ := os.Getwd()
+			if path, ok := view.pickFile(dir); ok && view.upload(path) {
+				view.reopen()
+				return
+			}
+		case chosen.Index >= 0 && chosen.Index < len(attachments):
+			view.runAttachment(&attachments[chosen.Index])
+		}
+		go view.handleIssueAction()
+	}
+}
+
+// runAttachment downloads the attachment to the download directory - see
+// workspaces.DownloadDir - or opens it with the system handler.
+func (view *issueView) runAttachment(attachment *jira.Attachment) {
+	dir := workspaces.DownloadDir(session)
+	options := []string{fmt.Sprintf(ui.MessageDownloadAttachment, dir), ui.MessageOpenAttachment}
+	view.fuzzyFind = app.NewFuzzyFind(fmt.Sprintf(ui.MessageAttachmentFuzzyFind, attachment.Filename), options)
+	if chosen := <-view.fuzzyFind.Complete; true {
+		view.fuzzyFind = nil
+		app.GetApp().ClearNow()
+		switch chosen.Index {
+		case 0:
+			if path, ok := view.download(attachment, dir, false); ok {
+				app.Success(fmt.Sprintf(ui.MessageAttachmentDownloaded, attachment.Filename, path))
+			}
+		case 1:
+			dir := filepath.Join(os.TempDir(), openedAttachmentsDir, attachment.Id)
+			if path, ok := view.download(attachment, dir, true); ok {
+				if err := app.TryOpenLink(path); err != nil {
+					app.Error(fmt.Sprintf(ui.MessageCannotOpenLink, path, err))
+				}
+			}
+		}
+	}
+}
+
+// download saves the attachment in dir and returns its path. The files
+// already there are kept - the new one gets a " (1)" suffix - unless
+// overwrite is set.
+func (view *issueView) download(attachment *jira.Attachment, dir string, overwrite bool) (string, bool) {
+	file, err := createFile(dir, attachmentFilename(attachment), overwrite)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotDownloadAttachment, attachment.Filename, err))
+		return "", false
+	}
+	app.GetApp().LoadingWithText(true, fmt.Sprintf(ui.MessageDownloadingAttachment, attachment.Filename))
+	err = view.api.DownloadAttachment(attachment, file)
+	app.GetApp().Loading(false)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		app.Error(fmt.Sprintf(ui.MessageCannotDownloadAttachment, attachment.Filename, ui.JiraErrorReason(err)))
+		return "", false
+	}
+	return file.Name(), true
+}
+
+// attachmentFilename is the name to save the attachment as; it can't point
+// out of the directory it's saved in.
+func attachmentFilename(attachment *jira.Attachment) string {
+	name := filepath.Base(filepath.FromSlash(attachment.Filename))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return attachment.Id
+	}
+	return name
+}
+
+func createFile(dir string, filename string, overwrite bool) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if overwrite {
+		return os.Create(filepath.Join(dir, filename))
+	}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for i := 0; ; i++ {
+		name := filename
+		if i > 0 {
+			name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, os.ErrExist) {
+			return file, err
+		}
+	}
+}
+
+// pickFile lets the user pick a file, starting in dir: a directory is opened,
+// "../" goes up, and a typed path - relative to dir, or starting with "~" - is
+// taken as is. false means it was cancelled.
+func (view *issueView) pickFile(dir string) (string, bool) {
+	for {
+		rows := fileRows(dir)
+		view.fuzzyFind = app.NewFuzzyFind(fmt.Sprintf(ui.MessageSelectFileToUpload, dir), rows)
+		chosen := <-view.fuzzyFind.Complete
+		query := strings.TrimSpace(view.fuzzyFind.GetQuery())
+		view.fuzzyFind = nil
+		app.GetApp().ClearNow()
+		var path string
+		switch {
+		case chosen.Index >= 0:
+			path = filepath.Join(dir, rows[chosen.Index])
+		case query != "":
+			path = workspaces.ExpandHomeDir(query)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+		default:
+			return "", false
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			app.Error(fmt.Sprintf(ui.MessageCannotOpenFile, path, err))
+			continue
+		}
+		if stat.IsDir() {
+			dir = path
+			continue
+		}
+		return path, true
+	}
+}
+
+// fileRows lists dir for pickFile: "../" first, then the directories - with
+// a trailing "/" - and the files, by name.
+func fileRows(dir string) []string {
+	entries, _ := os.ReadDir(dir)
+	dirs := make([]string, 0)
+	files := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name()+"/")
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	sort.Strings(dirs)
+	sort.Strings(files)
+	rows := make([]string, 0, len(dirs)+len(files)+1)
+	if filepath.Dir(dir) != dir {
+		rows = append(rows, "../")
+	}
+	return append(append(rows, dirs...), files...)
+}
+
+// upload attaches the file to the issue; false means it wasn't.
+func (view *issueView) upload(path string) bool {
+	filename := filepath.Base(path)
+	file, err := os.Open(path)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotUploadAttachment, filename, view.issue.Key, err))
+		return false
+	}
+	defer file.Close() //nolint:errcheck
+	app.GetApp().LoadingWithText(true, fmt.Sprintf(ui.MessageUploadingAttachment, filename))
+	_, err = view.api.AddAttachment(view.issue.Key, filename, file)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotUploadAttachment, filename, view.issue.Key, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageAttachmentUploaded, filename, view.issue.Key))
+	return true
+}
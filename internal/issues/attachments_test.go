@@ -0,0 +1,123 @@
+package issues
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_attachmentRows(t *testing.T) {
+	// given
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	attachments := []jira.Attachment{
+		{Filename: "screenshot.png", Size: 3 * 1024 * 1024, Author: jira.User{DisplayName: "Jane Doe"}, Created: "2024-03-04T09:00:00.000+0000"},
+		{Filename: "app.log", Size: 512, Author: jira.User{DisplayName: "Bob"}, Created: "2024-03-04T11:00:00.000+0000"},
+	}
+
+	// when
+	rows := attachmentRows(attachments, now)
+
+	// then
+	assert2.Equal(t, []string{
+		"screenshot.png  3.0 MB  Jane Doe  3 hours ago",
+		"app.log          512 B  Bob       1 hour ago",
+	}, rows)
+	assert2.Equal(t, "12.5 KB", formatSize(12800))
+}
+
+func Test_attachmentFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"error.log", "error.log"},
+		{"../../.bashrc", ".bashrc"},
+		{"/etc/passwd", "passwd"},
+		{"..", "10001"},
+		{"", "10001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			assert2.Equal(t, tt.want, attachmentFilename(&jira.Attachment{Id: "10001", Filename: tt.filename}))
+		})
+	}
+}
+
+func Test_fileRows(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	_ = os.Mkdir(filepath.Join(dir, "logs"), 0700)
+	_ = os.WriteFile(filepath.Join(dir, "b.txt"), nil, 0600)
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0600)
+
+	// when
+	rows := fileRows(dir)
+
+	// then
+	assert2.Equal(t, []string{"../", "logs/", "a.txt", "b.txt"}, rows)
+}
+
+func Test_issueView_download(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte("oops!")) //nolint:errcheck
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+	attachment := &jira.Attachment{Id: "10001", Filename: "error.log", Content: api.GetApiUrl() + "/secure/attachment/10001/error.log"}
+	dir := t.TempDir()
+
+	// when
+	first, ok1 := view.download(attachment, dir, false)
+	second, ok2 := view.download(attachment, dir, false)
+
+	// then - the first download is kept
+	assert2.True(t, ok1)
+	assert2.True(t, ok2)
+	assert2.Equal(t, filepath.Join(dir, "error.log"), first)
+	assert2.Equal(t, filepath.Join(dir, "error (1).log"), second)
+	content, _ := os.ReadFile(second)
+	assert2.Equal(t, "oops!", string(content))
+}
+
+func Test_issueView_upload(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var filename string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert2.Equal(t, "/rest/api/2/issue/ABC-1/attachments", r.URL.Path)
+		if _, header, err := r.FormFile("file"); err == nil {
+			filename = header.Filename
+		}
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[{"id": "10001", "filename": "error.log"}]`)) //nolint:errcheck
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+	path := filepath.Join(t.TempDir(), "error.log")
+	_ = os.WriteFile(path, []byte("oops!"), 0600)
+
+	// when
+	uploaded := view.upload(path)
+	missing := view.upload(path + ".gone")
+
+	// then
+	assert2.True(t, uploaded)
+	assert2.False(t, missing)
+	assert2.Equal(t, "error.log", filename)
+}
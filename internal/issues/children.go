@@ -0,0 +1,221 @@
+package issues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/users"
+)
+
+// childrenJql finds the children of the issue: the issues of an epic, or the
+// sub-tasks of any other issue.
+func childrenJql(issueKey string) string {
+	return fmt.Sprintf("parent = \"%s\" ORDER BY key ASC", issueKey)
+}
+
+// childIssueTypes are the types a child of the issue can be created as:
+// sub-tasks, or - under an epic - the other types that aren't epics. Only
+// the types whose create screen has the parent - or the Epic Link on Server/DC
+// - are there, see parentFieldValue. Sub-tasks have no children.
+func childIssueTypes(issue *jira.Issue, issueTypes []jira.IssueTypeMeta) []jira.IssueTypeMeta {
+	if issue.Fields.Parent.Key != "" && !strings.EqualFold(issue.Fields.Parent.Fields.Type.Name, "Epic") {
+		return nil
+	}
+	epic := strings.EqualFold(issue.Fields.Type.Name, "Epic")
+	types := make([]jira.IssueTypeMeta, 0, len(issueTypes))
+	for _, t := range issueTypes {
+		if epic == t.Subtask || strings.EqualFold(t.Name, "Epic") {
+			continue
+		}
+		if _, _, ok := parentFieldValue(t, issue.Key); ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// parentFieldValue is the field, and its value, making an issue of the type
+// a child of parentKey; false when the create screen has no such field.
+func parentFieldValue(issueType jira.IssueTypeMeta, parentKey string) (string, any, bool) {
+	epicLink := ""
+	for id, meta := range issueType.Fields {
+		switch kindOf(id, meta) {
+		case fieldParent:
+			return id, map[string]string{"key": parentKey}, true
+		case fieldEpicLink:
+			epicLink = id
+		}
+	}
+	if epicLink != "" && !issueType.Subtask {
+		return epicLink, parentKey, true
+	}
+	return "", nil, false
+}
+
+// runCreateChild creates a sub-task of the issue - or a child of the epic -
+// without leaving the view: it asks for the type, when there's more than one,
+// the summary and the assignee, and refreshes the Related column once it's
+// created, see loadRelated.
+func (view *issueView) runCreateChild() {
+	if created := view.createChild(); created != nil {
+		if issue, rows, keys, err := view.loadRelated(created); err == nil {
+			// actions read the links and sub-tasks the refresh replaces, so
+			// they wait for it
+			app.GetApp().RunOnAppRoutine(func() {
+				view.applyRelated(issue, rows, keys)
+				go view.handleIssueAction()
+			})
+			return
+		}
+	}
+	go view.handleIssueAction()
+}
+
+// createChild asks for the child and creates it; nil means it wasn't.
+func (view *issueView) createChild() *jira.Issue {
+	projectKey := view.issue.Fields.Project.Key
+	app.GetApp().Loading(true)
+	issueTypes, err := view.api.WithContext(app.GetApp().LoadingContext()).FindCreateMeta(projectKey)
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchCreateMeta, projectKey, ui.JiraErrorReason(err)))
+		}
+		return nil
+	}
+	types := childIssueTypes(view.issue, issueTypes)
+	if len(types) == 0 {
+		app.Error(fmt.Sprintf(ui.MessageNoChildIssueTypes, view.issue.Key))
+		return nil
+	}
+	issueType := &types[0]
+	if len(types) > 1 {
+		names := make([]string, 0, len(types))
+		for _, t := range types {
+			names = append(names, t.Name)
+		}
+		chosen := view.choose(app.NewFuzzyFind(ui.MessageSelectIssueType, names))
+		if chosen.Index < 0 {
+			return nil
+		}
+		issueType = &types[chosen.Index]
+	}
+	fuzzyFind := app.NewFuzzyFind(fmt.Sprintf(ui.MessageTypeChildSummary, issueType.Name, view.issue.Key), []string{})
+	// Esc clears the summary typed so far, so it can't be taken for enter
+	fuzzyFind.SetClearOnEsc(true)
+	view.choose(fuzzyFind)
+	summary := strings.TrimSpace(fuzzyFind.GetQuery())
+	if summary == "" {
+		return nil
+	}
+	parentId, parentValue, _ := parentFieldValue(*issueType, view.issue.Key)
+	fields := map[string]any{
+		"project":   map[string]string{"key": projectKey},
+		"issuetype": map[string]string{"id": issueType.Id},
+		"summary":   summary,
+		parentId:    parentValue,
+	}
+	if _, ok := issueType.Fields["assignee"]; ok {
+		assignee, ok := view.chooseAssignee(projectKey)
+		if !ok {
+			return nil
+		}
+		if assignee != nil {
+			fields["assignee"] = assignee
+		}
+	}
+	app.GetApp().LoadingWithText(true, ui.MessageCreatingIssue)
+	created, err := view.api.CreateIssue(fields)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotCreateIssue, projectKey, ui.JiraErrorReason(err)))
+		return nil
+	}
+	app.Success(fmt.Sprintf(ui.MessageCreateChildSuccess, created.Key, view.issue.Key))
+	created.Fields.Summary = summary
+	return created
+}
+
+// chooseAssignee picks the assignee of the child among the assignable users,
+// or leaves it unassigned - nil. false means it was cancelled.
+func (view *issueView) chooseAssignee(projectKey string) (map[string]string, bool) {
+	// found is written by the provider, on the fuzzy finder's goroutine
+	var found []jira.User
+	var foundMutex sync.Mutex
+	chosen := view.choose(app.NewFuzzyFindWithContextProvider(ui.MessageSelectUser, func(ctx context.Context, query string) []string {
+		app.GetApp().Loading(true)
+		loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+		fetched := users.NewApiRecordsProvider(view.api.WithContext(loadingCtx)).FetchUsers(projectKey, query)
+		cancel()
+		app.GetApp().Loading(false)
+		if ctx.Err() == nil {
+			// the finder drops the rows of a superseded query, so do we
+			foundMutex.Lock()
+			found = fetched
+			foundMutex.Unlock()
+		}
+		return append([]string{ui.MessageUnassigned}, users.FormatJiraUsers(fetched)...)
+	}))
+	foundMutex.Lock()
+	defer foundMutex.Unlock()
+	switch {
+	case chosen.Index < 0 || chosen.Index > len(found):
+		return nil, false
+	case chosen.Index == 0:
+		return nil, true
+	}
+	user := found[chosen.Index-1]
+	if user.AccountId == "" {
+		return map[string]string{"name": user.Name}, true
+	}
+	return map[string]string{"accountId": user.AccountId}, true
+}
+
+// choose shows the FuzzyFind over the view until something is chosen, or
+// it's cancelled (Index < 0).
+func (view *issueView) choose(fuzzyFind *app.FuzzyFind) app.FuzzyFindResult {
+	view.fuzzyFind = fuzzyFind
+	chosen := <-fuzzyFind.Complete
+	view.fuzzyFind = nil
+	app.GetApp().ClearNow()
+	return chosen
+}
+
+// loadRelated fetches the sub-tasks and links of the issue again - and the
+// children of an epic, see loadEpicChildren - for the Related column, see
+// applyRelated. The created child is added when the search doesn't return it
+// yet: the JQL index lags behind the write.
+func (view *issueView) loadRelated(created *jira.Issue) (*jira.Issue, []string, []string, error) {
+	issue, err := view.api.GetIssueDetailed(view.issue.Key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rows, keys := buildRelatedRows(issue)
+	if len(issue.Fields.Subtasks) == 0 {
+		if children, err := view.api.SearchJql(childrenJql(view.issue.Key)); err == nil {
+			childRows, childKeys := relatedRowsFromIssues(children)
+			rows, keys = append(rows, childRows...), append(keys, childKeys...)
+		}
+	}
+	if !slices.Contains(keys, created.Key) {
+		rows = append(rows, relatedLine(created.Key, created.Fields.Summary, created.Fields.Status))
+		keys = append(keys, created.Key)
+	}
+	return issue, rows, keys, nil
+}
+
+func (view *issueView) applyRelated(issue *jira.Issue, rows []string, keys []string) {
+	view.issue.Fields.Subtasks = issue.Fields.Subtasks
+	view.issue.Fields.IssueLinks = issue.Fields.IssueLinks
+	view.relatedRows = rows
+	view.relatedKeys = keys
+	view.recomputeDetailsLayout()
+	app.GetApp().SetDirty()
+}
@@ -0,0 +1,194 @@
+package issues
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_childIssueTypes(t *testing.T) {
+	parent := map[string]jira.FieldMeta{"parent": {Name: "Parent", Schema: jira.FieldSchema{Type: "issuelink", System: "parent"}}}
+	epicLink := map[string]jira.FieldMeta{"customfield_10014": {Name: "Epic Link", Schema: jira.FieldSchema{Type: "any", Custom: epicLinkSchema}}}
+	issueTypes := []jira.IssueTypeMeta{
+		{Id: "1", Name: "Task", Fields: parent},
+		{Id: "2", Name: "Story", Fields: epicLink},
+		{Id: "3", Name: "Bug", Fields: map[string]jira.FieldMeta{}},
+		{Id: "4", Name: "Epic", Fields: parent},
+		{Id: "5", Name: "Sub-task", Subtask: true, Fields: parent},
+	}
+	epic := &jira.Issue{Key: "ABC-1"}
+	epic.Fields.Type.Name = "Epic"
+	story := &jira.Issue{Key: "ABC-2"}
+	story.Fields.Type.Name = "Story"
+	story.Fields.Parent.Key = "ABC-1"
+	story.Fields.Parent.Fields.Type.Name = "Epic"
+	subtask := &jira.Issue{Key: "ABC-3"}
+	subtask.Fields.Type.Name = "Sub-task"
+	subtask.Fields.Parent.Key = "ABC-2"
+	subtask.Fields.Parent.Fields.Type.Name = "Story"
+	tests := []struct {
+		name  string
+		issue *jira.Issue
+		want  []string
+	}{
+		{"should create children of epics with a parent or an epic link", epic, []string{"1", "2"}},
+		{"should create sub-tasks of other issues", story, []string{"5"}},
+		{"should not create children of sub-tasks", subtask, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			var ids []string
+			for _, issueType := range childIssueTypes(tt.issue, issueTypes) {
+				ids = append(ids, issueType.Id)
+			}
+
+			// then
+			assert2.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func Test_parentFieldValue(t *testing.T) {
+	// given
+	story := jira.IssueTypeMeta{Name: "Story", Fields: map[string]jira.FieldMeta{
+		"customfield_10014": {Name: "Epic Link", Schema: jira.FieldSchema{Type: "any", Custom: epicLinkSchema}},
+	}}
+
+	// when
+	id, value, ok := parentFieldValue(story, "ABC-1")
+
+	// then
+	assert2.True(t, ok)
+	assert2.Equal(t, "customfield_10014", id)
+	assert2.Equal(t, "ABC-1", value)
+}
+
+func Test_issueView_createChild(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var body string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		switch r.URL.Path {
+		case "/rest/api/2/issue/createmeta":
+			_, _ = w.Write([]byte(`{"projects": [{"key": "ABC", "issuetypes": [
+				{"id": "5", "name": "Sub-task", "subtask": true, "fields": {
+					"summary": {"name": "Summary", "required": true, "schema": {"type": "string", "system": "summary"}},
+					"parent": {"name": "Parent", "required": true, "schema": {"type": "issuelink", "system": "parent"}}}},
+				{"id": "1", "name": "Task", "fields": {}}
+			]}]}`)) //nolint:errcheck
+		case "/rest/api/2/issue":
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			_, _ = w.Write([]byte(`{"id": "10002", "key": "ABC-2"}`)) //nolint:errcheck
+		}
+	})
+	issue := &jira.Issue{Key: "ABC-1"}
+	issue.Fields.Project.Key = "ABC"
+	view := NewIssueView(issue, nil, api).(*issueView)
+
+	// when
+	created := make(chan *jira.Issue)
+	go func() {
+		created <- view.createChild()
+	}()
+	assert2.Eventually(t, func() bool { return view.fuzzyFind != nil }, time.Second, 5*time.Millisecond)
+	for _, r := range "Fix it" {
+		view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+
+	// then
+	child := <-created
+	assert2.Equal(t, "ABC-2", child.Key)
+	assert2.Equal(t, "Fix it", child.Fields.Summary)
+	assert2.JSONEq(t, `{"fields": {"project": {"key": "ABC"}, "issuetype": {"id": "5"}, "summary": "Fix it", "parent": {"key": "ABC-1"}}}`, body)
+}
+
+func Test_issueView_chooseAssignee(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[{"accountId": "u-1", "displayName": "Bob"}]`)) //nolint:errcheck
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+
+	// when
+	type assignee struct {
+		fields map[string]string
+		ok     bool
+	}
+	chosen := make(chan assignee)
+	go func() {
+		fields, ok := view.chooseAssignee("ABC")
+		chosen <- assignee{fields, ok}
+	}()
+	assert2.Eventually(t, func() bool { return view.fuzzyFind != nil }, time.Second, 5*time.Millisecond)
+	// the users are fetched on the finder's goroutine
+	view.fuzzyFind.Update()
+	assert2.Eventually(t, func() bool { return strings.Contains(view.fuzzyFind.GetSelectedItem(), "Unassigned") }, time.Second, 5*time.Millisecond)
+	view.fuzzyFind.Update()
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+
+	// then
+	result := <-chosen
+	assert2.True(t, result.ok)
+	assert2.Equal(t, map[string]string{"accountId": "u-1"}, result.fields)
+}
+
+func Test_issueView_loadRelated(t *testing.T) {
+	tests := []struct {
+		name     string
+		children string
+	}{
+		{"should add the created child the search doesn't return yet", `[]`},
+		{"should list the created child once the search returns it", `[{"key": "ABC-2", "fields": {"summary": "Fix it"}}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := tcell.NewSimulationScreen("utf-8")
+			_ = screen.Init() //nolint:errcheck
+			defer screen.Fini()
+			app.InitTestApp(screen)
+
+			// given
+			api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(200)
+				if strings.Contains(r.URL.Path, "search") {
+					_, _ = w.Write([]byte(`{"issues": ` + tt.children + `, "total": 1}`)) //nolint:errcheck
+					return
+				}
+				_, _ = w.Write([]byte(`{"key": "ABC-1", "fields": {"issuetype": {"name": "Epic"}}}`)) //nolint:errcheck
+			})
+			view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+			created := &jira.Issue{Key: "ABC-2"}
+			created.Fields.Summary = "Fix it"
+
+			// when
+			_, rows, keys, err := view.loadRelated(created)
+
+			// then
+			assert2.NoError(t, err)
+			assert2.Equal(t, []string{notDoneMark + "ABC-2 Fix it"}, rows)
+			assert2.Equal(t, []string{"ABC-2"}, keys)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/comments"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+)
+
+// commentRowMaxWidth is how much of the body is shown on a row of runComments
+const commentRowMaxWidth = 60
+
+// commentRows renders the options of runComments: a row to add a restricted
+// comment, then a row per comment of the issue.
+func commentRows(cs []jira.Comment, now time.Time) []string {
+	rows := make([]string, 0, len(cs)+1)
+	rows = append(rows, ui.MessageAddRestrictedComment)
+	for _, comment := range cs {
+		rows = append(rows, fmt.Sprintf(ui.MessageCommentLine, comments.Title(comment, now), firstLine(strings.TrimSpace(comment.Body), commentRowMaxWidth)))
+	}
+	return rows
+}
+
+// visibilityRows renders the roles, then the groups, a comment can be
+// restricted to - in the order of visibilities.
+func visibilityRows(roles []string, groups []string) ([]string, []jira.CommentVisibility) {
+	rows := make([]string, 0, len(roles)+len(groups))
+	visibilities := make([]jira.CommentVisibility, 0, len(roles)+len(groups))
+	for _, role := range roles {
+		rows = append(rows, fmt.Sprintf(ui.MessageVisibilityRole, role))
+		visibilities = append(visibilities, jira.CommentVisibility{Type: jira.VisibilityRole, Value: role})
+	}
+	for _, group := range groups {
+		rows = append(rows, fmt.Sprintf(ui.MessageVisibilityGroup, group))
+		visibilities = append(visibilities, jira.CommentVisibility{Type: jira.VisibilityGroup, Value: group})
+	}
+	return rows, visibilities
+}
+
+// runComments opens a fuzzy-find modal to add a comment visible to a role or
+// group only, or to edit or delete one of the user's own comments.
+func (view *issueView) runComments() {
+	cs := view.issue.Fields.Comment.Comments
+	chosen := view.choose(app.NewFuzzyFind(fmt.Sprintf(ui.MessageCommentsFuzzyFind, view.issue.Key), commentRows(cs, time.Now())))
+	switch {
+	case chosen.Index == 0:
+		if visibility, ok := view.chooseVisibility(); ok {
+			app.GoTo("text-writer", &ui.TextWriterArgs{
+				Header: fmt.Sprintf(ui.MessageTypeRestrictedComment, visibility.Value),
+				GoBack: func() {
+					view.reopen()
+				},
+				TextConsumer: func(s string) {
+					view.doComment(view.issue, s, visibility)
+				},
+				MaxLength: maxCommentLineWidth,
+			})
+			return
+		}
+	case chosen.Index > 0 && chosen.Index <= len(cs):
+		if view.changeComment(cs[chosen.Index-1]) {
+			return
+		}
+	}
+	go view.handleIssueAction()
+}
+
+// chooseVisibility picks the project role, or the group, the comment is
+// restricted to; false means it was cancelled. Groups are searched as the
+// user types, roles are filtered like any other row.
+func (view *issueView) chooseVisibility() (*jira.CommentVisibility, bool) {
+	app.GetApp().Loading(true)
+	roles, err := view.api.WithContext(app.GetApp().LoadingContext()).FindProjectRoles(view.issue.Fields.Project.Key)
+	app.GetApp().Loading(false)
+	if errors.Is(err, context.Canceled) {
+		return nil, false
+	}
+	// groups may still do without the roles; visibilities is written by the
+	// provider, on the fuzzy finder's goroutine
+	var visibilities []jira.CommentVisibility
+	var visibilitiesMutex sync.Mutex
+	chosen := view.choose(app.NewFuzzyFindWithContextProvider(ui.MessageSelectVisibility, func(ctx context.Context, query string) []string {
+		app.GetApp().Loading(true)
+		loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+		groups, _ := view.api.WithContext(loadingCtx).FindGroups(query)
+		cancel()
+		app.GetApp().Loading(false)
+		rows, rowVisibilities := visibilityRows(roles, groups)
+		if ctx.Err() == nil {
+			// the finder drops the rows of a superseded query, so do we
+			visibilitiesMutex.Lock()
+			visibilities = rowVisibilities
+			visibilitiesMutex.Unlock()
+		}
+		return rows
+	}))
+	visibilitiesMutex.Lock()
+	defer visibilitiesMutex.Unlock()
+	if chosen.Index < 0 || chosen.Index >= len(visibilities) {
+		return nil, false
+	}
+	return &visibilities[chosen.Index], true
+}
+
+// changeComment edits or deletes the comment, when it's the user's own one;
+// true means the view is left, for the text writer or to reopen the issue.
+func (view *issueView) changeComment(comment jira.Comment) bool {
+	app.GetApp().Loading(true)
+	myself, err := view.api.WithContext(app.GetApp().LoadingContext()).GetMyself()
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchMyself, ui.JiraErrorReason(err)))
+		}
+		return false
+	}
+	if !myself.SameAs(&comment.Author) {
+		app.Error(ui.MessageNotYourComment)
+		return false
+	}
+	title := comments.Title(comment, time.Now())
+	chosen := view.choose(app.NewFuzzyFind(fmt.Sprintf(ui.MessageCommentFuzzyFind, title), []string{ui.MessageEditComment, ui.MessageDeleteComment}))
+	switch chosen.Index {
+	case 0:
+		app.GoTo("text-writer", &ui.TextWriterArgs{
+			Header: ui.MessageTypeCommentEditAndSave,
+			GoBack: func() {
+				view.reopen()
+			},
+			TextConsumer: func(s string) {
+				view.updateComment(comment, s)
+			},
+			MaxLength:   maxCommentLineWidth,
+			InitialText: toMarkdown(comment.Body, comment.BodyAdf),
+		})
+		return true
+	case 1:
+		if view.deleteComment(comment) {
+			view.reopen()
+			return true
+		}
+	}
+	return false
+}
+
+// updateComment replaces the body of the comment, keeping its visibility.
+func (view *issueView) updateComment(comment jira.Comment, body string) {
+	app.GetApp().LoadingWithText(true, ui.MessageUpdatingComment)
+	err := view.sendMarkdown(body, func(wiki string) error {
+		return view.api.UpdateComment(view.issue.Key, comment.Id, wiki, comment.Visibility)
+	}, func(doc json.RawMessage) error {
+		return view.api.UpdateCommentAdf(view.issue.Key, comment.Id, doc, comment.Visibility)
+	})
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotUpdateComment, view.issue.Key, ui.JiraErrorReason(err)))
+		return
+	}
+	app.Success(fmt.Sprintf(ui.MessageCommentUpdated, view.issue.Key))
+}
+
+// deleteComment deletes the comment once confirmed; false means it wasn't.
+func (view *issueView) deleteComment(comment jira.Comment) bool {
+	deleted := app.Confirm(app.GetApp(), fmt.Sprintf(ui.MessageDeleteCommentConfirm, view.issue.Key, comments.Title(comment, time.Now())))
+	// drops the confirmation, the view is kept alive
+	app.GetApp().ClearNow()
+	if !deleted {
+		return false
+	}
+	app.GetApp().LoadingWithText(true, ui.MessageDeletingComment)
+	err := view.api.DeleteComment(view.issue.Key, comment.Id)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotDeleteComment, view.issue.Key, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageCommentDeleted, view.issue.Key))
+	return true
+}
@@ -0,0 +1,161 @@
+package issues
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_commentRows(t *testing.T) {
+	// given
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cs := []jira.Comment{
+		{Id: "1", Body: "\nFirst line\nSecond line", Created: "2024-05-01T10:00:00.000+0000"},
+		{Id: "2", Body: "Internal note", Created: "2024-05-01T11:00:00.000+0000", Visibility: &jira.CommentVisibility{Type: jira.VisibilityRole, Value: "Developers"}},
+	}
+	cs[0].Author.DisplayName = "Alice"
+	cs[1].Author.DisplayName = "Bob"
+
+	// when
+	rows := commentRows(cs, now)
+
+	// then
+	assert2.Equal(t, []string{
+		"Add a comment visible to a role or group...",
+		"2 hours ago, Alice: First line …",
+		"1 hour ago, Bob (visible to Developers): Internal note",
+	}, rows)
+}
+
+func Test_visibilityRows(t *testing.T) {
+	// when
+	rows, visibilities := visibilityRows([]string{"Developers"}, []string{"support"})
+
+	// then
+	assert2.Equal(t, []string{"Role: Developers", "Group: support"}, rows)
+	assert2.Equal(t, []jira.CommentVisibility{
+		{Type: jira.VisibilityRole, Value: "Developers"},
+		{Type: jira.VisibilityGroup, Value: "support"},
+	}, visibilities)
+}
+
+func Test_issueView_chooseVisibility(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		if r.URL.Path == "/rest/api/2/groups/picker" {
+			_, _ = w.Write([]byte(`{"groups": [{"name": "support"}]}`)) //nolint:errcheck
+			return
+		}
+		_, _ = w.Write([]byte(`{"Developers": "https://jira/rest/api/2/project/ABC/role/10001"}`)) //nolint:errcheck
+	})
+	issue := &jira.Issue{Key: "ABC-1"}
+	issue.Fields.Project.Key = "ABC"
+	view := NewIssueView(issue, nil, api).(*issueView)
+
+	// when
+	type visibility struct {
+		visibility *jira.CommentVisibility
+		ok         bool
+	}
+	chosen := make(chan visibility)
+	go func() {
+		v, ok := view.chooseVisibility()
+		chosen <- visibility{v, ok}
+	}()
+	assert2.Eventually(t, func() bool { return view.fuzzyFind != nil }, time.Second, 5*time.Millisecond)
+	// the groups are fetched on the finder's goroutine
+	view.fuzzyFind.Update()
+	assert2.Eventually(t, func() bool { return view.fuzzyFind.GetSelectedItem() != "" }, time.Second, 5*time.Millisecond)
+	view.fuzzyFind.Update()
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+
+	// then
+	result := <-chosen
+	assert2.True(t, result.ok)
+	assert2.Equal(t, &jira.CommentVisibility{Type: jira.VisibilityGroup, Value: "support"}, result.visibility)
+}
+
+func Test_issueView_changeComment_of_someone_else(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var paths []string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"accountId": "712020:alice"}`)) //nolint:errcheck
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+	comment := jira.Comment{Id: "10100"}
+	comment.Author.AccountId = "712020:bob"
+
+	// when
+	changed := view.changeComment(comment)
+
+	// then
+	assert2.False(t, changed)
+	assert2.Equal(t, []string{"GET /rest/api/2/myself"}, paths)
+}
+
+func Test_issueView_deleteComment(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+
+	tests := []struct {
+		name    string
+		confirm rune
+		deleted bool
+	}{
+		{"should delete the comment once confirmed", app.Yes, true},
+		{"should keep the comment when not confirmed", app.No, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.InitTestApp(screen)
+
+			// given
+			var path string
+			api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+				path = r.Method + " " + r.URL.Path
+				w.WriteHeader(204)
+			})
+			view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+
+			// when
+			result := make(chan bool)
+			go func() {
+				result <- view.deleteComment(jira.Comment{Id: "10100", Body: "Obsolete"})
+			}()
+			var confirmation *app.Confirmation
+			assert2.Eventually(t, func() bool {
+				confirmation, _ = app.GetApp().LastDrawable().(*app.Confirmation)
+				return confirmation != nil
+			}, time.Second, 5*time.Millisecond)
+			confirmation.HandleKeyEvent(tcell.NewEventKey(0, tt.confirm, 0))
+
+			// then
+			assert2.Equal(t, tt.deleted, <-result)
+			if tt.deleted {
+				assert2.Equal(t, "DELETE /rest/api/2/issue/ABC-1/comment/10100", path)
+			} else {
+				assert2.Empty(t, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package issues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+)
+
+// createIssueView creates an issue without leaving fjira: it asks for the
+// project (unless it's given) and the issue type, then lists the fields of the
+// type's create screen - see fieldsForm. F2 creates the issue and opens it.
+type createIssueView struct {
+	app.View
+	api        jira.Api
+	bottomBar  *app.ActionBar
+	fuzzyFind  *app.FuzzyFind
+	form       *fieldsForm
+	projectKey string
+	issueType  *jira.IssueTypeMeta
+	goBackFn   func()
+	titleStyle tcell.Style
+}
+
+var createIssueNavItems = []ui.NavItemConfig{
+	{Action: ui.ActionSelect, Text1: ui.MessageEditField, Text2: "[enter]", Key: tcell.KeyEnter},
+	{Action: ui.ActionYes, Text1: ui.MessageCreate, Text2: "[F2]", Key: tcell.KeyF2},
+}
+
+// NewCreateIssueView creates issues in the project with the given key; an
+// empty key, or "All", lets the user pick the project first.
+func NewCreateIssueView(projectKey string, goBackFn func(), api jira.Api) app.View {
+	bottomBar := ui.CreateBottomActionBarWithItems(createIssueNavItems)
+	bottomBar.AddItem(ui.NewCancelBarItem())
+	return &createIssueView{
+		api:        api,
+		bottomBar:  bottomBar,
+		projectKey: projectKey,
+		goBackFn:   goBackFn,
+		titleStyle: app.DefaultStyle().Foreground(app.Color("default.foreground2")).Underline(true),
+	}
+}
+
+func (view *createIssueView) Init() {
+	if view.form != nil {
+		// back from the text writer
+		go view.handleActions()
+		return
+	}
+	go view.start()
+}
+
+func (view *createIssueView) Destroy() {
+	// do nothing
+}
+
+func (view *createIssueView) Draw(screen tcell.Screen) {
+	if view.fuzzyFind != nil {
+		view.fuzzyFind.Draw(screen)
+		return
+	}
+	if view.form == nil {
+		return
+	}
+	if view.form.fuzzyFind == nil {
+		app.DrawText(screen, 2, 2, view.titleStyle, fmt.Sprintf(ui.MessageCreateIssueTitle, view.issueType.Name, view.projectKey))
+		view.bottomBar.Draw(screen)
+	}
+	view.form.Draw(screen, 2, 4)
+}
+
+func (view *createIssueView) Update() {
+	view.bottomBar.Update()
+	if view.fuzzyFind != nil {
+		view.fuzzyFind.Update()
+	}
+	if view.form != nil {
+		view.form.Update()
+	}
+}
+
+func (view *createIssueView) Resize(screenX, screenY int) {
+	view.bottomBar.Resize(screenX, screenY)
+	if view.fuzzyFind != nil {
+		view.fuzzyFind.Resize(screenX, screenY)
+	}
+	if view.form != nil {
+		view.form.Resize(screenX, screenY)
+	}
+}
+
+func (view *createIssueView) HandleKeyEvent(ev *tcell.EventKey) {
+	if view.fuzzyFind != nil {
+		view.fuzzyFind.HandleKeyEvent(ev)
+		return
+	}
+	if view.form == nil || view.form.HandleKeyEvent(ev) {
+		return
+	}
+	view.bottomBar.HandleKeyEvent(ev)
+}
+
+// start asks for the project and the issue type, and shows the form.
+func (view *createIssueView) start() {
+	if view.projectKey == "" || view.projectKey == ui.MessageAll {
+		project := view.chooseProject()
+		if project == nil {
+			view.goBack()
+			return
+		}
+		view.projectKey = project.Key
+	}
+	app.GetApp().Loading(true)
+	issueTypes, err := view.api.WithContext(app.GetApp().LoadingContext()).FindCreateMeta(view.projectKey)
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchCreateMeta, view.projectKey, ui.JiraErrorReason(err)))
+		}
+		view.goBack()
+		return
+	}
+	if len(issueTypes) == 0 {
+		app.Error(fmt.Sprintf(ui.MessageNoIssueTypes, view.projectKey))
+		view.goBack()
+		return
+	}
+	names := make([]string, 0, len(issueTypes))
+	for _, t := range issueTypes {
+		names = append(names, t.Name)
+	}
+	chosen := view.choose(app.NewFuzzyFind(ui.MessageSelectIssueType, names))
+	if chosen.Index < 0 {
+		view.goBack()
+		return
+	}
+	view.issueType = &issueTypes[chosen.Index]
+	view.form = newFieldsForm(view.api, view.projectKey, view.issueType.Fields, view.issueType.Subtask, view.reopen)
+	go view.handleActions()
+}
+
+func (view *createIssueView) chooseProject() *jira.Project {
+	app.GetApp().LoadingWithText(true, ui.MessageSearchProjectsLoading)
+	projects, err := view.api.WithContext(app.GetApp().LoadingContext()).FindProjects()
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(ui.JiraErrorReason(err))
+		}
+		return nil
+	}
+	names := make([]string, 0, len(projects))
+	for _, p := range projects {
+		names = append(names, fmt.Sprintf("%s (%s)", p.Name, p.Key))
+	}
+	chosen := view.choose(app.NewFuzzyFind(ui.MessageSelectProject, names))
+	if chosen.Index < 0 {
+		return nil
+	}
+	return &projects[chosen.Index]
+}
+
+func (view *createIssueView) choose(fuzzyFind *app.FuzzyFind) app.FuzzyFindResult {
+	fuzzyFind.MarginBottom = 0
+	app.GetApp().ClearNow()
+	view.fuzzyFind = fuzzyFind
+	chosen := <-fuzzyFind.Complete
+	view.fuzzyFind = nil
+	app.GetApp().ClearNow()
+	return chosen
+}
+
+func (view *createIssueView) handleActions() {
+	switch <-view.bottomBar.Action {
+	case ui.ActionSelect:
+		if !view.form.edit() {
+			return
+		}
+	case ui.ActionYes:
+		if view.create() {
+			return
+		}
+	case ui.ActionCancel:
+		view.goBack()
+		return
+	}
+	go view.handleActions()
+}
+
+// create creates the issue and opens it; false means it wasn't created, and
+// the form stays.
+func (view *createIssueView) create() bool {
+	if missing := view.form.missing(); len(missing) > 0 {
+		app.Error(fmt.Sprintf(ui.MessageMissingRequiredFields, strings.Join(missing, ", ")))
+		return false
+	}
+	fields := view.form.values()
+	fields["project"] = map[string]string{"key": view.projectKey}
+	fields["issuetype"] = map[string]string{"id": view.issueType.Id}
+	app.GetApp().LoadingWithText(true, ui.MessageCreatingIssue)
+	created, err := view.api.CreateIssue(fields)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotCreateIssue, view.projectKey, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageCreateIssueSuccess, created.Key))
+	app.GoTo(issue, created.Key, view.goBackFn, view.api)
+	return true
+}
+
+func (view *createIssueView) reopen() {
+	app.GetApp().SetView(view)
+}
+
+func (view *createIssueView) goBack() {
+	if view.goBackFn != nil {
+		view.goBackFn()
+	}
+}
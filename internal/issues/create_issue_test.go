@@ -0,0 +1,86 @@
+package issues
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func testIssueTypeMeta() jira.IssueTypeMeta {
+	return jira.IssueTypeMeta{Id: "10001", Name: "Task", Fields: map[string]jira.FieldMeta{
+		"customfield_10200": {Name: "Team", Required: true, Schema: jira.FieldSchema{Type: "option"}, AllowedValues: []jira.FieldOption{{Id: "1", Value: "Platform"}}},
+		"customfield_10300": {Name: "Story points", Schema: jira.FieldSchema{Type: "number"}},
+		"customfield_10400": {Name: "Sprint", Required: true, Schema: jira.FieldSchema{Type: "array", Items: "json"}},
+		"labels":            {Name: "Labels", Schema: jira.FieldSchema{Type: "array", Items: "string", System: "labels"}},
+		"priority":          {Name: "Priority", HasDefaultValue: true, Schema: jira.FieldSchema{Type: "priority", System: "priority"}, AllowedValues: []jira.FieldOption{{Id: "3", Name: "Medium"}}},
+		"project":           {Name: "Project", Required: true, Schema: jira.FieldSchema{Type: "project", System: "project"}},
+		"summary":           {Name: "Summary", Required: true, Schema: jira.FieldSchema{Type: "string", System: "summary"}},
+		"customfield_10014": {Name: "Epic Link", Schema: jira.FieldSchema{Type: "any", Custom: epicLinkSchema}},
+	}}
+}
+
+func Test_newIssueFields(t *testing.T) {
+	// when
+	fields := newIssueFields(testIssueTypeMeta().Fields)
+
+	// then
+	var ids []string
+	var kinds []fieldKind
+	for _, f := range fields {
+		ids = append(ids, f.id)
+		kinds = append(kinds, f.kind)
+	}
+	assert2.Equal(t, []string{"summary", "priority", "labels", "customfield_10014", "customfield_10400", "customfield_10300", "customfield_10200"}, ids)
+	assert2.Equal(t, []fieldKind{fieldText, fieldOption, fieldLabels, fieldEpicLink, fieldUnsupported, fieldNumber, fieldOption}, kinds)
+}
+
+func Test_fieldsForm_should_tell_missing_required_fields(t *testing.T) {
+	// given
+	form := newFieldsForm(jira.NewJiraApiMock(nil), "ABC", testIssueTypeMeta().Fields, false, nil)
+
+	// when
+	form.fields[0].value = "Fix the build"
+
+	// then
+	assert2.Equal(t, []string{"Sprint", "Team"}, form.missing())
+	assert2.Equal(t, map[string]any{"summary": "Fix the build"}, form.values())
+}
+
+func Test_createIssueView_create(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var body string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+		_, _ = w.Write([]byte(`{"id": "10042", "key": "ABC-42"}`)) //nolint:errcheck
+	})
+	issueType := jira.IssueTypeMeta{Id: "10001", Name: "Task", Fields: map[string]jira.FieldMeta{
+		"summary": {Name: "Summary", Required: true, Schema: jira.FieldSchema{Type: "string", System: "summary"}},
+		"labels":  {Name: "Labels", Schema: jira.FieldSchema{Type: "array", Items: "string", System: "labels"}},
+	}}
+	view := NewCreateIssueView("ABC", nil, api).(*createIssueView)
+	view.issueType = &issueType
+	view.form = newFieldsForm(api, "ABC", issueType.Fields, false, nil)
+
+	// when
+	notCreated := view.create()
+	view.form.fields[0].value = "Fix the build"
+	view.form.fields[1].value = []string{"ci"}
+	created := view.create()
+
+	// then
+	assert2.False(t, notCreated, "summary is required")
+	assert2.True(t, created)
+	assert2.JSONEq(t, `{"fields": {"project": {"key": "ABC"}, "issuetype": {"id": "10001"}, "summary": "Fix the build", "labels": ["ci"]}}`, body)
+}
@@ -0,0 +1,190 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+)
+
+// editFieldsView lists the fields of the issue's edit screen, and saves every
+// field as soon as it's edited - see fieldsForm.
+type editFieldsView struct {
+	app.View
+	api        jira.Api
+	issue      *jira.Issue
+	bottomBar  *app.ActionBar
+	form       *fieldsForm
+	goBackFn   func()
+	titleStyle tcell.Style
+}
+
+var editFieldsNavItems = []ui.NavItemConfig{
+	{Action: ui.ActionSelect, Text1: ui.MessageEditField, Text2: "[enter]", Key: tcell.KeyEnter},
+}
+
+func NewEditFieldsView(issue *jira.Issue, goBackFn func(), api jira.Api) app.View {
+	bottomBar := ui.CreateBottomActionBarWithItems(editFieldsNavItems)
+	bottomBar.AddItem(ui.NewCancelBarItem())
+	return &editFieldsView{
+		api:        api,
+		issue:      issue,
+		bottomBar:  bottomBar,
+		goBackFn:   goBackFn,
+		titleStyle: app.DefaultStyle().Foreground(app.Color("default.foreground2")).Underline(true),
+	}
+}
+
+func (view *editFieldsView) Init() {
+	if view.form != nil {
+		// back from the text writer
+		go view.handleActions()
+		return
+	}
+	go view.start()
+}
+
+func (view *editFieldsView) Destroy() {
+	// do nothing
+}
+
+func (view *editFieldsView) Draw(screen tcell.Screen) {
+	if view.form == nil {
+		return
+	}
+	if view.form.fuzzyFind == nil {
+		app.DrawText(screen, 2, 2, view.titleStyle, fmt.Sprintf(ui.MessageEditFieldsTitle, view.issue.Key))
+		view.bottomBar.Draw(screen)
+	}
+	view.form.Draw(screen, 2, 4)
+}
+
+func (view *editFieldsView) Update() {
+	view.bottomBar.Update()
+	if view.form != nil {
+		view.form.Update()
+	}
+}
+
+func (view *editFieldsView) Resize(screenX, screenY int) {
+	view.bottomBar.Resize(screenX, screenY)
+	if view.form != nil {
+		view.form.Resize(screenX, screenY)
+	}
+}
+
+func (view *editFieldsView) HandleKeyEvent(ev *tcell.EventKey) {
+	if view.form != nil && view.form.HandleKeyEvent(ev) {
+		return
+	}
+	view.bottomBar.HandleKeyEvent(ev)
+}
+
+func (view *editFieldsView) start() {
+	app.GetApp().Loading(true)
+	metas, err := view.api.WithContext(app.GetApp().LoadingContext()).FindEditMeta(view.issue.Key)
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchEditMeta, view.issue.Key, ui.JiraErrorReason(err)))
+		}
+		view.goBack()
+		return
+	}
+	// edited with [d], as a document on Cloud
+	delete(metas, "description")
+	// a sub-task's parent is picked among the issues of the project, any other's among the epics
+	subtask := view.issue.Fields.Parent.Key != "" && !strings.EqualFold(view.issue.Fields.Parent.Fields.Type.Name, "Epic")
+	form := newFieldsForm(view.api, view.issue.Fields.Project.Key, metas, subtask, view.reopen)
+	if len(form.fields) == 0 {
+		app.Error(fmt.Sprintf(ui.MessageNoEditableFields, view.issue.Key))
+		view.goBack()
+		return
+	}
+	ids := make([]string, 0, len(form.fields))
+	for _, f := range form.fields {
+		ids = append(ids, f.id)
+	}
+	// every field starts from the value it has, so that a toggled option or
+	// label is added to the others instead of replacing them
+	app.GetApp().Loading(true)
+	values, err := view.api.WithContext(app.GetApp().LoadingContext()).GetIssueFieldValues(view.issue.Key, ids)
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchEditMeta, view.issue.Key, ui.JiraErrorReason(err)))
+		}
+		view.goBack()
+		return
+	}
+	for _, f := range form.fields {
+		f.display = currentFieldDisplay(view.issue, f, values[f.id])
+	}
+	form.onChange = view.save
+	view.form = form
+	go view.handleActions()
+}
+
+func (view *editFieldsView) handleActions() {
+	switch <-view.bottomBar.Action {
+	case ui.ActionSelect:
+		if !view.form.edit() {
+			return
+		}
+	case ui.ActionCancel:
+		view.goBack()
+		return
+	}
+	go view.handleActions()
+}
+
+// save sends the new value of the field; when Jira rejects it, the field gets
+// its old value back.
+func (view *editFieldsView) save(f *issueField, old issueField) {
+	value := f.value
+	if value == nil && (f.kind == fieldOptions || f.kind == fieldLabels) {
+		value = []any{}
+	}
+	app.GetApp().LoadingWithText(true, fmt.Sprintf(ui.MessageUpdatingField, f.meta.Name))
+	err := view.api.UpdateIssueFields(view.issue.Key, map[string]any{f.id: value})
+	app.GetApp().Loading(false)
+	if err != nil {
+		f.value, f.display = old.value, old.display
+		app.Error(fmt.Sprintf(ui.MessageCannotUpdateField, f.meta.Name, view.issue.Key, ui.JiraErrorReason(err)))
+		return
+	}
+	app.Success(fmt.Sprintf(ui.MessageUpdateFieldSuccess, f.meta.Name, view.issue.Key))
+}
+
+func (view *editFieldsView) reopen() {
+	app.GetApp().SetView(view)
+}
+
+func (view *editFieldsView) goBack() {
+	if view.goBackFn != nil {
+		view.goBackFn()
+	}
+}
+
+// currentFieldDisplay is how the value the issue has in the field reads;
+// value is the field as Jira sent it, see jira.Api.GetIssueFieldValues.
+// Options and labels are joined with labelsDelimiter, as editOptions and
+// editLabels split them.
+func currentFieldDisplay(issue *jira.Issue, f *issueField, value json.RawMessage) string {
+	decoded := jira.DecodeFieldValue(value)
+	switch f.kind {
+	case fieldOptions, fieldLabels:
+		return strings.Join(decoded.Values, labelsDelimiter)
+	case fieldParent:
+		if parent := issue.Fields.Parent; parent.Key != "" && parent.Key == decoded.Text {
+			return parent.Key + " " + parent.Fields.Summary
+		}
+	}
+	return decoded.Text
+}
@@ -0,0 +1,162 @@
+package issues
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_fieldsForm_setText(t *testing.T) {
+	app.InitTestApp(nil)
+	tests := []struct {
+		name        string
+		kind        fieldKind
+		text        string
+		wantChanged bool
+		wantValue   any
+	}{
+		{"should collapse text to one line", fieldText, "Fix\nthe  build", true, "Fix the build"},
+		{"should keep the lines of text areas", fieldTextArea, "Linux\n  Firefox 125\n", true, "Linux\n  Firefox 125"},
+		{"should take dates", fieldDate, "2024-05-31", true, "2024-05-31"},
+		{"should reject other dates", fieldDate, "31.05.2024", false, nil},
+		{"should take numbers", fieldNumber, " 3.5 ", true, 3.5},
+		{"should reject other numbers", fieldNumber, "three", false, nil},
+		{"should clear the field", fieldNumber, "", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			form := &fieldsForm{}
+			f := &issueField{kind: tt.kind, display: "1"}
+
+			// when
+			changed := form.setText(f, tt.text)
+
+			// then
+			assert2.Equal(t, tt.wantChanged, changed)
+			assert2.Equal(t, tt.wantValue, f.value)
+		})
+	}
+}
+
+func Test_editFieldsView_save(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var bodies []string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) > 2 {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"errors": {"duedate": "Error parsing date string: soon"}}`)) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(204)
+	})
+	view := NewEditFieldsView(&jira.Issue{Key: "ABC-1"}, nil, api).(*editFieldsView)
+	priority := &issueField{id: "priority", kind: fieldOption, value: map[string]string{"id": "2"}, display: "High"}
+	components := &issueField{id: "components", kind: fieldOptions}
+	dueDate := &issueField{id: "duedate", kind: fieldDate, value: "soon", display: "soon"}
+
+	// when
+	view.save(priority, issueField{display: "Medium"})
+	view.save(components, issueField{display: "Backend"})
+	view.save(dueDate, issueField{value: "2024-05-31", display: "2024-05-31"})
+
+	// then
+	assert2.JSONEq(t, `{"fields": {"priority": {"id": "2"}}}`, bodies[0])
+	assert2.JSONEq(t, `{"fields": {"components": []}}`, bodies[1])
+	assert2.Equal(t, "High", priority.display)
+	assert2.Equal(t, "2024-05-31", dueDate.display, "rejected value should be rolled back")
+}
+
+func Test_currentFieldDisplay(t *testing.T) {
+	// given
+	issue := &jira.Issue{}
+	issue.Fields.Parent.Key = "ABC-1"
+	issue.Fields.Parent.Fields.Summary = "Offline mode"
+	tests := []struct {
+		name  string
+		kind  fieldKind
+		value string
+		want  string
+	}{
+		{"should read text", fieldText, `"Fix the build"`, "Fix the build"},
+		{"should keep the lines of text areas", fieldTextArea, `"Linux\nFirefox 125"`, "Linux\nFirefox 125"},
+		{"should read options", fieldOption, `{"id": "2", "name": "High"}`, "High"},
+		{"should join every option there is", fieldOptions, `[{"id": "1", "name": "1.0"}, {"id": "2", "name": "1.1"}]`, "1.0 | 1.1"},
+		{"should join labels", fieldLabels, `["ci", "build"]`, "ci | build"},
+		{"should read custom multi-selects", fieldOptions, `[{"id": "10", "value": "Web"}, {"id": "11", "value": "iOS"}]`, "Web | iOS"},
+		{"should read users", fieldUser, `{"accountId": "1", "displayName": "Alice"}`, "Alice"},
+		{"should read the parent with its summary", fieldParent, `{"id": "10000", "key": "ABC-1"}`, "ABC-1 Offline mode"},
+		{"should leave unset fields empty", fieldOptions, ``, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			display := currentFieldDisplay(issue, &issueField{kind: tt.kind}, json.RawMessage(tt.value))
+
+			// then
+			assert2.Equal(t, tt.want, display)
+		})
+	}
+}
+
+func Test_editFieldsView_start_should_seed_every_field(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var fieldsParam string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		switch r.URL.Path {
+		case "/rest/api/2/issue/ABC-1/editmeta":
+			_, _ = w.Write([]byte(`{"fields": {
+				"versions": {"name": "Affects versions", "schema": {"type": "array", "items": "version", "system": "versions"},
+					"operations": ["set", "add", "remove"], "allowedValues": [{"id": "1", "name": "1.0"}, {"id": "2", "name": "1.1"}]},
+				"environment": {"name": "Environment", "schema": {"type": "string", "system": "environment"}, "operations": ["set"]}
+			}}`)) //nolint:errcheck
+		case "/rest/api/2/issue/ABC-1":
+			fieldsParam = r.URL.Query().Get("fields")
+			_, _ = w.Write([]byte(`{"key": "ABC-1", "fields": {"versions": [{"id": "1", "name": "1.0"}], "environment": "Linux\nFirefox 125"}}`)) //nolint:errcheck
+		}
+	})
+	view := NewEditFieldsView(&jira.Issue{Key: "ABC-1"}, nil, api).(*editFieldsView)
+
+	// when
+	view.start()
+
+	// then
+	assert2.ElementsMatch(t, []string{"versions", "environment"}, strings.Split(fieldsParam, ","))
+	displays := map[string]string{}
+	for _, f := range view.form.fields {
+		displays[f.id] = f.display
+	}
+	assert2.Equal(t, map[string]string{"versions": "1.0", "environment": "Linux\nFirefox 125"}, displays)
+}
+
+func Test_kindOf_should_tell_text_areas(t *testing.T) {
+	// given
+	environment := jira.FieldMeta{Name: "Environment", Schema: jira.FieldSchema{Type: "string", System: "environment"}}
+	notes := jira.FieldMeta{Name: "Release notes", Schema: jira.FieldSchema{Type: "string", Custom: textAreaSchema}}
+	team := jira.FieldMeta{Name: "Team name", Schema: jira.FieldSchema{Type: "string", Custom: "com.atlassian.jira.plugin.system.customfieldtypes:textfield"}}
+
+	// then
+	assert2.Equal(t, fieldTextArea, kindOf("environment", environment))
+	assert2.Equal(t, fieldTextArea, kindOf("customfield_10500", notes))
+	assert2.Equal(t, fieldText, kindOf("customfield_10501", team))
+}
@@ -0,0 +1,521 @@
+package issues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/markdown"
+	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/users"
+)
+
+// fieldKind is how a field of a create or edit screen is edited.
+type fieldKind int
+
+const (
+	fieldUnsupported fieldKind = iota
+	fieldText
+	// fieldTextArea is multi-line text, like the environment
+	fieldTextArea
+	fieldMarkdown
+	fieldOption
+	fieldOptions
+	fieldUser
+	fieldLabels
+	fieldParent
+	fieldEpicLink
+	fieldDate
+	fieldNumber
+)
+
+const (
+	epicLinkSchema     = "com.pyxis.greenhopper.jira:gh-epic-link"
+	textAreaSchema     = "com.atlassian.jira.plugin.system.customfieldtypes:textarea"
+	maxSummaryLength   = 255
+	maxFieldIssues     = 100
+	fieldValueMaxWidth = 80
+	fieldDateFormat    = "2006-01-02"
+)
+
+// skippedFields are set by fjira itself, or left to Jira's defaults.
+var skippedFields = map[string]bool{"project": true, "issuetype": true, "reporter": true}
+
+// fieldOrder is the order of Jira's own create screen; other fields follow,
+// by name.
+var fieldOrder = []string{"summary", "description", "priority", "assignee", "labels", "components", "parent"}
+
+// issueField is a field of a create or edit screen with its value, in the
+// shape the api takes it (nil while unset), and how the value reads.
+type issueField struct {
+	id      string
+	meta    jira.FieldMeta
+	kind    fieldKind
+	value   any
+	display string
+}
+
+// newIssueFields lists the fields fjira can edit, and the required ones it
+// can't - so that it's clear what Jira will reject the issue for. Fields an
+// update can't set (Operations without "set") are left out.
+func newIssueFields(metas map[string]jira.FieldMeta) []*issueField {
+	fields := make([]*issueField, 0, len(metas))
+	for id, meta := range metas {
+		kind := kindOf(id, meta)
+		if skippedFields[id] || kind == fieldUnsupported && (!meta.Required || meta.HasDefaultValue) {
+			continue
+		}
+		if len(meta.Operations) > 0 && !slices.Contains(meta.Operations, "set") {
+			continue
+		}
+		fields = append(fields, &issueField{id: id, meta: meta, kind: kind})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := slices.Index(fieldOrder, fields[i].id), slices.Index(fieldOrder, fields[j].id)
+		switch {
+		case a >= 0 && b >= 0:
+			return a < b
+		case a >= 0 || b >= 0:
+			return a >= 0
+		}
+		return fields[i].meta.Name < fields[j].meta.Name
+	})
+	return fields
+}
+
+func kindOf(id string, meta jira.FieldMeta) fieldKind {
+	switch {
+	case id == "summary":
+		return fieldText
+	case id == "description":
+		return fieldMarkdown
+	case meta.Schema.System == "environment" || meta.Schema.Custom == textAreaSchema:
+		return fieldTextArea
+	case meta.Schema.Type == "user":
+		return fieldUser
+	case meta.Schema.System == "labels":
+		return fieldLabels
+	case meta.Schema.System == "parent":
+		return fieldParent
+	case meta.Schema.Custom == epicLinkSchema:
+		return fieldEpicLink
+	case len(meta.AllowedValues) > 0 && meta.Schema.Type == "array":
+		return fieldOptions
+	case len(meta.AllowedValues) > 0:
+		return fieldOption
+	case meta.Schema.Type == "date":
+		return fieldDate
+	case meta.Schema.Type == "number":
+		return fieldNumber
+	case meta.Schema.Type == "string":
+		return fieldText
+	}
+	return fieldUnsupported
+}
+
+// fieldsForm lists the fields of an issue, and edits the selected one: text in
+// the TextWriterView, the rest in a FuzzyFind.
+type fieldsForm struct {
+	api        jira.Api
+	projectKey string
+	// subtask tells the parent of a sub-task from the epic of another issue
+	subtask   bool
+	fields    []*issueField
+	selected  int
+	fuzzyFind *app.FuzzyFind
+	// reopen brings the view of the form back after the text writer
+	reopen func()
+	// onChange, when set, is called when a field has got a new value; old
+	// is the field as it was before
+	onChange      func(f *issueField, old issueField)
+	labelStyle    tcell.Style
+	valueStyle    tcell.Style
+	dimStyle      tcell.Style
+	selectedStyle tcell.Style
+}
+
+func newFieldsForm(api jira.Api, projectKey string, metas map[string]jira.FieldMeta, subtask bool, reopen func()) *fieldsForm {
+	return &fieldsForm{
+		api:           api,
+		projectKey:    projectKey,
+		subtask:       subtask,
+		fields:        newIssueFields(metas),
+		reopen:        reopen,
+		labelStyle:    app.DefaultStyle().Foreground(app.Color("details.foreground")),
+		valueStyle:    app.DefaultStyle(),
+		dimStyle:      app.DefaultStyle().Foreground(app.Color("details.foreground")).Italic(true),
+		selectedStyle: app.DefaultStyle().Reverse(true),
+	}
+}
+
+func (form *fieldsForm) Draw(screen tcell.Screen, x, y int) {
+	if form.fuzzyFind != nil {
+		form.fuzzyFind.Draw(screen)
+		return
+	}
+	labelWidth := 0
+	for _, f := range form.fields {
+		labelWidth = max(labelWidth, len([]rune(form.label(f))))
+	}
+	for i, f := range form.fields {
+		labelStyle := form.labelStyle
+		if i == form.selected {
+			labelStyle = form.selectedStyle
+		}
+		label := form.label(f)
+		app.DrawText(screen, x, y+i, labelStyle, label)
+		valueX := x + labelWidth + 2
+		switch {
+		case f.kind == fieldUnsupported:
+			app.DrawText(screen, valueX, y+i, form.dimStyle, ui.MessageFieldNotSupported)
+		case f.display == "":
+			app.DrawText(screen, valueX, y+i, form.dimStyle, ui.MessageFieldEmpty)
+		default:
+			app.DrawText(screen, valueX, y+i, form.valueStyle, firstLine(f.display, fieldValueMaxWidth))
+		}
+	}
+}
+
+func (form *fieldsForm) label(f *issueField) string {
+	if f.meta.Required {
+		return f.meta.Name + " *"
+	}
+	return f.meta.Name
+}
+
+func (form *fieldsForm) Update() {
+	if form.fuzzyFind != nil {
+		form.fuzzyFind.Update()
+	}
+}
+
+func (form *fieldsForm) Resize(screenX, screenY int) {
+	if form.fuzzyFind != nil {
+		form.fuzzyFind.Resize(screenX, screenY)
+	}
+}
+
+// HandleKeyEvent moves the selection, or drives the open FuzzyFind; it tells
+// whether the key was taken.
+func (form *fieldsForm) HandleKeyEvent(ev *tcell.EventKey) bool {
+	if form.fuzzyFind != nil {
+		form.fuzzyFind.HandleKeyEvent(ev)
+		return true
+	}
+	switch ev.Key() {
+	case tcell.KeyUp:
+		form.selected = max(form.selected-1, 0)
+		return true
+	case tcell.KeyDown:
+		form.selected = min(form.selected+1, len(form.fields)-1)
+		return true
+	}
+	return false
+}
+
+// edit edits the selected field, and blocks until it's done. It returns false
+// when the field is edited in the text writer, which reopens the form itself.
+func (form *fieldsForm) edit() bool {
+	if form.selected >= len(form.fields) {
+		return true
+	}
+	f := form.fields[form.selected]
+	old := *f
+	changed := false
+	switch f.kind {
+	case fieldText, fieldTextArea, fieldMarkdown, fieldDate, fieldNumber:
+		form.editText(f)
+		return false
+	case fieldOption:
+		changed = form.editOption(f)
+	case fieldOptions:
+		changed = form.editOptions(f)
+	case fieldUser:
+		changed = form.editUser(f)
+	case fieldLabels:
+		changed = form.editLabels(f)
+	case fieldParent, fieldEpicLink:
+		changed = form.editParent(f)
+	default:
+		app.Error(fmt.Sprintf(ui.MessageCannotEditField, f.meta.Name))
+	}
+	if changed {
+		form.changed(f, old)
+	}
+	return true
+}
+
+func (form *fieldsForm) changed(f *issueField, old issueField) {
+	if form.onChange != nil {
+		form.onChange(f, old)
+	}
+}
+
+func (form *fieldsForm) editText(f *issueField) {
+	old := *f
+	args := &ui.TextWriterArgs{
+		Header:      fmt.Sprintf(ui.MessageTypeFieldAndSave, f.meta.Name),
+		GoBack:      form.reopen,
+		InitialText: f.display,
+	}
+	args.TextConsumer = func(s string) {
+		if form.setText(f, s) {
+			form.changed(f, old)
+		}
+	}
+	switch {
+	case f.id == "summary":
+		args.MaxLength = maxSummaryLength
+	case f.kind == fieldTextArea:
+		args.MaxLength = 1000
+	case f.kind == fieldMarkdown:
+		args.Header = fmt.Sprintf(ui.MessageTypeMarkdownFieldAndSave, f.meta.Name)
+		args.MaxLength = 1000
+	case f.kind == fieldDate:
+		args.Header = fmt.Sprintf(ui.MessageTypeDateFieldAndSave, f.meta.Name)
+	}
+	app.GoTo("text-writer", args)
+}
+
+// setText sets the field from the text written for it; false means the text
+// isn't a value of the field, or it's the same.
+func (form *fieldsForm) setText(f *issueField, s string) bool {
+	text := strings.Join(strings.Fields(s), " ")
+	var value any = text
+	switch f.kind {
+	case fieldTextArea:
+		// kept as written, lines and all - it's wiki markup, as it came
+		text = strings.TrimSpace(s)
+		value = text
+	case fieldMarkdown:
+		// the v2 api takes wiki markup both on Cloud and Server
+		text = strings.TrimSpace(s)
+		value = markdown.ToWiki(s)
+	case fieldDate:
+		if _, err := time.Parse(fieldDateFormat, text); text != "" && err != nil {
+			app.Error(fmt.Sprintf(ui.MessageInvalidFieldValue, text, f.meta.Name))
+			return false
+		}
+	case fieldNumber:
+		number, err := strconv.ParseFloat(text, 64)
+		if text != "" && err != nil {
+			app.Error(fmt.Sprintf(ui.MessageInvalidFieldValue, text, f.meta.Name))
+			return false
+		}
+		value = number
+	}
+	if text == f.display {
+		return false
+	}
+	f.value, f.display = value, text
+	if text == "" {
+		f.value = nil
+	}
+	return true
+}
+
+func (form *fieldsForm) editOption(f *issueField) bool {
+	labels := make([]string, 0, len(f.meta.AllowedValues)+1)
+	for _, o := range f.meta.AllowedValues {
+		labels = append(labels, o.Label())
+	}
+	if !f.meta.Required {
+		labels = append(labels, ui.MessageFieldNone)
+	}
+	chosen := form.choose(app.NewFuzzyFind(fmt.Sprintf(ui.MessageSelectFieldValue, f.meta.Name), labels))
+	switch {
+	case chosen.Index < 0:
+		return false
+	case chosen.Index == len(f.meta.AllowedValues):
+		f.value, f.display = nil, ""
+	default:
+		option := f.meta.AllowedValues[chosen.Index]
+		f.value, f.display = map[string]string{"id": option.Id}, option.Label()
+	}
+	return true
+}
+
+// editOptions adds the chosen option to the field, or removes it when it's
+// there already.
+func (form *fieldsForm) editOptions(f *issueField) bool {
+	labels := make([]string, 0, len(f.meta.AllowedValues))
+	for _, o := range f.meta.AllowedValues {
+		labels = append(labels, o.Label())
+	}
+	chosen := form.choose(app.NewFuzzyFind(fmt.Sprintf(ui.MessageToggleFieldValue, f.meta.Name), labels))
+	if chosen.Index < 0 {
+		return false
+	}
+	selected := toggle(splitDisplay(f.display), labels[chosen.Index])
+	values := make([]map[string]string, 0, len(selected))
+	for _, o := range f.meta.AllowedValues {
+		if slices.Contains(selected, o.Label()) {
+			values = append(values, map[string]string{"id": o.Id})
+		}
+	}
+	f.value, f.display = values, strings.Join(selected, labelsDelimiter)
+	if len(values) == 0 {
+		f.value = nil
+	}
+	return true
+}
+
+func (form *fieldsForm) editUser(f *issueField) bool {
+	var found []jira.User
+	chosen := form.choose(app.NewFuzzyFindWithContextProvider(ui.MessageSelectUser, func(ctx context.Context, query string) []string {
+		app.GetApp().Loading(true)
+		loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+		found = users.NewApiRecordsProvider(form.api.WithContext(loadingCtx)).FetchUsers(form.projectKey, query)
+		cancel()
+		app.GetApp().Loading(false)
+		return users.FormatJiraUsers(found)
+	}))
+	if chosen.Index < 0 || chosen.Index >= len(found) {
+		return false
+	}
+	user := found[chosen.Index]
+	f.value, f.display = map[string]string{"accountId": user.AccountId}, user.DisplayName
+	if user.AccountId == "" {
+		f.value = map[string]string{"name": user.Name}
+	}
+	return true
+}
+
+// editLabels adds the chosen label - or the typed one - to the field, or
+// removes it when it's there already.
+func (form *fieldsForm) editLabels(f *issueField) bool {
+	var found []string
+	fuzzyFind := app.NewFuzzyFindWithContextProvider(ui.MessageLabelFuzzyFind, func(ctx context.Context, query string) []string {
+		app.GetApp().LoadingWithText(true, ui.MessageSearchLabelsLoading)
+		loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+		labels, err := form.api.WithContext(loadingCtx).FindLabels(nil, query)
+		cancel()
+		app.GetApp().Loading(false)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			app.Error(err.Error())
+		}
+		found = labels
+		return labels
+	})
+	chosen := form.choose(fuzzyFind)
+	label := strings.TrimSpace(fuzzyFind.GetQuery())
+	if chosen.Index >= 0 && chosen.Index < len(found) {
+		label = found[chosen.Index]
+	}
+	if label == "" {
+		return false
+	}
+	labels := toggle(splitDisplay(f.display), label)
+	f.value, f.display = labels, strings.Join(labels, labelsDelimiter)
+	if len(labels) == 0 {
+		f.value = nil
+	}
+	return true
+}
+
+// editParent picks the parent of a sub-task among the other issues of the
+// project, or the epic of any other issue among the epics.
+func (form *fieldsForm) editParent(f *issueField) bool {
+	jql := fmt.Sprintf("project = \"%s\" AND issuetype = Epic ORDER BY updated DESC", form.projectKey)
+	if f.kind == fieldParent && form.subtask {
+		jql = fmt.Sprintf("project = \"%s\" AND issuetype not in subtaskIssueTypes() ORDER BY updated DESC", form.projectKey)
+	}
+	app.GetApp().Loading(true)
+	found, _, _, err := form.api.WithContext(app.GetApp().LoadingContext()).SearchJqlPageable(jql, 0, maxFieldIssues)
+	app.GetApp().Loading(false)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.Error(ui.JiraErrorReason(err))
+		}
+		return false
+	}
+	rows := make([]string, 0, len(found)+1)
+	for i := range found {
+		rows = append(rows, FormatJiraIssue(&found[i]))
+	}
+	if !f.meta.Required {
+		rows = append(rows, ui.MessageFieldNone)
+	}
+	chosen := form.choose(app.NewFuzzyFind(fmt.Sprintf(ui.MessageSelectFieldValue, f.meta.Name), rows))
+	switch {
+	case chosen.Index < 0:
+		return false
+	case chosen.Index == len(found):
+		f.value, f.display = nil, ""
+	case f.kind == fieldEpicLink:
+		f.value, f.display = found[chosen.Index].Key, found[chosen.Index].Key+" "+found[chosen.Index].Fields.Summary
+	default:
+		f.value, f.display = map[string]string{"key": found[chosen.Index].Key}, found[chosen.Index].Key+" "+found[chosen.Index].Fields.Summary
+	}
+	return true
+}
+
+// choose shows the FuzzyFind in place of the form until something is chosen,
+// or it's cancelled (Index < 0).
+func (form *fieldsForm) choose(fuzzyFind *app.FuzzyFind) app.FuzzyFindResult {
+	fuzzyFind.MarginBottom = 0
+	app.GetApp().ClearNow()
+	form.fuzzyFind = fuzzyFind
+	chosen := <-fuzzyFind.Complete
+	form.fuzzyFind = nil
+	app.GetApp().ClearNow()
+	return chosen
+}
+
+// values are the fields that are set, by id.
+func (form *fieldsForm) values() map[string]any {
+	values := make(map[string]any, len(form.fields))
+	for _, f := range form.fields {
+		if f.value != nil {
+			values[f.id] = f.value
+		}
+	}
+	return values
+}
+
+// missing are the names of the required fields that are still empty, and
+// that Jira has no default for.
+func (form *fieldsForm) missing() []string {
+	var names []string
+	for _, f := range form.fields {
+		if f.meta.Required && !f.meta.HasDefaultValue && f.value == nil {
+			names = append(names, f.meta.Name)
+		}
+	}
+	return names
+}
+
+func toggle(values []string, value string) []string {
+	if i := slices.Index(values, value); i >= 0 {
+		return slices.Delete(values, i, i+1)
+	}
+	return append(values, value)
+}
+
+func splitDisplay(display string) []string {
+	if display == "" {
+		return nil
+	}
+	return strings.Split(display, labelsDelimiter)
+}
+
+// firstLine cuts text to its first line, and to width runes.
+func firstLine(text string, width int) string {
+	line, _, more := strings.Cut(text, "\n")
+	runes := []rune(line)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	if more {
+		return line + " …"
+	}
+	return line
+}
@@ -33,6 +33,14 @@ func saveFilters(project *jira.Project) {
 			f.ExcludedStatusNames = append(f.ExcludedStatusNames, es.Name)
 		}
 	}
+	for _, ff := range searchForFields {
+		if ff.Value != "" && ff.Value != ui.MessageAll {
+			if f.FieldFilters == nil {
+				f.FieldFilters = make(map[string]string, len(searchForFields))
+			}
+			f.FieldFilters[ff.Field.Id] = ff.Value
+		}
+	}
 	f.SortByUpdated = sortByUpdated
 	_ = workspaces.SaveIssueFilters(project.Id, f)
 }
@@ -41,8 +49,9 @@ func saveFilters(project *jira.Project) {
 // navigator globals. It is load-or-clear: when a project has no saved filters
 // the globals are reset to nil, so a previous project's filters never bleed
 // into a project where those ids don't exist. Reconstructs the jira structs
-// from persisted primitives with no API round-trip.
-func restoreFilters(project *jira.Project) {
+// from persisted primitives with no API round-trip; custom field filters are
+// restored for the given fields only - the ones the workspace filters by.
+func restoreFilters(project *jira.Project, fields []jira.Field) {
 	if project == nil || project.Id == "" || project.Id == ui.MessageAll {
 		return
 	}
@@ -57,6 +66,7 @@ func restoreFilters(project *jira.Project) {
 		searchForUser = nil
 		searchForLabel = ""
 		excludedStatuses = nil
+		searchForFields = nil
 		sortByUpdated = false
 		return
 	}
@@ -83,6 +93,12 @@ func restoreFilters(project *jira.Project) {
 		}
 		excludedStatuses = append(excludedStatuses, &jira.IssueStatus{Id: id, Name: name})
 	}
+	searchForFields = nil
+	for _, field := range fields {
+		if value := f.FieldFilters[field.Id]; value != "" {
+			searchForFields = append(searchForFields, FieldFilter{Field: field, Value: value})
+		}
+	}
 	// Unconditional assignment: sortByUpdated is a process-global shared across
 	// projects, so it must be set to the saved value (not OR'd in), or one
 	// project's sort mode would leak into another. Same load-or-clear invariant
@@ -20,12 +20,14 @@ func Test_saveAndRestoreFilters_roundTrip(t *testing.T) {
 		{Id: "20", Name: "Done"},
 		{Id: "30", Name: "Rejected"},
 	}
+	team := jira.Field{Id: "customfield_10001", Name: "Team"}
+	searchForFields = []FieldFilter{{Field: team, Value: "Platform"}}
 	sortByUpdated = true
 
 	// when - persist, wipe live state, then restore
 	saveFilters(project)
 	resetFilterGlobals()
-	restoreFilters(project)
+	restoreFilters(project, []jira.Field{team})
 
 	// then - every filter is reconstructed, excluded ids/names stay index-aligned
 	assert.NotNil(t, searchForStatus)
@@ -40,9 +42,25 @@ func Test_saveAndRestoreFilters_roundTrip(t *testing.T) {
 	assert.Equal(t, "Done", excludedStatuses[0].Name)
 	assert.Equal(t, "30", excludedStatuses[1].Id)
 	assert.Equal(t, "Rejected", excludedStatuses[1].Name)
+	assert.Equal(t, []FieldFilter{{Field: team, Value: "Platform"}}, searchForFields)
 	assert.True(t, sortByUpdated, "sort mode should be restored")
 }
 
+func Test_restoreFilters_dropsFieldsNoLongerFiltered(t *testing.T) {
+	// given - a saved Team filter, but the workspace filters by Story Points now
+	_ = os2.SetUserHomeDir(t.TempDir())
+	defer resetFilterGlobals()
+	project := &jira.Project{Id: "COINS", Key: "COINS", Name: "Coins"}
+	searchForFields = []FieldFilter{{Field: jira.Field{Id: "customfield_10001", Name: "Team"}, Value: "Platform"}}
+	saveFilters(project)
+
+	// when
+	restoreFilters(project, []jira.Field{{Id: "customfield_10016", Name: "Story Points"}})
+
+	// then
+	assert.Nil(t, searchForFields)
+}
+
 func Test_restoreFilters_unknownProjectClears(t *testing.T) {
 	// given - COINS has saved filters, but we restore a never-saved project
 	_ = os2.SetUserHomeDir(t.TempDir())
@@ -52,18 +70,20 @@ func Test_restoreFilters_unknownProjectClears(t *testing.T) {
 	searchForUser = &jira.User{AccountId: "acc-1", DisplayName: "Jane Doe"}
 	searchForLabel = "backend"
 	excludedStatuses = []*jira.IssueStatus{{Id: "20", Name: "Done"}}
+	searchForFields = []FieldFilter{{Field: jira.Field{Id: "customfield_10001"}, Value: "Platform"}}
 	sortByUpdated = true
 	saveFilters(coins)
 
 	// when - switching to a project with no saved filters (COINS's state still live)
 	other := &jira.Project{Id: "FOO", Key: "FOO", Name: "Foo"}
-	restoreFilters(other)
+	restoreFilters(other, nil)
 
 	// then - load-or-clear: no leak from COINS, including the sort mode
 	assert.Nil(t, searchForStatus)
 	assert.Nil(t, searchForUser)
 	assert.Equal(t, "", searchForLabel)
 	assert.Nil(t, excludedStatuses)
+	assert.Nil(t, searchForFields)
 	assert.False(t, sortByUpdated, "sort mode must reset for a never-saved project")
 }
 
@@ -72,5 +92,6 @@ func resetFilterGlobals() {
 	searchForUser = nil
 	searchForLabel = ""
 	excludedStatuses = nil
+	searchForFields = nil
 	sortByUpdated = false
 }
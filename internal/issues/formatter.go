@@ -18,8 +18,15 @@ func FormatJiraIssue(issue *jira.Issue) string {
 		FormatAssignee(issue))
 }
 
-func FormatJiraIssueTable(issue *jira.Issue, summaryColWidth int, statusColWidth int, typeColWidth int, assigneeColWidth int, now time.Time) string {
-	row, _ := formatJiraIssueTableWithRanges(issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, now)
+// CustomColumn is a column of a custom field the workspace shows in issue
+// lists, after the assignee; Width is the longest value in the list.
+type CustomColumn struct {
+	Field jira.Field
+	Width int
+}
+
+func FormatJiraIssueTable(issue *jira.Issue, summaryColWidth int, statusColWidth int, typeColWidth int, assigneeColWidth int, columns []CustomColumn, now time.Time) string {
+	row, _ := formatJiraIssueTableWithRanges(issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, columns, now)
 	return row
 }
 
@@ -29,7 +36,7 @@ func FormatJiraIssueTable(issue *jira.Issue, summaryColWidth int, statusColWidth
 // assignee, and last-updated columns are never matched or highlighted. Offsets
 // are tracked during assembly rather than located afterwards, so summary text
 // that happens to recur elsewhere can't be mismatched.
-func formatJiraIssueTableWithRanges(issue *jira.Issue, summaryColWidth int, statusColWidth int, typeColWidth int, assigneeColWidth int, now time.Time) (string, []app.MatchRange) {
+func formatJiraIssueTableWithRanges(issue *jira.Issue, summaryColWidth int, statusColWidth int, typeColWidth int, assigneeColWidth int, columns []CustomColumn, now time.Time) (string, []app.MatchRange) {
 	assignee := issue.Fields.Assignee.DisplayName
 	if assignee == "" {
 		assignee = ui.MessageUnassigned
@@ -81,6 +88,12 @@ func formatJiraIssueTableWithRanges(issue *jira.Issue, summaryColWidth int, stat
 	b.WriteByte(' ')
 	writeCol(assigneeCol, -1)
 	b.WriteByte(' ')
+	for _, column := range columns {
+		width := app.MinInt(column.Width, ui.MaxCustomColWidth)
+		value := issue.Fields.Value(column.Field).Text
+		writeCol(fmt.Sprintf("%"+strconv.Itoa(width+ui.TableColumnPadding)+"s", value[:app.MinInt(width, len(value))]), -1)
+		b.WriteByte(' ')
+	}
 	writeCol(dateCol, -1)
 	return b.String(), ranges
 }
@@ -102,7 +115,9 @@ func issueKeyNumberOffset(key string) int {
 	return dash + 1
 }
 
-func FormatJiraIssues(issues []jira.Issue) []string {
+// FormatJiraIssues formats the issues as a table, with a column for each of
+// the given custom fields.
+func FormatJiraIssues(issues []jira.Issue, columns []jira.Field) []string {
 	formatted := make([]string, 0, len(issues))
 	summaryColWidth := findIssueColumnSize(&issues, func(i jira.Issue) string {
 		return i.Fields.Summary
@@ -119,9 +134,10 @@ func FormatJiraIssues(issues []jira.Issue) []string {
 		}
 		return i.Fields.Assignee.DisplayName
 	})
+	customColumns := customColumns(issues, columns)
 	now := time.Now()
 	for _, issue := range issues {
-		formatted = append(formatted, FormatJiraIssueTable(&issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, now))
+		formatted = append(formatted, FormatJiraIssueTable(&issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, customColumns, now))
 	}
 	return formatted
 }
@@ -129,7 +145,7 @@ func FormatJiraIssues(issues []jira.Issue) []string {
 // FormatJiraIssuesWithRanges formats the issues and returns, index-aligned with
 // the rows, the matchable byte ranges (key + summary) for each. Fed to the
 // range-aware fuzzy finder so matching/highlighting ignore the other columns.
-func FormatJiraIssuesWithRanges(issues []jira.Issue) ([]string, [][]app.MatchRange) {
+func FormatJiraIssuesWithRanges(issues []jira.Issue, columns []jira.Field) ([]string, [][]app.MatchRange) {
 	formatted := make([]string, 0, len(issues))
 	ranges := make([][]app.MatchRange, 0, len(issues))
 	summaryColWidth := findIssueColumnSize(&issues, func(i jira.Issue) string {
@@ -147,15 +163,26 @@ func FormatJiraIssuesWithRanges(issues []jira.Issue) ([]string, [][]app.MatchRan
 		}
 		return i.Fields.Assignee.DisplayName
 	})
+	customColumns := customColumns(issues, columns)
 	now := time.Now()
 	for _, issue := range issues {
-		row, rr := formatJiraIssueTableWithRanges(&issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, now)
+		row, rr := formatJiraIssueTableWithRanges(&issue, summaryColWidth, statusColWidth, typeColWidth, assigneeColWidth, customColumns, now)
 		formatted = append(formatted, row)
 		ranges = append(ranges, rr)
 	}
 	return formatted, ranges
 }
 
+func customColumns(issues []jira.Issue, fields []jira.Field) []CustomColumn {
+	columns := make([]CustomColumn, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, CustomColumn{Field: field, Width: findIssueColumnSize(&issues, func(i jira.Issue) string {
+			return i.Fields.Value(field).Text
+		})})
+	}
+	return columns
+}
+
 func FormatAssignee(issue *jira.Issue) string {
 	assignee := issue.Fields.Assignee.DisplayName
 	if assignee == "" {
@@ -1,6 +1,7 @@
 package issues
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
@@ -26,7 +27,7 @@ func Test_FormatJiraIssuesWithRanges_scopesToKeyAndSummary(t *testing.T) {
 	issues := []jira.Issue{
 		mkIssue("PROJ-53", "Fix login flow", "Open", "Bug", "Alice", ""),
 	}
-	rows, ranges := FormatJiraIssuesWithRanges(issues)
+	rows, ranges := FormatJiraIssuesWithRanges(issues, nil)
 	assert.Len(t, rows, 1)
 	assert.Len(t, ranges, 1)
 	assert.Len(t, ranges[0], 2, "expected key + summary ranges")
@@ -79,7 +80,7 @@ func Test_rangeProvider_alphabeticQueryNeverHitsKey(t *testing.T) {
 	issues := []jira.Issue{
 		mkIssue("COINS-115", `C2Profile: Make "Skipping entity resolution`, "Open", "Task", "Bob", ""),
 	}
-	rows, ranges := FormatJiraIssuesWithRanges(issues)
+	rows, ranges := FormatJiraIssuesWithRanges(issues, nil)
 
 	screen := tcell.NewSimulationScreen("utf-8")
 	_ = screen.Init() //nolint:errcheck
@@ -119,7 +120,7 @@ func Test_rangeProvider_highlightAndSelection(t *testing.T) {
 		mkIssue("AAA-1", "unrelated ticket", "Open", "Task", "Bob", ""),
 		mkIssue("AAA-2", "login page bug", "Open", "Bug", "Alice", ""),
 	}
-	rows, ranges := FormatJiraIssuesWithRanges(issues)
+	rows, ranges := FormatJiraIssuesWithRanges(issues, nil)
 
 	screen := tcell.NewSimulationScreen("utf-8")
 	_ = screen.Init() //nolint:errcheck
@@ -156,3 +157,22 @@ func Test_rangeProvider_highlightAndSelection(t *testing.T) {
 		assert.Truef(t, inRange(idx), "highlighted index %d outside key/summary ranges", idx)
 	}
 }
+
+func Test_FormatJiraIssuesWithRanges_customColumns(t *testing.T) {
+	// given
+	points := jira.Field{Id: "customfield_10016", Name: "Story Points"}
+	team := jira.Field{Id: "customfield_10001", Name: "Team"}
+	first := mkIssue("PROJ-1", "First", "Open", "Bug", "Alice", "")
+	first.Fields.Custom = map[string]json.RawMessage{"customfield_10016": json.RawMessage(`13`), "customfield_10001": json.RawMessage(`{"value":"Platform"}`)}
+	second := mkIssue("PROJ-2", "Second", "Open", "Bug", "Alice", "")
+	second.Fields.Custom = map[string]json.RawMessage{"customfield_10016": json.RawMessage(`3`)}
+
+	// when
+	rows, ranges := FormatJiraIssuesWithRanges([]jira.Issue{first, second}, []jira.Field{points, team})
+
+	// then
+	assert.Contains(t, rows[0], "   13   Platform ")
+	assert.Contains(t, rows[1], "    3            ")
+	assert.Equal(t, len(rows[0]), len(rows[1]), "custom columns should keep rows aligned")
+	assert.Len(t, ranges[0], 2, "custom columns shouldn't be matchable")
+}
@@ -1,9 +1,12 @@
 package issues
 
 import (
+	"context"
+	"errors"
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/jira"
 	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/workspaces"
 	"time"
 )
 
@@ -15,11 +18,33 @@ type SearchArgs struct {
 
 const (
 	issue           string = "issue"
+	issueCreate     string = "issue-create"
+	issueFields     string = "issue-fields"
+	issueTransition string = "issue-transition"
 	issuesSearch    string = "issues-search"
 	issuesSearchJql string = "issues-search-jql"
 	jql             string = "jql"
 )
 
+// session is the workspace fjira was started with, see UseWorkspace. It's
+// not necessarily the current one, and settings from env variables or
+// --demo have no workspace at all.
+var session *workspaces.WorkspaceSettings
+
+// UseWorkspace hands the settings of the session to the issue views: the
+// worklog timers are kept per workspace, and attachments go to its
+// download directory.
+func UseWorkspace(settings *workspaces.WorkspaceSettings) {
+	session = settings
+}
+
+func sessionWorkspace() string {
+	if session == nil {
+		return workspaces.EmptyWorkspace
+	}
+	return session.Workspace
+}
+
 func RegisterGoTo() {
 	app.RegisterGoto(issue, func(args ...interface{}) {
 		issueKey := args[0].(string)
@@ -31,7 +56,11 @@ func RegisterGoTo() {
 
 		defer app.GetApp().PanicRecover()
 		app.GetApp().Loading(true)
-		issue, err := api.GetIssueDetailed(issueKey)
+		issue, err := api.WithContext(app.GetApp().LoadingContext()).GetIssueDetailed(issueKey)
+		if errors.Is(err, context.Canceled) {
+			// aborted with Esc - stay on the current view
+			return
+		}
 		if err != nil {
 			app.GetApp().Loading(false)
 			app.Error(err.Error())
@@ -48,6 +77,37 @@ func RegisterGoTo() {
 		issueView := NewIssueView(issue, goBackFn, api)
 		app.GetApp().SetView(issueView)
 	})
+	app.RegisterGoto(issueCreate, func(args ...interface{}) {
+		defer app.GetApp().PanicRecover()
+		projectKey := args[0].(string)
+		var goBackFn func()
+		if fn, ok := args[1].(func()); ok {
+			goBackFn = fn
+		}
+		api := args[2].(jira.Api)
+		app.GetApp().SetView(NewCreateIssueView(projectKey, goBackFn, api))
+	})
+	app.RegisterGoto(issueFields, func(args ...interface{}) {
+		defer app.GetApp().PanicRecover()
+		issue := args[0].(*jira.Issue)
+		var goBackFn func()
+		if fn, ok := args[1].(func()); ok {
+			goBackFn = fn
+		}
+		api := args[2].(jira.Api)
+		app.GetApp().SetView(NewEditFieldsView(issue, goBackFn, api))
+	})
+	app.RegisterGoto(issueTransition, func(args ...interface{}) {
+		defer app.GetApp().PanicRecover()
+		issue := args[0].(*jira.Issue)
+		transition := args[1].(*jira.IssueTransition)
+		var goBackFn func()
+		if fn, ok := args[2].(func()); ok {
+			goBackFn = fn
+		}
+		api := args[3].(jira.Api)
+		app.GetApp().SetView(NewTransitionView(issue, transition, goBackFn, api))
+	})
 	app.RegisterGoto(issuesSearch, func(args ...interface{}) {
 		projectKey := args[0].(string)
 		var goBackFn func()
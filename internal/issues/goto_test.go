@@ -37,6 +37,26 @@ func TestGoIntoIssues(t *testing.T) {
 				return app.CurrentScreenName() == "issue"
 			},
 		}},
+		{"should switch view into create issue view", args{
+			gotoMethod: func() { app.GoTo("issue-create", "ABC", func() {}, jira.NewJiraApiMock(nil)) },
+			viewPredicate: func() bool {
+				return app.CurrentScreenName() == "issue-create"
+			},
+		}},
+		{"should switch view into edit fields view", args{
+			gotoMethod: func() { app.GoTo("issue-fields", &jira.Issue{Key: "ABC-1"}, func() {}, jira.NewJiraApiMock(nil)) },
+			viewPredicate: func() bool {
+				return app.CurrentScreenName() == "issue-fields"
+			},
+		}},
+		{"should switch view into transition view", args{
+			gotoMethod: func() {
+				app.GoTo("issue-transition", &jira.Issue{Key: "ABC-1"}, &jira.IssueTransition{HasScreen: true}, func() {}, jira.NewJiraApiMock(nil))
+			},
+			viewPredicate: func() bool {
+				return app.CurrentScreenName() == "issue-transition"
+			},
+		}},
 		{"should switch view into issues view with jql", args{
 			gotoMethod: func() { app.GoTo("issues-search-jql", "test jql", func() {}, jira.NewJiraApiMock(nil)) },
 			viewPredicate: func() bool {
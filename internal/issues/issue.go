@@ -1,12 +1,17 @@
 package issues
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/adf"
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/comments"
 	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/markdown"
+	"github.com/mk-5/fjira/internal/richtext"
 	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/wiki"
 	"math"
 	"strings"
 	"time"
@@ -21,27 +26,35 @@ type issueView struct {
 	issue             *jira.Issue
 	goBackFn          func()
 	descriptionLimitX int
-	descriptionLimitY int
 	scrollY           int
 	descriptionLines  int
 	commentsLines     int
 	detailsLines      int
+	worklogLines      int
+	attachmentLines   int
 	maxScrollY        int
-	body              string
-	detailRows        []detailRow
-	detailLabelWidth  int
-	relatedRows       []string
-	relatedKeys       []string
-	relatedColStart   int
-	summaryLen        int
-	labels            string
-	labelsLen         int
-	comments          []comments.Comment
-	lastY             int
-	screenY           int
-	boxTitleStyle     tcell.Style
-	defaultStyle      tcell.Style
-	dimStyle          tcell.Style
+	// bodyLines is the laid out description, see comments.RenderBody
+	bodyLines []richtext.Line
+	// links are the urls footnoted in the description and comments
+	links            []string
+	detailRows       []detailRow
+	detailLabelWidth int
+	relatedRows      []string
+	relatedKeys      []string
+	relatedColStart  int
+	summaryLen       int
+	labels           string
+	labelsLen        int
+	comments         []comments.Comment
+	attachmentRows   []string
+	// worklogRows and worklogTitle are the Worklog box, see loadWorklogs
+	worklogRows   []string
+	worklogTitle  string
+	lastY         int
+	screenY       int
+	boxTitleStyle tcell.Style
+	defaultStyle  tcell.Style
+	dimStyle      tcell.Style
 }
 
 var (
@@ -51,9 +64,16 @@ var (
 		ui.NavItemConfig{Action: ui.ActionComment, Text1: ui.MessageComment, Text2: "[c]", Rune: 'c'},
 		ui.NavItemConfig{Action: ui.ActionEditDescription, Text1: "Edit description ", Text2: "[d]", Rune: 'd'},
 		ui.NavItemConfig{Action: ui.ActionAddLabel, Text1: ui.MessageLabel, Text2: "[l]", Rune: 'l'},
+		ui.NavItemConfig{Action: ui.ActionEditField, Text1: ui.MessageEditFields, Text2: "[e]", Rune: 'e'},
 		ui.NavItemConfig{Action: ui.ActionCreateIssue, Text1: ui.MessageCreateIssue, Text2: "[F6]", Key: tcell.KeyF6},
 		ui.NavItemConfig{Action: ui.ActionOpen, Text1: ui.MessageOpen, Text2: "[o]", Rune: 'o'},
 		ui.NavItemConfig{Action: ui.ActionJumpToRelated, Text1: ui.MessageJumpToRelated, Text2: "[j]", Rune: 'j'},
+		ui.NavItemConfig{Action: ui.ActionOpenLink, Text1: ui.MessageOpenLink, Text2: "[u]", Rune: 'u'},
+		ui.NavItemConfig{Action: ui.ActionWorklog, Text1: ui.MessageWorklog, Text2: "[w]", Rune: 'w'},
+		ui.NavItemConfig{Action: ui.ActionAttachments, Text1: ui.MessageAttachments, Text2: "[f]", Rune: 'f'},
+		ui.NavItemConfig{Action: ui.ActionLinks, Text1: ui.MessageLinks, Text2: "[i]", Rune: 'i'},
+		ui.NavItemConfig{Action: ui.ActionCreateChild, Text1: ui.MessageCreateChild, Text2: "[t]", Rune: 't'},
+		ui.NavItemConfig{Action: ui.ActionComments, Text1: ui.MessageComments, Text2: "[m]", Rune: 'm'},
 	}
 )
 
@@ -99,6 +119,17 @@ func buildDetailRows(issue *jira.Issue, now time.Time) []detailRow {
 	)
 }
 
+// customDetailRows builds a Details row for each custom field the workspace
+// shows there, in the configured order. Like the built-in rows, a field the
+// issue has no value for renders blank.
+func customDetailRows(issue *jira.Issue, fields []jira.Field) []detailRow {
+	rows := make([]detailRow, 0, len(fields))
+	for _, field := range fields {
+		rows = append(rows, detailRow{label: field.Name, value: issue.Fields.Value(field).Text})
+	}
+	return rows
+}
+
 // parentDetailRow builds the Details row for the issue's parent link, and false
 // when there is none. The parent is an epic (label "Epic") or a regular ticket
 // (label "Parent"), distinguished by the parent's issue type. The human-readable
@@ -202,10 +233,10 @@ func NewIssueView(issue *jira.Issue, goBackFn func(), api jira.Api) app.View {
 		Text1: ui.MessageLabelUpdated,
 		Text2: app.ActionBarLabel(app.FormatRelativeTime(issue.Fields.Updated, time.Now())),
 	}))
-	cs := comments.ParseCommentsFromIssue(issue, 1000, 1000)
+	cs := comments.ParseCommentsFromIssue(issue, 1000, wiki.NewRenderer(richtext.DefaultTheme()))
 	ls := strings.Join(issue.Fields.Labels, labelsDelimiter)
 	labelsLen := len(ls)
-	detailRows := buildDetailRows(issue, time.Now())
+	detailRows := append(buildDetailRows(issue, time.Now()), customDetailRows(issue, api.CustomFields().Details)...)
 	relatedRows, relatedKeys := buildRelatedRows(issue)
 
 	return &issueView{
@@ -214,7 +245,6 @@ func NewIssueView(issue *jira.Issue, goBackFn func(), api jira.Api) app.View {
 		topBar:           issueActionBar,
 		issue:            issue,
 		scrollY:          0,
-		body:             issue.Fields.Description,
 		comments:         cs,
 		labels:           ls,
 		labelsLen:        labelsLen,
@@ -222,6 +252,7 @@ func NewIssueView(issue *jira.Issue, goBackFn func(), api jira.Api) app.View {
 		detailLabelWidth: detailLabelWidth(detailRows),
 		relatedRows:      relatedRows,
 		relatedKeys:      relatedKeys,
+		attachmentRows:   attachmentRows(issue.Fields.Attachments, time.Now()),
 		summaryLen:       len(issue.Fields.Summary),
 		goBackFn:         goBackFn,
 		boxTitleStyle:    app.DefaultStyle().Foreground(app.Color("details.foreground")),
@@ -243,6 +274,9 @@ func (view *issueView) Init() {
 	if len(view.issue.Fields.Subtasks) == 0 && view.issue.Key != "" {
 		go view.loadEpicChildren()
 	}
+	if view.issue.Key != "" {
+		go view.loadWorklogs()
+	}
 }
 
 // loadEpicChildren runs the deferred `parent = KEY` search off the UI thread,
@@ -251,7 +285,7 @@ func (view *issueView) Init() {
 // after navigating away harmlessly recomputes/redraws a detached view.
 func (view *issueView) loadEpicChildren() {
 	defer app.GetApp().PanicRecover()
-	children, err := view.api.SearchJql(fmt.Sprintf("parent = \"%s\" ORDER BY key ASC", view.issue.Key))
+	children, err := view.api.SearchJql(childrenJql(view.issue.Key))
 	if err != nil || len(children) == 0 {
 		return
 	}
@@ -325,14 +359,33 @@ func (view *issueView) Draw(screen tcell.Screen) {
 
 		app.DrawBox(screen, 1, view.lastY+1, view.descriptionLimitX+4, view.lastY+1+view.descriptionLines+4, view.boxTitleStyle)
 		app.DrawText(screen, 2, view.lastY+1, view.boxTitleStyle, ui.MessageDescription)
-		app.DrawTextLimited(screen, 3, view.lastY+2, view.descriptionLimitX, view.descriptionLimitY, view.defaultStyle, view.body)
+		richtext.Draw(screen, 3, view.lastY+2, view.bodyLines)
 
 		view.lastY = view.lastY + view.descriptionLines + 6
 
+		if len(view.attachmentRows) > 0 {
+			app.DrawBox(screen, 1, view.lastY+1, view.descriptionLimitX+4, view.lastY+2+len(view.attachmentRows), view.boxTitleStyle)
+			app.DrawText(screen, 2, view.lastY+1, view.boxTitleStyle, ui.MessageAttachmentsTitle)
+			for i, row := range view.attachmentRows {
+				app.DrawTextLimited(screen, 3, view.lastY+2+i, view.descriptionLimitX+2, view.lastY+2+i, view.defaultStyle, row)
+			}
+			view.lastY = view.lastY + view.attachmentLines
+		}
+
+		if len(view.worklogRows) > 0 {
+			app.DrawBox(screen, 1, view.lastY+1, view.descriptionLimitX+4, view.lastY+2+len(view.worklogRows), view.boxTitleStyle)
+			app.DrawText(screen, 2, view.lastY+1, view.boxTitleStyle, view.worklogTitle)
+			for i, row := range view.worklogRows {
+				app.DrawTextLimited(screen, 3, view.lastY+2+i, view.descriptionLimitX+2, view.lastY+2+i, view.defaultStyle, row)
+			}
+			view.lastY = view.lastY + view.worklogLines
+		}
+
 		for _, comment := range view.comments {
 			app.DrawBox(screen, 1, view.lastY+1, view.descriptionLimitX+4, view.lastY+1+comment.Lines+2, view.boxTitleStyle)
 			app.DrawText(screen, 2, view.lastY+1, view.boxTitleStyle, comment.Title)
-			app.DrawTextLimited(screen, 3, view.lastY+2, view.descriptionLimitX, view.descriptionLimitY, view.defaultStyle, comment.Body)
+			// one blank line above the body, see comments.Comment.Lines
+			richtext.Draw(screen, 3, view.lastY+3, comment.Rich)
 			view.lastY = view.lastY + 1 + comment.Lines + 3
 		}
 	}
@@ -364,10 +417,14 @@ func (view *issueView) leftColLimit() int {
 func (view *issueView) Resize(screenX, screenY int) {
 	view.screenY = screenY
 	view.descriptionLimitX = app.ClampInt(int(math.Floor(float64(screenX)*0.9)), 1, 10000)
-	view.descriptionLimitY = 1000
-	view.descriptionLines = app.DrawTextLimited(nil, 0, 0, view.descriptionLimitX, view.descriptionLimitY, view.defaultStyle, view.body) + 1
+	// one renderer for the description and the comments, so their links are
+	// numbered through
+	renderer := wiki.NewRenderer(richtext.DefaultTheme())
+	view.bodyLines = comments.RenderBody(view.issue.Fields.Description, view.issue.Fields.DescriptionAdf, view.descriptionLimitX, renderer)
+	view.descriptionLines = len(view.bodyLines)
 	commentsLines := 0
-	view.comments = comments.ParseCommentsFromIssue(view.issue, view.descriptionLimitX, view.descriptionLimitY)
+	view.comments = comments.ParseCommentsFromIssue(view.issue, view.descriptionLimitX, renderer)
+	view.links = renderer.Links()
 	for _, comment := range view.comments {
 		commentsLines = commentsLines + comment.Lines + 3
 	}
@@ -394,6 +451,16 @@ func (view *issueView) recomputeDetailsLayout() {
 	// border. The box is as tall as the taller column. Single source of truth
 	// shared with Draw and the maxScrollY math below.
 	view.detailsLines = app.MaxInt(len(view.detailRows), len(view.relatedRows)) + 2
+	// Attachments and Worklog boxes: title + rows + bottom border + a blank
+	// line, when shown
+	view.attachmentLines = 0
+	if len(view.attachmentRows) > 0 {
+		view.attachmentLines = len(view.attachmentRows) + 3
+	}
+	view.worklogLines = 0
+	if len(view.worklogRows) > 0 {
+		view.worklogLines = len(view.worklogRows) + 3
+	}
 	const topAndBottomBarSize = 12
 	// maxScrollY is the content height beyond the viewport (the existing
 	// heuristic), plus a screenY/3 buffer so scrolling to the end lands on an
@@ -401,7 +468,7 @@ func (view *issueView) recomputeDetailsLayout() {
 	// scrollY pushes content up, so the buffer enlarges the cap; it can never
 	// hide the last line (that's reached before the cap).
 	scrollBuffer := view.screenY / 3
-	view.maxScrollY = app.ClampInt(int(math.Abs(float64(view.screenY-topAndBottomBarSize-view.descriptionLines-view.commentsLines-view.detailsLines-10)))+scrollBuffer, 0, 2000)
+	view.maxScrollY = app.ClampInt(int(math.Abs(float64(view.screenY-topAndBottomBarSize-view.descriptionLines-view.commentsLines-view.detailsLines-view.attachmentLines-view.worklogLines-10)))+scrollBuffer, 0, 2000)
 }
 
 func (view *issueView) HandleKeyEvent(ev *tcell.EventKey) {
@@ -462,7 +529,7 @@ func (view *issueView) handleIssueAction() {
 					view.reopen()
 				},
 				TextConsumer: func(s string) {
-					view.doComment(view.issue, s)
+					view.doComment(view.issue, s, nil)
 				},
 				MaxLength: maxCommentLineWidth,
 			})
@@ -477,19 +544,17 @@ func (view *issueView) handleIssueAction() {
 					view.doUpdateDescription(view.issue, s)
 				},
 				MaxLength:   1000,
-				InitialText: view.issue.Fields.Description,
+				InitialText: descriptionMarkdown(view.issue),
 			})
 			return
 		case ui.ActionAddLabel:
 			app.GoTo("labels-add", view.issue, view.reopen, view.api)
 			return
+		case ui.ActionEditField:
+			app.GoTo(issueFields, view.issue, view.reopen, view.api)
+			return
 		case ui.ActionCreateIssue:
-			projectId := ""
-			if view.issue != nil {
-				projectId = view.issue.Fields.Project.Id
-			}
-			ui.OpenCreateIssueInBrowser(view.api, projectId, 0)
-			go view.handleIssueAction()
+			app.GoTo(issueCreate, view.issue.Fields.Project.Key, view.reopen, view.api)
 			return
 		case ui.ActionOpen:
 			OpenIssueInBrowser(view.issue, view.api)
@@ -498,6 +563,24 @@ func (view *issueView) handleIssueAction() {
 		case ui.ActionJumpToRelated:
 			view.runJumpToRelated()
 			return
+		case ui.ActionOpenLink:
+			view.runOpenLink()
+			return
+		case ui.ActionWorklog:
+			view.runWorklog()
+			return
+		case ui.ActionAttachments:
+			view.runAttachments()
+			return
+		case ui.ActionLinks:
+			view.runLinks()
+			return
+		case ui.ActionCreateChild:
+			view.runCreateChild()
+			return
+		case ui.ActionComments:
+			view.runComments()
+			return
 		}
 	}
 }
@@ -555,27 +638,98 @@ func (view *issueView) runJumpToRelated() {
 	}
 }
 
+// runOpenLink opens a fuzzy-find modal over the footnoted links of the
+// description and comments ("[1] https://..."), and opens the selected one in
+// the browser. Shows a toast instead when there are none.
+func (view *issueView) runOpenLink() {
+	links := view.links
+	if len(links) == 0 {
+		app.Error(ui.MessageNoLinksToOpen)
+		go view.handleIssueAction()
+		return
+	}
+	rows := make([]string, 0, len(links))
+	for i, link := range links {
+		rows = append(rows, fmt.Sprintf("[%d] %s", i+1, link))
+	}
+	a := app.GetApp()
+	view.fuzzyFind = app.NewFuzzyFind(ui.MessageOpenLinkFuzzyFind, rows)
+	if chosen := <-view.fuzzyFind.Complete; true {
+		view.fuzzyFind = nil
+		a.ClearNow()
+		if chosen.Index >= 0 && chosen.Index < len(links) {
+			if err := app.TryOpenLink(links[chosen.Index]); err != nil {
+				app.Error(fmt.Sprintf(ui.MessageCannotOpenLink, links[chosen.Index], err))
+			}
+		}
+		go view.handleIssueAction()
+	}
+}
+
+func (view *issueView) Reload() {
+	view.reopen()
+}
+
 func (view *issueView) reopen() {
 	app.GoTo("issue", view.issue.Key, view.goBackFn, view.api)
 }
 
-func (view *issueView) doComment(issue *jira.Issue, comment string) {
+// doComment adds the comment, restricted to the visibility's role or group -
+// visible to everyone when it's nil.
+func (view *issueView) doComment(issue *jira.Issue, comment string, visibility *jira.CommentVisibility) {
 	app.GetApp().LoadingWithText(true, ui.MessageAddingComment)
-	err := view.api.DoComment(issue.Key, comment)
+	err := view.sendMarkdown(comment, func(wiki string) error {
+		return view.api.DoComment(issue.Key, wiki, visibility)
+	}, func(doc json.RawMessage) error {
+		return view.api.DoCommentAdf(issue.Key, doc, visibility)
+	})
 	app.GetApp().Loading(false)
 	if err != nil {
-		app.Error(fmt.Sprintf(ui.MessageCannotAddComment, issue.Key, err))
+		app.Error(fmt.Sprintf(ui.MessageCannotAddComment, issue.Key, ui.JiraErrorReason(err)))
+		return
 	}
 	app.Success(fmt.Sprintf(ui.MessageCommentSuccess, issue.Key))
 }
 
 func (view *issueView) doUpdateDescription(issue *jira.Issue, description string) {
 	app.GetApp().LoadingWithText(true, "Updating description")
-	err := view.api.DoUpdateDescription(issue.Key, description)
+	err := view.sendMarkdown(description, func(wiki string) error {
+		return view.api.DoUpdateDescription(issue.Key, wiki)
+	}, func(doc json.RawMessage) error {
+		return view.api.DoUpdateDescriptionAdf(issue.Key, doc)
+	})
 	app.GetApp().Loading(false)
 	if err != nil {
-		app.Error(fmt.Sprintf("Cannot update description for %s. Reason: %s", issue.Key, err))
+		app.Error(fmt.Sprintf("Cannot update description for %s. Reason: %s", issue.Key, ui.JiraErrorReason(err)))
 		return
 	}
 	app.Success(fmt.Sprintf("Description updated successfully for %s", issue.Key))
 }
+
+// sendMarkdown sends text written in Markdown with sendAdf as a document when
+// Jira takes them (Cloud), with sendWiki as wiki markup otherwise.
+func (view *issueView) sendMarkdown(text string, sendWiki func(string) error, sendAdf func(json.RawMessage) error) error {
+	if !view.api.Capabilities().SupportsAdf() {
+		return sendWiki(markdown.ToWiki(text))
+	}
+	doc, err := markdown.ToAdf(text)
+	if err != nil {
+		return err
+	}
+	return sendAdf(doc)
+}
+
+// descriptionMarkdown is the description to edit, as Markdown: converted from
+// its document on Cloud, from its wiki markup on Server.
+func descriptionMarkdown(issue *jira.Issue) string {
+	return toMarkdown(issue.Fields.Description, issue.Fields.DescriptionAdf)
+}
+
+// toMarkdown converts the document when there's one, the wiki markup of text
+// otherwise.
+func toMarkdown(text string, doc json.RawMessage) string {
+	if parsed, err := adf.Parse(doc); err == nil {
+		return markdown.FromAdf(parsed)
+	}
+	return markdown.FromWiki(text)
+}
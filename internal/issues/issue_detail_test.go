@@ -2,6 +2,7 @@ package issues
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -55,6 +56,20 @@ func Test_buildDetailRows_empty_timestamps(t *testing.T) {
 	assert.Empty(t, rows[3].dimValue)
 }
 
+func Test_customDetailRows(t *testing.T) {
+	issue := &jira.Issue{}
+	issue.Fields.Custom = map[string]json.RawMessage{"customfield_10016": json.RawMessage(`5`)}
+	rows := customDetailRows(issue, []jira.Field{
+		{Id: "customfield_10016", Name: "Story Points"},
+		{Id: "customfield_10001", Name: "Team"},
+	})
+	// a field the issue has no value for renders blank, like the built-in rows
+	assert.Equal(t, []detailRow{
+		{label: "Story Points", value: "5"},
+		{label: "Team"},
+	}, rows)
+}
+
 func Test_parentDetailRow(t *testing.T) {
 	t.Run("no parent -> omitted", func(t *testing.T) {
 		_, ok := parentDetailRow(&jira.Issue{})
@@ -196,6 +211,27 @@ func Test_issueView_draws_details_box(t *testing.T) {
 	}
 }
 
+// A Cloud (ADF) description and comment are drawn rendered, never as raw JSON.
+func Test_issueView_draws_adf_description_and_comments(t *testing.T) {
+	const w, h = 100, 60
+	screen := newDetailTestScreen(t, w, h)
+	defer screen.Fini()
+	issue := detailTestIssue(1)
+	issue.Fields.Description = "Steps\n\n• open the board"
+	issue.Fields.DescriptionAdf = []byte(`{"type":"doc","content":[
+		{"type":"heading","attrs":{"level":3},"content":[{"type":"text","text":"Steps"}]},
+		{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"open the board"}]}]}]}]}`)
+	issue.Fields.Comment.Comments[0].BodyAdf = []byte(`{"type":"doc","content":[{"type":"paragraph","content":[
+		{"type":"mention","attrs":{"text":"@Bob"}},{"type":"text","text":" ADF-COMMENT"}]}]}`)
+	view := NewIssueView(issue, nil, jira.NewJiraApiMock(nil)).(*issueView)
+	view.Resize(w, h)
+
+	joined := strings.Join(renderVisibleRows(view, screen), "\n")
+	assert.Contains(t, joined, "• open the board")
+	assert.Contains(t, joined, "@Bob ADF-COMMENT")
+	assert.NotContains(t, joined, `"type"`)
+}
+
 // The parent/epic link renders as its own Details row: the summary as the
 // primary value and the key in the dimmer style. The fixture's parent is an
 // Epic, so the row is labelled "Epic".
@@ -2,6 +2,7 @@ package issues
 
 import (
 	"bytes"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
@@ -262,7 +263,7 @@ func Test_issueView_doComment(t *testing.T) {
 
 			// when
 			view.Init()
-			go view.doComment(view.issue, "abcde")
+			go view.doComment(view.issue, "abcde", nil)
 
 			// then
 			select {
@@ -274,6 +275,49 @@ func Test_issueView_doComment(t *testing.T) {
 	}
 }
 
+func Test_issueView_doComment_should_send_markdown_as_wiki_markup(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	bodies := make(chan string, 1)
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(200)
+		bodies <- string(body)
+	})
+	view := NewIssueView(&jira.Issue{Key: "test"}, nil, api).(*issueView)
+
+	// when
+	go view.doComment(view.issue, "**done** in `main`", nil)
+
+	// then
+	select {
+	case body := <-bodies:
+		assert2.JSONEq(t, `{"body":"*done* in {{main}}"}`, body)
+	case <-time.After(3 * time.Second):
+		t.Fail()
+	}
+}
+
+func Test_descriptionMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue *jira.Issue
+		want  string
+	}{
+		{"should convert wiki markup", &jira.Issue{Fields: jira.IssueFields{Description: "h1. Steps\n# open\n# *click*"}}, "# Steps\n\n1. open\n2. **click**"},
+		{"should convert documents", &jira.Issue{Fields: jira.IssueFields{Description: "Steps", DescriptionAdf: []byte(`{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"Steps","marks":[{"type":"em"}]}]}]}`)}}, "_Steps_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert2.Equal(t, tt.want, descriptionMarkdown(tt.issue))
+		})
+	}
+}
+
 func Test_fjiraIssueView_HandleKeyEvent(t *testing.T) {
 	screen := tcell.NewSimulationScreen("utf-8")
 	_ = screen.Init() //nolint:errcheck
@@ -488,3 +532,66 @@ func Test_issueView_runJumpToRelated_opensSelected(t *testing.T) {
 	assert2.Equal(t, "A-2", gotKey, "should navigate to the selected related issue")
 	assert2.Nil(t, view.fuzzyFind, "modal should be cleared after selection")
 }
+
+// Wiki markup (Server/DC, or the v2 api) is drawn styled, its links numbered
+// through the description and the comments for the links modal.
+func Test_issueView_renders_wiki_markup(t *testing.T) {
+	screen := newDetailTestScreen(t, 100, 60)
+	defer screen.Fini()
+	issue := &jira.Issue{Key: "test"}
+	issue.Fields.Description = "h2. Steps\n* *open* the [board|https://example.com/board]"
+	issue.Fields.Comment.Comments = []jira.Comment{{Body: "see [docs|https://example.com/docs]"}}
+	view := NewIssueView(issue, nil, jira.NewJiraApiMock(nil)).(*issueView)
+
+	view.Resize(100, 60)
+
+	joined := strings.Join(renderVisibleRows(view, screen), "\n")
+	assert2.Contains(t, joined, "• open the board[1]")
+	assert2.Contains(t, joined, "see docs[2]")
+	assert2.NotContains(t, joined, "h2.")
+	assert2.Equal(t, []string{"https://example.com/board", "https://example.com/docs"}, view.links)
+}
+
+// runOpenLink with no links shows a toast instead of opening a modal.
+func Test_issueView_runOpenLink_noLinks_showsToast(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	a := app.InitTestApp(screen)
+	view := NewIssueView(&jira.Issue{Key: "test"}, nil, jira.NewJiraApiMock(nil)).(*issueView)
+	view.Resize(100, 60)
+
+	view.runOpenLink()
+
+	assert2.Nil(t, view.fuzzyFind, "no modal should open when there are no links")
+	a.Render()
+	a.Render()
+	contents, x, y := screen.GetContents()
+	var buffer bytes.Buffer
+	for i := 0; i < x*y; i++ {
+		buffer.Write(contents[i].Bytes)
+	}
+	assert2.Contains(t, buffer.String(), ui.MessageNoLinksToOpen)
+}
+
+// The links modal lists the footnotes by number and closes on selection.
+func Test_issueView_runOpenLink_listsFootnotes(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+	view := NewIssueView(&jira.Issue{Key: "test"}, nil, jira.NewJiraApiMock(nil)).(*issueView)
+	view.links = []string{"https://example.com/a", "https://example.com/b"}
+
+	done := make(chan struct{})
+	go func() {
+		view.runOpenLink()
+		close(done)
+	}()
+
+	assert2.Eventually(t, func() bool { return view.fuzzyFind != nil }, time.Second, 5*time.Millisecond)
+	view.fuzzyFind.Complete <- app.FuzzyFindResult{Index: -1}
+	<-done
+
+	assert2.Nil(t, view.fuzzyFind, "the modal should close")
+}
@@ -22,7 +22,24 @@ const (
 	OrderByUpdated = "ORDER BY updated DESC"
 )
 
-func BuildSearchIssuesJql(project *jira.Project, query string, status *jira.IssueStatus, user *jira.User, label string, excludedStatuses []*jira.IssueStatus, orderBy string) string {
+// FieldFilter narrows the search to issues with the given value of a custom
+// field, e.g. {Story Points, 5} or {Team, Platform}.
+type FieldFilter struct {
+	Field jira.Field
+	Value string
+}
+
+// jql is the filter's clause: text fields are matched with ~, since jql can't
+// compare them with =; everything else - options, numbers, sprints, ... - with =.
+func (f FieldFilter) jql() string {
+	operator := "="
+	if f.Field.Schema.Type == "string" && f.Field.Schema.Items == "" {
+		operator = "~"
+	}
+	return fmt.Sprintf("%s%s\"%s\"", f.Field.JqlClause(), operator, strings.ReplaceAll(f.Value, `"`, `\"`))
+}
+
+func BuildSearchIssuesJql(project *jira.Project, query string, status *jira.IssueStatus, user *jira.User, label string, excludedStatuses []*jira.IssueStatus, fieldFilters []FieldFilter, orderBy string) string {
 	jql := ""
 	if project != nil && project.Id != ui.MessageAll {
 		jql = jql + fmt.Sprintf("project=%s", project.Id)
@@ -53,6 +70,11 @@ func BuildSearchIssuesJql(project *jira.Project, query string, status *jira.Issu
 			jql = jql + fmt.Sprintf(" AND status!=%s", excludedStatus.Id)
 		}
 	}
+	for _, fieldFilter := range fieldFilters {
+		if fieldFilter.Value != "" && fieldFilter.Value != ui.MessageAll {
+			jql = jql + " AND " + fieldFilter.jql()
+		}
+	}
 	if query != "" && issueRegExp.MatchString(query) {
 		jql = jql + fmt.Sprintf(" OR issuekey=\"%s\"", query)
 	}
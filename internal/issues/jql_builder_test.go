@@ -15,6 +15,7 @@ func Test_buildSearchIssuesJql(t *testing.T) {
 		user             *jira.User
 		label            string
 		excludedStatuses []*jira.IssueStatus
+		fieldFilters     []FieldFilter
 		orderBy          string
 	}
 	tests := []struct {
@@ -51,10 +52,22 @@ func Test_buildSearchIssuesJql(t *testing.T) {
 			project: &jira.Project{Id: "123"}, status: &jira.IssueStatus{Id: "st1"}, orderBy: OrderByUpdated},
 			"project=123 AND status=st1 ORDER BY updated DESC",
 		},
+		{"should filter by custom field", args{
+			project: &jira.Project{Id: "123"}, fieldFilters: []FieldFilter{{Field: jira.Field{Id: "customfield_10001", Schema: jira.FieldSchema{Type: "option"}}, Value: "Platform"}}},
+			"project=123 AND cf[10001]=\"Platform\" ORDER BY status",
+		},
+		{"should match text custom field", args{
+			project: &jira.Project{Id: "123"}, fieldFilters: []FieldFilter{{Field: jira.Field{Id: "customfield_10002", Schema: jira.FieldSchema{Type: "string"}}, Value: `say "hi"`}}},
+			"project=123 AND cf[10002]~\"say \\\"hi\\\"\" ORDER BY status",
+		},
+		{"should skip All custom field filter", args{
+			project: &jira.Project{Id: "123"}, fieldFilters: []FieldFilter{{Field: jira.Field{Id: "customfield_10001"}, Value: ui.MessageAll}}},
+			"project=123 ORDER BY status",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, BuildSearchIssuesJql(tt.args.project, tt.args.query, tt.args.status, tt.args.user, tt.args.label, tt.args.excludedStatuses, tt.args.orderBy), "BuildSearchIssuesJql(%v, %v, %v, %v)", tt.args.project, tt.args.query, tt.args.status, tt.args.user)
+			assert.Equalf(t, tt.want, BuildSearchIssuesJql(tt.args.project, tt.args.query, tt.args.status, tt.args.user, tt.args.label, tt.args.excludedStatuses, tt.args.fieldFilters, tt.args.orderBy), "BuildSearchIssuesJql(%v, %v, %v, %v)", tt.args.project, tt.args.query, tt.args.status, tt.args.user)
 		})
 	}
 }
@@ -1,6 +1,8 @@
 package issues
 
 import (
+	"context"
+	"errors"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/jira"
@@ -37,6 +39,10 @@ func (view *jqlSearchView) Destroy() {
 	// do nothing
 }
 
+func (view *jqlSearchView) Reload() {
+	app.GoTo("jql", view.api)
+}
+
 func (view *jqlSearchView) Draw(screen tcell.Screen) {
 	if view.fuzzyFind != nil {
 		view.fuzzyFind.Draw(screen)
@@ -64,7 +70,7 @@ func (view *jqlSearchView) HandleKeyEvent(ev *tcell.EventKey) {
 func (view *jqlSearchView) startJqlFuzzyFind() {
 	app.GetApp().ClearNow()
 	app.GetApp().Loading(true)
-	view.fuzzyFind = app.NewFuzzyFindWithProvider(ui.MessageJqlFuzzyFind, view.findIssues)
+	view.fuzzyFind = app.NewFuzzyFindWithContextProvider(ui.MessageJqlFuzzyFind, view.findIssues)
 	view.fuzzyFind.MarginBottom = 0
 	view.fuzzyFind.SetQuery(DefaultJqlQuery)
 	view.fuzzyFind.AlwaysShowAllResults()
@@ -90,18 +96,23 @@ func (view *jqlSearchView) reopen() {
 	app.GoTo("jql", view.api)
 }
 
-func (view *jqlSearchView) findIssues(query string) []string {
+func (view *jqlSearchView) findIssues(ctx context.Context, query string) []string {
 	app.GetApp().LoadingWithText(true, ui.MessageSearchIssuesLoading)
-	issues, err := view.api.SearchJql(query)
+	loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+	issues, err := view.api.WithContext(loadingCtx).SearchJql(query)
+	cancel()
 	app.GetApp().Loading(false)
+	if errors.Is(err, context.Canceled) {
+		return FormatJiraIssues(view.issues, view.api.CustomFields().Columns)
+	}
 	if err != nil && strings.Contains(err.Error(), BadRequest) {
 		// do nothing, invalid JQL query
-		return FormatJiraIssues(view.issues)
+		return FormatJiraIssues(view.issues, view.api.CustomFields().Columns)
 	}
 	if err != nil {
 		app.Error(err.Error())
-		return FormatJiraIssues(view.issues)
+		return FormatJiraIssues(view.issues, view.api.CustomFields().Columns)
 	}
 	view.issues = issues
-	return FormatJiraIssues(view.issues)
+	return FormatJiraIssues(view.issues, view.api.CustomFields().Columns)
 }
@@ -0,0 +1,153 @@
+package issues
+
+import (
+	"fmt"
+
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+)
+
+// linkDirection is a way to link the issue to another one: with the link
+// type read from the issue - Outward, like "blocks" - or towards it -
+// Inward, like "is blocked by".
+type linkDirection struct {
+	linkType jira.IssueLinkType
+	inward   bool
+}
+
+func (d linkDirection) String() string {
+	if d.inward {
+		return d.linkType.Inward
+	}
+	return d.linkType.Outward
+}
+
+// linkDirections lists both directions of every link type, except for the
+// ones reading the same both ways, like "relates to", listed once.
+func linkDirections(linkTypes []jira.IssueLinkType) []linkDirection {
+	directions := make([]linkDirection, 0, 2*len(linkTypes))
+	for _, linkType := range linkTypes {
+		directions = append(directions, linkDirection{linkType: linkType})
+		if linkType.Inward != linkType.Outward {
+			directions = append(directions, linkDirection{linkType: linkType, inward: true})
+		}
+	}
+	return directions
+}
+
+// linkRows renders the options of runLinks: a row per link direction, then
+// a row per link of the issue, to remove it.
+func linkRows(directions []linkDirection, links []jira.IssueLink) []string {
+	rows := make([]string, 0, len(directions)+len(links))
+	for _, d := range directions {
+		rows = append(rows, fmt.Sprintf(ui.MessageLinkDirection, d, d.linkType.Name))
+	}
+	for _, link := range links {
+		rows = append(rows, fmt.Sprintf(ui.MessageRemoveLink, linkLine(link)))
+	}
+	return rows
+}
+
+// linkLine is "blocks ABC-1 Summary of ABC-1", see jira.IssueLink.Line.
+func linkLine(link jira.IssueLink) string {
+	if ref := link.Linked(); ref != nil && ref.Fields.Summary != "" {
+		return fmt.Sprintf("%s %s", link.Line(), ref.Fields.Summary)
+	}
+	return link.Line()
+}
+
+// runLinks opens a fuzzy-find modal to link the issue to another one - the
+// link type and direction are chosen first, then the other issue with the
+// issue search, see pickIssue - or to remove one of its links.
+func (view *issueView) runLinks() {
+	app.GetApp().LoadingWithText(true, ui.MessageSearchLinkTypesLoading)
+	linkTypes, err := view.api.WithContext(app.GetApp().LoadingContext()).FindIssueLinkTypes()
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotFetchLinkTypes, ui.JiraErrorReason(err)))
+		go view.handleIssueAction()
+		return
+	}
+	directions := linkDirections(linkTypes)
+	links := view.issue.Fields.IssueLinks
+	view.fuzzyFind = app.NewFuzzyFind(fmt.Sprintf(ui.MessageLinksFuzzyFind, view.issue.Key), linkRows(directions, links))
+	if chosen := <-view.fuzzyFind.Complete; true {
+		view.fuzzyFind = nil
+		app.GetApp().ClearNow()
+		switch {
+		case chosen.Index >= 0 && chosen.Index < len(directions):
+			if view.createLink(directions[chosen.Index]) {
+				view.reopen()
+				return
+			}
+		case chosen.Index >= len(directions) && chosen.Index < len(directions)+len(links):
+			if view.removeLink(links[chosen.Index-len(directions)]) {
+				view.reopen()
+				return
+			}
+		}
+		go view.handleIssueAction()
+	}
+}
+
+// createLink links the issue to one picked with pickIssue; false means it
+// wasn't.
+func (view *issueView) createLink(direction linkDirection) bool {
+	target, ok := view.pickIssue(fmt.Sprintf(ui.MessageSelectIssueToLink, view.issue.Key, direction))
+	if !ok {
+		return false
+	}
+	inwardKey, outwardKey := view.issue.Key, target.Key
+	if direction.inward {
+		inwardKey, outwardKey = target.Key, view.issue.Key
+	}
+	app.GetApp().LoadingWithText(true, ui.MessageLinkingIssues)
+	err := view.api.CreateIssueLink(direction.linkType.Name, inwardKey, outwardKey)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotLinkIssues, view.issue.Key, target.Key, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageLinkSuccess, view.issue.Key, direction, target.Key))
+	return true
+}
+
+// removeLink removes the link once confirmed; false means it wasn't.
+func (view *issueView) removeLink(link jira.IssueLink) bool {
+	removed := app.Confirm(app.GetApp(), fmt.Sprintf(ui.MessageRemoveLinkConfirm, view.issue.Key, link.Line()))
+	// drops the confirmation, the view is kept alive
+	app.GetApp().ClearNow()
+	if !removed {
+		return false
+	}
+	app.GetApp().LoadingWithText(true, ui.MessageRemovingLink)
+	err := view.api.DeleteIssueLink(link.Id)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotRemoveLink, view.issue.Key, link.Line(), ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageRemoveLinkSuccess, view.issue.Key, link.Line()))
+	return true
+}
+
+// pickIssue lets the user choose an issue with the issue search fuzzy finder
+// of the issue's project - keys of other projects can be typed too. The
+// search filters of the issue search apply, as they do there. false means it
+// was cancelled.
+func (view *issueView) pickIssue(title string) (*jira.Issue, bool) {
+	search := &searchIssuesView{api: view.api, project: &view.issue.Fields.Project}
+	defer search.replaceStream(nil)
+	view.fuzzyFind = app.NewFuzzyFindWithRangeContextProvider(title, search.findIssuesWithRanges)
+	// later result pages are pushed into the finder, see streamRemainingPages
+	search.fuzzyFind = view.fuzzyFind
+	chosen := <-view.fuzzyFind.Complete
+	view.fuzzyFind = nil
+	app.GetApp().ClearNow()
+	issue, ok := search.chosenIssue(chosen)
+	if !ok {
+		return nil, false
+	}
+	return &issue, true
+}
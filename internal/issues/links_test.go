@@ -0,0 +1,87 @@
+package issues
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_linkRows(t *testing.T) {
+	// given
+	linkTypes := []jira.IssueLinkType{
+		{Id: "1", Name: "Blocks", Inward: "is blocked by", Outward: "blocks"},
+		{Id: "2", Name: "Relates", Inward: "relates to", Outward: "relates to"},
+	}
+	var link jira.IssueLink
+	link.Type.Name, link.Type.Inward, link.Type.Outward = "Blocks", "is blocked by", "blocks"
+	link.InwardIssue = &jira.IssueRef{Key: "ABC-2"}
+	link.InwardIssue.Fields.Summary = "Fix the login"
+
+	// when
+	directions := linkDirections(linkTypes)
+	rows := linkRows(directions, []jira.IssueLink{link})
+
+	// then
+	assert2.Equal(t, []linkDirection{{linkType: linkTypes[0]}, {linkType: linkTypes[0], inward: true}, {linkType: linkTypes[1]}}, directions)
+	assert2.Equal(t, []string{
+		"blocks... (Blocks)",
+		"is blocked by... (Blocks)",
+		"relates to... (Relates)",
+		"Remove link: is blocked by ABC-2 Fix the login",
+	}, rows)
+}
+
+func Test_issueView_removeLink(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+
+	tests := []struct {
+		name    string
+		confirm rune
+		removed bool
+	}{
+		{"should remove the link once confirmed", app.Yes, true},
+		{"should keep the link when not confirmed", app.No, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.InitTestApp(screen)
+
+			// given
+			var path string
+			api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+				path = r.Method + " " + r.URL.Path
+				w.WriteHeader(204)
+			})
+			view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+			link := jira.IssueLink{Id: "10050", OutwardIssue: &jira.IssueRef{Key: "ABC-2"}}
+			link.Type.Outward = "blocks"
+
+			// when
+			result := make(chan bool)
+			go func() {
+				result <- view.removeLink(link)
+			}()
+			var confirmation *app.Confirmation
+			assert2.Eventually(t, func() bool {
+				confirmation, _ = app.GetApp().LastDrawable().(*app.Confirmation)
+				return confirmation != nil
+			}, time.Second, 5*time.Millisecond)
+			confirmation.HandleKeyEvent(tcell.NewEventKey(0, tt.confirm, 0))
+
+			// then
+			assert2.Equal(t, tt.removed, <-result)
+			if tt.removed {
+				assert2.Equal(t, "DELETE /rest/api/2/issueLink/10050", path)
+			} else {
+				assert2.Empty(t, path)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package issues
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
@@ -34,14 +39,27 @@ type searchIssuesView struct {
 	// multiple round-trips on every F4 press. Boards rarely change during a
 	// session; cache miss = first F4 press, hit = every subsequent press.
 	cachedBoards []jira.BoardItem
+	// issuesMutex guards issues, which the background page stream appends to
+	// while the fuzzy-find provider reads them.
+	issuesMutex sync.Mutex
+	// streamCancel stops the background fetch of further result pages started
+	// by the last search.
+	streamCancel context.CancelFunc
+	streamMutex  sync.Mutex
+	// truncated is set when the last search had more than MaxSearchIssues
+	// results; guarded by issuesMutex.
+	truncated bool
 }
 
 const (
 	JiraFetchRecordsThreshold = 100
-	topBarStatus              = 1
-	topBarExcludeStatus       = 2
-	topBarAssignee            = 3
-	topBarLabel               = 4
+	// MaxSearchIssues caps how many results are streamed into the list.
+	MaxSearchIssues     = 1000
+	topBarStatus        = 1
+	topBarExcludeStatus = 2
+	topBarAssignee      = 3
+	topBarLabel         = 4
+	topBarFieldFilter   = 5 // the first one, the rest follow
 )
 
 var (
@@ -51,6 +69,7 @@ var (
 	searchForUser          *jira.User
 	searchForLabel         string
 	excludedStatuses       []*jira.IssueStatus
+	searchForFields        []FieldFilter // by the workspace's custom fields, see CustomFieldsConfig
 	// sortByUpdated toggles the issue list order between status (default) and
 	// last-updated ascending. Global, like the filters above, so it survives
 	// the view recreation reopen() does. In-session only — not persisted.
@@ -85,6 +104,14 @@ func NewIssuesSearchView(project *jira.Project, goBackFn func(), api jira.Api) a
 		ui.NavItemConfig{Text1: ui.MessageLabelAssignee, Text2: ui.MessageAll},
 		ui.NavItemConfig{Text1: ui.MessageLabelLabel, Text2: ui.MessageAll},
 	}
+	// F10 and a top-bar item per custom field filter, after the label one -
+	// see topBarFieldFilter.
+	if filters := api.CustomFields().Filters; len(filters) > 0 {
+		bottomBar.AddItem(ui.NewSearchByFieldBarItem())
+		for _, field := range filters {
+			topBarItems = append(topBarItems, ui.NavItemConfig{Text1: field.Name + ": ", Text2: ui.MessageAll})
+		}
+	}
 	topBar := ui.CreateTopActionBarWithItems(topBarItems)
 	return &searchIssuesView{
 		api:       api,
@@ -125,7 +152,7 @@ func (view *searchIssuesView) Init() {
 	// viewed for this project (load-or-clear, so another project's filters
 	// never leak in). Runs before the first query so JQL and top bar reflect
 	// the restored state immediately.
-	restoreFilters(view.project)
+	restoreFilters(view.project, view.api.CustomFields().Filters)
 	// Sync the F9 label to the restored sort mode — the bar item's initial
 	// label was set at construction from the pre-restore global.
 	view.updateSortBarItem()
@@ -134,7 +161,7 @@ func (view *searchIssuesView) Init() {
 }
 
 func (view *searchIssuesView) Destroy() {
-	// do nothing
+	view.replaceStream(nil)
 }
 
 func (view *searchIssuesView) Draw(screen tcell.Screen) {
@@ -179,6 +206,7 @@ func (view *searchIssuesView) Update() {
 		view.topBar.Resize(view.screenX, view.screenY)
 	}
 	view.refreshExcludedStatusesUI()
+	view.refreshFieldFiltersUI()
 }
 
 // refreshExcludedStatusesUI keeps the top-bar "Exclude Status: " text in sync
@@ -203,6 +231,28 @@ func (view *searchIssuesView) refreshExcludedStatusesUI() {
 	}
 }
 
+// refreshFieldFiltersUI keeps the top-bar items of the custom field filters
+// in sync with the searchForFields global.
+func (view *searchIssuesView) refreshFieldFiltersUI() {
+	if view.customJql != "" {
+		return
+	}
+	for i, field := range view.api.CustomFields().Filters {
+		item := view.topBar.GetItem(topBarFieldFilter + i)
+		if item == nil {
+			return
+		}
+		text := ui.MessageAll
+		if value := fieldFilterValue(field.Id); value != "" {
+			text = value
+		}
+		if item.Text2 != text {
+			item.ChangeText(field.Name+": ", text)
+			view.topBar.Resize(view.screenX, view.screenY)
+		}
+	}
+}
+
 func (view *searchIssuesView) Resize(screenX, screenY int) {
 	view.bottomBar.Resize(screenX, screenY)
 	view.topBar.Resize(screenX, screenY)
@@ -227,7 +277,7 @@ func (view *searchIssuesView) HandleKeyEvent(ev *tcell.EventKey) {
 
 func (view *searchIssuesView) runIssuesFuzzyFind() {
 	a := app.GetApp()
-	view.fuzzyFind = app.NewFuzzyFindWithRangeProvider(ui.MessageSelectIssue, view.findIssuesWithRanges)
+	view.fuzzyFind = app.NewFuzzyFindWithRangeContextProvider(ui.MessageSelectIssue, view.findIssuesWithRanges)
 	view.fuzzyFind.MarginBottom = 1
 	if view.customJql != "" {
 		view.fuzzyFind.MarginBottom = 0
@@ -245,11 +295,39 @@ func (view *searchIssuesView) runIssuesFuzzyFind() {
 			searchForStatus = nil
 			searchForUser = nil
 			excludedStatuses = nil
+			searchForFields = nil
 			return
 		}
-		chosenIssue := view.issues[chosen.Index]
-		go view.goToIssueView(chosenIssue.Key)
+		if chosenIssue, ok := view.chosenIssue(chosen); ok {
+			go view.goToIssueView(chosenIssue.Key)
+		}
+	}
+}
+
+// chosenIssue resolves the row chosen in the finder to its issue by key: more
+// result pages may have streamed in since the rows were made, so its index
+// can point at another issue.
+func (view *searchIssuesView) chosenIssue(chosen app.FuzzyFindResult) (jira.Issue, bool) {
+	if chosen.Index < 0 {
+		return jira.Issue{}, false
+	}
+	key := rowIssueKey(chosen.Match)
+	view.issuesMutex.Lock()
+	defer view.issuesMutex.Unlock()
+	for _, issue := range view.issues {
+		if issue.Key == key {
+			return issue, true
+		}
+	}
+	return jira.Issue{}, false
+}
+
+// rowIssueKey is the key a row of FormatJiraIssuesWithRanges starts with.
+func rowIssueKey(row string) string {
+	if fields := strings.Fields(row); len(fields) > 0 {
+		return fields[0]
 	}
+	return ""
 }
 
 func (view *searchIssuesView) goToIssueView(issueKey string) {
@@ -258,21 +336,31 @@ func (view *searchIssuesView) goToIssueView(issueKey string) {
 
 // refetchIfNeeded fetches issues from the API when the current cache is stale
 // for the given query, and records the query. Shared by the fuzzy-find
-// providers so their fetch semantics stay identical.
-func (view *searchIssuesView) refetchIfNeeded(query string) {
+// providers so their fetch semantics stay identical. The fetch is aborted when
+// ctx is cancelled (a newer query) or when Esc is pressed while loading.
+func (view *searchIssuesView) refetchIfNeeded(ctx context.Context, query string) {
 	a := app.GetApp()
 	query = strings.TrimSpace(query)
 
 	// when no custom jql set
 	// when manual set dirty=true
-	// when there is more records than max
+	// when there is more records than we stream in
 	// when query has issue format (e.g. PROJ-1234)
 	// when query is numeric-only and a project is selected (we'll expand
 	//   it to PROJ-N server-side so an exact issue-key match can be found)
 	// when there is no results
-	if view.customJql == "" || len(view.issues) >= JiraFetchRecordsThreshold || len(view.issues) == 0 || view.dirty || view.queryHasIssueFormat() || view.queryIsNumericWithProject(query) || query == "" {
+	if view.customJql == "" || view.isTruncated() || view.issuesCount() == 0 || view.dirty || view.queryHasIssueFormat() || view.queryIsNumericWithProject(query) || query == "" {
 		a.LoadingWithText(true, ui.MessageSearchIssuesLoading)
-		view.issues = view.searchForIssues(query)
+		loadingCtx, cancel := a.LoadingContextFrom(ctx)
+		issues, err := view.searchForIssues(loadingCtx, query)
+		cancel()
+		if errors.Is(err, context.Canceled) {
+			// superseded or aborted with Esc - keep the previous results
+			return
+		}
+		view.issuesMutex.Lock()
+		view.issues = issues
+		view.issuesMutex.Unlock()
 		a.Loading(false)
 		view.dirty = false
 	}
@@ -286,19 +374,33 @@ func (view *searchIssuesView) refetchIfNeeded(query string) {
 // fetched issues so filter-aligned ones come first — a soft tiebreak under the
 // fuzzy sort — and flags excluded-status issues dimmed so they render muted and
 // sort last. When browsing (no query) nothing is reordered or dimmed.
-func (view *searchIssuesView) findIssuesWithRanges(query string) ([]string, [][]app.MatchRange, []bool) {
-	view.refetchIfNeeded(query)
+func (view *searchIssuesView) findIssuesWithRanges(ctx context.Context, query string) ([]string, [][]app.MatchRange, []bool) {
+	view.refetchIfNeeded(ctx, query)
+	return view.issueRows(query)
+}
+
+func (view *searchIssuesView) issueRows(query string) ([]string, [][]app.MatchRange, []bool) {
+	view.issuesMutex.Lock()
+	issues := view.issues
+	view.issuesMutex.Unlock()
 	if strings.TrimSpace(query) != "" {
-		view.issues = orderAlignedFirst(view.issues, searchForStatus, searchForUser, searchForLabel)
+		// a reordered copy: view.issues stays in the order it was fetched
+		issues = orderAlignedFirst(issues, searchForStatus, searchForUser, searchForLabel)
 	}
-	rows, ranges := FormatJiraIssuesWithRanges(view.issues)
-	dimmed := make([]bool, len(view.issues))
-	for i := range view.issues {
-		dimmed[i] = issueHasExcludedStatus(&view.issues[i], excludedStatuses)
+	rows, ranges := FormatJiraIssuesWithRanges(issues, view.api.CustomFields().Columns)
+	dimmed := make([]bool, len(issues))
+	for i := range issues {
+		dimmed[i] = issueHasExcludedStatus(&issues[i], excludedStatuses)
 	}
 	return rows, ranges, dimmed
 }
 
+func (view *searchIssuesView) issuesCount() int {
+	view.issuesMutex.Lock()
+	defer view.issuesMutex.Unlock()
+	return len(view.issues)
+}
+
 // queryIsNumericWithProject reports whether the given query is purely numeric
 // AND a non-"All" project is currently selected — the conditions under which
 // searchForIssues expands `1234` to `PROJ-1234`.
@@ -321,15 +423,15 @@ func (view *searchIssuesView) handleSearchActions() {
 		case ui.ActionBoards:
 			view.runSelectBoard()
 		case ui.ActionCreateIssue:
-			projectId := ""
+			projectKey := ""
 			if view.project != nil {
-				projectId = view.project.Id
+				projectKey = view.project.Key
 			}
-			ui.OpenCreateIssueInBrowser(view.api, projectId, 0)
-			go view.runIssuesFuzzyFind()
-			go view.handleSearchActions()
+			app.GoTo(issueCreate, projectKey, view.reopen, view.api)
 		case ui.ActionExcludeStatus:
 			view.runExcludeStatus()
+		case ui.ActionSearchByField:
+			view.runSelectFieldFilter()
 		case ui.ActionClearFilters:
 			view.clearAllFilters()
 		case ui.ActionToggleSort:
@@ -428,8 +530,8 @@ func (view *searchIssuesView) runExcludeStatus() {
 	}
 }
 
-// clearAllFilters (F8) resets every filter — status, assignee, label, and
-// excluded statuses — then refetches. Unlike the Esc-reset, this is an explicit
+// clearAllFilters (F8) resets every filter — status, assignee, label, custom
+// fields, and excluded statuses — then refetches. Unlike the Esc-reset, this is an explicit
 // user action, so the emptied state is persisted per-project via saveFilters.
 // The top-bar labels reset to "All" on the next Update() (see the else-branches
 // there).
@@ -437,6 +539,7 @@ func (view *searchIssuesView) clearAllFilters() {
 	searchForStatus = nil
 	searchForUser = nil
 	searchForLabel = ""
+	searchForFields = nil
 	excludedStatuses = nil
 	view.dirty = true
 	saveFilters(view.project)
@@ -444,6 +547,100 @@ func (view *searchIssuesView) clearAllFilters() {
 	go view.handleSearchActions()
 }
 
+// runSelectFieldFilter (F10) filters by a custom field: the field is asked for
+// when there's more than one, then the value - one of those the listed issues
+// have, or a typed one. "All" removes the filter.
+func (view *searchIssuesView) runSelectFieldFilter() {
+	app.GetApp().ClearNow()
+	fields := view.api.CustomFields().Filters
+	if len(fields) == 0 {
+		go view.runIssuesFuzzyFind()
+		go view.handleSearchActions()
+		return
+	}
+	field := fields[0]
+	if len(fields) > 1 {
+		names := make([]string, 0, len(fields))
+		for _, f := range fields {
+			names = append(names, f.Name)
+		}
+		view.fuzzyFind = app.NewFuzzyFind(ui.MessageSelectFilterField, names)
+		chosen := <-view.fuzzyFind.Complete
+		app.GetApp().ClearNow()
+		if chosen.Index < 0 {
+			go view.runIssuesFuzzyFind()
+			go view.handleSearchActions()
+			return
+		}
+		field = fields[chosen.Index]
+	}
+	view.issuesMutex.Lock()
+	values := append(fieldValues(view.issues, field), ui.MessageAll)
+	view.issuesMutex.Unlock()
+	view.fuzzyFind = app.NewFuzzyFind(fmt.Sprintf(ui.MessageSelectFilterValue, field.Name), values)
+	if chosen := <-view.fuzzyFind.Complete; true {
+		app.GetApp().ClearNow()
+		value := strings.TrimSpace(view.fuzzyFind.GetQuery())
+		if chosen.Index >= 0 {
+			value = values[chosen.Index]
+		}
+		if value != "" {
+			setFieldFilter(field, value)
+			view.dirty = true
+			saveFilters(view.project)
+		}
+		go view.runIssuesFuzzyFind()
+		go view.handleSearchActions()
+	}
+}
+
+// fieldValues returns the distinct values the issues have in the field,
+// sorted - the values of multi-value fields one by one.
+func fieldValues(issues []jira.Issue, field jira.Field) []string {
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	for i := range issues {
+		value := issues[i].Fields.Value(field)
+		all := value.Values
+		if all == nil && !value.IsEmpty() {
+			all = []string{value.Text}
+		}
+		for _, v := range all {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// setFieldFilter sets, or with "All" removes, the filter by the field.
+func setFieldFilter(field jira.Field, value string) {
+	filters := make([]FieldFilter, 0, len(searchForFields)+1)
+	for _, f := range searchForFields {
+		if f.Field.Id != field.Id {
+			filters = append(filters, f)
+		}
+	}
+	if value != ui.MessageAll {
+		filters = append(filters, FieldFilter{Field: field, Value: value})
+	}
+	searchForFields = filters
+}
+
+// fieldFilterValue is the value the issues are filtered by in the field with
+// the given id, empty when they aren't.
+func fieldFilterValue(fieldId string) string {
+	for _, f := range searchForFields {
+		if f.Field.Id == fieldId {
+			return f.Value
+		}
+	}
+	return ""
+}
+
 func (view *searchIssuesView) runSelectUser() {
 	app.GetApp().ClearNow()
 	app.GetApp().Loading(true)
@@ -497,6 +694,10 @@ func (view *searchIssuesView) runSelectBoard() {
 	}
 }
 
+func (view *searchIssuesView) Reload() {
+	view.reopen()
+}
+
 func (view *searchIssuesView) reopen() {
 	if view.customJql != "" {
 		app.GoTo("issues-search-jql", view.customJql, view.goBackFn, view.api)
@@ -505,7 +706,7 @@ func (view *searchIssuesView) reopen() {
 	app.GoTo("issues-search", view.project.Id, view.goBackFn, view.api)
 }
 
-func (view *searchIssuesView) searchForIssues(query string) []jira.Issue {
+func (view *searchIssuesView) searchForIssues(ctx context.Context, query string) ([]jira.Issue, error) {
 	q := strings.TrimSpace(query)
 	var jql string
 	switch {
@@ -524,16 +725,102 @@ func (view *searchIssuesView) searchForIssues(query string) []jira.Issue {
 		// returned — "find anything the JQL would return". Filter semantics move
 		// client-side: filter-aligned issues float up (input-order tiebreak under
 		// the fuzzy sort) and excluded-status issues are shown dimly and last.
-		jql = BuildSearchIssuesJql(view.project, q, nil, nil, "", nil, currentOrderBy())
+		jql = BuildSearchIssuesJql(view.project, q, nil, nil, "", nil, nil, currentOrderBy())
 	default:
 		// No query = browsing: filters are a hard intersection.
-		jql = BuildSearchIssuesJql(view.project, q, searchForStatus, searchForUser, searchForLabel, excludedStatuses, currentOrderBy())
+		jql = BuildSearchIssuesJql(view.project, q, searchForStatus, searchForUser, searchForLabel, excludedStatuses, searchForFields, currentOrderBy())
 	}
-	issues, err := view.api.SearchJql(jql)
-	if err != nil {
+	issues, err := view.searchIssues(ctx, jql)
+	if err != nil && !errors.Is(err, context.Canceled) {
 		app.Error(err.Error())
 	}
-	return issues
+	return issues, err
+}
+
+// searchIssues returns the first page of jql results and keeps fetching the
+// following ones in the background, pushing them into the fuzzy finder as
+// they arrive. Until the first page is in, ctx (newer query, Esc) aborts the
+// fetch; after that only the next search or leaving the view stops it.
+func (view *searchIssuesView) searchIssues(ctx context.Context, jql string) ([]jira.Issue, error) {
+	streamCtx, stop := context.WithCancel(context.Background())
+	view.replaceStream(stop)
+	unbind := context.AfterFunc(ctx, stop)
+	next, closePages := iter.Pull2(view.api.WithContext(streamCtx).SearchJqlPages(jql, JiraFetchRecordsThreshold))
+	issues, err, ok := next()
+	unbind()
+	view.issuesMutex.Lock()
+	view.truncated = false
+	view.issuesMutex.Unlock()
+	if !ok || err != nil {
+		closePages()
+		stop()
+		if issues == nil && err == nil {
+			issues = []jira.Issue{}
+		}
+		return issues, err
+	}
+	go view.streamRemainingPages(streamCtx, issues, next, func() {
+		closePages()
+		stop()
+	})
+	return issues, nil
+}
+
+func (view *searchIssuesView) streamRemainingPages(ctx context.Context, issues []jira.Issue, next func() ([]jira.Issue, error, bool), stop func()) {
+	defer stop()
+	for len(issues) < MaxSearchIssues {
+		page, err, ok := next()
+		if !ok || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				app.Error(err.Error())
+			}
+			return
+		}
+		issues = append(issues, page...)
+		view.issuesMutex.Lock()
+		view.issues = append(view.issues[:0:0], issues...)
+		view.issuesMutex.Unlock()
+		view.pushRows(ctx)
+	}
+	// the cap was reached, but the results are only cut when a page is left
+	if _, err, ok := next(); !ok || err != nil || ctx.Err() != nil {
+		return
+	}
+	view.issuesMutex.Lock()
+	view.truncated = true
+	view.issuesMutex.Unlock()
+}
+
+// pushRows refreshes the rows of the fuzzy finder with the issues streamed in
+// so far. The query is read on the app routine, where the finder updates it.
+func (view *searchIssuesView) pushRows(ctx context.Context) {
+	app.GetApp().RunOnAppRoutine(func() {
+		if view.fuzzyFind == nil || ctx.Err() != nil {
+			return
+		}
+		view.fuzzyFind.SetRangeRecords(view.issueRows(view.fuzzyFind.GetQuery()))
+	})
+}
+
+// isTruncated tells whether the last search had more results than streamed in.
+func (view *searchIssuesView) isTruncated() bool {
+	view.issuesMutex.Lock()
+	defer view.issuesMutex.Unlock()
+	return view.truncated
+}
+
+// replaceStream stops the page stream of the previous search, if any, and
+// remembers the stop func of the new one (nil when just stopping).
+func (view *searchIssuesView) replaceStream(stop context.CancelFunc) {
+	view.streamMutex.Lock()
+	defer view.streamMutex.Unlock()
+	if view.streamCancel != nil {
+		view.streamCancel()
+	}
+	view.streamCancel = stop
 }
 
 func (view *searchIssuesView) fetchStatuses(projectId string) []jira.IssueStatus {
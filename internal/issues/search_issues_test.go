@@ -2,6 +2,7 @@ package issues
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"testing"
 	"time"
@@ -657,3 +658,89 @@ func Test_fjiraSearchIssuesView_reopen(t *testing.T) {
 		})
 	}
 }
+
+func Test_searchIssuesView_should_stream_remaining_pages_into_fuzzy_find(t *testing.T) {
+	// given
+	app.InitTestApp(nil)
+	secondPage := make(chan struct{})
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		if r.URL.Query().Get("nextPageToken") == "" {
+			_, _ = w.Write([]byte(`{"issues":[{"key":"TEST-1","fields":{"summary":"first"}}],"nextPageToken":"p2","isLast":false}`)) //nolint:errcheck
+			return
+		}
+		<-secondPage
+		_, _ = w.Write([]byte(`{"issues":[{"key":"TEST-2","fields":{"summary":"second"}}],"isLast":true}`)) //nolint:errcheck
+	})
+	view := NewIssuesSearchViewWithCustomJql("project=TEST", nil, api).(*searchIssuesView)
+	view.fuzzyFind = app.NewFuzzyFindWithRangeContextProvider("test", view.findIssuesWithRanges)
+	defer view.Destroy()
+
+	// when
+	issues, err := view.searchForIssues(context.Background(), "")
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, issues, 1, "the first page is returned without waiting for the rest")
+
+	// and when
+	close(secondPage)
+
+	// then
+	assert.Eventually(t, func() bool {
+		return view.issuesCount() == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, view.truncated)
+}
+
+func Test_searchIssuesView_streamRemainingPages_should_mark_truncated_only_when_pages_are_left(t *testing.T) {
+	tests := []struct {
+		name              string
+		pages             int
+		expectedTruncated bool
+	}{
+		{"should not mark truncated when the results end at the cap", 2, false},
+		{"should mark truncated when a page is left after the cap", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			app.InitTestApp(nil)
+			view := &searchIssuesView{}
+			page := make([]jira.Issue, MaxSearchIssues/2)
+			served := 1
+			next := func() ([]jira.Issue, error, bool) {
+				if served == tt.pages {
+					return nil, nil, false
+				}
+				served++
+				return page, nil, true
+			}
+
+			// when
+			view.streamRemainingPages(context.Background(), page, next, func() {})
+
+			// then
+			assert.Equal(t, MaxSearchIssues, view.issuesCount())
+			assert.Equal(t, tt.expectedTruncated, view.isTruncated())
+		})
+	}
+}
+
+func Test_searchIssuesView_chosenIssue_should_resolve_the_row_by_key(t *testing.T) {
+	// given
+	view := &searchIssuesView{issues: []jira.Issue{
+		{Key: "TEST-1", Fields: jira.IssueFields{Summary: "first"}},
+		{Key: "TEST-2", Fields: jira.IssueFields{Summary: "second"}},
+	}}
+	rows, _ := FormatJiraIssuesWithRanges([]jira.Issue{view.issues[1], view.issues[0]}, nil)
+
+	// when
+	issue, ok := view.chosenIssue(app.FuzzyFindResult{Index: 0, Match: rows[0]})
+	_, cancelled := view.chosenIssue(app.FuzzyFindResult{Index: -1})
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, "TEST-2", issue.Key)
+	assert.False(t, cancelled)
+}
@@ -1,6 +1,7 @@
 package issues
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -68,7 +69,7 @@ func Test_searchForIssues_dropsFiltersDuringSearch(t *testing.T) {
 	})
 	view := NewIssuesSearchView(&jira.Project{Id: "TEST", Key: "TEST", Name: "TEST"}, nil, api).(*searchIssuesView)
 
-	view.searchForIssues("login")
+	_, _ = view.searchForIssues(context.Background(), "login")
 
 	assert.Contains(t, capturedJql, "project=TEST")
 	assert.Contains(t, capturedJql, `summary~"login*"`)
@@ -91,7 +92,7 @@ func Test_searchForIssues_keepsFiltersWhenBrowsing(t *testing.T) {
 	})
 	view := NewIssuesSearchView(&jira.Project{Id: "TEST", Key: "TEST", Name: "TEST"}, nil, api).(*searchIssuesView)
 
-	view.searchForIssues("")
+	_, _ = view.searchForIssues(context.Background(), "")
 
 	assert.Contains(t, capturedJql, "status=10", "filters apply as a hard intersection when browsing")
 }
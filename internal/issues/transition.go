@@ -0,0 +1,162 @@
+package issues
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+)
+
+// commentField is the comment of a transition screen; it's added with the
+// transition's update, not set like the other fields.
+const commentField = "comment"
+
+// transitionView is the screen of a transition: it lists its fields - the
+// resolution, fix versions, ... - and a comment, see fieldsForm. F2 moves the
+// issue.
+type transitionView struct {
+	app.View
+	api        jira.Api
+	issue      *jira.Issue
+	transition *jira.IssueTransition
+	bottomBar  *app.ActionBar
+	form       *fieldsForm
+	goBackFn   func()
+	titleStyle tcell.Style
+}
+
+var transitionNavItems = []ui.NavItemConfig{
+	{Action: ui.ActionSelect, Text1: ui.MessageEditField, Text2: "[enter]", Key: tcell.KeyEnter},
+	{Action: ui.ActionYes, Text1: ui.MessageMove, Text2: "[F2]", Key: tcell.KeyF2},
+}
+
+func NewTransitionView(issue *jira.Issue, transition *jira.IssueTransition, goBackFn func(), api jira.Api) app.View {
+	bottomBar := ui.CreateBottomActionBarWithItems(transitionNavItems)
+	bottomBar.AddItem(ui.NewCancelBarItem())
+	view := &transitionView{
+		api:        api,
+		issue:      issue,
+		transition: transition,
+		bottomBar:  bottomBar,
+		goBackFn:   goBackFn,
+		titleStyle: app.DefaultStyle().Foreground(app.Color("default.foreground2")).Underline(true),
+	}
+	view.form = newTransitionForm(api, issue, transition, view.reopen)
+	return view
+}
+
+// newTransitionForm lists the fields of the transition's screen, and the
+// comment last. Jira lists the comment among the fields on some instances
+// only, so it's added for every transition with a screen.
+func newTransitionForm(api jira.Api, issue *jira.Issue, transition *jira.IssueTransition, reopen func()) *fieldsForm {
+	metas := maps.Clone(transition.Fields)
+	comment, hasComment := metas[commentField]
+	delete(metas, commentField)
+	subtask := issue.Fields.Parent.Key != "" && !strings.EqualFold(issue.Fields.Parent.Fields.Type.Name, "Epic")
+	form := newFieldsForm(api, issue.Fields.Project.Key, metas, subtask, reopen)
+	if transition.HasScreen || hasComment {
+		if comment.Name == "" {
+			comment.Name = ui.MessageFieldComment
+		}
+		form.fields = append(form.fields, &issueField{id: commentField, meta: comment, kind: fieldMarkdown})
+	}
+	return form
+}
+
+func (view *transitionView) Init() {
+	go view.handleActions()
+}
+
+func (view *transitionView) Destroy() {
+	// do nothing
+}
+
+func (view *transitionView) Draw(screen tcell.Screen) {
+	if view.form.fuzzyFind == nil {
+		app.DrawText(screen, 2, 2, view.titleStyle, fmt.Sprintf(ui.MessageTransitionTitle, view.issue.Key, view.transition.To.Name))
+		view.bottomBar.Draw(screen)
+	}
+	view.form.Draw(screen, 2, 4)
+}
+
+func (view *transitionView) Update() {
+	view.bottomBar.Update()
+	view.form.Update()
+}
+
+func (view *transitionView) Resize(screenX, screenY int) {
+	view.bottomBar.Resize(screenX, screenY)
+	view.form.Resize(screenX, screenY)
+}
+
+func (view *transitionView) HandleKeyEvent(ev *tcell.EventKey) {
+	if view.form.HandleKeyEvent(ev) {
+		return
+	}
+	view.bottomBar.HandleKeyEvent(ev)
+}
+
+func (view *transitionView) handleActions() {
+	switch <-view.bottomBar.Action {
+	case ui.ActionSelect:
+		if !view.form.edit() {
+			return
+		}
+	case ui.ActionYes:
+		if view.move() {
+			return
+		}
+	case ui.ActionCancel:
+		view.goBack()
+		return
+	}
+	go view.handleActions()
+}
+
+// move does the transition with what's filled in; false means it wasn't done,
+// and the form stays.
+func (view *transitionView) move() bool {
+	if missing := view.form.missing(); len(missing) > 0 {
+		app.Error(fmt.Sprintf(ui.MessageMissingRequiredFields, strings.Join(missing, ", ")))
+		return false
+	}
+	app.GetApp().LoadingWithText(true, ui.MessageChangingStatus)
+	err := view.api.DoTransition(view.issue.Key, view.transition, transitionInput(view.form))
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotChangeStatus, view.issue.Key, view.transition.Name, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageChangeStatusSuccess, view.issue.Key, view.transition.To.Name))
+	view.issue.Fields.Status.Id = view.transition.To.StatusId
+	view.issue.Fields.Status.Name = view.transition.To.Name
+	view.goBack()
+	return true
+}
+
+// transitionInput is the filled in fields of the form; the comment goes to
+// the update.
+func transitionInput(form *fieldsForm) *jira.TransitionInput {
+	input := &jira.TransitionInput{Fields: form.values()}
+	if comment, ok := input.Fields[commentField]; ok {
+		delete(input.Fields, commentField)
+		input.Update = map[string]any{
+			commentField: []any{map[string]any{"add": map[string]any{"body": comment}}},
+		}
+	}
+	return input
+}
+
+func (view *transitionView) reopen() {
+	app.GetApp().SetView(view)
+}
+
+func (view *transitionView) goBack() {
+	if view.goBackFn != nil {
+		view.goBackFn()
+	}
+}
@@ -0,0 +1,85 @@
+package issues
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func testDoneTransition() *jira.IssueTransition {
+	transition := &jira.IssueTransition{Id: "41", Name: "Done", HasScreen: true, Fields: map[string]jira.FieldMeta{
+		"resolution": {Required: true, Name: "Resolution", Schema: jira.FieldSchema{Type: "resolution", System: "resolution"},
+			Operations: []string{"set"}, AllowedValues: []jira.FieldOption{{Id: "1", Name: "Done"}}},
+	}}
+	transition.To.StatusId = "5"
+	transition.To.Name = "Done"
+	return transition
+}
+
+func Test_newTransitionForm(t *testing.T) {
+	app.InitTestApp(nil)
+
+	t.Run("should add the comment last", func(t *testing.T) {
+		form := newTransitionForm(jira.NewJiraApiMock(nil), &jira.Issue{}, testDoneTransition(), nil)
+
+		assert2.Len(t, form.fields, 2)
+		assert2.Equal(t, "resolution", form.fields[0].id)
+		assert2.Equal(t, commentField, form.fields[1].id)
+		assert2.Equal(t, fieldMarkdown, form.fields[1].kind)
+		assert2.Equal(t, []string{"Resolution"}, form.missing())
+	})
+	t.Run("should keep the comment Jira lists", func(t *testing.T) {
+		transition := &jira.IssueTransition{Fields: map[string]jira.FieldMeta{
+			commentField: {Required: true, Name: "Reason", Operations: []string{"add"}},
+		}}
+
+		form := newTransitionForm(jira.NewJiraApiMock(nil), &jira.Issue{}, transition, nil)
+
+		assert2.Len(t, form.fields, 1)
+		assert2.Equal(t, "Reason", form.fields[0].meta.Name)
+		assert2.Equal(t, []string{"Reason"}, form.missing())
+	})
+}
+
+func Test_transitionView_move(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+
+	// given
+	var body string
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert2.Equal(t, "/rest/api/2/issue/ABC-1/transitions", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(204)
+	})
+	issue := &jira.Issue{Key: "ABC-1"}
+	wentBack := false
+	view := NewTransitionView(issue, testDoneTransition(), func() { wentBack = true }, api).(*transitionView)
+
+	// when - the resolution is missing
+	moved := view.move()
+
+	// then
+	assert2.False(t, moved)
+	assert2.Empty(t, body)
+
+	// when - filled in
+	view.form.fields[0].value = map[string]string{"id": "1"}
+	view.form.setText(view.form.fields[1], "**done**")
+	moved = view.move()
+
+	// then
+	assert2.True(t, moved)
+	assert2.True(t, wentBack)
+	assert2.JSONEq(t, `{"transition": {"id": "41"}, "fields": {"resolution": {"id": "1"}},
+		"update": {"comment": [{"add": {"body": "*done*"}}]}}`, body)
+	assert2.Equal(t, "Done", issue.Fields.Status.Name)
+}
@@ -0,0 +1,163 @@
+package issues
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/mk-5/fjira/internal/ui"
+	"github.com/mk-5/fjira/internal/workspaces"
+)
+
+// worklogRows renders the Worklog box of the issue view: the running timer
+// first, then the time logged, newest first - "<time spent>  <author>  <when>"
+// with the time spent and authors lined up. Returns nil when there's neither.
+func worklogRows(worklogs []jira.Worklog, timerStarted time.Time, timerRunning bool, now time.Time) []string {
+	rows := make([]string, 0, len(worklogs)+1)
+	if timerRunning {
+		rows = append(rows, fmt.Sprintf("%s  %s (%s)", ui.MessageWorklogTimerRunning,
+			jira.FormatTimeSpent(now.Sub(timerStarted)), app.FormatAbsoluteTime(timerStarted.Format(app.JiraTimestampLayout))))
+	}
+	spentWidth, authorWidth := 0, 0
+	for _, w := range worklogs {
+		spentWidth = max(spentWidth, len(w.TimeSpent))
+		authorWidth = max(authorWidth, len(w.Author.DisplayName))
+	}
+	for _, w := range slices.Backward(worklogs) {
+		rows = append(rows, strings.TrimRight(fmt.Sprintf("%-*s  %-*s  %s", spentWidth, w.TimeSpent, authorWidth, w.Author.DisplayName,
+			app.FormatRelativeTime(w.Started, now)), " "))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows
+}
+
+// worklogTotal is the time logged on the issue altogether, like "3h 30m".
+func worklogTotal(worklogs []jira.Worklog) string {
+	seconds := 0
+	for _, w := range worklogs {
+		seconds += w.TimeSpentSeconds
+	}
+	if seconds == 0 {
+		return "0m"
+	}
+	return jira.FormatTimeSpent(time.Duration(seconds) * time.Second)
+}
+
+// loadWorklogs fetches the time logged on the issue off the UI thread, like
+// loadEpicChildren. Worklogs can't be fetched offline - the timer is shown
+// anyway.
+func (view *issueView) loadWorklogs() {
+	defer app.GetApp().PanicRecover()
+	worklogs, err := view.api.FindWorklogs(view.issue.Key)
+	if err != nil {
+		worklogs = nil
+	}
+	started, running, _ := workspaces.LoadIssueTimer(sessionWorkspace(), view.issue.Key)
+	rows := worklogRows(worklogs, started, running, time.Now())
+	title := fmt.Sprintf(ui.MessageWorklogTitle, worklogTotal(worklogs))
+	app.GetApp().RunOnAppRoutine(func() { view.applyWorklogs(title, rows) })
+}
+
+func (view *issueView) applyWorklogs(title string, rows []string) {
+	view.worklogTitle = title
+	view.worklogRows = rows
+	view.recomputeDetailsLayout()
+	app.GetApp().SetDirty()
+}
+
+// runWorklog opens a fuzzy-find modal to log time on the issue: a typed time
+// spent ("1h 30m") is logged right away, or a timer is started - and, when
+// one is running, stopped and logged, or discarded. The timer is kept in
+// fjira.yaml, so it runs on while fjira is closed.
+func (view *issueView) runWorklog() {
+	started, running, err := workspaces.LoadIssueTimer(sessionWorkspace(), view.issue.Key)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotSaveTimer, view.issue.Key, err))
+	}
+	var options []string
+	if running {
+		options = []string{fmt.Sprintf(ui.MessageWorklogStopTimer, jira.FormatTimeSpent(time.Since(started))), ui.MessageWorklogDiscardTimer}
+	} else {
+		options = []string{ui.MessageWorklogStartTimer}
+	}
+	a := app.GetApp()
+	view.fuzzyFind = app.NewFuzzyFind(ui.MessageWorklogFuzzyFind, options)
+	if chosen := <-view.fuzzyFind.Complete; true {
+		query := strings.TrimSpace(view.fuzzyFind.GetQuery())
+		view.fuzzyFind = nil
+		a.ClearNow()
+		// a typed time spent wins over the row it happens to fuzzy-match:
+		// "45m" matches "Stop timer (1h 45m)" as well
+		timeSpent, err := jira.NormalizeTimeSpent(query)
+		switch {
+		case err == nil:
+			if view.logWork(timeSpent, time.Now()) {
+				view.reopen()
+				return
+			}
+		case chosen.Index < 0 && query != "":
+			if view.logWork(query, time.Now()) {
+				view.reopen()
+				return
+			}
+		case chosen.Index == 0 && !running:
+			view.startTimer(time.Now())
+		case chosen.Index == 0:
+			if view.stopTimer(started, time.Now()) {
+				view.reopen()
+				return
+			}
+		case chosen.Index == 1:
+			view.discardTimer()
+		}
+		go view.handleIssueAction()
+	}
+}
+
+// logWork logs timeSpent as work started at started; false means it wasn't.
+func (view *issueView) logWork(timeSpent string, started time.Time) bool {
+	app.GetApp().LoadingWithText(true, ui.MessageLoggingWork)
+	err := view.api.AddWorklog(view.issue.Key, timeSpent, started)
+	app.GetApp().Loading(false)
+	if err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotLogWork, view.issue.Key, ui.JiraErrorReason(err)))
+		return false
+	}
+	app.Success(fmt.Sprintf(ui.MessageWorklogSuccess, timeSpent, view.issue.Key))
+	return true
+}
+
+func (view *issueView) startTimer(now time.Time) {
+	if err := workspaces.StartIssueTimer(sessionWorkspace(), view.issue.Key, now); err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotSaveTimer, view.issue.Key, err))
+		return
+	}
+	app.Success(fmt.Sprintf(ui.MessageWorklogTimerStarted, view.issue.Key))
+	go view.loadWorklogs()
+}
+
+// stopTimer logs the time since started; the timer is kept if it can't be
+// logged, so no time is lost.
+func (view *issueView) stopTimer(started time.Time, now time.Time) bool {
+	if !view.logWork(jira.FormatTimeSpent(now.Sub(started)), started) {
+		return false
+	}
+	if err := workspaces.StopIssueTimer(sessionWorkspace(), view.issue.Key); err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotSaveTimer, view.issue.Key, err))
+	}
+	return true
+}
+
+func (view *issueView) discardTimer() {
+	if err := workspaces.StopIssueTimer(sessionWorkspace(), view.issue.Key); err != nil {
+		app.Error(fmt.Sprintf(ui.MessageCannotSaveTimer, view.issue.Key, err))
+		return
+	}
+	app.Success(fmt.Sprintf(ui.MessageWorklogTimerDiscarded, view.issue.Key))
+	go view.loadWorklogs()
+}
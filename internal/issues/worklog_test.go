@@ -0,0 +1,122 @@
+package issues
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+	"github.com/mk-5/fjira/internal/jira"
+	os2 "github.com/mk-5/fjira/internal/os"
+	"github.com/mk-5/fjira/internal/workspaces"
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func Test_worklogRows(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	worklogs := []jira.Worklog{
+		{Author: jira.User{DisplayName: "Jane Doe"}, TimeSpent: "1h 30m", TimeSpentSeconds: 5400, Started: "2024-03-04T09:00:00.000+0000"},
+		{Author: jira.User{DisplayName: "Bob"}, TimeSpent: "2h", TimeSpentSeconds: 7200, Started: "2024-03-04T11:00:00.000+0000"},
+	}
+
+	t.Run("should list the newest first, lined up", func(t *testing.T) {
+		rows := worklogRows(worklogs, time.Time{}, false, now)
+
+		assert2.Equal(t, []string{
+			"2h      Bob       1 hour ago",
+			"1h 30m  Jane Doe  3 hours ago",
+		}, rows)
+		assert2.Equal(t, "3h 30m", worklogTotal(worklogs))
+	})
+	t.Run("should show the running timer first", func(t *testing.T) {
+		rows := worklogRows(nil, now.Add(-25*time.Minute), true, now)
+
+		assert2.Len(t, rows, 1)
+		assert2.Contains(t, rows[0], "Timer running  25m (")
+		assert2.Equal(t, "0m", worklogTotal(nil))
+	})
+	t.Run("should be empty without worklogs and timer", func(t *testing.T) {
+		assert2.Nil(t, worklogRows(nil, time.Time{}, false, now))
+	})
+}
+
+func Test_issueView_stopTimer(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+	_ = os2.SetUserHomeDir(t.TempDir())
+	UseWorkspace(&workspaces.WorkspaceSettings{Workspace: "work"})
+	defer UseWorkspace(nil)
+	started := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	// given
+	status := http.StatusInternalServerError
+	var body []byte
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+	_ = workspaces.StartIssueTimer("work", "ABC-1", started)
+
+	// when - Jira fails
+	stopped := view.stopTimer(started, started.Add(90*time.Minute))
+
+	// then - the timer keeps running
+	assert2.False(t, stopped)
+	_, running, _ := workspaces.LoadIssueTimer("work", "ABC-1")
+	assert2.True(t, running)
+
+	// when
+	status = http.StatusCreated
+	stopped = view.stopTimer(started, started.Add(90*time.Minute))
+
+	// then
+	assert2.True(t, stopped)
+	assert2.JSONEq(t, `{"timeSpent": "1h 30m", "started": "2024-03-04T09:00:00.000+0000"}`, string(body))
+	_, running, _ = workspaces.LoadIssueTimer("work", "ABC-1")
+	assert2.False(t, running)
+}
+
+func Test_issueView_runWorklog_should_log_typed_time_while_timer_runs(t *testing.T) {
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init() //nolint:errcheck
+	defer screen.Fini()
+	app.InitTestApp(screen)
+	_ = os2.SetUserHomeDir(t.TempDir())
+	UseWorkspace(&workspaces.WorkspaceSettings{Workspace: "work"})
+	defer UseWorkspace(nil)
+
+	// given
+	var body []byte
+	api := jira.NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ = io.ReadAll(r.Body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	view := NewIssueView(&jira.Issue{Key: "ABC-1"}, nil, api).(*issueView)
+	// the stop row reads "Stop timer (1h 45m) and log it", which "45m" matches
+	_ = workspaces.StartIssueTimer("work", "ABC-1", time.Now().Add(-104*time.Minute-30*time.Second))
+
+	// when
+	done := make(chan struct{})
+	go func() {
+		view.runWorklog()
+		close(done)
+	}()
+	assert2.Eventually(t, func() bool { return view.fuzzyFind != nil }, time.Second, 5*time.Millisecond)
+	for _, r := range "45m" {
+		view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	view.fuzzyFind.HandleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	<-done
+
+	// then
+	assert2.Contains(t, string(body), `"timeSpent":"45m"`)
+	_, running, _ := workspaces.LoadIssueTimer("work", "ABC-1")
+	assert2.True(t, running)
+}
@@ -1,29 +1,62 @@
 package jira
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"io"
+	"iter"
 	"log"
 	"net/http"
 	"net/url"
+	"sync/atomic"
+	"time"
 )
 
 type Api interface {
 	Search(query string) ([]Issue, int32, error)
 	SearchJql(query string) ([]Issue, error)
 	SearchJqlPageable(query string, page int32, pageSize int32) ([]Issue, int32, int32, error)
+	SearchJqlPages(query string, pageSize int32) iter.Seq2[[]Issue, error]
 	FindUsers(project string) ([]User, error)
 	FindUsersWithQuery(project string, query string) ([]User, error)
+	GetMyself() (*User, error)
 	FindProjects() ([]Project, error)
 	FindLabels(issue *Issue, query string) ([]string, error)
 	AddLabel(issueId string, label string) error
 	FindProject(projectKey string) (*Project, error)
 	FindTransitions(issueId string) ([]IssueTransition, error)
 	FindProjectStatuses(projectId string) ([]IssueStatus, error)
-	DoTransition(issueId string, transition *IssueTransition) error
+	DoTransition(issueId string, transition *IssueTransition, input *TransitionInput) error
 	DoAssignee(issueId string, user *User) error
 	GetIssueDetailed(issueId string) (*Issue, error)
-	DoComment(issueId string, commentBody string) error
+	DoComment(issueId string, commentBody string, visibility *CommentVisibility) error
+	UpdateComment(issueId string, commentId string, commentBody string, visibility *CommentVisibility) error
+	DeleteComment(issueId string, commentId string) error
+	FindProjectRoles(projectKey string) ([]string, error)
+	FindGroups(query string) ([]string, error)
 	DoUpdateDescription(issueId string, description string) error
+	// DoCommentAdf, UpdateCommentAdf and DoUpdateDescriptionAdf send Atlassian
+	// Document Format documents, for Cloud - see Capabilities.SupportsAdf.
+	DoCommentAdf(issueId string, body json.RawMessage, visibility *CommentVisibility) error
+	UpdateCommentAdf(issueId string, commentId string, body json.RawMessage, visibility *CommentVisibility) error
+	DoUpdateDescriptionAdf(issueId string, description json.RawMessage) error
+	FindCreateMeta(projectKey string) ([]IssueTypeMeta, error)
+	CreateIssue(fields map[string]any) (*Issue, error)
+	FindEditMeta(issueKey string) (map[string]FieldMeta, error)
+	GetIssueFieldValues(issueKey string, fieldIds []string) (map[string]json.RawMessage, error)
+	UpdateIssueFields(issueKey string, fields map[string]any) error
+	FindWorklogs(issueKey string) ([]Worklog, error)
+	AddWorklog(issueKey string, timeSpent string, started time.Time) error
+	DownloadAttachment(attachment *Attachment, w io.Writer) error
+	AddAttachment(issueKey string, filename string, content io.Reader) ([]Attachment, error)
+	FindIssueLinkTypes() ([]IssueLinkType, error)
+	CreateIssueLink(linkType string, inwardKey string, outwardKey string) error
+	DeleteIssueLink(linkId string) error
+	FindFields() ([]Field, error)
+	// UseFields and CustomFields - see httpApi.UseFields
+	UseFields(fields []Field)
+	CustomFields() *CustomFields
 	FindBoards(projectKeyOrId string) ([]BoardItem, error)
 	GetBoardConfiguration(boardId int) (*BoardConfiguration, error)
 	GetBoardSprints(boardId int) ([]SprintItem, error)
@@ -33,6 +66,12 @@ type Api interface {
 	GetMyFilters() ([]Filter, error)
 	Close()
 	GetApiUrl() string
+	// WithContext returns a copy of the api whose requests are bound to ctx, so
+	// a slow or hung call can be cancelled by the caller. The copy shares the
+	// underlying http client and connection pool with the original.
+	WithContext(ctx context.Context) Api
+	GetServerInfo() (*ServerInfo, error)
+	Capabilities() Capabilities
 
 	IsJiraServer() bool
 }
@@ -47,6 +86,8 @@ type JiraTokenType string
 const (
 	ApiToken      JiraTokenType = "api token"
 	PersonalToken JiraTokenType = "personal token"
+	// OAuthToken workspaces sign in with OAuth 2.0 (3LO), Jira Cloud only.
+	OAuthToken JiraTokenType = "oauth"
 )
 
 type httpApi struct {
@@ -54,40 +95,142 @@ type httpApi struct {
 	tokenType JiraTokenType
 	client    *http.Client
 	restUrl   *url.URL
+	ctx       context.Context
+	// shared with WithContext copies, so a detection made through one of
+	// them is visible to all
+	capabilities *atomic.Pointer[Capabilities]
+	pageTokens   *searchPageTokens
+	// customFields is shared with WithContext copies too, see UseFields
+	customFields       *atomic.Pointer[CustomFields]
+	customFieldsConfig CustomFieldsConfig
+}
+
+// ApiConfig holds everything needed to build an Api for a single workspace.
+type ApiConfig struct {
+	ApiUrl    string
+	Username  string
+	Token     string
+	TokenType JiraTokenType
+	// Retry is applied to idempotent requests only; the zero value disables retrying.
+	Retry RetryPolicy
+	// Deployment and ServerVersion are the cached result of a previous
+	// GetServerInfo; leave empty to fall back to guessing from TokenType.
+	Deployment    DeploymentType
+	ServerVersion string
+	// Transport replaces the http transport, e.g. with a RecordingTransport
+	// or ReplayTransport; nil means a new one built from Connection.
+	Transport  http.RoundTripper
+	Connection ConnectionConfig
+	// OAuth and OAuthTokens are used with the OAuthToken token type instead
+	// of Username and Token. Requests then go to OAuth.SiteApiUrl(), while
+	// ApiUrl stays the site url, for links. OnOAuthRefresh gets the new
+	// tokens after every refresh, to store them.
+	OAuth          OAuthConfig
+	OAuthTokens    OAuthTokens
+	OnOAuthRefresh func(OAuthTokens)
+	// CustomFields are the custom fields to show and filter by; names in it
+	// are resolved with UseFields.
+	CustomFields CustomFieldsConfig
 }
 
 func NewApi(apiUrl string, username string, token string, tokenType JiraTokenType) (Api, error) {
-	baseUrl, err := url.Parse(apiUrl)
+	return NewApiWithConfig(ApiConfig{
+		ApiUrl:    apiUrl,
+		Username:  username,
+		Token:     token,
+		TokenType: tokenType,
+		Retry:     DefaultRetryPolicy(),
+	})
+}
+
+func NewApiWithConfig(cfg ApiConfig) (Api, error) {
+	baseUrl, err := url.Parse(cfg.ApiUrl)
 	if err != nil {
 		log.Fatalln(err)
 	}
 	var authToken string
 	var authType AuthType
-	switch tokenType {
+	switch cfg.TokenType {
 	case PersonalToken:
-		authToken = token
+		authToken = cfg.Token
 		authType = Bearer
 	default:
-		authToken = base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+		authToken = base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Token))
 		authType = Basic
 	}
-	return &httpApi{
-		apiUrl:    apiUrl,
-		tokenType: tokenType,
-		client: &http.Client{
-			Transport: &authInterceptor{core: defaultHttpTransport, token: authToken, authType: authType},
-		},
-		restUrl: baseUrl,
-	}, nil
+	capabilities := capabilitiesFor(cfg)
+	var connection http.RoundTripper
+	if cfg.Transport == nil || cfg.TokenType == OAuthToken {
+		// the oauth token endpoint is never recorded or replayed, see below
+		t, err := NewHttpTransport(cfg.Connection)
+		if err != nil {
+			return nil, err
+		}
+		connection = t
+	}
+	transport := cfg.Transport
+	if transport == nil {
+		transport = connection
+	}
+	var interceptor http.RoundTripper = &authInterceptor{
+		core:     newRetryInterceptor(transport, cfg.Retry),
+		token:    authToken,
+		authType: authType,
+		host:     baseUrl.Host,
+	}
+	if cfg.TokenType == OAuthToken {
+		if baseUrl, err = url.Parse(cfg.OAuth.SiteApiUrl()); err != nil {
+			return nil, err
+		}
+		interceptor = &oauthInterceptor{
+			core: newRetryInterceptor(transport, cfg.Retry),
+			host: baseUrl.Host,
+			source: &oauthTokenSource{
+				cfg:       cfg.OAuth,
+				tokens:    cfg.OAuthTokens,
+				onRefresh: cfg.OnOAuthRefresh,
+				now:       time.Now,
+				// the token endpoint is never recorded or replayed
+				transport: connection,
+			},
+		}
+	}
+	api := &httpApi{
+		apiUrl:             cfg.ApiUrl,
+		tokenType:          cfg.TokenType,
+		client:             &http.Client{Transport: interceptor},
+		restUrl:            baseUrl,
+		capabilities:       &atomic.Pointer[Capabilities]{},
+		pageTokens:         &searchPageTokens{},
+		customFields:       &atomic.Pointer[CustomFields]{},
+		customFieldsConfig: cfg.CustomFields,
+	}
+	api.capabilities.Store(&capabilities)
+	api.customFields.Store(ResolveCustomFields(cfg.CustomFields, nil))
+	return api, nil
 }
 
 func (api *httpApi) GetApiUrl() string {
 	return api.apiUrl
 }
 
+func (api *httpApi) WithContext(ctx context.Context) Api {
+	c := *api
+	c.ctx = ctx
+	return &c
+}
+
+// context returns the context requests should run under; Background when the
+// api was not derived with WithContext.
+func (api *httpApi) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
+
 func (api *httpApi) IsJiraServer() bool {
-	// for now - just a stupid impl like this
-	return api.tokenType == PersonalToken
+	return api.Capabilities().IsServer()
 }
 
 func (api *httpApi) Close() {
@@ -0,0 +1,95 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-attachments/
+//
+
+const (
+	AttachmentsPath       = "/rest/api/2/issue/%s/attachments"
+	AttachmentContentPath = "/rest/api/2/attachment/content/%s"
+)
+
+// Attachment is a file attached to an issue, from fields.attachment. Content
+// is the url of the file itself, see DownloadAttachment.
+type Attachment struct {
+	Id       string `json:"id"`
+	Filename string `json:"filename"`
+	Author   User   `json:"author"`
+	Created  string `json:"created"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Content  string `json:"content"`
+}
+
+// DownloadAttachment writes the file of the attachment to w. The request is
+// authorized like any other, so the file is only fetched from Content when
+// it's on the Jira fjira talks to - through api.atlassian.com (OAuth) it's
+// fetched by id instead.
+func (api *httpApi) DownloadAttachment(attachment *Attachment, w io.Writer) error {
+	u, err := api.attachmentContentUrl(attachment)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(api.context(), "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	response, err := api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	if response.StatusCode >= 400 {
+		body, _ := io.ReadAll(response.Body)
+		return newError(req, response, body)
+	}
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+func (api *httpApi) attachmentContentUrl(attachment *Attachment) (string, error) {
+	content, err := url.Parse(attachment.Content)
+	if err == nil && attachment.Content != "" && content.Scheme == api.restUrl.Scheme && content.Host == api.restUrl.Host {
+		return content.String(), nil
+	}
+	return api.jiraRequestUrl(fmt.Sprintf(AttachmentContentPath, attachment.Id), &nilParams{})
+}
+
+// AddAttachment uploads content as a file named filename to the issue, and
+// returns the attachments Jira made of it. Jira takes uploads only with
+// the X-Atlassian-Token header, which every request has - see authInterceptor.
+func (api *httpApi) AddAttachment(issueKey string, filename string, content io.Reader) ([]Attachment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+	response, err := api.jiraRequestWithContentType("POST", fmt.Sprintf(AttachmentsPath, issueKey), &nilParams{}, &body, form.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	var attachments []Attachment
+	if err := json.Unmarshal(response, &attachments); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	return attachments, nil
+}
@@ -0,0 +1,93 @@
+package jira
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_AddAttachment(t *testing.T) {
+	// given
+	var filename, content, token string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/rest/api/2/issue/ABC-1/attachments", r.URL.Path)
+		token = r.Header.Get(XAtlassianToken)
+		file, header, err := r.FormFile("file")
+		if assert.Nil(t, err) {
+			filename = header.Filename
+			data, _ := io.ReadAll(file)
+			content = string(data)
+		}
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[{"id": "10001", "filename": "error.log", "size": 5, "mimeType": "text/plain",
+			"author": {"displayName": "Jane Doe"}, "created": "2024-03-04T09:30:00.000+0000",
+			"content": "https://example.atlassian.net/rest/api/2/attachment/content/10001"}]`)) //nolint:errcheck
+	})
+
+	// when
+	attachments, err := api.AddAttachment("ABC-1", "error.log", strings.NewReader("oops!"))
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "no-check", token)
+	assert.Equal(t, "error.log", filename)
+	assert.Equal(t, "oops!", content)
+	assert.Equal(t, []Attachment{{Id: "10001", Filename: "error.log", Size: 5, MimeType: "text/plain", Author: User{DisplayName: "Jane Doe"},
+		Created: "2024-03-04T09:30:00.000+0000", Content: "https://example.atlassian.net/rest/api/2/attachment/content/10001"}}, attachments)
+}
+
+func Test_httpJiraApi_DownloadAttachment(t *testing.T) {
+	var requested string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte("file content")) //nolint:errcheck
+	})
+	tests := []struct {
+		name          string
+		content       string
+		wantRequested string
+		wantErr       bool
+	}{
+		{"should fetch the content url", api.GetApiUrl() + "/secure/attachment/10001/error.log", "/secure/attachment/10001/error.log", false},
+		{"should fetch by id from another host", "https://example.atlassian.net/secure/attachment/10001/error.log", "/rest/api/2/attachment/content/10001", false},
+		{"should fail on errors", api.GetApiUrl() + "/secure/attachment/missing", "/secure/attachment/missing", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var downloaded strings.Builder
+
+			// when
+			err := api.DownloadAttachment(&Attachment{Id: "10001", Content: tt.content}, &downloaded)
+
+			// then
+			assert.Equal(t, tt.wantRequested, requested)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "file content", downloaded.String())
+		})
+	}
+}
+
+func Test_IssueFields_attachments(t *testing.T) {
+	// when
+	var issue Issue
+	err := json.Unmarshal([]byte(`{"key": "ABC-1", "fields": {"attachment": [{"id": "10001", "filename": "error.log", "size": 2048}]}}`), &issue)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []Attachment{{Id: "10001", Filename: "error.log", Size: 2048}}, issue.Fields.Attachments)
+}
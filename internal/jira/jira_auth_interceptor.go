@@ -3,6 +3,7 @@ package jira
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -21,6 +22,9 @@ type authInterceptor struct {
 	core     http.RoundTripper
 	authType AuthType
 	token    string
+	// host is the Jira the credentials are for; requests to any other host -
+	// like the media host attachment downloads redirect to - go without them.
+	host string
 }
 
 func (a *authInterceptor) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -34,7 +38,17 @@ func (a *authInterceptor) RoundTrip(r *http.Request) (*http.Response, error) {
 }
 
 func (a *authInterceptor) modifyRequest(r *http.Request) *http.Request {
+	if !sameHost(r, a.host) {
+		r.Header.Del(Authorization)
+		return r
+	}
 	r.Header.Set(Authorization, fmt.Sprintf("%s %s", a.authType, a.token))
 	r.Header.Set(XAtlassianToken, "no-check")
 	return r
 }
+
+// sameHost tells whether the request goes to host, the host of the Jira the
+// credentials were given for.
+func sameHost(r *http.Request, host string) bool {
+	return strings.EqualFold(r.URL.Host, host)
+}
@@ -0,0 +1,332 @@
+package jira
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEndpoint groups Api read calls sharing a TTL, e.g. FindBoards,
+// GetBoardConfiguration and GetBoardProjects are all CacheBoards.
+type CacheEndpoint string
+
+const (
+	CacheProjects    CacheEndpoint = "projects"
+	CacheStatuses    CacheEndpoint = "statuses"
+	CacheTransitions CacheEndpoint = "transitions"
+	CacheBoards      CacheEndpoint = "boards"
+	CacheSprints     CacheEndpoint = "sprints"
+	CacheUsers       CacheEndpoint = "users"
+	CacheLabels      CacheEndpoint = "labels"
+	CacheFilters     CacheEndpoint = "filters"
+	CacheCreateMeta  CacheEndpoint = "createmeta"
+	CacheFields      CacheEndpoint = "fields"
+	CacheLinkTypes   CacheEndpoint = "linktypes"
+)
+
+type cachePolicy struct {
+	ttl time.Duration
+	// disk marks endpoints that are safe to keep between runs: no personal
+	// data, and never invalidated by a mutating call.
+	disk bool
+}
+
+var defaultCachePolicies = map[CacheEndpoint]cachePolicy{
+	CacheProjects:    {ttl: time.Hour, disk: true},
+	CacheStatuses:    {ttl: time.Hour, disk: true},
+	CacheBoards:      {ttl: time.Hour, disk: true},
+	CacheFilters:     {ttl: 10 * time.Minute},
+	CacheSprints:     {ttl: 5 * time.Minute},
+	CacheTransitions: {ttl: 5 * time.Minute},
+	CacheUsers:       {ttl: 15 * time.Minute},
+	CacheLabels:      {ttl: 5 * time.Minute},
+	CacheCreateMeta:  {ttl: 10 * time.Minute},
+	CacheFields:      {ttl: 24 * time.Hour, disk: true},
+	CacheLinkTypes:   {ttl: 24 * time.Hour, disk: true},
+}
+
+// CacheConfig is the per-workspace cache setup, see workspaces.WorkspaceSettings.
+type CacheConfig struct {
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Disk additionally keeps the stable endpoints (projects, statuses, boards,
+	// custom fields, link types) in Dir, so they survive restarts.
+	Disk bool `json:"disk,omitempty" yaml:"disk,omitempty"`
+	// TTL overrides the default time-to-live per endpoint; 0 disables caching
+	// of that endpoint.
+	TTL map[CacheEndpoint]time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// Dir is where the disk cache lives; set by the caller, not configurable.
+	Dir string `json:"-" yaml:"-"`
+}
+
+type cacheEntry struct {
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// cacheStore is shared between a CachingApi and its WithContext copies.
+type cacheStore struct {
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	policies map[CacheEndpoint]cachePolicy
+	dir      string
+	now      func() time.Time
+}
+
+// CachingApi decorates an Api with an in-memory (and optionally on-disk)
+// cache of read calls. Calls it doesn't override go straight to the
+// decorated Api. Mutating calls drop the cached entries they affect;
+// Invalidate drops everything.
+type CachingApi struct {
+	Api
+	store *cacheStore
+}
+
+func NewCachingApi(api Api, cfg CacheConfig) *CachingApi {
+	policies := make(map[CacheEndpoint]cachePolicy, len(defaultCachePolicies))
+	for endpoint, policy := range defaultCachePolicies {
+		if ttl, ok := cfg.TTL[endpoint]; ok {
+			policy.ttl = ttl
+		}
+		policy.disk = policy.disk && cfg.Disk && cfg.Dir != ""
+		policies[endpoint] = policy
+	}
+	return &CachingApi{
+		Api: api,
+		store: &cacheStore{
+			entries:  make(map[string]cacheEntry),
+			policies: policies,
+			dir:      cfg.Dir,
+			now:      time.Now,
+		},
+	}
+}
+
+func (c *CachingApi) WithContext(ctx context.Context) Api {
+	return &CachingApi{Api: c.Api.WithContext(ctx), store: c.store}
+}
+
+// Invalidate drops every cached entry, in memory and on disk.
+func (c *CachingApi) Invalidate() {
+	c.store.invalidate("")
+	if c.store.dir != "" {
+		files, _ := filepath.Glob(filepath.Join(c.store.dir, "*.json"))
+		for _, f := range files {
+			_ = os.Remove(f)
+		}
+	}
+}
+
+func (c *CachingApi) FindProjects() ([]Project, error) {
+	return cached(c.store, CacheProjects, "", c.Api.FindProjects)
+}
+
+func (c *CachingApi) FindProject(projectKey string) (*Project, error) {
+	return cached(c.store, CacheProjects, projectKey, func() (*Project, error) {
+		return c.Api.FindProject(projectKey)
+	})
+}
+
+func (c *CachingApi) FindProjectStatuses(projectId string) ([]IssueStatus, error) {
+	return cached(c.store, CacheStatuses, projectId, func() ([]IssueStatus, error) {
+		return c.Api.FindProjectStatuses(projectId)
+	})
+}
+
+func (c *CachingApi) FindTransitions(issueId string) ([]IssueTransition, error) {
+	return cached(c.store, CacheTransitions, issueId, func() ([]IssueTransition, error) {
+		return c.Api.FindTransitions(issueId)
+	})
+}
+
+func (c *CachingApi) FindBoards(projectKeyOrId string) ([]BoardItem, error) {
+	return cached(c.store, CacheBoards, "list/"+projectKeyOrId, func() ([]BoardItem, error) {
+		return c.Api.FindBoards(projectKeyOrId)
+	})
+}
+
+func (c *CachingApi) GetBoardConfiguration(boardId int) (*BoardConfiguration, error) {
+	return cached(c.store, CacheBoards, "configuration/"+strconv.Itoa(boardId), func() (*BoardConfiguration, error) {
+		return c.Api.GetBoardConfiguration(boardId)
+	})
+}
+
+func (c *CachingApi) GetBoardProjects(boardId int) ([]Project, error) {
+	return cached(c.store, CacheBoards, "projects/"+strconv.Itoa(boardId), func() ([]Project, error) {
+		return c.Api.GetBoardProjects(boardId)
+	})
+}
+
+func (c *CachingApi) GetBoardSprints(boardId int) ([]SprintItem, error) {
+	return cached(c.store, CacheSprints, strconv.Itoa(boardId), func() ([]SprintItem, error) {
+		return c.Api.GetBoardSprints(boardId)
+	})
+}
+
+func (c *CachingApi) FindUsers(project string) ([]User, error) {
+	return c.FindUsersWithQuery(project, "")
+}
+
+func (c *CachingApi) FindUsersWithQuery(project string, query string) ([]User, error) {
+	return cached(c.store, CacheUsers, project+"/"+query, func() ([]User, error) {
+		return c.Api.FindUsersWithQuery(project, query)
+	})
+}
+
+func (c *CachingApi) GetMyself() (*User, error) {
+	return cached(c.store, CacheUsers, "myself", c.Api.GetMyself)
+}
+
+func (c *CachingApi) FindLabels(issue *Issue, query string) ([]string, error) {
+	key := "/" + query
+	if issue != nil {
+		key = issue.Id + key
+	}
+	return cached(c.store, CacheLabels, key, func() ([]string, error) {
+		return c.Api.FindLabels(issue, query)
+	})
+}
+
+func (c *CachingApi) GetFilter(filterId string) (*Filter, error) {
+	return cached(c.store, CacheFilters, filterId, func() (*Filter, error) {
+		return c.Api.GetFilter(filterId)
+	})
+}
+
+func (c *CachingApi) GetMyFilters() ([]Filter, error) {
+	return cached(c.store, CacheFilters, "my", c.Api.GetMyFilters)
+}
+
+func (c *CachingApi) FindCreateMeta(projectKey string) ([]IssueTypeMeta, error) {
+	return cached(c.store, CacheCreateMeta, projectKey, func() ([]IssueTypeMeta, error) {
+		return c.Api.FindCreateMeta(projectKey)
+	})
+}
+
+func (c *CachingApi) FindFields() ([]Field, error) {
+	return cached(c.store, CacheFields, "", c.Api.FindFields)
+}
+
+func (c *CachingApi) FindIssueLinkTypes() ([]IssueLinkType, error) {
+	return cached(c.store, CacheLinkTypes, "", c.Api.FindIssueLinkTypes)
+}
+
+func (c *CachingApi) DoTransition(issueId string, transition *IssueTransition, input *TransitionInput) error {
+	// transitions are cached by issue id, but this call gets the key - drop them all
+	defer c.store.invalidate(string(CacheTransitions) + "/")
+	return c.Api.DoTransition(issueId, transition, input)
+}
+
+func (c *CachingApi) DoAssignee(issueId string, user *User) error {
+	// workflow conditions may depend on the assignee
+	defer c.store.invalidate(string(CacheTransitions) + "/")
+	return c.Api.DoAssignee(issueId, user)
+}
+
+func (c *CachingApi) AddLabel(issueId string, label string) error {
+	defer c.store.invalidate(string(CacheLabels) + "/")
+	return c.Api.AddLabel(issueId, label)
+}
+
+func (c *CachingApi) CreateIssue(fields map[string]any) (*Issue, error) {
+	// the new issue may bring new labels
+	defer c.store.invalidate(string(CacheLabels) + "/")
+	return c.Api.CreateIssue(fields)
+}
+
+func (c *CachingApi) UpdateIssueFields(issueKey string, fields map[string]any) error {
+	// labels may be among the fields, and workflow conditions may depend on any of them
+	defer c.store.invalidate(string(CacheLabels) + "/")
+	defer c.store.invalidate(string(CacheTransitions) + "/")
+	return c.Api.UpdateIssueFields(issueKey, fields)
+}
+
+func cached[T any](s *cacheStore, endpoint CacheEndpoint, id string, fetch func() (T, error)) (T, error) {
+	policy := s.policies[endpoint]
+	if policy.ttl <= 0 {
+		return fetch()
+	}
+	key := string(endpoint) + "/" + id
+	var value T
+	if raw, ok := s.get(key, policy); ok && json.Unmarshal(raw, &value) == nil {
+		return value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		// errors are never cached
+		return value, err
+	}
+	if raw, err := json.Marshal(value); err == nil {
+		s.put(key, raw, policy)
+	}
+	return value, nil
+}
+
+func (s *cacheStore) get(key string, policy cachePolicy) (json.RawMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok && policy.disk {
+		entry, ok = s.readFile(key)
+		if ok {
+			s.entries[key] = entry
+		}
+	}
+	if !ok || s.now().After(entry.Expires) {
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+func (s *cacheStore) put(key string, raw json.RawMessage, policy cachePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := cacheEntry{Expires: s.now().Add(policy.ttl), Value: raw}
+	s.entries[key] = entry
+	if policy.disk {
+		s.writeFile(key, entry)
+	}
+}
+
+// invalidate drops the in-memory entries whose key starts with prefix.
+func (s *cacheStore) invalidate(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *cacheStore) filePath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (s *cacheStore) readFile(key string) (cacheEntry, bool) {
+	var entry cacheEntry
+	data, err := os.ReadFile(s.filePath(key))
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// writeFile is best-effort - a failing disk cache only costs a request.
+func (s *cacheStore) writeFile(key string, entry cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(s.filePath(key), data, 0600)
+}
@@ -0,0 +1,173 @@
+package jira
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingApiMock(status int, body string) (Api, *atomic.Int32) {
+	var requests atomic.Int32
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	})
+	return api, &requests
+}
+
+func Test_CachingApi_should_serve_repeated_reads_from_cache(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		call func(api Api)
+	}{
+		{"FindProjectStatuses", `[{"statuses":[{"id":"1","name":"Done"}]}]`, func(api Api) { _, _ = api.FindProjectStatuses("P") }},
+		{"FindTransitions", `{"transitions":[{"id":"1","name":"Done"}]}`, func(api Api) { _, _ = api.FindTransitions("1") }},
+		{"GetBoardConfiguration", `{"id":1,"name":"Board"}`, func(api Api) { _, _ = api.GetBoardConfiguration(1) }},
+		{"FindUsersWithQuery", `[{"accountId":"1"}]`, func(api Api) { _, _ = api.FindUsersWithQuery("P", "bob") }},
+		{"GetMyFilters", `[{"id":"1","name":"Mine"}]`, func(api Api) { _, _ = api.GetMyFilters() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			inner, requests := newCountingApiMock(200, tt.body)
+			api := NewCachingApi(inner, CacheConfig{})
+
+			// when
+			tt.call(api)
+			tt.call(api)
+			tt.call(api.WithContext(context.Background()))
+
+			// then
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+}
+
+func Test_CachingApi_should_return_cached_value(t *testing.T) {
+	// given
+	inner, _ := newCountingApiMock(200, `[{"statuses":[{"id":"10","name":"In Progress"}]}]`)
+	api := NewCachingApi(inner, CacheConfig{})
+	first, _ := api.FindProjectStatuses("P")
+
+	// when
+	second, err := api.FindProjectStatuses("P")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "In Progress", second[0].Name)
+}
+
+func Test_CachingApi_should_refetch_after_ttl(t *testing.T) {
+	// given
+	inner, requests := newCountingApiMock(200, `[{"statuses":[{"id":"1"}]}]`)
+	api := NewCachingApi(inner, CacheConfig{TTL: map[CacheEndpoint]time.Duration{CacheStatuses: time.Minute}})
+	now := time.Now()
+	api.store.now = func() time.Time { return now }
+	_, _ = api.FindProjectStatuses("P")
+
+	// when
+	now = now.Add(2 * time.Minute)
+	_, _ = api.FindProjectStatuses("P")
+
+	// then
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_CachingApi_should_not_cache_when_ttl_is_zero(t *testing.T) {
+	// given
+	inner, requests := newCountingApiMock(200, `[{"statuses":[{"id":"1"}]}]`)
+	api := NewCachingApi(inner, CacheConfig{TTL: map[CacheEndpoint]time.Duration{CacheStatuses: 0}})
+
+	// when
+	_, _ = api.FindProjectStatuses("P")
+	_, _ = api.FindProjectStatuses("P")
+
+	// then
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_CachingApi_should_not_cache_errors(t *testing.T) {
+	// given
+	inner, requests := newCountingApiMock(500, ``)
+	api := NewCachingApi(inner, CacheConfig{})
+
+	// when
+	_, err1 := api.GetFilter("1")
+	_, err2 := api.GetFilter("1")
+
+	// then
+	assert.NotNil(t, err1)
+	assert.NotNil(t, err2)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_CachingApi_mutations_should_invalidate_affected_entries(t *testing.T) {
+	tests := []struct {
+		name   string
+		read   func(api Api)
+		mutate func(api Api)
+	}{
+		{"DoTransition drops transitions",
+			func(api Api) { _, _ = api.FindTransitions("10001") },
+			func(api Api) { _ = api.DoTransition("ABC-1", &IssueTransition{Id: "1"}, nil) }},
+		{"DoAssignee drops transitions",
+			func(api Api) { _, _ = api.FindTransitions("10001") },
+			func(api Api) { _ = api.DoAssignee("ABC-1", &User{AccountId: "1"}) }},
+		{"AddLabel drops labels",
+			func(api Api) { _, _ = api.FindLabels(nil, "back") },
+			func(api Api) { _ = api.AddLabel("ABC-1", "backend") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			inner, requests := newCountingApiMock(200, `{}`)
+			api := NewCachingApi(inner, CacheConfig{})
+			tt.read(api)
+
+			// when
+			tt.mutate(api)
+			tt.read(api)
+
+			// then
+			assert.Equal(t, int32(3), requests.Load(), "read, mutation, and read again")
+		})
+	}
+}
+
+func Test_CachingApi_Invalidate_should_drop_all_entries(t *testing.T) {
+	// given
+	inner, requests := newCountingApiMock(200, `[{"statuses":[{"id":"1"}]}]`)
+	api := NewCachingApi(inner, CacheConfig{Disk: true, Dir: t.TempDir()})
+	_, _ = api.FindProjectStatuses("P")
+
+	// when
+	api.Invalidate()
+	_, _ = api.FindProjectStatuses("P")
+
+	// then
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_CachingApi_should_keep_stable_endpoints_on_disk(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	inner, requests := newCountingApiMock(200, `[{"statuses":[{"id":"1","name":"Done"}]}]`)
+	_, _ = NewCachingApi(inner, CacheConfig{Disk: true, Dir: dir}).FindProjectStatuses("P")
+	_, _ = NewCachingApi(inner, CacheConfig{Disk: true, Dir: dir}).FindUsersWithQuery("P", "")
+
+	// when
+	restarted := NewCachingApi(inner, CacheConfig{Disk: true, Dir: dir})
+	statuses, err := restarted.FindProjectStatuses("P")
+	_, _ = restarted.FindUsersWithQuery("P", "")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "Done", statuses[0].Name)
+	assert.Equal(t, int32(3), requests.Load(), "statuses come from disk, users are memory-only")
+}
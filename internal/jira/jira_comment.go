@@ -3,26 +3,89 @@ package jira
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 )
 
 type Comment struct {
+	Id      string `json:"id"`
 	Author  User   `json:"author"`
 	Body    string `json:"body"`
 	Created string `json:"created"`
+	// BodyAdf is the body as a document, from the v3 api - see
+	// IssueFields.DescriptionAdf.
+	BodyAdf json.RawMessage `json:"bodyAdf,omitempty"`
+	// Visibility restricts who sees the comment; nil when everyone does.
+	Visibility *CommentVisibility `json:"visibility,omitempty"`
+}
+
+const (
+	VisibilityRole  = "role"
+	VisibilityGroup = "group"
+)
+
+// CommentVisibility restricts a comment to the users of a project role or of
+// a group: Type is VisibilityRole or VisibilityGroup, Value its name.
+type CommentVisibility struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	type comment Comment
+	fields := struct {
+		*comment
+		Body json.RawMessage `json:"body"`
+	}{comment: (*comment)(c)}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	text, doc, err := richText(fields.Body)
+	c.Body = text
+	if doc != nil {
+		c.BodyAdf = doc
+	}
+	return err
 }
 
 type commentRequestBody struct {
-	Body string `json:"body"`
+	Body       string             `json:"body"`
+	Visibility *CommentVisibility `json:"visibility,omitempty"`
+}
+
+type commentAdfRequestBody struct {
+	Body       json.RawMessage    `json:"body"`
+	Visibility *CommentVisibility `json:"visibility,omitempty"`
+}
+
+type groupsQueryParams struct {
+	Query      string `url:"query"`
+	MaxResults int    `url:"maxResults"`
+}
+
+type groupsResponse struct {
+	Groups []struct {
+		Name string `json:"name"`
+	} `json:"groups"`
 }
 
 const (
-	DoCommentIssueRestPath = "/rest/api/2/issue/%s/comment"
+	DoCommentIssueRestPath   = "/rest/api/2/issue/%s/comment"
+	DoCommentIssueRestPathV3 = "/rest/api/3/issue/%s/comment"
+	CommentRestPath          = "/rest/api/2/issue/%s/comment/%s"
+	CommentRestPathV3        = "/rest/api/3/issue/%s/comment/%s"
+	ProjectRolesRestPath     = "/rest/api/2/project/%s/role"
+	GroupsPickerRestPath     = "/rest/api/2/groups/picker"
+	maxGroups                = 50
 )
 
-func (api *httpApi) DoComment(issueId string, commentBody string) error {
+// DoComment adds a comment to the issue, seen only by the users of the
+// visibility's role or group - everyone when it's nil.
+func (api *httpApi) DoComment(issueId string, commentBody string, visibility *CommentVisibility) error {
 	jsonBody, err := json.Marshal(&commentRequestBody{
-		Body: commentBody,
+		Body:       commentBody,
+		Visibility: visibility,
 	})
 	if err != nil {
 		return err
@@ -33,3 +96,91 @@ func (api *httpApi) DoComment(issueId string, commentBody string) error {
 	}
 	return nil
 }
+
+// DoCommentAdf adds a comment whose body is an Atlassian Document Format
+// document, through the v3 api - Jira Cloud only.
+func (api *httpApi) DoCommentAdf(issueId string, body json.RawMessage, visibility *CommentVisibility) error {
+	jsonBody, err := json.Marshal(&commentAdfRequestBody{
+		Body:       body,
+		Visibility: visibility,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("POST", fmt.Sprintf(DoCommentIssueRestPathV3, issueId), &nilParams{}, strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateComment replaces the body of the comment. The visibility is sent
+// along, so pass the comment's own to keep it.
+func (api *httpApi) UpdateComment(issueId string, commentId string, commentBody string, visibility *CommentVisibility) error {
+	jsonBody, err := json.Marshal(&commentRequestBody{
+		Body:       commentBody,
+		Visibility: visibility,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("PUT", fmt.Sprintf(CommentRestPath, issueId, url.PathEscape(commentId)), &nilParams{}, strings.NewReader(string(jsonBody)))
+	return err
+}
+
+// UpdateCommentAdf is UpdateComment with an Atlassian Document Format
+// document, through the v3 api - Jira Cloud only.
+func (api *httpApi) UpdateCommentAdf(issueId string, commentId string, body json.RawMessage, visibility *CommentVisibility) error {
+	jsonBody, err := json.Marshal(&commentAdfRequestBody{
+		Body:       body,
+		Visibility: visibility,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("PUT", fmt.Sprintf(CommentRestPathV3, issueId, url.PathEscape(commentId)), &nilParams{}, strings.NewReader(string(jsonBody)))
+	return err
+}
+
+func (api *httpApi) DeleteComment(issueId string, commentId string) error {
+	_, err := api.jiraRequest("DELETE", fmt.Sprintf(CommentRestPath, issueId, url.PathEscape(commentId)), &nilParams{}, nil)
+	return err
+}
+
+// FindProjectRoles returns the names of the roles of the project, by name -
+// the values of a VisibilityRole.
+func (api *httpApi) FindProjectRoles(projectKey string) ([]string, error) {
+	body, err := api.jiraRequest("GET", fmt.Sprintf(ProjectRolesRestPath, url.PathEscape(projectKey)), &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	// role names to their urls
+	var roles map[string]string
+	if err := json.Unmarshal(body, &roles); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	names := make([]string, 0, len(roles))
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FindGroups returns the names of the groups matching query - the values of
+// a VisibilityGroup.
+func (api *httpApi) FindGroups(query string) ([]string, error) {
+	body, err := api.jiraRequest("GET", GroupsPickerRestPath, &groupsQueryParams{Query: query, MaxResults: maxGroups}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response groupsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	names := make([]string, 0, len(response.Groups))
+	for _, group := range response.Groups {
+		names = append(names, group.Name)
+	}
+	return names, nil
+}
@@ -1,8 +1,12 @@
 package jira
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_httpJiraApi_DoComment(t *testing.T) {
@@ -26,9 +30,151 @@ func Test_httpJiraApi_DoComment(t *testing.T) {
 				w.WriteHeader(200)
 				w.Write([]byte(``)) //nolint:errcheck
 			})
-			if err := api.DoComment(tt.args.issueId, tt.args.commentBody); (err != nil) != tt.wantErr {
+			if err := api.DoComment(tt.args.issueId, tt.args.commentBody, nil); (err != nil) != tt.wantErr {
 				t.Errorf("DoComment() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_httpJiraApi_DoCommentAdf(t *testing.T) {
+	// given
+	doc := json.RawMessage(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"LGTM"}]}]}`)
+	var method, path, body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+	})
+
+	// when
+	err := api.DoCommentAdf("ABC-123", doc, nil)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "POST /rest/api/3/issue/ABC-123/comment", method+" "+path)
+	assert.JSONEq(t, `{"body":`+string(doc)+`}`, body)
+}
+
+func Test_httpJiraApi_DoComment_withVisibility(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+	})
+
+	// when
+	err := api.DoComment("ABC-123", "Customer called", &CommentVisibility{Type: VisibilityRole, Value: "Service Desk Team"})
+
+	// then
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"body": "Customer called", "visibility": {"type": "role", "value": "Service Desk Team"}}`, body)
+}
+
+func Test_httpJiraApi_UpdateComment(t *testing.T) {
+	// given
+	var method, path, body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(200)
+	})
+
+	// when
+	err := api.UpdateComment("ABC-123", "10100", "Lorem ipsum", &CommentVisibility{Type: VisibilityGroup, Value: "support"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "PUT /rest/api/2/issue/ABC-123/comment/10100", method+" "+path)
+	assert.JSONEq(t, `{"body": "Lorem ipsum", "visibility": {"type": "group", "value": "support"}}`, body)
+}
+
+func Test_httpJiraApi_UpdateCommentAdf(t *testing.T) {
+	// given
+	doc := json.RawMessage(`{"version":1,"type":"doc","content":[]}`)
+	var method, path, body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(200)
+	})
+
+	// when
+	err := api.UpdateCommentAdf("ABC-123", "10100", doc, nil)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "PUT /rest/api/3/issue/ABC-123/comment/10100", method+" "+path)
+	assert.JSONEq(t, `{"body":`+string(doc)+`}`, body)
+}
+
+func Test_httpJiraApi_DeleteComment(t *testing.T) {
+	// given
+	var method, path string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.WriteHeader(204)
+	})
+
+	// when
+	err := api.DeleteComment("ABC-123", "10100")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE /rest/api/2/issue/ABC-123/comment/10100", method+" "+path)
+}
+
+func Test_httpJiraApi_FindProjectRoles(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/project/ABC/role", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{
+			"Developers": "https://example.atlassian.net/rest/api/2/project/10000/role/10001",
+			"Administrators": "https://example.atlassian.net/rest/api/2/project/10000/role/10002"
+		}`)) //nolint:errcheck
+	})
+
+	// when
+	roles, err := api.FindProjectRoles("ABC")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Administrators", "Developers"}, roles)
+}
+
+func Test_httpJiraApi_FindGroups(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/groups/picker", r.URL.Path)
+		assert.Equal(t, "sup", r.URL.Query().Get("query"))
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"header": "Showing 1 of 1 matching groups", "total": 1, "groups": [{"name": "support", "html": "<b>sup</b>port"}]}`)) //nolint:errcheck
+	})
+
+	// when
+	groups, err := api.FindGroups("sup")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"support"}, groups)
+}
+
+func Test_Comment_UnmarshalJSON_idAndVisibility(t *testing.T) {
+	// given
+	data := `{"id": "10100", "body": "Internal note", "created": "2024-01-02T10:00:00.000+0000", "visibility": {"type": "role", "value": "Developers"}}`
+
+	// when
+	var comment Comment
+	err := json.Unmarshal([]byte(data), &comment)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "10100", comment.Id)
+	assert.Equal(t, &CommentVisibility{Type: VisibilityRole, Value: "Developers"}, comment.Visibility)
+}
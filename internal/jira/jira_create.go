@@ -0,0 +1,119 @@
+package jira
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-createmeta-get
+//
+
+// IssueTypeMeta is an issue type that can be created in a project, with the
+// fields its create screen has, by field id - "summary", "priority",
+// "customfield_10014", ...
+type IssueTypeMeta struct {
+	Id      string               `json:"id"`
+	Name    string               `json:"name"`
+	Subtask bool                 `json:"subtask"`
+	Fields  map[string]FieldMeta `json:"fields"`
+}
+
+// FieldMeta describes a field of a create or edit screen. AllowedValues are
+// the options of priorities, components, versions, select lists, ...;
+// Operations what an update can do with the field - "set", "add", "remove".
+type FieldMeta struct {
+	Required        bool          `json:"required"`
+	Name            string        `json:"name"`
+	HasDefaultValue bool          `json:"hasDefaultValue"`
+	Schema          FieldSchema   `json:"schema"`
+	AllowedValues   []FieldOption `json:"allowedValues"`
+	Operations      []string      `json:"operations"`
+}
+
+// FieldSchema is the type of a field: Type is "string", "array", "user",
+// "priority", ...; Items the type of the elements of an array. System is set
+// for Jira's own fields, Custom for custom ones, e.g.
+// "com.pyxis.greenhopper.jira:gh-epic-link".
+type FieldSchema struct {
+	Type   string `json:"type"`
+	Items  string `json:"items"`
+	System string `json:"system"`
+	Custom string `json:"custom"`
+}
+
+// FieldOption is one of the allowed values of a field. Priorities,
+// components and versions have a Name, select list options a Value.
+type FieldOption struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Label is how the option reads: its name, or its value.
+func (o FieldOption) Label() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return o.Value
+}
+
+const (
+	FindCreateMetaPath = "/rest/api/2/issue/createmeta"
+	CreateIssuePath    = "/rest/api/2/issue"
+)
+
+type createMetaQueryParams struct {
+	ProjectKeys string `url:"projectKeys"`
+	Expand      string `url:"expand"`
+}
+
+type createMetaResponse struct {
+	Projects []struct {
+		Key        string          `json:"key"`
+		IssueTypes []IssueTypeMeta `json:"issuetypes"`
+	} `json:"projects"`
+}
+
+type createIssueRequestBody struct {
+	Fields map[string]any `json:"fields"`
+}
+
+// FindCreateMeta returns the issue types that can be created in the project,
+// with the fields of their create screens.
+func (api *httpApi) FindCreateMeta(projectKey string) ([]IssueTypeMeta, error) {
+	params := &createMetaQueryParams{ProjectKeys: projectKey, Expand: "projects.issuetypes.fields"}
+	body, err := api.jiraRequest("GET", FindCreateMetaPath, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response createMetaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	for _, project := range response.Projects {
+		if strings.EqualFold(project.Key, projectKey) {
+			return project.IssueTypes, nil
+		}
+	}
+	return []IssueTypeMeta{}, nil
+}
+
+// CreateIssue creates an issue from its fields, in the shapes the v2 api takes
+// them: "summary": "text", "priority": {"id": "3"}, "labels": ["a"], ...
+// The returned issue has only its id and key.
+func (api *httpApi) CreateIssue(fields map[string]any) (*Issue, error) {
+	jsonBody, err := json.Marshal(&createIssueRequestBody{Fields: fields})
+	if err != nil {
+		return nil, err
+	}
+	body, err := api.jiraRequest("POST", CreateIssuePath, &nilParams{}, strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, err
+	}
+	var issue Issue
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	return &issue, nil
+}
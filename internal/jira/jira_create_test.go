@@ -0,0 +1,81 @@
+package jira
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_FindCreateMeta(t *testing.T) {
+	// given
+	var query string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+{
+    "projects": [
+        {
+            "key": "PROJ",
+            "issuetypes": [
+                {
+                    "id": "10001",
+                    "name": "Task",
+                    "subtask": false,
+                    "fields": {
+                        "summary": {"required": true, "name": "Summary", "hasDefaultValue": false, "schema": {"type": "string", "system": "summary"}},
+                        "priority": {"required": false, "name": "Priority", "hasDefaultValue": true, "schema": {"type": "priority", "system": "priority"},
+                            "allowedValues": [{"id": "1", "name": "Highest"}, {"id": "3", "name": "Medium"}]},
+                        "customfield_10100": {"required": true, "name": "Team", "schema": {"type": "option", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:select"},
+                            "allowedValues": [{"id": "10200", "value": "Platform"}]}
+                    }
+                },
+                {"id": "10003", "name": "Sub-task", "subtask": true, "fields": {}}
+            ]
+        }
+    ]
+}`)) //nolint:errcheck
+	})
+
+	// when
+	types, err := api.FindCreateMeta("PROJ")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "expand=projects.issuetypes.fields&projectKeys=PROJ", query)
+	assert.Len(t, types, 2)
+	assert.Equal(t, "Task", types[0].Name)
+	assert.True(t, types[0].Fields["summary"].Required)
+	assert.Equal(t, "Medium", types[0].Fields["priority"].AllowedValues[1].Label())
+	assert.Equal(t, "Platform", types[0].Fields["customfield_10100"].AllowedValues[0].Label())
+	assert.True(t, types[1].Subtask)
+}
+
+func Test_httpJiraApi_CreateIssue(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/rest/api/2/issue", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+		_, _ = w.Write([]byte(`{"id": "10042", "key": "PROJ-42", "self": "https://example.atlassian.net/rest/api/2/issue/10042"}`)) //nolint:errcheck
+	})
+
+	// when
+	issue, err := api.CreateIssue(map[string]any{
+		"project":   map[string]string{"key": "PROJ"},
+		"issuetype": map[string]string{"id": "10001"},
+		"summary":   "Fix the build",
+		"labels":    []string{"ci"},
+	})
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "PROJ-42", issue.Key)
+	assert.Equal(t, "10042", issue.Id)
+	assert.JSONEq(t, `{"fields": {"project": {"key": "PROJ"}, "issuetype": {"id": "10001"}, "summary": "Fix the build", "labels": ["ci"]}}`, body)
+}
@@ -0,0 +1,81 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-issueidorkey-editmeta-get
+//
+
+const (
+	FindEditMetaPath = "/rest/api/2/issue/%s/editmeta"
+)
+
+type editMetaResponse struct {
+	Fields map[string]FieldMeta `json:"fields"`
+}
+
+type updateFieldsRequestBody struct {
+	Fields map[string]any `json:"fields"`
+}
+
+type issueFieldsQueryParams struct {
+	Fields string `url:"fields"`
+}
+
+type issueFieldsResponse struct {
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// FindEditMeta returns the fields of the issue that the user can edit, by
+// field id.
+func (api *httpApi) FindEditMeta(issueKey string) (map[string]FieldMeta, error) {
+	body, err := api.jiraRequest("GET", fmt.Sprintf(FindEditMetaPath, issueKey), &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response editMetaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	if response.Fields == nil {
+		return map[string]FieldMeta{}, nil
+	}
+	return response.Fields, nil
+}
+
+// GetIssueFieldValues returns the values the issue has in the given fields,
+// as Jira sends them through the v2 api - text fields in wiki markup, even on
+// Cloud - by field id. Unset fields are left out.
+func (api *httpApi) GetIssueFieldValues(issueKey string, fieldIds []string) (map[string]json.RawMessage, error) {
+	body, err := api.jiraRequest("GET", fmt.Sprintf(GetJiraIssuePath, issueKey), &issueFieldsQueryParams{Fields: strings.Join(fieldIds, ",")}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response issueFieldsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	values := make(map[string]json.RawMessage, len(response.Fields))
+	for id, value := range response.Fields {
+		if string(value) != "null" {
+			values[id] = value
+		}
+	}
+	return values, nil
+}
+
+// UpdateIssueFields sets the given fields of the issue, leaving the others
+// as they are. The values are in the shapes CreateIssue takes them; nil
+// clears the field.
+func (api *httpApi) UpdateIssueFields(issueKey string, fields map[string]any) error {
+	jsonBody, err := json.Marshal(&updateFieldsRequestBody{Fields: fields})
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("PUT", fmt.Sprintf(GetJiraIssuePath, issueKey), &nilParams{}, strings.NewReader(string(jsonBody)))
+	return err
+}
@@ -0,0 +1,77 @@
+package jira
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_FindEditMeta(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue/PROJ-1/editmeta", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+{
+    "fields": {
+        "summary": {"required": true, "name": "Summary", "schema": {"type": "string", "system": "summary"}, "operations": ["set"]},
+        "components": {"required": false, "name": "Components", "schema": {"type": "array", "items": "component", "system": "components"},
+            "operations": ["add", "set", "remove"], "allowedValues": [{"id": "10000", "name": "Backend"}]},
+        "duedate": {"required": false, "name": "Due date", "schema": {"type": "date", "system": "duedate"}, "operations": ["set"]}
+    }
+}`)) //nolint:errcheck
+	})
+
+	// when
+	fields, err := api.FindEditMeta("PROJ-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "Backend", fields["components"].AllowedValues[0].Label())
+	assert.Equal(t, []string{"add", "set", "remove"}, fields["components"].Operations)
+	assert.Equal(t, "date", fields["duedate"].Schema.Type)
+}
+
+func Test_httpJiraApi_UpdateIssueFields(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/rest/api/2/issue/PROJ-1", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(204)
+	})
+
+	// when
+	err := api.UpdateIssueFields("PROJ-1", map[string]any{
+		"priority": map[string]string{"id": "2"},
+		"duedate":  nil,
+	})
+
+	// then
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"fields": {"priority": {"id": "2"}, "duedate": null}}`, body)
+}
+
+func Test_httpJiraApi_GetIssueFieldValues(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue/PROJ-1", r.URL.Path)
+		assert.Equal(t, "versions,environment,duedate", r.URL.Query().Get("fields"))
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"key": "PROJ-1", "fields": {"versions": [{"id": "1", "name": "1.0"}], "environment": "Linux", "duedate": null}}`)) //nolint:errcheck
+	})
+
+	// when
+	values, err := api.GetIssueFieldValues("PROJ-1", []string{"versions", "environment", "duedate"})
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, values, 2)
+	assert.JSONEq(t, `[{"id": "1", "name": "1.0"}]`, string(values["versions"]))
+	assert.JSONEq(t, `"Linux"`, string(values["environment"]))
+}
@@ -0,0 +1,92 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Error is returned by every Api call that gets a >= 400 response. Jira puts
+// the useful part ("Field 'resolution' is required", "Issue does not exist or
+// you do not have permission to see it") into the body, so it's parsed here
+// instead of being dropped. Use errors.As, or the IsXxx helpers below.
+type Error struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Path       string
+	// Messages are the general errorMessages from the response body.
+	Messages []string
+	// FieldErrors maps a field id to its validation message (the "errors" object).
+	FieldErrors map[string]string
+}
+
+type errorResponse struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+func newError(req *http.Request, response *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+	}
+	var parsed errorResponse
+	// non-JSON bodies (proxy HTML pages etc.) are simply ignored
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		e.Messages = parsed.ErrorMessages
+		e.FieldErrors = parsed.Errors
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("jira error, status: %s - request: %s %s", e.Status, e.Method, e.Path)
+	if details := e.Details(); details != "" {
+		msg += " - " + details
+	}
+	return msg
+}
+
+// Details joins the server-provided messages into a single line, field
+// errors sorted by field id so the output is stable. Empty when Jira didn't
+// send any.
+func (e *Error) Details() string {
+	parts := make([]string, 0, len(e.Messages)+len(e.FieldErrors))
+	parts = append(parts, e.Messages...)
+	fields := make([]string, 0, len(e.FieldErrors))
+	for field := range e.FieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", field, e.FieldErrors[field]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func hasStatus(err error, status int) bool {
+	var jiraErr *Error
+	return errors.As(err, &jiraErr) && jiraErr.StatusCode == status
+}
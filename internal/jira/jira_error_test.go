@@ -0,0 +1,69 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_httpApi_jiraRequest_should_return_typed_error(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":["Transition is not valid"],"errors":{"resolution":"Field 'resolution' is required","assignee":"User is inactive"}}`))
+	})
+
+	// when
+	err := api.DoTransition("ABC-1", &IssueTransition{Id: "1"}, nil)
+
+	// then
+	var jiraErr *Error
+	assert.True(t, errors.As(err, &jiraErr))
+	assert.Equal(t, http.StatusBadRequest, jiraErr.StatusCode)
+	assert.Equal(t, http.MethodPost, jiraErr.Method)
+	assert.Equal(t, "/rest/api/2/issue/ABC-1/transitions", jiraErr.Path)
+	assert.Equal(t, "Transition is not valid; assignee: User is inactive; resolution: Field 'resolution' is required", jiraErr.Details())
+	assert.Contains(t, err.Error(), "POST /rest/api/2/issue/ABC-1/transitions")
+}
+
+func Test_httpApi_jiraRequest_should_ignore_non_json_error_body(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`<html>Bad gateway</html>`))
+	})
+
+	// when
+	_, err := api.GetIssueDetailed("ABC-1")
+
+	// then
+	var jiraErr *Error
+	assert.True(t, errors.As(err, &jiraErr))
+	assert.Equal(t, http.StatusBadGateway, jiraErr.StatusCode)
+	assert.Empty(t, jiraErr.Details())
+}
+
+func Test_jiraError_status_checks(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		check  func(error) bool
+		wanted bool
+	}{
+		{"should detect unauthorized", &Error{StatusCode: 401}, IsUnauthorized, true},
+		{"should detect wrapped unauthorized", fmt.Errorf("wrapped: %w", &Error{StatusCode: 401}), IsUnauthorized, true},
+		{"should detect forbidden", &Error{StatusCode: 403}, IsForbidden, true},
+		{"should detect not found", &Error{StatusCode: 404}, IsNotFound, true},
+		{"should detect rate limited", &Error{StatusCode: 429}, IsRateLimited, true},
+		{"should not match other status", &Error{StatusCode: 400}, IsNotFound, false},
+		{"should not match plain error", errors.New("404"), IsNotFound, false},
+		{"should not match nil", nil, IsUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wanted, tt.check(tt.err))
+		})
+	}
+}
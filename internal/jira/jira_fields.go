@@ -0,0 +1,234 @@
+package jira
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-fields/#api-rest-api-2-field-get
+//
+
+const (
+	FindFieldsPath    = "/rest/api/2/field"
+	customFieldPrefix = "customfield_"
+)
+
+// Field is a field of the Jira instance - one of its own, or a custom one
+// like {Id: "customfield_10016", Name: "Story Points", Custom: true}.
+type Field struct {
+	Id          string      `json:"id"`
+	Name        string      `json:"name"`
+	Custom      bool        `json:"custom"`
+	ClauseNames []string    `json:"clauseNames"`
+	Schema      FieldSchema `json:"schema"`
+}
+
+// JqlClause is how jql refers to the field: cf[10016] for custom fields, so
+// that names with spaces or clashing with other fields don't matter.
+func (f Field) JqlClause() string {
+	if number, ok := strings.CutPrefix(f.Id, customFieldPrefix); ok {
+		return "cf[" + number + "]"
+	}
+	return f.Id
+}
+
+// FindFields returns all fields of the Jira instance, see FieldRegistry.
+func (api *httpApi) FindFields() ([]Field, error) {
+	body, err := api.jiraRequest("GET", FindFieldsPath, &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var fields []Field
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	return fields, nil
+}
+
+// FieldRegistry finds fields by id, or by name - names are matched ignoring
+// case, since that's how they're typed into fjira.yaml.
+type FieldRegistry struct {
+	byId   map[string]Field
+	byName map[string]Field
+}
+
+func NewFieldRegistry(fields []Field) *FieldRegistry {
+	r := &FieldRegistry{byId: make(map[string]Field, len(fields)), byName: make(map[string]Field, len(fields))}
+	for _, f := range fields {
+		r.byId[f.Id] = f
+		if _, taken := r.byName[strings.ToLower(f.Name)]; !taken {
+			r.byName[strings.ToLower(f.Name)] = f
+		}
+	}
+	return r
+}
+
+// Find returns the field with the given id or name. Custom field ids are found
+// even without the registry (nil), named after themselves.
+func (r *FieldRegistry) Find(idOrName string) (Field, bool) {
+	idOrName = strings.TrimSpace(idOrName)
+	if r != nil {
+		if f, ok := r.byId[idOrName]; ok {
+			return f, true
+		}
+		if f, ok := r.byName[strings.ToLower(idOrName)]; ok {
+			return f, true
+		}
+	}
+	if strings.HasPrefix(idOrName, customFieldPrefix) {
+		return Field{Id: idOrName, Name: idOrName, Custom: true}, true
+	}
+	return Field{}, false
+}
+
+// CustomFieldsConfig lists, by name or id, the fields a workspace shows in
+// the Details box of the issue view, as columns of issue lists, and as
+// filters of the issue search.
+type CustomFieldsConfig struct {
+	Details []string `json:"details,omitempty" yaml:"details,omitempty"`
+	Columns []string `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Filters []string `json:"filters,omitempty" yaml:"filters,omitempty"`
+}
+
+func (c CustomFieldsConfig) IsEmpty() bool {
+	return len(c.Details) == 0 && len(c.Columns) == 0 && len(c.Filters) == 0
+}
+
+// CustomFields is CustomFieldsConfig resolved against a FieldRegistry; the
+// fields it can't find are left out.
+type CustomFields struct {
+	Details []Field
+	Columns []Field
+	Filters []Field
+}
+
+func ResolveCustomFields(cfg CustomFieldsConfig, registry *FieldRegistry) *CustomFields {
+	resolve := func(names []string) []Field {
+		fields := make([]Field, 0, len(names))
+		for _, name := range names {
+			if f, ok := registry.Find(name); ok {
+				fields = append(fields, f)
+			}
+		}
+		return fields
+	}
+	return &CustomFields{
+		Details: resolve(cfg.Details),
+		Columns: resolve(cfg.Columns),
+		Filters: resolve(cfg.Filters),
+	}
+}
+
+// searchFieldIds are the ids search results need besides searchFields: the
+// columns, and the filters - whose values are offered to filter by.
+func (c *CustomFields) searchFieldIds() []string {
+	ids := make([]string, 0, len(c.Columns)+len(c.Filters))
+	for _, f := range append(append([]Field{}, c.Columns...), c.Filters...) {
+		ids = append(ids, f.Id)
+	}
+	return ids
+}
+
+// CustomFields returns the workspace's custom fields; names in its config are
+// known after UseFields.
+func (api *httpApi) CustomFields() *CustomFields {
+	return api.customFields.Load()
+}
+
+// UseFields resolves the names in the workspace's custom fields config with
+// the given fields - typically FindFields, cached.
+func (api *httpApi) UseFields(fields []Field) {
+	api.customFields.Store(ResolveCustomFields(api.customFieldsConfig, NewFieldRegistry(fields)))
+}
+
+// FieldValue is the value of a field of an issue, decoded from whatever shape
+// the field has: Text is how it reads; Number is set for numbers, Values for
+// arrays (options, versions, users, sprints, ...).
+type FieldValue struct {
+	Text     string
+	Number   float64
+	IsNumber bool
+	Values   []string
+}
+
+func (v FieldValue) IsEmpty() bool {
+	return v.Text == ""
+}
+
+// sprintString is how Server sends sprints:
+// com.atlassian.greenhopper.service.sprint.Sprint@1f[id=1,rapidViewId=1,state=ACTIVE,name=Sprint 1,...]
+var sprintString = regexp.MustCompile(`\[.*\bname=([^,\]]*)`)
+
+// DecodeFieldValue decodes a value of any field: strings and numbers as they
+// are; options, users, versions, ... by their value, name or display name;
+// documents by their plain text.
+func DecodeFieldValue(data json.RawMessage) FieldValue {
+	var value any
+	if len(data) == 0 || json.Unmarshal(data, &value) != nil {
+		return FieldValue{}
+	}
+	switch v := value.(type) {
+	case float64:
+		return FieldValue{Text: strconv.FormatFloat(v, 'f', -1, 64), Number: v, IsNumber: true}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if text := fieldValueText(item); text != "" {
+				values = append(values, text)
+			}
+		}
+		return FieldValue{Text: strings.Join(values, ", "), Values: values}
+	case map[string]any:
+		if v["type"] == "doc" {
+			if doc, err := adf.Parse(data); err == nil {
+				return FieldValue{Text: adf.PlainText(doc)}
+			}
+		}
+	}
+	return FieldValue{Text: fieldValueText(value)}
+}
+
+func fieldValueText(value any) string {
+	switch v := value.(type) {
+	case string:
+		if m := sprintString.FindStringSubmatch(v); m != nil {
+			return m[1]
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]any:
+		for _, key := range []string{"value", "name", "displayName", "key"} {
+			if text, ok := v[key].(string); ok && text != "" {
+				if child := fieldValueText(v["child"]); child != "" {
+					// cascading select
+					return text + " - " + child
+				}
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// Value decodes the value the issue has in the field; empty when the field
+// isn't a custom one, or the issue doesn't have it.
+func (f *IssueFields) Value(field Field) FieldValue {
+	return DecodeFieldValue(f.Custom[field.Id])
+}
+
+// Values decodes the values of the given fields, by field id.
+func (f *IssueFields) Values(fields []Field) map[string]FieldValue {
+	values := make(map[string]FieldValue, len(fields))
+	for _, field := range fields {
+		values[field.Id] = f.Value(field)
+	}
+	return values
+}
@@ -0,0 +1,152 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_FindFields(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/field", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+[
+    {"id": "summary", "name": "Summary", "custom": false, "clauseNames": ["summary"], "schema": {"type": "string", "system": "summary"}},
+    {"id": "customfield_10016", "name": "Story Points", "custom": true, "clauseNames": ["cf[10016]", "Story Points"],
+        "schema": {"type": "number", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:float", "customId": 10016}}
+]`)) //nolint:errcheck
+	})
+
+	// when
+	fields, err := api.FindFields()
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "Story Points", fields[1].Name)
+	assert.True(t, fields[1].Custom)
+	assert.Equal(t, "number", fields[1].Schema.Type)
+	assert.Equal(t, "cf[10016]", fields[1].JqlClause())
+	assert.Equal(t, "summary", fields[0].JqlClause())
+}
+
+func Test_FieldRegistry_Find(t *testing.T) {
+	registry := NewFieldRegistry([]Field{
+		{Id: "customfield_10016", Name: "Story Points", Custom: true},
+		{Id: "customfield_10001", Name: "Team", Custom: true},
+	})
+	tests := []struct {
+		name      string
+		registry  *FieldRegistry
+		idOrName  string
+		wantId    string
+		wantName  string
+		wantFound bool
+	}{
+		{"by id", registry, "customfield_10001", "customfield_10001", "Team", true},
+		{"by name, ignoring case", registry, "story points", "customfield_10016", "Story Points", true},
+		{"unknown custom field id", registry, "customfield_99999", "customfield_99999", "customfield_99999", true},
+		{"unknown name", registry, "Sprint", "", "", false},
+		{"custom field id without registry", nil, "customfield_10016", "customfield_10016", "customfield_10016", true},
+		{"name without registry", nil, "Story Points", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, found := tt.registry.Find(tt.idOrName)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantId, field.Id)
+			assert.Equal(t, tt.wantName, field.Name)
+		})
+	}
+}
+
+func Test_ResolveCustomFields(t *testing.T) {
+	// given
+	registry := NewFieldRegistry([]Field{{Id: "customfield_10016", Name: "Story Points", Custom: true}})
+	cfg := CustomFieldsConfig{Details: []string{"Story Points", "Unknown"}, Columns: []string{"customfield_10020"}}
+
+	// when
+	fields := ResolveCustomFields(cfg, registry)
+
+	// then
+	assert.Equal(t, []Field{{Id: "customfield_10016", Name: "Story Points", Custom: true}}, fields.Details)
+	assert.Equal(t, []Field{{Id: "customfield_10020", Name: "customfield_10020", Custom: true}}, fields.Columns)
+	assert.Empty(t, fields.Filters)
+}
+
+func Test_DecodeFieldValue(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want FieldValue
+	}{
+		{"empty", ``, FieldValue{}},
+		{"null", `null`, FieldValue{}},
+		{"string", `"Platform"`, FieldValue{Text: "Platform"}},
+		{"number", `5.5`, FieldValue{Text: "5.5", Number: 5.5, IsNumber: true}},
+		{"option", `{"self": "...", "value": "Platform", "id": "10100"}`, FieldValue{Text: "Platform"}},
+		{"user", `{"accountId": "acc-1", "displayName": "Jane Doe"}`, FieldValue{Text: "Jane Doe"}},
+		{"cascading select", `{"value": "Europe", "child": {"value": "Poland"}}`, FieldValue{Text: "Europe - Poland"}},
+		{"multi select", `[{"value": "A"}, {"value": "B"}]`, FieldValue{Text: "A, B", Values: []string{"A", "B"}}},
+		{"cloud sprints", `[{"id": 1, "name": "Sprint 1", "state": "closed"}, {"id": 2, "name": "Sprint 2", "state": "active"}]`,
+			FieldValue{Text: "Sprint 1, Sprint 2", Values: []string{"Sprint 1", "Sprint 2"}}},
+		{"server sprints", `["com.atlassian.greenhopper.service.sprint.Sprint@1f[id=1,rapidViewId=1,state=ACTIVE,name=Sprint 1,startDate=<null>]"]`,
+			FieldValue{Text: "Sprint 1", Values: []string{"Sprint 1"}}},
+		{"document", `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Hello"}]}]}`,
+			FieldValue{Text: "Hello"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DecodeFieldValue(json.RawMessage(tt.data)))
+		})
+	}
+}
+
+func Test_IssueFields_customFields(t *testing.T) {
+	// given
+	data := `{"summary": "Fix login", "customfield_10016": 3, "customfield_10001": null, "customfield_10020": [{"name": "Sprint 7"}]}`
+
+	// when
+	var fields IssueFields
+	err := json.Unmarshal([]byte(data), &fields)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "Fix login", fields.Summary)
+	assert.Len(t, fields.Custom, 2, "null custom fields are left out")
+	values := fields.Values([]Field{{Id: "customfield_10016"}, {Id: "customfield_10001"}, {Id: "customfield_10020"}})
+	assert.Equal(t, "3", values["customfield_10016"].Text)
+	assert.True(t, values["customfield_10001"].IsEmpty())
+	assert.Equal(t, "Sprint 7", values["customfield_10020"].Text)
+}
+
+func Test_httpJiraApi_SearchJql_fetchesCustomFields(t *testing.T) {
+	// given
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Query().Get("fields")
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"issues": [], "isLast": true}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+	api, _ := NewApiWithConfig(ApiConfig{
+		ApiUrl:       server.URL,
+		Username:     "test",
+		Token:        "test",
+		CustomFields: CustomFieldsConfig{Details: []string{"Sprint"}, Columns: []string{"Story Points"}, Filters: []string{"customfield_10001"}},
+	})
+	api.UseFields([]Field{{Id: "customfield_10016", Name: "Story Points", Custom: true}})
+
+	// when
+	_, _ = api.SearchJql("project=PROJ")
+
+	// then - the details aren't, the issue view gets all fields anyway
+	assert.True(t, strings.HasSuffix(requested, ",customfield_10016,customfield_10001"), requested)
+	assert.NotContains(t, requested, "Sprint")
+}
@@ -1,9 +1,12 @@
 package jira
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/mk-5/fjira/internal/adf"
 )
 
 type IssueType struct {
@@ -24,8 +27,10 @@ type IssueRef struct {
 
 // IssueLink is one entry in fields.issuelinks. Each entry carries exactly one
 // of InwardIssue / OutwardIssue (never both); Type names the relationship.
-// Linked returns whichever side is populated.
+// Linked returns whichever side is populated. Id is what DeleteIssueLink
+// takes.
 type IssueLink struct {
+	Id   string `json:"id"`
 	Type struct {
 		Name    string `json:"name"`
 		Inward  string `json:"inward"`
@@ -75,9 +80,14 @@ type IssueFields struct {
 	} `json:"comment"`
 	Labels   []string `json:"labels"`
 	Priority struct {
+		Id   string `json:"id"`
 		Name string `json:"name"`
 	} `json:"priority"`
 	Created string `json:"created"`
+	// DueDate is a date like "2024-05-31", empty when unset.
+	DueDate     string        `json:"duedate"`
+	Components  []FieldOption `json:"components"`
+	FixVersions []FieldOption `json:"fixVersions"`
 	// Parent is the standard Jira parent link. For a story it is the epic; for
 	// a sub-task it is the containing ticket. Modern Jira (v2/v3, Cloud and
 	// recent Server) exposes both through this one field, distinguished by
@@ -87,6 +97,67 @@ type IssueFields struct {
 	// tickets. Both are empty arrays (never null) when there are none.
 	Subtasks   []IssueRef  `json:"subtasks"`
 	IssueLinks []IssueLink `json:"issuelinks"`
+	// Attachments are the files attached to the issue, oldest first.
+	Attachments []Attachment `json:"attachment"`
+	// DescriptionAdf is the description as an Atlassian Document Format
+	// document, when it came from the v3 api (Cloud). Description then holds
+	// its plain text.
+	DescriptionAdf json.RawMessage `json:"descriptionAdf,omitempty"`
+	// Custom are the values of the custom fields the issue came with, by
+	// field id - see Value.
+	Custom map[string]json.RawMessage `json:"customFields,omitempty"`
+}
+
+// UnmarshalJSON takes the description both as the v2 string and as the v3
+// document, and keeps the customfield_* values in Custom.
+func (f *IssueFields) UnmarshalJSON(data []byte) error {
+	type issueFields IssueFields
+	fields := struct {
+		*issueFields
+		Description json.RawMessage `json:"description"`
+	}{issueFields: (*issueFields)(f)}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte(customFieldPrefix)) {
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return err
+		}
+		for id, value := range all {
+			if !strings.HasPrefix(id, customFieldPrefix) || string(value) == "null" {
+				continue
+			}
+			if f.Custom == nil {
+				f.Custom = make(map[string]json.RawMessage)
+			}
+			f.Custom[id] = value
+		}
+	}
+	text, doc, err := richText(fields.Description)
+	f.Description = text
+	if doc != nil {
+		f.DescriptionAdf = doc
+	}
+	return err
+}
+
+// richText reads a v2 string or a v3 document; for a document it returns its
+// plain text too.
+func richText(data json.RawMessage) (string, json.RawMessage, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil, nil
+	}
+	if !adf.IsDocument(data) {
+		var text string
+		err := json.Unmarshal(data, &text)
+		return text, nil, err
+	}
+	doc, err := adf.Parse(data)
+	if err != nil {
+		return "", nil, err
+	}
+	return adf.PlainText(doc), data, nil
 }
 
 type descriptionUpdateRequestBody struct {
@@ -95,12 +166,23 @@ type descriptionUpdateRequestBody struct {
 	} `json:"fields"`
 }
 
+type descriptionAdfUpdateRequestBody struct {
+	Fields struct {
+		Description json.RawMessage `json:"description"`
+	} `json:"fields"`
+}
+
 const (
-	GetJiraIssuePath = "/rest/api/2/issue/%s"
+	GetJiraIssuePath   = "/rest/api/2/issue/%s"
+	GetJiraIssuePathV3 = "/rest/api/3/issue/%s"
 )
 
 func (api *httpApi) GetIssueDetailed(id string) (*Issue, error) {
-	body, err := api.jiraRequest("GET", fmt.Sprintf(GetJiraIssuePath, id), &nilParams{}, nil)
+	path := GetJiraIssuePath
+	if api.Capabilities().SupportsAdf() {
+		path = GetJiraIssuePathV3
+	}
+	body, err := api.jiraRequest("GET", fmt.Sprintf(path, id), &nilParams{}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -124,3 +206,19 @@ func (api *httpApi) DoUpdateDescription(issueId string, description string) erro
 	}
 	return nil
 }
+
+// DoUpdateDescriptionAdf sets a description that is an Atlassian Document
+// Format document, through the v3 api - Jira Cloud only.
+func (api *httpApi) DoUpdateDescriptionAdf(issueId string, description json.RawMessage) error {
+	request := &descriptionAdfUpdateRequestBody{}
+	request.Fields.Description = description
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("PUT", fmt.Sprintf(GetJiraIssuePathV3, issueId), &nilParams{}, strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-links/
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-link-types/
+//
+
+const (
+	IssueLinkTypesPath = "/rest/api/2/issueLinkType"
+	IssueLinkPath      = "/rest/api/2/issueLink"
+)
+
+// IssueLinkType is a kind of link between issues: Inward and Outward read
+// how the issues relate, like "is blocked by" and "blocks".
+type IssueLinkType struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type issueLinkTypesResponse struct {
+	IssueLinkTypes []IssueLinkType `json:"issueLinkTypes"`
+}
+
+type issueLinkKey struct {
+	Key string `json:"key"`
+}
+
+type createIssueLinkRequestBody struct {
+	Type struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	InwardIssue  issueLinkKey `json:"inwardIssue"`
+	OutwardIssue issueLinkKey `json:"outwardIssue"`
+}
+
+// FindIssueLinkTypes returns the kinds of links issues can have.
+func (api *httpApi) FindIssueLinkTypes() ([]IssueLinkType, error) {
+	body, err := api.jiraRequest("GET", IssueLinkTypesPath, &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response issueLinkTypesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	return response.IssueLinkTypes, nil
+}
+
+// CreateIssueLink links two issues with the link type named linkType. The
+// inward issue is the one the Outward of the type reads from: for Blocks,
+// inwardKey blocks outwardKey - and outwardKey is blocked by inwardKey.
+func (api *httpApi) CreateIssueLink(linkType string, inwardKey string, outwardKey string) error {
+	request := createIssueLinkRequestBody{
+		InwardIssue:  issueLinkKey{Key: inwardKey},
+		OutwardIssue: issueLinkKey{Key: outwardKey},
+	}
+	request.Type.Name = linkType
+	jsonBody, err := json.Marshal(&request)
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("POST", IssueLinkPath, &nilParams{}, strings.NewReader(string(jsonBody)))
+	return err
+}
+
+// DeleteIssueLink removes the link, from both of the issues; linkId is the
+// Id of an IssueLink.
+func (api *httpApi) DeleteIssueLink(linkId string) error {
+	_, err := api.jiraRequest("DELETE", IssueLinkPath+"/"+url.PathEscape(linkId), &nilParams{}, nil)
+	return err
+}
+
+// Line reads how the issue relates to the linked one, like
+// "blocks ABC-1" - Type.Outward when the link points out of the issue,
+// Type.Inward when it points in.
+func (l IssueLink) Line() string {
+	if l.InwardIssue != nil {
+		return fmt.Sprintf("%s %s", l.Type.Inward, l.InwardIssue.Key)
+	}
+	if l.OutwardIssue != nil {
+		return fmt.Sprintf("%s %s", l.Type.Outward, l.OutwardIssue.Key)
+	}
+	return l.Type.Name
+}
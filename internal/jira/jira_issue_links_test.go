@@ -0,0 +1,88 @@
+package jira
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_FindIssueLinkTypes(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issueLinkType", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+{
+    "issueLinkTypes": [
+        {"id": "10000", "name": "Blocks", "inward": "is blocked by", "outward": "blocks", "self": "https://example.atlassian.net/rest/api/2/issueLinkType/10000"}
+    ]
+}`)) //nolint:errcheck
+	})
+
+	// when
+	linkTypes, err := api.FindIssueLinkTypes()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []IssueLinkType{{Id: "10000", Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}}, linkTypes)
+}
+
+func Test_httpJiraApi_CreateIssueLink(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/rest/api/2/issueLink", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+	})
+
+	// when
+	err := api.CreateIssueLink("Blocks", "ABC-1", "ABC-2")
+
+	// then
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type": {"name": "Blocks"}, "inwardIssue": {"key": "ABC-1"}, "outwardIssue": {"key": "ABC-2"}}`, body)
+}
+
+func Test_httpJiraApi_DeleteIssueLink(t *testing.T) {
+	// given
+	var method, path string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.WriteHeader(204)
+	})
+
+	// when
+	err := api.DeleteIssueLink("10050")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE", method)
+	assert.Equal(t, "/rest/api/2/issueLink/10050", path)
+}
+
+func Test_IssueLink_Line(t *testing.T) {
+	linkType := struct {
+		Name    string `json:"name"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	}{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}
+	tests := []struct {
+		name string
+		link IssueLink
+		want string
+	}{
+		{"should read the outward side", IssueLink{Type: linkType, OutwardIssue: &IssueRef{Key: "ABC-2"}}, "blocks ABC-2"},
+		{"should read the inward side", IssueLink{Type: linkType, InwardIssue: &IssueRef{Key: "ABC-3"}}, "is blocked by ABC-3"},
+		{"should fall back to the type name", IssueLink{Type: linkType}, "Blocks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.link.Line())
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package jira
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_httpJiraApi_GetIssueDetailed(t *testing.T) {
@@ -59,11 +65,12 @@ func Test_httpJiraApi_GetIssueDetailed(t *testing.T) {
 						},
 						MaxResults: 1, Total: 1, StartAt: 0},
 					),
-					// The fixture has "subtasks": [] and "issuelinks": [], which
+					// The fixture has "subtasks": [], "issuelinks": [] and "fixVersions": [], which
 					// unmarshal to non-nil empty slices (DeepEqual distinguishes
 					// those from nil).
-					Subtasks:   []IssueRef{},
-					IssueLinks: []IssueLink{},
+					Subtasks:    []IssueRef{},
+					IssueLinks:  []IssueLink{},
+					FixVersions: []FieldOption{},
 				},
 			},
 			false,
@@ -287,3 +294,76 @@ func Test_httpJiraApi_GetIssueDetailed_subtasks_and_links(t *testing.T) {
 		t.Errorf("outward link resolved wrong: %+v", l)
 	}
 }
+
+// On a detected Cloud the issue comes from the v3 api, where description and
+// comment bodies are ADF documents rather than strings.
+func Test_httpJiraApi_GetIssueDetailed_adf(t *testing.T) {
+	body := `{
+        "id": "1", "key": "FJ-1",
+        "fields": {
+            "summary": "adf",
+            "description": {"type": "doc", "version": 1, "content": [
+                {"type": "paragraph", "content": [{"type": "text", "text": "Hello "}, {"type": "mention", "attrs": {"text": "@Alice"}}]}
+            ]},
+            "comment": {"comments": [
+                {"body": {"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "LGTM"}]}]}}
+            ]}
+        }
+    }`
+	var path string
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer stub.Close()
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: stub.URL, Username: "test", Token: "test", TokenType: ApiToken, Deployment: DeploymentCloud})
+
+	got, err := api.GetIssueDetailed("FJ-1")
+	if err != nil {
+		t.Fatalf("GetIssueDetailed() error = %v", err)
+	}
+
+	if path != "/rest/api/3/issue/FJ-1" {
+		t.Errorf("path = %q, want the v3 api", path)
+	}
+	if got.Fields.Description != "Hello @Alice" || got.Fields.DescriptionAdf == nil {
+		t.Errorf("Description = %q, DescriptionAdf = %s", got.Fields.Description, got.Fields.DescriptionAdf)
+	}
+	c := got.Fields.Comment.Comments[0]
+	if c.Body != "LGTM" || c.BodyAdf == nil {
+		t.Errorf("Body = %q, BodyAdf = %s", c.Body, c.BodyAdf)
+	}
+
+	// A snapshot (json round-trip) keeps both forms.
+	data, _ := json.Marshal(got)
+	var restored Issue
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	var compact bytes.Buffer
+	_ = json.Compact(&compact, got.Fields.DescriptionAdf)
+	if restored.Fields.Description != "Hello @Alice" || string(restored.Fields.DescriptionAdf) != compact.String() {
+		t.Errorf("restored description = %q, %s", restored.Fields.Description, restored.Fields.DescriptionAdf)
+	}
+}
+
+func Test_httpJiraApi_DoUpdateDescriptionAdf(t *testing.T) {
+	// given
+	doc := json.RawMessage(`{"version":1,"type":"doc","content":[{"type":"rule"}]}`)
+	var method, path, body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(204)
+	})
+
+	// when
+	err := api.DoUpdateDescriptionAdf("ABC-123", doc)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "PUT /rest/api/3/issue/ABC-123", method+" "+path)
+	assert.JSONEq(t, `{"fields":{"description":`+string(doc)+`}}`, body)
+}
@@ -18,7 +18,22 @@ func NewJiraApiMockWithTokenType(handler func(w http.ResponseWriter, r *http.Req
 		w.WriteHeader(200)
 		w.Write([]byte("")) //nolint:errcheck
 	}))
-	api, err := NewApi(stubServer.URL, "test", "test", tokenType)
+	// no retries - tests asserting on 429/503 handling want the first response
+	api, err := NewApiWithConfig(ApiConfig{ApiUrl: stubServer.URL, Username: "test", Token: "test", TokenType: tokenType})
+	if err != nil {
+		panic(err)
+	}
+	return api
+}
+
+// NewJiraApiReplay serves a recording made with `fjira --record`, e.g. to
+// build a regression test from real Cloud or Server payloads.
+func NewJiraApiReplay(dir string, tokenType JiraTokenType) Api {
+	transport, err := NewReplayTransport(dir)
+	if err != nil {
+		panic(err)
+	}
+	api, err := NewApiWithConfig(ApiConfig{ApiUrl: "https://jira.example.com", Username: "test", Token: "test", TokenType: tokenType, Transport: transport})
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,430 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AtlassianAuthorizeUrl   = "https://auth.atlassian.com/authorize"
+	AtlassianTokenUrl       = "https://auth.atlassian.com/oauth/token"
+	AtlassianResourcesUrl   = "https://api.atlassian.com/oauth/token/accessible-resources"
+	AtlassianApiUrl         = "https://api.atlassian.com/ex/jira/"
+	DefaultOAuthRedirectUrl = "http://localhost:8765/callback"
+	// oauthRefreshMargin refreshes a bit before the expiry, so a request
+	// doesn't leave with a token that expires on the way
+	oauthRefreshMargin = time.Minute
+)
+
+// DefaultOAuthScopes are what fjira needs; offline_access is what makes
+// Atlassian hand out a refresh token.
+var DefaultOAuthScopes = []string{
+	"read:jira-work",
+	"write:jira-work",
+	"read:jira-user",
+	"read:board-scope:jira-software",
+	"read:sprint:jira-software",
+	"read:project:jira",
+	"offline_access",
+}
+
+var (
+	ErrOAuthDenied       = errors.New("oauth authorization was denied")
+	ErrOAuthStateInvalid = errors.New("oauth callback state doesn't match")
+	// ErrOAuthRefresh means the session can't be renewed anymore - the
+	// refresh token expired or was revoked, and the user has to sign in again.
+	ErrOAuthRefresh      = errors.New("cannot refresh oauth session")
+	ErrOAuthSiteNotFound = errors.New("the oauth app has no access to this Jira site")
+)
+
+// OAuthConfig is an OAuth 2.0 (3LO) app registered in the Atlassian developer
+// console, stored per workspace. The urls default to Atlassian's and are only
+// set to talk to a stand-in authorization server. The app has to list
+// RedirectUrl as its callback url.
+type OAuthConfig struct {
+	ClientId     string   `json:"clientId" yaml:"clientId"`
+	ClientSecret string   `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	RedirectUrl  string   `json:"redirectUrl,omitempty" yaml:"redirectUrl,omitempty"`
+	AuthorizeUrl string   `json:"authorizeUrl,omitempty" yaml:"authorizeUrl,omitempty"`
+	TokenUrl     string   `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	ResourcesUrl string   `json:"resourcesUrl,omitempty" yaml:"resourcesUrl,omitempty"`
+	// ApiUrl is where requests go, followed by CloudId - the site picked
+	// during authorization.
+	ApiUrl  string `json:"apiUrl,omitempty" yaml:"apiUrl,omitempty"`
+	CloudId string `json:"cloudId,omitempty" yaml:"cloudId,omitempty"`
+}
+
+// OAuthTokens are rotated on every refresh: the old refresh token stops
+// working, so the new ones have to be stored each time (see
+// ApiConfig.OnOAuthRefresh).
+type OAuthTokens struct {
+	AccessToken  string    `json:"accessToken" yaml:"accessToken"`
+	RefreshToken string    `json:"refreshToken" yaml:"refreshToken"`
+	Expiry       time.Time `json:"expiry" yaml:"expiry"`
+}
+
+// OAuthResource is a Jira site the user granted the app access to.
+type OAuthResource struct {
+	Id     string   `json:"id"`
+	Url    string   `json:"url"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c OAuthConfig) withDefaults() OAuthConfig {
+	if len(c.Scopes) == 0 {
+		c.Scopes = DefaultOAuthScopes
+	}
+	if c.RedirectUrl == "" {
+		c.RedirectUrl = DefaultOAuthRedirectUrl
+	}
+	if c.AuthorizeUrl == "" {
+		c.AuthorizeUrl = AtlassianAuthorizeUrl
+	}
+	if c.TokenUrl == "" {
+		c.TokenUrl = AtlassianTokenUrl
+	}
+	if c.ResourcesUrl == "" {
+		c.ResourcesUrl = AtlassianResourcesUrl
+	}
+	if c.ApiUrl == "" {
+		c.ApiUrl = AtlassianApiUrl
+	}
+	return c
+}
+
+// SiteApiUrl is the base url of the REST api of the CloudId site.
+func (c OAuthConfig) SiteApiUrl() string {
+	return strings.TrimSuffix(c.withDefaults().ApiUrl, "/") + "/" + c.CloudId
+}
+
+// AuthorizeOAuth runs the authorization code flow with PKCE: it listens on
+// the loopback RedirectUrl, hands the authorization url to open (a browser,
+// usually), and exchanges the code the browser is redirected back with for
+// tokens over transport - the workspace's, see NewHttpTransport. Callbacks
+// with a state other than ours are turned away. It gives up when ctx is done.
+func AuthorizeOAuth(ctx context.Context, transport http.RoundTripper, cfg OAuthConfig, open func(authorizeUrl string)) (*OAuthTokens, error) {
+	cfg = cfg.withDefaults()
+	redirect, err := url.Parse(cfg.RedirectUrl)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for the oauth callback on %s: %w", redirect.Host, err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+	// a :0 port in tests means any free one
+	redirect.Host = listener.Addr().String()
+	verifier, state := randomUrlToken(), randomUrlToken()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	callbacks := make(chan callback, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != redirect.Path {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			if query.Get("state") != state {
+				// not the redirect of our authorization - a stale tab, or
+				// someone else's request; keep waiting for the real one
+				http.Error(w, "fjira: "+ErrOAuthStateInvalid.Error(), http.StatusBadRequest)
+				return
+			}
+			result := callback{code: query.Get("code")}
+			if query.Get("error") != "" {
+				result.err = fmt.Errorf("%w: %s", ErrOAuthDenied, strings.TrimSpace(query.Get("error")+" "+query.Get("error_description")))
+			}
+			if result.err != nil {
+				http.Error(w, "fjira: "+result.err.Error(), http.StatusBadRequest)
+			} else {
+				_, _ = fmt.Fprintln(w, "fjira is signed in to Jira. You can close this tab.")
+			}
+			select {
+			case callbacks <- result:
+			default:
+			}
+		}),
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	open(authorizeUrl(cfg, redirect.String(), state, pkceChallenge(verifier)))
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-callbacks:
+		if result.err != nil {
+			return nil, result.err
+		}
+		return requestOAuthTokens(ctx, transport, cfg, map[string]string{
+			"grant_type":    "authorization_code",
+			"code":          result.code,
+			"redirect_uri":  redirect.String(),
+			"code_verifier": verifier,
+		})
+	}
+}
+
+func authorizeUrl(cfg OAuthConfig, redirectUrl string, state string, challenge string) string {
+	params := url.Values{
+		"audience":              {"api.atlassian.com"},
+		"client_id":             {cfg.ClientId},
+		"scope":                 {strings.Join(cfg.Scopes, " ")},
+		"redirect_uri":          {redirectUrl},
+		"state":                 {state},
+		"response_type":         {"code"},
+		"prompt":                {"consent"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	return cfg.AuthorizeUrl + "?" + params.Encode()
+}
+
+// RefreshOAuthTokens trades the refresh token for a new pair of tokens.
+func RefreshOAuthTokens(ctx context.Context, transport http.RoundTripper, cfg OAuthConfig, refreshToken string) (*OAuthTokens, error) {
+	return refreshOAuthTokens(ctx, transport, cfg, refreshToken)
+}
+
+func refreshOAuthTokens(ctx context.Context, transport http.RoundTripper, cfg OAuthConfig, refreshToken string) (*OAuthTokens, error) {
+	tokens, err := requestOAuthTokens(ctx, transport, cfg.withDefaults(), map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOAuthRefresh, err)
+	}
+	return tokens, nil
+}
+
+func requestOAuthTokens(ctx context.Context, transport http.RoundTripper, cfg OAuthConfig, params map[string]string) (*OAuthTokens, error) {
+	params["client_id"] = cfg.ClientId
+	if cfg.ClientSecret != "" {
+		params["client_secret"] = cfg.ClientSecret
+	}
+	body, _ := json.Marshal(params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// never through a RecordingTransport - a --record session must not
+	// capture the tokens
+	response, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	responseBody, _ := io.ReadAll(response.Body)
+	var tokenResponse oauthTokenResponse
+	_ = json.Unmarshal(responseBody, &tokenResponse)
+	if response.StatusCode >= 400 || tokenResponse.AccessToken == "" {
+		reason := strings.TrimSpace(tokenResponse.Error + " " + tokenResponse.ErrorDescription)
+		if reason == "" {
+			reason = response.Status
+		}
+		return nil, fmt.Errorf("oauth token request failed: %s", reason)
+	}
+	return &OAuthTokens{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// FindOAuthResources lists the Jira sites the access token can be used with.
+func FindOAuthResources(ctx context.Context, transport http.RoundTripper, cfg OAuthConfig, accessToken string) ([]OAuthResource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.withDefaults().ResourcesUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(Authorization, fmt.Sprintf("%s %s", Bearer, accessToken))
+	req.Header.Set("Accept", "application/json")
+	response, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode >= 400 {
+		return nil, newError(req, response, body)
+	}
+	var resources []OAuthResource
+	if err := json.Unmarshal(body, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// SelectOAuthResource picks the site with the given url; with no url, the
+// only site there is.
+func SelectOAuthResource(resources []OAuthResource, siteUrl string) (*OAuthResource, error) {
+	siteUrl = strings.TrimSuffix(strings.TrimSpace(siteUrl), "/")
+	for i, resource := range resources {
+		if strings.EqualFold(strings.TrimSuffix(resource.Url, "/"), siteUrl) {
+			return &resources[i], nil
+		}
+	}
+	if siteUrl == "" && len(resources) == 1 {
+		return &resources[0], nil
+	}
+	sites := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		sites = append(sites, resource.Url)
+	}
+	return nil, fmt.Errorf("%w: %s (available: %s)", ErrOAuthSiteNotFound, siteUrl, strings.Join(sites, ", "))
+}
+
+// oauthTokenSource hands out a valid access token, refreshing it when it's
+// about to expire or Jira rejected it. Shared by every request of an Api.
+type oauthTokenSource struct {
+	mu        sync.Mutex
+	cfg       OAuthConfig
+	tokens    OAuthTokens
+	onRefresh func(OAuthTokens)
+	now       func() time.Time
+	// transport reaches the token endpoint: the workspace's connection
+	transport http.RoundTripper
+}
+
+func (s *oauthTokenSource) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens.RefreshToken != "" && !s.tokens.Expiry.IsZero() && s.now().Add(oauthRefreshMargin).After(s.tokens.Expiry) {
+		if err := s.refreshLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return s.tokens.AccessToken, nil
+}
+
+// refresh renews the tokens unless another request already did since stale
+// was handed out.
+func (s *oauthTokenSource) refresh(ctx context.Context, stale string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens.AccessToken != stale {
+		return s.tokens.AccessToken, nil
+	}
+	if err := s.refreshLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.tokens.AccessToken, nil
+}
+
+func (s *oauthTokenSource) refreshLocked(ctx context.Context) error {
+	if s.tokens.RefreshToken == "" {
+		return ErrOAuthRefresh
+	}
+	tokens, err := refreshOAuthTokens(ctx, s.transport, s.cfg, s.tokens.RefreshToken)
+	if err != nil {
+		return err
+	}
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = s.tokens.RefreshToken
+	}
+	s.tokens = *tokens
+	if s.onRefresh != nil {
+		s.onRefresh(*tokens)
+	}
+	return nil
+}
+
+// oauthInterceptor is the authInterceptor of OAuth workspaces. A request
+// rejected with 401 is retried once with refreshed tokens.
+type oauthInterceptor struct {
+	core   http.RoundTripper
+	source *oauthTokenSource
+	// host is api.atlassian.com, or whatever OAuth.SiteApiUrl points at - see
+	// authInterceptor.host.
+	host string
+}
+
+func (o *oauthInterceptor) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !sameHost(r, o.host) {
+		unauthorized := r.Clone(r.Context())
+		unauthorized.Header.Del(Authorization)
+		return o.core.RoundTrip(unauthorized)
+	}
+	defer func() {
+		if r.Body != nil {
+			_ = r.Body.Close()
+		}
+	}()
+	token, err := o.source.token(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	response, err := o.core.RoundTrip(o.authorize(r, token))
+	if err != nil || response.StatusCode != http.StatusUnauthorized || (r.Body != nil && r.GetBody == nil) {
+		return response, err
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+	if token, err = o.source.refresh(r.Context(), token); err != nil {
+		return nil, err
+	}
+	retry := o.authorize(r, token)
+	if r.GetBody != nil {
+		if retry.Body, err = r.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return o.core.RoundTrip(retry)
+}
+
+func (o *oauthInterceptor) authorize(r *http.Request, token string) *http.Request {
+	authorized := r.Clone(r.Context())
+	authorized.Header.Set(Authorization, fmt.Sprintf("%s %s", Bearer, token))
+	authorized.Header.Set(XAtlassianToken, "no-check")
+	return authorized
+}
+
+func randomUrlToken() string {
+	data := make([]byte, 32)
+	_, _ = rand.Read(data)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,343 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// standInAuthServer plays auth.atlassian.com and api.atlassian.com: it checks
+// PKCE, rotates refresh tokens, and serves one Jira site behind bearer tokens.
+type standInAuthServer struct {
+	*httptest.Server
+	mu            sync.Mutex
+	seq           int
+	challenges    map[string]string
+	accessTokens  map[string]bool
+	refreshTokens map[string]bool
+	refreshes     int
+	deny          bool
+	comments      []string
+}
+
+func newStandInAuthServer(t *testing.T) *standInAuthServer {
+	s := &standInAuthServer{
+		challenges:    map[string]string{},
+		accessTokens:  map[string]bool{},
+		refreshTokens: map[string]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /oauth/token", s.token)
+	mux.HandleFunc("GET /oauth/token/accessible-resources", s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"cloud-1","url":"https://fjira.atlassian.net","name":"fjira","scopes":["read:jira-work"]}]`))
+	}))
+	mux.HandleFunc("GET /ex/jira/cloud-1/rest/api/2/serverInfo", s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"deploymentType":"Cloud","version":"1001.0.0"}`))
+	}))
+	mux.HandleFunc("POST /ex/jira/cloud-1/rest/api/2/issue/{key}/comment", s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.comments = append(s.comments, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *standInAuthServer) config() OAuthConfig {
+	return OAuthConfig{
+		ClientId:     "fjira-client",
+		ClientSecret: "fjira-secret",
+		RedirectUrl:  "http://127.0.0.1:0/callback",
+		AuthorizeUrl: s.URL + "/authorize",
+		TokenUrl:     s.URL + "/oauth/token",
+		ResourcesUrl: s.URL + "/oauth/token/accessible-resources",
+		ApiUrl:       s.URL + "/ex/jira/",
+		CloudId:      "cloud-1",
+	}
+}
+
+func (s *standInAuthServer) authorize(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	callback := url.Values{"state": {query.Get("state")}}
+	if s.deny || query.Get("client_id") != "fjira-client" || query.Get("code_challenge_method") != "S256" {
+		callback.Set("error", "access_denied")
+	} else {
+		s.seq++
+		code := fmt.Sprintf("code-%d", s.seq)
+		s.challenges[code] = query.Get("code_challenge")
+		callback.Set("code", code)
+	}
+	redirect.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *standInAuthServer) token(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var request map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&request)
+	valid := request["client_id"] == "fjira-client" && request["client_secret"] == "fjira-secret"
+	switch request["grant_type"] {
+	case "authorization_code":
+		challenge, ok := s.challenges[request["code"]]
+		delete(s.challenges, request["code"])
+		valid = valid && ok && challenge == pkceChallenge(request["code_verifier"])
+	case "refresh_token":
+		valid = valid && s.refreshTokens[request["refresh_token"]]
+		delete(s.refreshTokens, request["refresh_token"])
+		s.refreshes++
+	default:
+		valid = false
+	}
+	if !valid {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Unknown or invalid refresh token."}`))
+		return
+	}
+	s.seq++
+	access, refresh := fmt.Sprintf("access-%d", s.seq), fmt.Sprintf("refresh-%d", s.seq)
+	s.accessTokens[access] = true
+	s.refreshTokens[refresh] = true
+	_ = json.NewEncoder(w).Encode(map[string]any{"access_token": access, "refresh_token": refresh, "expires_in": 3600})
+}
+
+func (s *standInAuthServer) authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		valid := s.accessTokens[strings.TrimPrefix(r.Header.Get(Authorization), "Bearer ")]
+		s.mu.Unlock()
+		if !valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// expireAccessTokens makes Jira reject every access token issued so far.
+func (s *standInAuthServer) expireAccessTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessTokens = map[string]bool{}
+}
+
+func (s *standInAuthServer) revokeRefreshTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshTokens = map[string]bool{}
+}
+
+func (s *standInAuthServer) newApi(tokens OAuthTokens, onRefresh func(OAuthTokens)) Api {
+	api, _ := NewApiWithConfig(ApiConfig{
+		ApiUrl:         "https://fjira.atlassian.net",
+		TokenType:      OAuthToken,
+		OAuth:          s.config(),
+		OAuthTokens:    tokens,
+		OnOAuthRefresh: onRefresh,
+	})
+	return api
+}
+
+// followInBrowser plays the browser: the stand-in redirects straight back
+// to fjira's callback listener.
+func followInBrowser(authorizeUrl string) {
+	response, err := http.Get(authorizeUrl)
+	if err == nil {
+		_ = response.Body.Close()
+	}
+}
+
+func Test_AuthorizeOAuth_should_sign_in_with_pkce(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// when
+	tokens, err := AuthorizeOAuth(ctx, defaultHttpTransport, server.config(), followInBrowser)
+
+	// then
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.True(t, tokens.Expiry.After(time.Now()))
+	resources, err := FindOAuthResources(ctx, defaultHttpTransport, server.config(), tokens.AccessToken)
+	assert.Nil(t, err)
+	site, err := SelectOAuthResource(resources, "https://fjira.atlassian.net/")
+	assert.Nil(t, err)
+	assert.Equal(t, "cloud-1", site.Id)
+	api := server.newApi(*tokens, nil)
+	info, err := api.GetServerInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, DeploymentCloud, info.DeploymentType)
+	assert.Equal(t, "https://fjira.atlassian.net", api.GetApiUrl())
+}
+
+func Test_AuthorizeOAuth_should_keep_waiting_after_callback_with_wrong_state(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var forgedStatus int
+	open := func(authorizeUrl string) {
+		authorize, _ := url.Parse(authorizeUrl)
+		callbackUrl, _ := url.Parse(authorize.Query().Get("redirect_uri"))
+		callbackUrl.RawQuery = url.Values{"code": {"forged"}, "state": {"wrong"}}.Encode()
+		if response, err := http.Get(callbackUrl.String()); err == nil {
+			forgedStatus = response.StatusCode
+			_ = response.Body.Close()
+		}
+		followInBrowser(authorizeUrl)
+	}
+
+	// when
+	tokens, err := AuthorizeOAuth(ctx, defaultHttpTransport, server.config(), open)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, forgedStatus)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+}
+
+func Test_AuthorizeOAuth_should_fail_when_access_is_denied(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	server.deny = true
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// when
+	_, err := AuthorizeOAuth(ctx, defaultHttpTransport, server.config(), followInBrowser)
+
+	// then
+	assert.True(t, errors.Is(err, ErrOAuthDenied))
+}
+
+func Test_AuthorizeOAuth_should_give_up_when_context_is_done(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := AuthorizeOAuth(ctx, defaultHttpTransport, server.config(), func(string) {})
+
+	// then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func Test_oauthInterceptor_should_refresh_rejected_tokens_and_report_rotated_ones(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	tokens, _ := AuthorizeOAuth(context.Background(), defaultHttpTransport, server.config(), followInBrowser)
+	var stored []OAuthTokens
+	api := server.newApi(*tokens, func(t OAuthTokens) { stored = append(stored, t) })
+	server.expireAccessTokens()
+
+	// when
+	err := api.DoComment("FJ-1", "after refresh", nil)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"body":"after refresh"}`}, server.comments, "the body should be sent again with the retry")
+	assert.Len(t, stored, 1)
+	assert.NotEqual(t, tokens.RefreshToken, stored[0].RefreshToken)
+	_, err = api.GetServerInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, server.refreshes, "the refreshed token should be reused")
+}
+
+func Test_oauthInterceptor_should_refresh_tokens_about_to_expire(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	tokens, _ := AuthorizeOAuth(context.Background(), defaultHttpTransport, server.config(), followInBrowser)
+	tokens.Expiry = time.Now().Add(30 * time.Second)
+	api := server.newApi(*tokens, nil)
+
+	// when
+	_, err := api.GetServerInfo()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, 1, server.refreshes)
+}
+
+func Test_oauthInterceptor_should_fail_when_session_cannot_be_refreshed(t *testing.T) {
+	// given
+	server := newStandInAuthServer(t)
+	tokens, _ := AuthorizeOAuth(context.Background(), defaultHttpTransport, server.config(), followInBrowser)
+	api := server.newApi(*tokens, nil)
+	server.expireAccessTokens()
+	server.revokeRefreshTokens()
+
+	// when
+	_, err := api.GetServerInfo()
+
+	// then
+	assert.True(t, errors.Is(err, ErrOAuthRefresh))
+	assert.Contains(t, err.Error(), "invalid_grant")
+}
+
+func Test_SelectOAuthResource(t *testing.T) {
+	one := []OAuthResource{{Id: "1", Url: "https://a.atlassian.net"}}
+	two := append([]OAuthResource{{Id: "2", Url: "https://b.atlassian.net"}}, one...)
+	tests := []struct {
+		name      string
+		resources []OAuthResource
+		siteUrl   string
+		wantId    string
+		wantErr   bool
+	}{
+		{"should match site url", two, "https://a.atlassian.net", "1", false},
+		{"should ignore trailing slash and case", two, " https://A.atlassian.net/ ", "1", false},
+		{"should pick the only site without url", one, "", "1", false},
+		{"should not guess between sites", two, "", "", true},
+		{"should fail for sites the app can't access", one, "https://c.atlassian.net", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectOAuthResource(tt.resources, tt.siteUrl)
+
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrOAuthSiteNotFound))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantId, got.Id)
+		})
+	}
+}
+
+func Test_FindOAuthResources_should_use_the_workspace_proxy(t *testing.T) {
+	// given
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`[{"id": "cloud-1", "url": "https://fjira.atlassian.net"}]`))
+	}))
+	defer proxy.Close()
+	transport, _ := NewHttpTransport(ConnectionConfig{ProxyUrl: proxy.URL})
+
+	// when
+	resources, err := FindOAuthResources(context.Background(), transport, OAuthConfig{ResourcesUrl: "http://auth.internal/oauth/token/accessible-resources"}, "access-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "http://auth.internal/oauth/token/accessible-resources", proxied)
+}
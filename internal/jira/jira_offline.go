@@ -0,0 +1,670 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var ErrOffline = errors.New("not available in offline mode")
+
+var projectClauseRegexp = regexp.MustCompile(`(?i)\bproject\s*=\s*"?([^"\s)]+)"?`)
+
+type PendingOperationType string
+
+const (
+	PendingComment     PendingOperationType = "comment"
+	PendingTransition  PendingOperationType = "transition"
+	PendingAssignee    PendingOperationType = "assignee"
+	PendingLabel       PendingOperationType = "label"
+	PendingDescription PendingOperationType = "description"
+	PendingWorklog     PendingOperationType = "worklog"
+)
+
+// PendingOperation is a change made in offline mode, waiting to be sent to
+// Jira. Text is the comment, label, description or time spent, depending on
+// Type; a comment or description written as a document is in Adf instead.
+type PendingOperation struct {
+	Type       PendingOperationType `json:"type"`
+	IssueKey   string               `json:"issueKey"`
+	Text       string               `json:"text,omitempty"`
+	Adf        json.RawMessage      `json:"adf,omitempty"`
+	Transition *IssueTransition     `json:"transition,omitempty"`
+	// TransitionInput is what was filled in on the transition's screen
+	TransitionInput *TransitionInput `json:"transitionInput,omitempty"`
+	User            *User            `json:"user,omitempty"`
+	// Visibility is who a queued comment is restricted to
+	Visibility *CommentVisibility `json:"visibility,omitempty"`
+	// Started is when the logged work started
+	Started *time.Time `json:"started,omitempty"`
+	// BaseUpdated is the issue's "updated" timestamp in the snapshot when the
+	// change was made. If Jira has a different one at replay time, someone
+	// else touched the issue in the meantime.
+	BaseUpdated string    `json:"baseUpdated,omitempty"`
+	QueuedAt    time.Time `json:"queuedAt"`
+}
+
+func (op PendingOperation) String() string {
+	switch op.Type {
+	case PendingTransition:
+		if op.Transition != nil {
+			return fmt.Sprintf("%s status -> %s", op.IssueKey, op.Transition.To.Name)
+		}
+	case PendingAssignee:
+		if op.User != nil {
+			return fmt.Sprintf("%s assignee -> %s", op.IssueKey, op.User.DisplayName)
+		}
+	case PendingLabel:
+		return fmt.Sprintf("%s label %s", op.IssueKey, op.Text)
+	case PendingWorklog:
+		return fmt.Sprintf("%s worklog %s", op.IssueKey, op.Text)
+	}
+	return fmt.Sprintf("%s %s", op.IssueKey, op.Type)
+}
+
+// ReplayConflict is a queued change that was dropped instead of sent:
+// either the issue moved on in Jira (Reason), or Jira rejected it (Err).
+type ReplayConflict struct {
+	Operation PendingOperation
+	Reason    string
+	Err       error
+}
+
+type ReplayResult struct {
+	Applied   int
+	Conflicts []ReplayConflict
+	// Remaining are still queued, because Jira couldn't be reached.
+	Remaining int
+}
+
+// OfflineApi serves a SnapshotStore through the Api interface, for
+// `fjira --offline`. Reads return what was recorded while online (or
+// ErrOffline for what never was); mutations are applied to the snapshot
+// and queued as PendingOperations for SnapshotStore.Replay.
+type OfflineApi struct {
+	store              *SnapshotStore
+	apiUrl             string
+	capabilities       Capabilities
+	customFields       *atomic.Pointer[CustomFields]
+	customFieldsConfig CustomFieldsConfig
+	now                func() time.Time
+}
+
+// NewOfflineApi takes the ApiConfig the online Api would be built from, for
+// the url and the cached deployment type.
+func NewOfflineApi(store *SnapshotStore, cfg ApiConfig) *OfflineApi {
+	api := &OfflineApi{
+		store:              store,
+		apiUrl:             cfg.ApiUrl,
+		capabilities:       capabilitiesFor(cfg),
+		customFields:       &atomic.Pointer[CustomFields]{},
+		customFieldsConfig: cfg.CustomFields,
+		now:                time.Now,
+	}
+	api.customFields.Store(ResolveCustomFields(cfg.CustomFields, nil))
+	return api
+}
+
+func (o *OfflineApi) Search(query string) ([]Issue, int32, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	query = strings.ToLower(query)
+	issues := make([]Issue, 0)
+	for key, stored := range o.store.data.Issues {
+		if strings.ToLower(key) == query || strings.Contains(strings.ToLower(stored.Issue.Fields.Summary), query) {
+			issues = append(issues, stored.Issue)
+		}
+	}
+	sortIssues(issues)
+	return issues, int32(len(issues)), nil
+}
+
+func (o *OfflineApi) SearchJql(query string) ([]Issue, error) {
+	return o.searchJql(query)
+}
+
+func (o *OfflineApi) SearchJqlPageable(query string, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	issues, err := o.searchJql(query)
+	if err != nil {
+		return nil, -1, pageSize, err
+	}
+	from := min(int(page*pageSize), len(issues))
+	to := min(from+int(pageSize), len(issues))
+	return issues[from:to], int32(len(issues)), pageSize, nil
+}
+
+func (o *OfflineApi) SearchJqlPages(query string, pageSize int32) iter.Seq2[[]Issue, error] {
+	return func(yield func([]Issue, error) bool) {
+		issues, err := o.searchJql(query)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for page := range slices.Chunk(issues, int(pageSize)) {
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// searchJql can't evaluate jql, so it returns what the exact same jql
+// returned online. Other queries fall back to every recorded issue of the
+// project they're about.
+func (o *OfflineApi) searchJql(jql string) ([]Issue, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	issues := make([]Issue, 0)
+	if keys, ok := o.store.data.Searches[jql]; ok {
+		for _, key := range keys {
+			if stored, ok := o.store.data.Issues[key]; ok {
+				issues = append(issues, stored.Issue)
+			}
+		}
+		return issues, nil
+	}
+	match := projectClauseRegexp.FindStringSubmatch(jql)
+	if match == nil {
+		return nil, ErrOffline
+	}
+	for _, stored := range o.store.data.Issues {
+		project := stored.Issue.Fields.Project
+		if strings.EqualFold(project.Key, match[1]) || project.Id == match[1] {
+			issues = append(issues, stored.Issue)
+		}
+	}
+	sortIssues(issues)
+	return issues, nil
+}
+
+func (o *OfflineApi) FindUsers(project string) ([]User, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) FindUsersWithQuery(project string, query string) ([]User, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetMyself() (*User, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) FindProjects() ([]Project, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	return append([]Project{}, o.store.data.Projects...), nil
+}
+
+// FindFields returns the fields recorded the last time they were fetched
+// online.
+func (o *OfflineApi) FindFields() ([]Field, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	if len(o.store.data.Fields) == 0 {
+		return nil, ErrOffline
+	}
+	return append([]Field{}, o.store.data.Fields...), nil
+}
+
+func (o *OfflineApi) UseFields(fields []Field) {
+	o.customFields.Store(ResolveCustomFields(o.customFieldsConfig, NewFieldRegistry(fields)))
+}
+
+func (o *OfflineApi) CustomFields() *CustomFields {
+	return o.customFields.Load()
+}
+
+func (o *OfflineApi) FindProject(projectKey string) (*Project, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	for _, project := range o.store.data.Projects {
+		if strings.EqualFold(project.Key, projectKey) || project.Id == projectKey {
+			return &project, nil
+		}
+	}
+	return nil, ErrOffline
+}
+
+// FindLabels suggests the labels of the recorded issues.
+func (o *OfflineApi) FindLabels(issue *Issue, query string) ([]string, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	labels := make([]string, 0)
+	for _, stored := range o.store.data.Issues {
+		for _, label := range stored.Issue.Fields.Labels {
+			if strings.HasPrefix(strings.ToLower(label), strings.ToLower(query)) && !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+func (o *OfflineApi) FindTransitions(issueId string) ([]IssueTransition, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	transitions, ok := o.store.data.Transitions[o.store.keyOf(issueId)]
+	if !ok {
+		return nil, ErrOffline
+	}
+	return transitions, nil
+}
+
+func (o *OfflineApi) FindProjectStatuses(projectId string) ([]IssueStatus, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	statuses, ok := o.store.data.Statuses[projectId]
+	if !ok {
+		return nil, ErrOffline
+	}
+	return statuses, nil
+}
+
+func (o *OfflineApi) GetIssueDetailed(issueId string) (*Issue, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	stored, ok := o.store.data.Issues[o.store.keyOf(issueId)]
+	if !ok {
+		return nil, ErrOffline
+	}
+	return &stored.Issue, nil
+}
+
+func (o *OfflineApi) DoTransition(issueId string, transition *IssueTransition, input *TransitionInput) error {
+	return o.queue(PendingOperation{Type: PendingTransition, Transition: transition, TransitionInput: input}, issueId, func(issue *Issue) {
+		issue.Fields.Status = Status{Id: transition.To.StatusId, Name: transition.To.Name}
+	})
+}
+
+func (o *OfflineApi) DoAssignee(issueId string, user *User) error {
+	return o.queue(PendingOperation{Type: PendingAssignee, User: user}, issueId, func(issue *Issue) {
+		issue.Fields.Assignee.AccountId = ""
+		issue.Fields.Assignee.DisplayName = ""
+		if user != nil {
+			issue.Fields.Assignee.AccountId = user.AccountId
+			issue.Fields.Assignee.DisplayName = user.DisplayName
+		}
+	})
+}
+
+func (o *OfflineApi) AddLabel(issueId string, label string) error {
+	return o.queue(PendingOperation{Type: PendingLabel, Text: label}, issueId, func(issue *Issue) {
+		if !slices.Contains(issue.Fields.Labels, label) {
+			issue.Fields.Labels = append(issue.Fields.Labels, label)
+		}
+	})
+}
+
+func (o *OfflineApi) DoComment(issueId string, commentBody string, visibility *CommentVisibility) error {
+	return o.queue(PendingOperation{Type: PendingComment, Text: commentBody, Visibility: visibility}, issueId, func(issue *Issue) {
+		issue.Fields.Comment.Comments = append(issue.Fields.Comment.Comments, Comment{
+			Body:       commentBody,
+			Created:    o.now().Format("2006-01-02T15:04:05.000-0700"),
+			Visibility: visibility,
+		})
+		issue.Fields.Comment.Total++
+	})
+}
+
+func (o *OfflineApi) DoUpdateDescription(issueId string, description string) error {
+	return o.queue(PendingOperation{Type: PendingDescription, Text: description}, issueId, func(issue *Issue) {
+		issue.Fields.Description = description
+		issue.Fields.DescriptionAdf = nil
+	})
+}
+
+func (o *OfflineApi) DoCommentAdf(issueId string, body json.RawMessage, visibility *CommentVisibility) error {
+	text, _, _ := richText(body)
+	return o.queue(PendingOperation{Type: PendingComment, Adf: body, Visibility: visibility}, issueId, func(issue *Issue) {
+		issue.Fields.Comment.Comments = append(issue.Fields.Comment.Comments, Comment{
+			Body:       text,
+			BodyAdf:    body,
+			Created:    o.now().Format("2006-01-02T15:04:05.000-0700"),
+			Visibility: visibility,
+		})
+		issue.Fields.Comment.Total++
+	})
+}
+
+func (o *OfflineApi) DoUpdateDescriptionAdf(issueId string, description json.RawMessage) error {
+	text, _, _ := richText(description)
+	return o.queue(PendingOperation{Type: PendingDescription, Adf: description}, issueId, func(issue *Issue) {
+		issue.Fields.Description = text
+		issue.Fields.DescriptionAdf = description
+	})
+}
+
+func (o *OfflineApi) FindWorklogs(issueKey string) ([]Worklog, error) {
+	return nil, ErrOffline
+}
+
+// AddWorklog is queued, but the snapshot has no worklogs to apply it to.
+func (o *OfflineApi) AddWorklog(issueKey string, timeSpent string, started time.Time) error {
+	timeSpent, err := NormalizeTimeSpent(timeSpent)
+	if err != nil {
+		return err
+	}
+	return o.queue(PendingOperation{Type: PendingWorklog, Text: timeSpent, Started: &started}, issueKey, func(issue *Issue) {})
+}
+
+func (o *OfflineApi) DownloadAttachment(attachment *Attachment, w io.Writer) error {
+	return ErrOffline
+}
+
+// AddAttachment isn't queued: the file may be gone or changed by the time
+// the queue is replayed.
+func (o *OfflineApi) AddAttachment(issueKey string, filename string, content io.Reader) ([]Attachment, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) FindIssueLinkTypes() ([]IssueLinkType, error) {
+	return nil, ErrOffline
+}
+
+// CreateIssueLink and DeleteIssueLink aren't queued: a link touches two
+// issues, and the other one may not be in the snapshot.
+func (o *OfflineApi) CreateIssueLink(linkType string, inwardKey string, outwardKey string) error {
+	return ErrOffline
+}
+
+func (o *OfflineApi) DeleteIssueLink(linkId string) error {
+	return ErrOffline
+}
+
+// UpdateComment, UpdateCommentAdf and DeleteComment aren't queued: comments
+// added offline have no id until they're replayed.
+func (o *OfflineApi) UpdateComment(issueId string, commentId string, commentBody string, visibility *CommentVisibility) error {
+	return ErrOffline
+}
+
+func (o *OfflineApi) UpdateCommentAdf(issueId string, commentId string, body json.RawMessage, visibility *CommentVisibility) error {
+	return ErrOffline
+}
+
+func (o *OfflineApi) DeleteComment(issueId string, commentId string) error {
+	return ErrOffline
+}
+
+func (o *OfflineApi) FindProjectRoles(projectKey string) ([]string, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) FindGroups(query string) ([]string, error) {
+	return nil, ErrOffline
+}
+
+// queue records op and applies it to the snapshot copy of the issue, so it
+// shows up right away. The issue's "updated" is left alone - it's what
+// replay compares against.
+func (o *OfflineApi) queue(op PendingOperation, issueId string, apply func(issue *Issue)) error {
+	o.store.mu.Lock()
+	key := o.store.keyOf(issueId)
+	op.IssueKey = key
+	op.QueuedAt = o.now()
+	if stored, ok := o.store.data.Issues[key]; ok {
+		op.BaseUpdated = stored.Issue.Fields.Updated
+		apply(&stored.Issue)
+		o.store.data.Issues[key] = stored
+	}
+	o.store.data.Pending = append(o.store.data.Pending, op)
+	o.store.mu.Unlock()
+	// queued changes must not wait for Close
+	return o.store.Save()
+}
+
+func (o *OfflineApi) FindCreateMeta(projectKey string) ([]IssueTypeMeta, error) {
+	return nil, ErrOffline
+}
+
+// CreateIssue isn't queued: the new issue has no key until Jira gives it one.
+func (o *OfflineApi) CreateIssue(fields map[string]any) (*Issue, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) FindEditMeta(issueKey string) (map[string]FieldMeta, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetIssueFieldValues(issueKey string, fieldIds []string) (map[string]json.RawMessage, error) {
+	return nil, ErrOffline
+}
+
+// UpdateIssueFields isn't queued: fields are edited from the edit screen,
+// which can't be fetched offline.
+func (o *OfflineApi) UpdateIssueFields(issueKey string, fields map[string]any) error {
+	return ErrOffline
+}
+
+func (o *OfflineApi) FindBoards(projectKeyOrId string) ([]BoardItem, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetBoardConfiguration(boardId int) (*BoardConfiguration, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetBoardSprints(boardId int) ([]SprintItem, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetBoardSprintIssues(boardId int, sprintId int, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	return nil, -1, pageSize, ErrOffline
+}
+
+func (o *OfflineApi) GetBoardProjects(boardId int) ([]Project, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetFilter(filterId string) (*Filter, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) GetMyFilters() ([]Filter, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) Close() {
+	_ = o.store.Save()
+}
+
+func (o *OfflineApi) GetApiUrl() string {
+	return o.apiUrl
+}
+
+func (o *OfflineApi) WithContext(ctx context.Context) Api {
+	// nothing to cancel - every call is answered from memory
+	return o
+}
+
+func (o *OfflineApi) GetServerInfo() (*ServerInfo, error) {
+	return nil, ErrOffline
+}
+
+func (o *OfflineApi) Capabilities() Capabilities {
+	return o.capabilities
+}
+
+func (o *OfflineApi) IsJiraServer() bool {
+	return o.capabilities.IsServer()
+}
+
+// Replay sends the queued offline changes to Jira, oldest first. A change
+// to an issue that has been updated in Jira since it was queued is dropped
+// as a conflict - except comments and labels, which can't overwrite anyone's
+// work. Changes Jira rejects are dropped too. If Jira can't be reached, the
+// rest stays queued for the next time. Every change leaves the queue on disk
+// as soon as it's been sent, so an interrupted replay doesn't send it twice.
+func (s *SnapshotStore) Replay(api Api) ReplayResult {
+	var result ReplayResult
+	replayed := make(map[string]replayedIssue)
+	for {
+		s.mu.Lock()
+		if len(s.data.Pending) == 0 {
+			s.mu.Unlock()
+			return result
+		}
+		op := s.data.Pending[0]
+		s.mu.Unlock()
+
+		reason, err := replayOne(api, op, replayed)
+		var jiraErr *Error
+		switch {
+		case err != nil && !errors.As(err, &jiraErr):
+			// not an answer from Jira - keep this and the rest for next time
+			result.Remaining = len(s.Pending())
+			return result
+		case err != nil || reason != "":
+			conflict := ReplayConflict{Operation: op, Reason: reason, Err: err}
+			result.Conflicts = append(result.Conflicts, conflict)
+			_ = s.saveConflicts([]ReplayConflict{conflict})
+		default:
+			result.Applied++
+		}
+		s.mu.Lock()
+		s.data.Pending = s.data.Pending[1:]
+		s.mu.Unlock()
+		_ = s.Save()
+	}
+}
+
+// ConflictsPath is where dropped changes end up, so that e.g. a description
+// written offline isn't lost.
+func (s *SnapshotStore) ConflictsPath() string {
+	if s.dir == "" {
+		return ""
+	}
+	return filepath.Join(s.dir, ConflictsFilename)
+}
+
+type conflictRecord struct {
+	Operation PendingOperation `json:"operation"`
+	Reason    string           `json:"reason"`
+}
+
+// saveConflicts appends conflicts to the ones kept from earlier replays.
+func (s *SnapshotStore) saveConflicts(conflicts []ReplayConflict) error {
+	path := s.ConflictsPath()
+	if path == "" || len(conflicts) == 0 {
+		return nil
+	}
+	var records []conflictRecord
+	if raw, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(raw, &records)
+	}
+	for _, c := range conflicts {
+		reason := c.Reason
+		if c.Err != nil {
+			reason = c.Err.Error()
+		}
+		records = append(records, conflictRecord{Operation: c.Operation, Reason: reason})
+	}
+	raw, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0600)
+}
+
+// replayedIssue is the "updated" a replayed change left behind, so the next
+// queued change to the same issue isn't taken for a conflict.
+type replayedIssue struct {
+	base    string
+	updated string
+}
+
+func replayOne(api Api, op PendingOperation, replayed map[string]replayedIssue) (string, error) {
+	current, err := api.GetIssueDetailed(op.IssueKey)
+	if err != nil {
+		return "", err
+	}
+	base := op.BaseUpdated
+	if r, ok := replayed[op.IssueKey]; ok && r.base == base {
+		base = r.updated
+	}
+	if reason, err := replayConflict(api, op, current, base); reason != "" || err != nil {
+		return reason, err
+	}
+	if err := replayOperation(api, op); err != nil {
+		return "", err
+	}
+	if after, err := api.GetIssueDetailed(op.IssueKey); err == nil {
+		replayed[op.IssueKey] = replayedIssue{base: op.BaseUpdated, updated: after.Fields.Updated}
+	}
+	return "", nil
+}
+
+// replayConflict returns why op can't be sent anymore, or "" if it can.
+func replayConflict(api Api, op PendingOperation, current *Issue, base string) (string, error) {
+	if op.Type == PendingComment || op.Type == PendingLabel || op.Type == PendingWorklog {
+		return "", nil
+	}
+	if base != "" && base != current.Fields.Updated {
+		return "changed in Jira since it was edited offline", nil
+	}
+	if op.Type != PendingTransition {
+		return "", nil
+	}
+	transitions, err := api.FindTransitions(op.IssueKey)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range transitions {
+		if t.Id == op.Transition.Id {
+			return "", nil
+		}
+	}
+	return "the transition isn't available anymore", nil
+}
+
+func replayOperation(api Api, op PendingOperation) error {
+	switch op.Type {
+	case PendingComment:
+		if op.Adf != nil {
+			return api.DoCommentAdf(op.IssueKey, op.Adf, op.Visibility)
+		}
+		return api.DoComment(op.IssueKey, op.Text, op.Visibility)
+	case PendingTransition:
+		return api.DoTransition(op.IssueKey, op.Transition, op.TransitionInput)
+	case PendingAssignee:
+		return api.DoAssignee(op.IssueKey, op.User)
+	case PendingLabel:
+		return api.AddLabel(op.IssueKey, op.Text)
+	case PendingDescription:
+		if op.Adf != nil {
+			return api.DoUpdateDescriptionAdf(op.IssueKey, op.Adf)
+		}
+		return api.DoUpdateDescription(op.IssueKey, op.Text)
+	case PendingWorklog:
+		started := op.QueuedAt
+		if op.Started != nil {
+			started = *op.Started
+		}
+		return api.AddWorklog(op.IssueKey, op.Text, started)
+	}
+	return fmt.Errorf("unknown pending operation: %s", op.Type)
+}
+
+// sortIssues puts the recently updated issues first, as the default jql does.
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Fields.Updated != issues[j].Fields.Updated {
+			return issues[i].Fields.Updated > issues[j].Fields.Updated
+		}
+		return issues[i].Key > issues[j].Key
+	})
+}
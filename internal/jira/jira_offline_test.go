@@ -0,0 +1,354 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newOfflineTestStore(dir string) *SnapshotStore {
+	store := NewSnapshotStore(dir)
+	store.recordSearch("project=100 ORDER BY updated DESC", []Issue{
+		{Key: "ABC-1", Id: "1", Fields: IssueFields{Summary: "Fix login", Updated: "2024-01-02", Project: Project{Id: "100", Key: "ABC"}}},
+		{Key: "ABC-2", Id: "2", Fields: IssueFields{Summary: "Add logout", Updated: "2024-01-01", Project: Project{Id: "100", Key: "ABC"}, Labels: []string{"backend"}}},
+		{Key: "XYZ-1", Id: "3", Fields: IssueFields{Summary: "Other", Updated: "2024-01-03", Project: Project{Id: "200", Key: "XYZ"}}},
+	}, true)
+	store.recordTransitions("1", []IssueTransition{{Id: "31", Name: "Done"}})
+	return store
+}
+
+func Test_OfflineApi_searchJql(t *testing.T) {
+	tests := []struct {
+		name    string
+		jql     string
+		want    []string
+		wantErr error
+	}{
+		{"should return recorded results of the same jql", "project=100 ORDER BY updated DESC", []string{"ABC-1", "ABC-2", "XYZ-1"}, nil},
+		{"should fall back to issues of the project by id", "project=100 AND status=1", []string{"ABC-1", "ABC-2"}, nil},
+		{"should fall back to issues of the project by key", `project = "abc"`, []string{"ABC-1", "ABC-2"}, nil},
+		{"should fail on jql without project", "assignee=currentUser()", nil, ErrOffline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			api := NewOfflineApi(newOfflineTestStore(""), ApiConfig{})
+
+			// when
+			issues, err := api.SearchJql(tt.jql)
+
+			// then
+			assert.Equal(t, tt.wantErr, err)
+			var keys []string
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+func Test_OfflineApi_should_serve_search_and_labels_from_snapshot(t *testing.T) {
+	// given
+	api := NewOfflineApi(newOfflineTestStore(""), ApiConfig{})
+
+	// when
+	byKey, _, _ := api.Search("abc-2")
+	bySummary, _, _ := api.Search("LOG")
+	labels, _ := api.FindLabels(nil, "back")
+
+	// then
+	assert.Equal(t, "ABC-2", byKey[0].Key)
+	assert.Len(t, bySummary, 2)
+	assert.Equal(t, []string{"backend"}, labels)
+}
+
+func Test_OfflineApi_should_fail_for_data_never_seen_online(t *testing.T) {
+	// given
+	api := NewOfflineApi(newOfflineTestStore(""), ApiConfig{})
+
+	// when
+	_, issueErr := api.GetIssueDetailed("ABC-99")
+	_, boardsErr := api.FindBoards("ABC")
+	_, usersErr := api.FindUsers("ABC")
+
+	// then
+	assert.ErrorIs(t, issueErr, ErrOffline)
+	assert.ErrorIs(t, boardsErr, ErrOffline)
+	assert.ErrorIs(t, usersErr, ErrOffline)
+}
+
+func Test_OfflineApi_should_queue_mutations_and_show_them_locally(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	api := NewOfflineApi(newOfflineTestStore(dir), ApiConfig{})
+	transition := &IssueTransition{Id: "31", Name: "Done"}
+	transition.To.StatusId = "3"
+	transition.To.Name = "Done"
+
+	// when
+	assert.Nil(t, api.DoComment("ABC-1", "offline comment", nil))
+	assert.Nil(t, api.DoTransition("1", transition, nil))
+	assert.Nil(t, api.AddLabel("ABC-1", "urgent"))
+	assert.Nil(t, api.DoUpdateDescription("ABC-1", "new description"))
+
+	// then
+	issue, _ := api.GetIssueDetailed("ABC-1")
+	assert.Equal(t, "offline comment", issue.Fields.Comment.Comments[0].Body)
+	assert.Equal(t, "Done", issue.Fields.Status.Name)
+	assert.Equal(t, []string{"urgent"}, issue.Fields.Labels)
+	assert.Equal(t, "new description", issue.Fields.Description)
+	assert.Equal(t, "2024-01-02", issue.Fields.Updated, "updated is what replay compares against")
+	// and queued on disk right away
+	pending := NewSnapshotStore(dir).Pending()
+	assert.Len(t, pending, 4)
+	for _, op := range pending {
+		assert.Equal(t, "ABC-1", op.IssueKey)
+		assert.Equal(t, "2024-01-02", op.BaseUpdated)
+	}
+	assert.Equal(t, PendingTransition, pending[1].Type)
+}
+
+// replayServer is a minimal stateful Jira: every change bumps the issue's updated.
+type replayServer struct {
+	mu          sync.Mutex
+	updated     map[string]string
+	transitions string
+	down        bool
+	received    []string
+}
+
+func (s *replayServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		// hijack and drop the connection, like an unreachable server
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+		return
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/rest/api/3/issue/")
+	parts := strings.Split(path, "/")
+	key := parts[0]
+	if r.Method == http.MethodGet && len(parts) == 1 {
+		_, _ = w.Write([]byte(`{"key":"` + key + `","fields":{"updated":"` + s.updated[key] + `"}}`))
+		return
+	}
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(s.transitions))
+		return
+	}
+	if key == "BAD-1" && strings.HasSuffix(r.URL.Path, "/comment") {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":["Comment body can not be empty!"]}`))
+		return
+	}
+	s.received = append(s.received, r.Method+" "+r.URL.Path)
+	s.updated[key] += "+"
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func Test_SnapshotStore_Replay(t *testing.T) {
+	done := &IssueTransition{Id: "31"}
+	done.To.Name = "Done"
+	tests := []struct {
+		name          string
+		pending       []PendingOperation
+		updated       map[string]string
+		down          bool
+		wantApplied   int
+		wantConflicts []string
+		wantRemaining int
+		wantReceived  []string
+	}{
+		{
+			name: "should send changes to issues nobody touched",
+			pending: []PendingOperation{
+				{Type: PendingTransition, IssueKey: "ABC-1", Transition: done, BaseUpdated: "t1"},
+				{Type: PendingDescription, IssueKey: "ABC-1", Text: "new", BaseUpdated: "t1"},
+			},
+			updated:      map[string]string{"ABC-1": "t1"},
+			wantApplied:  2,
+			wantReceived: []string{"POST /rest/api/2/issue/ABC-1/transitions", "PUT /rest/api/2/issue/ABC-1"},
+		},
+		{
+			name: "should report conflict when issue changed in the meantime",
+			pending: []PendingOperation{
+				{Type: PendingDescription, IssueKey: "ABC-1", Text: "new", BaseUpdated: "t1"},
+				{Type: PendingComment, IssueKey: "ABC-1", Text: "still fine", BaseUpdated: "t1"},
+				{Type: PendingWorklog, IssueKey: "ABC-1", Text: "1h 30m", BaseUpdated: "t1"},
+			},
+			updated:       map[string]string{"ABC-1": "t2"},
+			wantApplied:   2,
+			wantConflicts: []string{"changed in Jira since it was edited offline"},
+			wantReceived:  []string{"POST /rest/api/2/issue/ABC-1/comment", "POST /rest/api/2/issue/ABC-1/worklog"},
+		},
+		{
+			name: "should send documents to the v3 api",
+			pending: []PendingOperation{
+				{Type: PendingComment, IssueKey: "ABC-1", Adf: json.RawMessage(`{"type":"doc"}`), BaseUpdated: "t1"},
+				{Type: PendingDescription, IssueKey: "ABC-1", Adf: json.RawMessage(`{"type":"doc"}`), BaseUpdated: "t1"},
+			},
+			updated:      map[string]string{"ABC-1": "t1"},
+			wantApplied:  2,
+			wantReceived: []string{"POST /rest/api/3/issue/ABC-1/comment", "PUT /rest/api/3/issue/ABC-1"},
+		},
+		{
+			name: "should report conflict when transition is gone",
+			pending: []PendingOperation{
+				{Type: PendingTransition, IssueKey: "ABC-1", Transition: &IssueTransition{Id: "99"}, BaseUpdated: "t1"},
+			},
+			updated:       map[string]string{"ABC-1": "t1"},
+			wantConflicts: []string{"the transition isn't available anymore"},
+		},
+		{
+			name: "should report changes rejected by jira",
+			pending: []PendingOperation{
+				{Type: PendingComment, IssueKey: "BAD-1", Text: ""},
+			},
+			updated:       map[string]string{"BAD-1": "t1"},
+			wantConflicts: []string{"Comment body can not be empty!"},
+		},
+		{
+			name: "should keep everything queued when jira is unreachable",
+			pending: []PendingOperation{
+				{Type: PendingComment, IssueKey: "ABC-1", Text: "a"},
+				{Type: PendingComment, IssueKey: "ABC-1", Text: "b"},
+			},
+			updated:       map[string]string{"ABC-1": "t1"},
+			down:          true,
+			wantRemaining: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			server := &replayServer{updated: tt.updated, down: tt.down, transitions: `{"transitions":[{"id":"31"}]}`}
+			api := NewJiraApiMock(server.handle)
+			dir := t.TempDir()
+			store := NewSnapshotStore(dir)
+			store.data.Pending = tt.pending
+
+			// when
+			result := store.Replay(api)
+
+			// then
+			assert.Equal(t, tt.wantApplied, result.Applied)
+			assert.Equal(t, tt.wantRemaining, result.Remaining)
+			assert.Len(t, store.Pending(), tt.wantRemaining)
+			assert.Equal(t, tt.wantReceived, server.received)
+			var conflicts []string
+			for _, c := range result.Conflicts {
+				reason := c.Reason
+				var jiraErr *Error
+				if errors.As(c.Err, &jiraErr) {
+					reason = jiraErr.Details()
+				}
+				conflicts = append(conflicts, reason)
+			}
+			assert.Equal(t, tt.wantConflicts, conflicts)
+			if len(conflicts) > 0 {
+				_, err := os.Stat(filepath.Join(dir, ConflictsFilename))
+				assert.Nil(t, err, "conflicts should be kept on disk")
+			}
+		})
+	}
+}
+
+func Test_SnapshotStore_Replay_should_dequeue_every_change_once_sent(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	server := &replayServer{updated: map[string]string{"ABC-1": "t1"}}
+	var queuedOnDisk []int
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			// what a replay interrupted while sending would resume from
+			queuedOnDisk = append(queuedOnDisk, len(NewSnapshotStore(dir).Pending()))
+		}
+		server.handle(w, r)
+	})
+	store := NewSnapshotStore(dir)
+	store.data.Pending = []PendingOperation{
+		{Type: PendingComment, IssueKey: "ABC-1", Text: "a"},
+		{Type: PendingComment, IssueKey: "ABC-1", Text: "b"},
+		{Type: PendingComment, IssueKey: "ABC-1", Text: "c"},
+	}
+	_ = store.Save()
+
+	// when
+	result := store.Replay(api)
+
+	// then
+	assert.Equal(t, 3, result.Applied)
+	assert.Equal(t, []int{3, 2, 1}, queuedOnDisk)
+	assert.Empty(t, NewSnapshotStore(dir).Pending())
+}
+
+func Test_OfflineApi_should_stamp_queued_operations(t *testing.T) {
+	// given
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	api := NewOfflineApi(NewSnapshotStore(""), ApiConfig{})
+	api.now = func() time.Time { return now }
+
+	// when
+	_ = api.DoComment("NEW-1", "hi", nil)
+
+	// then
+	pending := api.store.Pending()
+	assert.Equal(t, now, pending[0].QueuedAt)
+	assert.Equal(t, "", pending[0].BaseUpdated, "issue not in snapshot - nothing to compare against")
+}
+
+func Test_OfflineApi_should_queue_worklogs(t *testing.T) {
+	// given
+	started := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	api := NewOfflineApi(newOfflineTestStore(""), ApiConfig{})
+
+	// when
+	err := api.AddWorklog("ABC-1", "1h30m", started)
+	invalidErr := api.AddWorklog("ABC-1", "an hour", started)
+	_, findErr := api.FindWorklogs("ABC-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, ErrInvalidTimeSpent, invalidErr)
+	assert.Equal(t, ErrOffline, findErr)
+	pending := api.store.Pending()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "1h 30m", pending[0].Text)
+	assert.Equal(t, started, *pending[0].Started)
+	assert.Equal(t, "ABC-1 worklog 1h 30m", pending[0].String())
+}
+
+func Test_OfflineApi_should_queue_restricted_comments(t *testing.T) {
+	// given
+	visibility := &CommentVisibility{Type: VisibilityRole, Value: "Developers"}
+	api := NewOfflineApi(newOfflineTestStore(""), ApiConfig{})
+	var body string
+	online := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+	})
+
+	// when
+	err := api.DoComment("ABC-1", "internal note", visibility)
+	pending := api.store.Pending()
+	replayErr := replayOperation(online, pending[0])
+
+	// then
+	assert.Nil(t, err)
+	issue, _ := api.GetIssueDetailed("ABC-1")
+	assert.Equal(t, visibility, issue.Fields.Comment.Comments[0].Visibility)
+	assert.Equal(t, visibility, pending[0].Visibility)
+	assert.Nil(t, replayErr)
+	assert.JSONEq(t, `{"body": "internal note", "visibility": {"type": "role", "value": "Developers"}}`, body)
+}
@@ -0,0 +1,259 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	RedactedValue = "<redacted>"
+)
+
+var ErrNotRecorded = errors.New("no recorded response")
+
+// redactedHeaders never end up in a recording.
+var redactedHeaders = []string{Authorization, "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+var recordingSlugRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Interaction is one recorded request/response pair, stored as a json file.
+// Only the path and query of the url are kept, so recordings don't depend on
+// (or leak) the Jira host.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	RecordedBody
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	RecordedBody
+}
+
+// RecordedBody keeps text bodies readable (and diffable); anything that isn't
+// valid UTF-8 goes to BodyBase64 instead.
+type RecordedBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"bodyBase64,omitempty"`
+}
+
+func newRecordedBody(data []byte) RecordedBody {
+	if utf8.Valid(data) {
+		return RecordedBody{Body: string(data)}
+	}
+	return RecordedBody{BodyBase64: data}
+}
+
+func (b RecordedBody) bytes() []byte {
+	if b.BodyBase64 != nil {
+		return b.BodyBase64
+	}
+	return []byte(b.Body)
+}
+
+// RecordingTransport sits in place of the default http transport and writes
+// every request/response pair into dir, credentials redacted - see
+// `fjira --record`. The files are what ReplayTransport serves back.
+type RecordingTransport struct {
+	core http.RoundTripper
+	dir  string
+	mu   sync.Mutex
+	seq  int
+}
+
+// NewRecordingTransport records into dir, after the recordings already there.
+// A nil core means the default http transport.
+func NewRecordingTransport(dir string, core http.RoundTripper) (*RecordingTransport, error) {
+	if core == nil {
+		core = defaultHttpTransport
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingTransport{core: core, dir: dir, seq: len(existing)}, nil
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err := t.core.RoundTrip(req)
+	if err != nil {
+		return response, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:       req.Method,
+			Url:          req.URL.RequestURI(),
+			Header:       redactHeader(req.Header),
+			RecordedBody: newRecordedBody(requestBody),
+		},
+		Response: RecordedResponse{
+			StatusCode:   response.StatusCode,
+			Header:       redactHeader(response.Header),
+			RecordedBody: newRecordedBody(responseBody),
+		},
+	}
+	// a recording that fails to save must not break the session itself
+	_ = t.save(interaction)
+	return response, nil
+}
+
+func (t *RecordingTransport) save(interaction Interaction) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	// keep bodies readable - Jira's html fragments would be full of \u003c
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(interaction); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+	slug := strings.Trim(recordingSlugRegexp.ReplaceAllString(strings.SplitN(interaction.Request.Url, "?", 2)[0], "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	name := fmt.Sprintf("%04d-%s-%s.json", seq, interaction.Request.Method, slug)
+	return os.WriteFile(filepath.Join(t.dir, name), data.Bytes(), 0600)
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, RedactedValue)
+		}
+	}
+	return redacted
+}
+
+// ReplayTransport serves the responses of a recording instead of talking to
+// Jira - see `fjira --replay`. Requests are matched by method, path and
+// query. When the same request was recorded more than once, the responses
+// are served in the recorded order, and the last one keeps being repeated.
+// Anything that wasn't recorded fails with ErrNotRecorded.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	responses map[string][]RecordedResponse
+	served    map[string]int
+}
+
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+	// the sequence number prefix keeps them in recording order
+	sort.Strings(files)
+	t := &ReplayTransport{
+		responses: make(map[string][]RecordedResponse),
+		served:    make(map[string]int),
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		key, err := replayKey(interaction.Request.Method, interaction.Request.Url)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		t.responses[key] = append(t.responses[key], interaction.Response)
+	}
+	return t, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	key, err := replayKey(req.Method, req.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	responses := t.responses[key]
+	i := t.served[key]
+	if i < len(responses)-1 {
+		t.served[key] = i + 1
+	}
+	t.mu.Unlock()
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNotRecorded, key)
+	}
+	recorded := responses[min(i, len(responses)-1)]
+	body := recorded.bytes()
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// replayKey normalises the query, so parameter order doesn't matter.
+func replayKey(method string, requestUri string) (string, error) {
+	path, query, _ := strings.Cut(requestUri, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	return method + " " + path, nil
+}
@@ -0,0 +1,154 @@
+package jira
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_RecordingTransport_should_record_session_that_replays_offline(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			_, _ = w.Write([]byte(`{"issues":[{"key":"ABC-1","fields":{"summary":"Recorded"}}],"isLast":true}`))
+		case "/rest/api/2/issue/ABC-1":
+			_, _ = w.Write([]byte(`{"key":"ABC-1","fields":{"summary":"Recorded","description":"details"}}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	dir := t.TempDir()
+	recorder, _ := NewRecordingTransport(dir, http.DefaultTransport)
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: server.URL, Username: "bob", Token: "secret-token", TokenType: ApiToken, Transport: recorder})
+	_, _ = api.SearchJql("project=ABC")
+	_, _ = api.GetIssueDetailed("ABC-1")
+	_ = api.DoComment("ABC-1", "hello", nil)
+	server.Close()
+
+	// when
+	replayed := NewJiraApiReplay(dir, ApiToken)
+
+	// then
+	issues, err := replayed.SearchJql("project=ABC")
+	assert.Nil(t, err)
+	assert.Equal(t, "Recorded", issues[0].Fields.Summary)
+	issue, err := replayed.GetIssueDetailed("ABC-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "details", issue.Fields.Description)
+	assert.Nil(t, replayed.DoComment("ABC-1", "hello", nil))
+}
+
+func Test_RecordingTransport_should_redact_credentials_and_host(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session-secret"})
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	recorder, _ := NewRecordingTransport(dir, http.DefaultTransport)
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: server.URL, Username: "bob", Token: "secret-token", TokenType: PersonalToken, Transport: recorder})
+
+	// when
+	_, _ = api.GetServerInfo()
+
+	// then
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0], "0001-GET-rest_api_2_serverInfo.json"))
+	data, _ := os.ReadFile(files[0])
+	assert.NotContains(t, string(data), "secret-token")
+	assert.NotContains(t, string(data), "session-secret")
+	assert.NotContains(t, string(data), strings.TrimPrefix(server.URL, "http://"))
+	assert.Contains(t, string(data), RedactedValue)
+	info, _ := os.Stat(files[0])
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_ReplayTransport_should_serve_repeated_requests_in_recorded_order(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"key":"ABC-1","fields":{"summary":"` + r.Header.Get("X-Version") + `"}}`))
+	}))
+	dir := t.TempDir()
+	recorder, _ := NewRecordingTransport(dir, http.DefaultTransport)
+	for _, version := range []string{"before", "after"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/rest/api/2/issue/ABC-1", nil)
+		req.Header.Set("X-Version", version)
+		response, _ := recorder.RoundTrip(req)
+		_ = response.Body.Close()
+	}
+	server.Close()
+	api := NewJiraApiReplay(dir, ApiToken)
+
+	// when
+	first, _ := api.GetIssueDetailed("ABC-1")
+	second, _ := api.GetIssueDetailed("ABC-1")
+	third, _ := api.GetIssueDetailed("ABC-1")
+
+	// then
+	assert.Equal(t, "before", first.Fields.Summary)
+	assert.Equal(t, "after", second.Fields.Summary)
+	assert.Equal(t, "after", third.Fields.Summary, "the last response should keep being served")
+}
+
+func Test_ReplayTransport_should_fail_for_unrecorded_requests(t *testing.T) {
+	// given
+	api := NewJiraApiReplay("testdata/recordings/server-search", PersonalToken)
+
+	// when
+	_, err := api.GetIssueDetailed("FJ-1")
+
+	// then
+	assert.True(t, errors.Is(err, ErrNotRecorded))
+}
+
+func Test_NewReplayTransport_should_fail_without_recordings(t *testing.T) {
+	_, err := NewReplayTransport(t.TempDir())
+
+	assert.NotNil(t, err)
+}
+
+func Test_replayKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		requestUri string
+		want       string
+	}{
+		{"should keep plain path", "GET", "/rest/api/2/serverInfo", "GET /rest/api/2/serverInfo"},
+		{"should sort query parameters", "GET", "/rest/api/2/search?startAt=0&jql=project%3DFJ", "GET /rest/api/2/search?jql=project%3DFJ&startAt=0"},
+		{"should tell methods apart", "POST", "/rest/api/2/issue/FJ-1/comment", "POST /rest/api/2/issue/FJ-1/comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replayKey(tt.method, tt.requestUri)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_httpJiraApi_should_search_on_recorded_jira_server(t *testing.T) {
+	// given
+	api := NewJiraApiReplay("testdata/recordings/server-search", ApiToken)
+
+	// when
+	info, err := api.GetServerInfo()
+	issues, searchErr := api.SearchJql("project=FJ")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, DeploymentServer, info.DeploymentType)
+	assert.True(t, api.Capabilities().IsServer())
+	assert.Nil(t, searchErr)
+	assert.Equal(t, []string{"FJ-2", "FJ-1"}, []string{issues[0].Key, issues[1].Key})
+	assert.Equal(t, "In Progress", issues[1].Fields.Status.Name)
+}
@@ -1,7 +1,6 @@
 package jira
 
 import (
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -11,27 +10,33 @@ import (
 )
 
 func (api *httpApi) jiraRequest(method string, restPath string, queryParams interface{}, reqBody io.Reader) ([]byte, error) {
+	return api.jiraRequestWithContentType(method, restPath, queryParams, reqBody, "application/json")
+}
+
+// jiraRequestWithContentType is jiraRequest for bodies other than json, like
+// the multipart form of an upload.
+func (api *httpApi) jiraRequestWithContentType(method string, restPath string, queryParams interface{}, reqBody io.Reader, contentType string) ([]byte, error) {
 	u, err := api.jiraRequestUrl(restPath, queryParams)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(method, u, reqBody)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	req, err := http.NewRequestWithContext(api.context(), method, u, reqBody)
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", contentType)
 	response, err := api.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	if response.StatusCode >= 400 {
-		return nil, fmt.Errorf("jira error, status: %s - request: %s", response.Status, req.RequestURI)
-	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(response.Body)
 	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode >= 400 {
+		return nil, newError(req, response, body)
+	}
 	return body, nil
 }
 
@@ -1,10 +1,13 @@
 package jira
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 )
 
 func Test_httpApi_jiraRequest_should_return_error_when_http_client_error(t *testing.T) {
@@ -61,3 +64,44 @@ func Test_jiraRequest_combinePaths(t *testing.T) {
 		})
 	}
 }
+
+func Test_httpApi_WithContext_should_cancel_in_flight_request(t *testing.T) {
+	// given
+	release := make(chan struct{})
+	defer close(release)
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-time.NewTimer(50 * time.Millisecond).C
+		cancel()
+	}()
+
+	// when
+	_, err := api.WithContext(ctx).GetIssueDetailed("ABC-1")
+
+	// then
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func Test_httpApi_WithContext_should_not_affect_original_api(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"key":"ABC-1"}`)) //nolint:errcheck
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	_ = api.WithContext(ctx)
+	issue, err := api.GetIssueDetailed("ABC-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC-1", issue.Key)
+}
@@ -0,0 +1,150 @@
+package jira
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how rate-limited (429) and temporarily unavailable
+// (502/503/504) responses are retried. Only idempotent requests (GET, HEAD,
+// OPTIONS) are ever retried - a POST/PUT that failed may still have been
+// applied by Jira, so replaying it could double-comment or double-transition.
+// Configured per workspace, see workspaces.WorkspaceSettings.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retrying.
+	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+	// BaseDelay is the backoff for the first retry; it doubles on every next one.
+	BaseDelay time.Duration `json:"baseDelay" yaml:"baseDelay"`
+	// MaxDelay caps both the computed backoff and the server's Retry-After.
+	MaxDelay time.Duration `json:"maxDelay" yaml:"maxDelay"`
+}
+
+const (
+	RetryAfter = "Retry-After"
+)
+
+// DefaultRetryPolicy is used when a workspace doesn't configure its own.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// WithDefaults fills the delays a partial policy leaves unset from
+// DefaultRetryPolicy - a MaxDelay of 0 would cap every retry at no delay at
+// all. MaxRetries is kept as is, since 0 disables retrying.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaults.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	return p
+}
+
+type retryInterceptor struct {
+	core   http.RoundTripper
+	policy RetryPolicy
+	// jitter picks the actual delay in [0, d]; swapped in tests
+	jitter func(d time.Duration) time.Duration
+}
+
+func newRetryInterceptor(core http.RoundTripper, policy RetryPolicy) *retryInterceptor {
+	return &retryInterceptor{
+		core:   core,
+		policy: policy,
+		jitter: fullJitter,
+	}
+}
+
+func (r *retryInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := r.core.RoundTrip(req)
+	if !isIdempotent(req.Method) {
+		return response, err
+	}
+	for attempt := 0; attempt < r.policy.MaxRetries; attempt++ {
+		if err != nil || !isRetryableStatus(response.StatusCode) {
+			return response, err
+		}
+		delay := r.delay(attempt, response)
+		// drain, so the connection can be reused for the retry
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		response, err = r.core.RoundTrip(req)
+	}
+	return response, err
+}
+
+// delay honours the server's Retry-After when present, and falls back to
+// exponential backoff with full jitter otherwise. Both are capped at MaxDelay.
+func (r *retryInterceptor) delay(attempt int, response *http.Response) time.Duration {
+	if d, ok := parseRetryAfter(response.Header.Get(RetryAfter), time.Now()); ok {
+		return min(d, r.policy.MaxDelay)
+	}
+	backoff := r.policy.BaseDelay << attempt
+	if backoff <= 0 || backoff > r.policy.MaxDelay {
+		backoff = r.policy.MaxDelay
+	}
+	return r.jitter(backoff)
+}
+
+// parseRetryAfter supports both forms allowed by RFC 9110: delay-seconds and
+// an HTTP-date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func fullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+// sleep waits for d, returning early with the context's error when it is
+// cancelled (e.g. Esc pressed while loading).
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
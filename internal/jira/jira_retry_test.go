@@ -0,0 +1,188 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestApi(url string, policy RetryPolicy) *httpApi {
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: url, Username: "test", Token: "test", TokenType: ApiToken, Retry: policy})
+	return api.(*httpApi)
+}
+
+func Test_retryInterceptor_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{"should retry GET on 429 until success", http.MethodGet, []int{429, 429, 200}, 200, 3},
+		{"should retry GET on 503 until success", http.MethodGet, []int{503, 200}, 200, 2},
+		{"should give up after max retries", http.MethodGet, []int{429, 429, 429, 429, 429}, 429, 4},
+		{"should not retry POST", http.MethodPost, []int{429, 200}, 429, 1},
+		{"should not retry PUT", http.MethodPut, []int{503, 200}, 503, 1},
+		{"should not retry on 500", http.MethodGet, []int{500, 200}, 500, 1},
+		{"should not retry on 404", http.MethodGet, []int{404, 200}, 404, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := attempts.Add(1) - 1
+				w.WriteHeader(tt.statuses[min(int(i), len(tt.statuses)-1)])
+			}))
+			defer server.Close()
+			r := newRetryInterceptor(http.DefaultTransport, RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader(""))
+
+			// when
+			response, err := r.RoundTrip(req)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func Test_retryInterceptor_should_honour_retry_after(t *testing.T) {
+	// given
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set(RetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	r := newRetryInterceptor(http.DefaultTransport, RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond})
+	r.jitter = func(d time.Duration) time.Duration {
+		t.Fatal("jitter should not be used when Retry-After is present")
+		return d
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	// when
+	start := time.Now()
+	response, err := r.RoundTrip(req)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "Retry-After of 1s should be capped to MaxDelay")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_retryInterceptor_should_stop_waiting_when_context_cancelled(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RetryAfter, "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	api := newRetryTestApi(server.URL, RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// when
+	start := time.Now()
+	_, err := api.WithContext(ctx).(*httpApi).jiraRequest(http.MethodGet, "/", &nilParams{}, nil)
+
+	// then
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func Test_retryInterceptor_delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{"should use base delay for first retry", 0, 100 * time.Millisecond},
+		{"should double delay for next retries", 2, 400 * time.Millisecond},
+		{"should cap delay to max delay", 10, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			r := newRetryInterceptor(nil, RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+			r.jitter = func(d time.Duration) time.Duration { return d }
+
+			// when
+			got := r.delay(tt.attempt, &http.Response{Header: http.Header{}})
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_RetryPolicy_WithDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   RetryPolicy
+	}{
+		{"should fill delays of a partial policy", RetryPolicy{MaxRetries: 5}, RetryPolicy{MaxRetries: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}},
+		{"should keep retrying disabled", RetryPolicy{}, RetryPolicy{BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}},
+		{"should keep configured delays", RetryPolicy{MaxRetries: 1, BaseDelay: time.Second, MaxDelay: time.Minute}, RetryPolicy{MaxRetries: 1, BaseDelay: time.Second, MaxDelay: time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.WithDefaults())
+		})
+	}
+}
+
+func Test_retryInterceptor_delay_of_partial_policy(t *testing.T) {
+	// given
+	r := newRetryInterceptor(nil, RetryPolicy{MaxRetries: 5}.WithDefaults())
+	r.jitter = func(d time.Duration) time.Duration { return d }
+
+	// when
+	backoff := r.delay(0, &http.Response{Header: http.Header{}})
+	retryAfter := r.delay(0, &http.Response{Header: http.Header{RetryAfter: []string{"2"}}})
+
+	// then
+	assert.Equal(t, 500*time.Millisecond, backoff)
+	assert.Equal(t, 2*time.Second, retryAfter)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"should parse delay seconds", "5", 5 * time.Second, true},
+		{"should parse http date", now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"should clamp past http date to zero", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"should ignore empty value", "", 0, false},
+		{"should ignore negative seconds", "-1", 0, false},
+		{"should ignore garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
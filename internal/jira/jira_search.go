@@ -4,18 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/mk-5/fjira/internal/app"
 )
 
 const (
-	SearchJira      = "/rest/api/3/search/jql"
-	JiraIssueRegexp = "^[a-zA-Z0-9]{1,10}-[0-9]{1,20}$"
+	SearchJira       = "/rest/api/3/search/jql"
+	SearchJiraServer = "/rest/api/2/search"
+	JiraIssueRegexp  = "^[a-zA-Z0-9]{1,10}-[0-9]{1,20}$"
+	searchFields     = "id,key,summary,issuetype,project,reporter,status,assignee,updated"
+	// maxPageTokens bounds the nextPageToken cache; it's simply reset when full
+	maxPageTokens = 256
 )
 
 var ErrSearchDeserialize = errors.New("cannot deserialize jira search response")
 
+// searchQueryParams is the Server/DC /rest/api/2/search request, paged with startAt.
 type searchQueryParams struct {
 	Jql        string `url:"jql"`
 	MaxResults int32  `url:"maxResults"`
@@ -23,11 +31,61 @@ type searchQueryParams struct {
 	StartAt    int32  `url:"startAt"`
 }
 
+// searchJqlQueryParams is the Cloud /rest/api/3/search/jql request, paged
+// with the nextPageToken returned by the previous page.
+type searchJqlQueryParams struct {
+	Jql           string `url:"jql"`
+	MaxResults    int32  `url:"maxResults"`
+	Fields        string `url:"fields"`
+	NextPageToken string `url:"nextPageToken,omitempty"`
+}
+
 type searchResponse struct {
-	Total      int32   `json:"total"`
-	MaxResults int32   `json:"maxResults"`
-	Issues     []Issue `json:"issues"`
-	IsLast     bool    `json:"isLast"`
+	// Total is only sent by /rest/api/2/search
+	Total         *int32  `json:"total"`
+	MaxResults    int32   `json:"maxResults"`
+	Issues        []Issue `json:"issues"`
+	IsLast        bool    `json:"isLast"`
+	NextPageToken string  `json:"nextPageToken"`
+}
+
+type searchPageKey struct {
+	jql      string
+	pageSize int32
+	page     int32
+}
+
+// searchPageTokens remembers the nextPageToken leading to each page, so
+// loading page N of a Cloud search doesn't walk pages 0..N-1 every time.
+type searchPageTokens struct {
+	mu     sync.Mutex
+	tokens map[searchPageKey]string
+}
+
+func (t *searchPageTokens) get(key searchPageKey) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	token, ok := t.tokens[key]
+	return token, ok
+}
+
+func (t *searchPageTokens) put(key searchPageKey, token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tokens == nil || len(t.tokens) >= maxPageTokens {
+		t.tokens = make(map[searchPageKey]string)
+	}
+	t.tokens[key] = token
+}
+
+// searchFields are the fields of search results: searchFields, and the
+// custom ones issue lists show.
+func (api *httpApi) searchFields() string {
+	ids := api.CustomFields().searchFieldIds()
+	if len(ids) == 0 {
+		return searchFields
+	}
+	return searchFields + "," + strings.Join(ids, ",")
 }
 
 func (api *httpApi) Search(query string) ([]Issue, int32, error) {
@@ -45,21 +103,148 @@ func (api *httpApi) SearchJql(jql string) ([]Issue, error) {
 	return issues, err
 }
 
+// SearchJqlPageable returns the given page of jql results, with the total
+// number of matching issues. Cloud's search/jql doesn't report a total, so
+// there it is a lower bound: everything fetched so far, +1 while more pages
+// remain - enough for "fetch until len(issues) >= total" loops.
 func (api *httpApi) SearchJqlPageable(jql string, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	if !api.Capabilities().SupportsSearchJql() {
+		return api.searchStartAt(jql, page, pageSize)
+	}
+	return api.searchNextPageToken(jql, page, pageSize)
+}
+
+func (api *httpApi) searchStartAt(jql string, page int32, pageSize int32) ([]Issue, int32, int32, error) {
 	queryParams := searchQueryParams{
 		Jql:        jql,
 		MaxResults: pageSize,
 		StartAt:    page * pageSize,
-		Fields:     "id,key,summary,issuetype,project,reporter,status,assignee,updated",
+		Fields:     api.searchFields(),
 	}
-	body, err := api.jiraRequest("GET", SearchJira, queryParams, nil)
+	sResponse, err := api.doSearch(SearchJiraServer, queryParams)
 	if err != nil {
 		return nil, -1, pageSize, err
 	}
+	total := page*pageSize + int32(len(sResponse.Issues))
+	if sResponse.Total != nil {
+		total = *sResponse.Total
+	}
+	return sResponse.Issues, total, sResponse.MaxResults, nil
+}
+
+func (api *httpApi) searchNextPageToken(jql string, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	// find the closest page we know the token for, and walk from there
+	from := page
+	token := ""
+	for ; from > 0; from-- {
+		if t, ok := api.pageTokens.get(searchPageKey{jql, pageSize, from}); ok {
+			token = t
+			break
+		}
+	}
+	for current := from; ; current++ {
+		queryParams := searchJqlQueryParams{
+			Jql:           jql,
+			MaxResults:    pageSize,
+			Fields:        api.searchFields(),
+			NextPageToken: token,
+		}
+		sResponse, err := api.doSearch(SearchJira, queryParams)
+		if err != nil {
+			return nil, -1, pageSize, err
+		}
+		hasNext := !sResponse.IsLast && sResponse.NextPageToken != ""
+		if hasNext {
+			api.pageTokens.put(searchPageKey{jql, pageSize, current + 1}, sResponse.NextPageToken)
+		}
+		if current < page && hasNext {
+			token = sResponse.NextPageToken
+			continue
+		}
+		if current < page {
+			// asked for a page past the last one
+			return []Issue{}, current*pageSize + int32(len(sResponse.Issues)), pageSize, nil
+		}
+		total := current*pageSize + int32(len(sResponse.Issues))
+		if sResponse.Total != nil {
+			total = *sResponse.Total
+		} else if hasNext {
+			total++
+		}
+		return sResponse.Issues, total, pageSize, nil
+	}
+}
+
+// SearchJqlPages streams jql results page by page, following startAt on
+// Server/DC and nextPageToken on Cloud, so callers can show the first page
+// while the rest is still loading. It stops after the last page, when the loop
+// breaks, or after yielding the first error (with nil issues).
+func (api *httpApi) SearchJqlPages(jql string, pageSize int32) iter.Seq2[[]Issue, error] {
+	if !api.Capabilities().SupportsSearchJql() {
+		return api.searchPagesStartAt(jql, pageSize)
+	}
+	return api.searchPagesNextPageToken(jql, pageSize)
+}
+
+func (api *httpApi) searchPagesStartAt(jql string, pageSize int32) iter.Seq2[[]Issue, error] {
+	return func(yield func([]Issue, error) bool) {
+		startAt := int32(0)
+		for {
+			sResponse, err := api.doSearch(SearchJiraServer, searchQueryParams{
+				Jql:        jql,
+				MaxResults: pageSize,
+				StartAt:    startAt,
+				Fields:     api.searchFields(),
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(sResponse.Issues) == 0 || !yield(sResponse.Issues, nil) {
+				return
+			}
+			startAt += int32(len(sResponse.Issues))
+			if sResponse.Total != nil && startAt >= *sResponse.Total {
+				return
+			}
+		}
+	}
+}
+
+func (api *httpApi) searchPagesNextPageToken(jql string, pageSize int32) iter.Seq2[[]Issue, error] {
+	return func(yield func([]Issue, error) bool) {
+		token := ""
+		for {
+			sResponse, err := api.doSearch(SearchJira, searchJqlQueryParams{
+				Jql:           jql,
+				MaxResults:    pageSize,
+				Fields:        api.searchFields(),
+				NextPageToken: token,
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(sResponse.Issues) == 0 || !yield(sResponse.Issues, nil) {
+				return
+			}
+			if sResponse.IsLast || sResponse.NextPageToken == "" {
+				return
+			}
+			token = sResponse.NextPageToken
+		}
+	}
+}
+
+func (api *httpApi) doSearch(restPath string, queryParams interface{}) (*searchResponse, error) {
+	body, err := api.jiraRequest("GET", restPath, queryParams, nil)
+	if err != nil {
+		return nil, err
+	}
 	var sResponse searchResponse
 	if err := json.Unmarshal(body, &sResponse); err != nil {
 		app.Error(err.Error())
-		return nil, -1, pageSize, ErrSearchDeserialize
+		return nil, ErrSearchDeserialize
 	}
-	return sResponse.Issues, sResponse.Total, sResponse.MaxResults, err
+	return &sResponse, nil
 }
@@ -1,6 +1,7 @@
 package jira
 
 import (
+	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
 )
@@ -179,3 +180,177 @@ func Test_httpJiraApi_SearchJql(t *testing.T) {
 		})
 	}
 }
+
+func Test_httpJiraApi_SearchJqlPageable_server_should_use_startAt_paging(t *testing.T) {
+	// given
+	var gotPath, gotStartAt string
+	api := NewJiraApiMockWithTokenType(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotStartAt = r.URL.Query().Get("startAt")
+		w.WriteHeader(200)
+		w.Write([]byte(`{"startAt":50,"maxResults":50,"total":120,"issues":[{"key":"ISSUE-51"},{"key":"ISSUE-52"}]}`)) //nolint:errcheck
+	}, PersonalToken)
+
+	// when
+	issues, total, pageSize, err := api.SearchJqlPageable("project=ISSUE", 1, 50)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, SearchJiraServer, gotPath)
+	assert.Equal(t, "50", gotStartAt)
+	assert.Equal(t, int32(120), total)
+	assert.Equal(t, int32(50), pageSize)
+	assert.Equal(t, "ISSUE-51", issues[0].Key)
+}
+
+func Test_httpJiraApi_SearchJqlPageable_cloud_should_use_nextPageToken_paging(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"issues":[{"key":"ISSUE-1"},{"key":"ISSUE-2"}],"nextPageToken":"t1","isLast":false}`,
+		"t1": `{"issues":[{"key":"ISSUE-3"},{"key":"ISSUE-4"}],"nextPageToken":"t2","isLast":false}`,
+		"t2": `{"issues":[{"key":"ISSUE-5"}],"isLast":true}`,
+	}
+	tests := []struct {
+		name         string
+		page         int32
+		wantKeys     []string
+		wantTotal    int32
+		wantRequests int
+	}{
+		{"should fetch first page without token", 0, []string{"ISSUE-1", "ISSUE-2"}, 3, 1},
+		{"should walk tokens to requested page", 1, []string{"ISSUE-3", "ISSUE-4"}, 5, 2},
+		{"should report exact total on last page", 2, []string{"ISSUE-5"}, 5, 3},
+		{"should return empty page past the end", 3, []string{}, 5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			requests := 0
+			api := NewJiraApiMockWithTokenType(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				assert.Equal(t, SearchJira, r.URL.Path)
+				assert.Empty(t, r.URL.Query().Get("startAt"))
+				w.WriteHeader(200)
+				w.Write([]byte(pages[r.URL.Query().Get("nextPageToken")])) //nolint:errcheck
+			}, ApiToken)
+
+			// when
+			issues, total, _, err := api.SearchJqlPageable("project=ISSUE", tt.page, 2)
+
+			// then
+			assert.Nil(t, err)
+			keys := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			assert.Equal(t, tt.wantKeys, keys)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func Test_httpJiraApi_SearchJqlPageable_cloud_should_reuse_known_page_tokens(t *testing.T) {
+	// given
+	var tokens []string
+	api := NewJiraApiMockWithTokenType(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("nextPageToken")
+		tokens = append(tokens, token)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"issues":[{"key":"ISSUE-1"}],"nextPageToken":"` + token + `x","isLast":false}`)) //nolint:errcheck
+	}, ApiToken)
+
+	// when
+	for page := int32(0); page < 3; page++ {
+		_, _, _, _ = api.SearchJqlPageable("project=ISSUE", page, 1)
+	}
+
+	// then
+	assert.Equal(t, []string{"", "x", "xx"}, tokens, "every page should be fetched exactly once")
+}
+
+func Test_httpJiraApi_SearchJqlPages(t *testing.T) {
+	cloudPages := map[string]string{
+		"":   `{"issues":[{"key":"ISSUE-1"},{"key":"ISSUE-2"}],"nextPageToken":"t1","isLast":false}`,
+		"t1": `{"issues":[{"key":"ISSUE-3"}],"isLast":true}`,
+	}
+	serverPages := map[string]string{
+		"0": `{"startAt":0,"maxResults":2,"total":3,"issues":[{"key":"ISSUE-1"},{"key":"ISSUE-2"}]}`,
+		"2": `{"startAt":2,"maxResults":2,"total":3,"issues":[{"key":"ISSUE-3"}]}`,
+	}
+	tests := []struct {
+		name      string
+		tokenType JiraTokenType
+		wantPath  string
+		respond   func(r *http.Request) string
+	}{
+		{"should follow nextPageToken on cloud", ApiToken, SearchJira, func(r *http.Request) string {
+			return cloudPages[r.URL.Query().Get("nextPageToken")]
+		}},
+		{"should follow startAt on server", PersonalToken, SearchJiraServer, func(r *http.Request) string {
+			return serverPages[r.URL.Query().Get("startAt")]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			requests := 0
+			api := NewJiraApiMockWithTokenType(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				w.WriteHeader(200)
+				w.Write([]byte(tt.respond(r))) //nolint:errcheck
+			}, tt.tokenType)
+
+			// when
+			var pages [][]string
+			for issues, err := range api.SearchJqlPages("project=ISSUE", 2) {
+				assert.Nil(t, err)
+				keys := make([]string, 0, len(issues))
+				for _, issue := range issues {
+					keys = append(keys, issue.Key)
+				}
+				pages = append(pages, keys)
+			}
+
+			// then
+			assert.Equal(t, [][]string{{"ISSUE-1", "ISSUE-2"}, {"ISSUE-3"}}, pages)
+			assert.Equal(t, 2, requests)
+		})
+	}
+}
+
+func Test_httpJiraApi_SearchJqlPages_should_stop_when_loop_breaks(t *testing.T) {
+	// given
+	requests := 0
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(200)
+		w.Write([]byte(`{"issues":[{"key":"ISSUE-1"}],"nextPageToken":"next","isLast":false}`)) //nolint:errcheck
+	})
+
+	// when
+	for range api.SearchJqlPages("project=ISSUE", 1) {
+		break
+	}
+
+	// then
+	assert.Equal(t, 1, requests)
+}
+
+func Test_httpJiraApi_SearchJqlPages_should_yield_error(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	// when
+	var errs []error
+	for issues, err := range api.SearchJqlPages("project=ISSUE", 1) {
+		assert.Nil(t, issues)
+		errs = append(errs, err)
+	}
+
+	// then
+	assert.Len(t, errs, 1)
+	assert.NotNil(t, errs[0])
+}
@@ -0,0 +1,98 @@
+package jira
+
+import (
+	"encoding/json"
+)
+
+const (
+	ServerInfoRestPath = "/rest/api/2/serverInfo"
+)
+
+type DeploymentType string
+
+const (
+	DeploymentCloud      DeploymentType = "Cloud"
+	DeploymentServer     DeploymentType = "Server"
+	DeploymentDataCenter DeploymentType = "DataCenter"
+)
+
+type ServerInfo struct {
+	BaseUrl        string         `json:"baseUrl"`
+	Version        string         `json:"version"`
+	VersionNumbers []int          `json:"versionNumbers"`
+	DeploymentType DeploymentType `json:"deploymentType"`
+	BuildNumber    int            `json:"buildNumber"`
+	ServerTitle    string         `json:"serverTitle"`
+}
+
+// Capabilities describes what the connected Jira supports, so callers can
+// branch on a feature instead of guessing from the token type. Until
+// GetServerInfo succeeds (or a cached deployment type is passed through
+// ApiConfig) the deployment is guessed: personal tokens mean Server/DC.
+type Capabilities struct {
+	Deployment DeploymentType
+	Version    string
+	// Detected is false while Deployment is only the token-type guess.
+	Detected bool
+}
+
+// IsServer is true for both Server and Data Center deployments.
+func (c Capabilities) IsServer() bool {
+	return c.Deployment == DeploymentServer || c.Deployment == DeploymentDataCenter
+}
+
+// SupportsSearchJql tells whether the enhanced /rest/api/3/search/jql
+// endpoint exists - Cloud only, Server/DC still use /rest/api/2/search.
+func (c Capabilities) SupportsSearchJql() bool {
+	return !c.IsServer()
+}
+
+// SupportsAccountId tells whether users are identified by accountId (Cloud,
+// GDPR mode) rather than by username/key (Server/DC).
+func (c Capabilities) SupportsAccountId() bool {
+	return !c.IsServer()
+}
+
+// SupportsAdf tells whether issues are read from the v3 api, which returns
+// descriptions and comments as Atlassian Document Format. Only once Cloud is
+// known for sure - Server/DC have no v3 api, so a wrong guess would break
+// opening issues.
+func (c Capabilities) SupportsAdf() bool {
+	return c.Detected && !c.IsServer()
+}
+
+func guessCapabilities(tokenType JiraTokenType) Capabilities {
+	if tokenType == PersonalToken {
+		return Capabilities{Deployment: DeploymentServer}
+	}
+	return Capabilities{Deployment: DeploymentCloud}
+}
+
+// capabilitiesFor uses the cached deployment from cfg, if there is one.
+func capabilitiesFor(cfg ApiConfig) Capabilities {
+	if cfg.Deployment != "" {
+		return Capabilities{Deployment: cfg.Deployment, Version: cfg.ServerVersion, Detected: true}
+	}
+	return guessCapabilities(cfg.TokenType)
+}
+
+// GetServerInfo fetches serverInfo and, when it carries a deployment type,
+// switches Capabilities from the guess to the detected values.
+func (api *httpApi) GetServerInfo() (*ServerInfo, error) {
+	body, err := api.jiraRequest("GET", ServerInfoRestPath, &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var info ServerInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+	if info.DeploymentType != "" {
+		api.capabilities.Store(&Capabilities{Deployment: info.DeploymentType, Version: info.Version, Detected: true})
+	}
+	return &info, nil
+}
+
+func (api *httpApi) Capabilities() Capabilities {
+	return *api.capabilities.Load()
+}
@@ -0,0 +1,62 @@
+package jira
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func Test_httpApi_GetServerInfo(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokenType      JiraTokenType
+		response       string
+		wantDeployment DeploymentType
+		wantDetected   bool
+		wantServer     bool
+	}{
+		{"should detect cloud despite personal token", PersonalToken, `{"deploymentType":"Cloud","version":"1001.0.0"}`, DeploymentCloud, true, false},
+		{"should detect server despite api token", ApiToken, `{"deploymentType":"Server","version":"8.20.1"}`, DeploymentServer, true, true},
+		{"should detect data center", ApiToken, `{"deploymentType":"DataCenter","version":"9.12.0"}`, DeploymentDataCenter, true, true},
+		{"should keep guess when deployment type missing", PersonalToken, `{}`, DeploymentServer, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			api := NewJiraApiMockWithTokenType(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != ServerInfoRestPath {
+					t.Fail()
+				}
+				w.WriteHeader(200)
+				_, _ = w.Write([]byte(tt.response))
+			}, tt.tokenType)
+			// detection through a derived api must be visible on the original one
+			derived := api.WithContext(context.Background())
+
+			// when
+			_, err := derived.GetServerInfo()
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantDeployment, api.Capabilities().Deployment)
+			assert.Equal(t, tt.wantDetected, api.Capabilities().Detected)
+			assert.Equal(t, tt.wantServer, api.IsJiraServer())
+		})
+	}
+}
+
+func Test_httpApi_should_use_cached_deployment_from_config(t *testing.T) {
+	// given
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: "http://localhost", TokenType: ApiToken, Deployment: DeploymentDataCenter, ServerVersion: "9.12.0"})
+
+	// when
+	caps := api.Capabilities()
+
+	// then
+	assert.True(t, caps.Detected)
+	assert.True(t, caps.IsServer())
+	assert.False(t, caps.SupportsSearchJql())
+	assert.False(t, caps.SupportsAccountId())
+	assert.Equal(t, "9.12.0", caps.Version)
+}
@@ -0,0 +1,339 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	SnapshotFilename  = "snapshot.json"
+	ConflictsFilename = "conflicts.json"
+	// MaxSnapshotIssues caps the issues kept in the snapshot; the ones seen
+	// longest ago are dropped first.
+	MaxSnapshotIssues = 2000
+)
+
+type snapshotIssue struct {
+	Issue Issue `json:"issue"`
+	// Detailed is true once the issue came from GetIssueDetailed; search
+	// results only carry searchFields.
+	Detailed bool `json:"detailed,omitempty"`
+	// Seen is when the issue was last read online.
+	Seen time.Time `json:"seen,omitempty"`
+}
+
+type snapshotData struct {
+	// Issues are keyed by issue key.
+	Issues map[string]snapshotIssue `json:"issues,omitempty"`
+	// Searches maps a jql to the keys it returned, in order.
+	Searches    map[string][]string          `json:"searches,omitempty"`
+	Projects    []Project                    `json:"projects,omitempty"`
+	Fields      []Field                      `json:"fields,omitempty"`
+	Statuses    map[string][]IssueStatus     `json:"statuses,omitempty"`
+	Transitions map[string][]IssueTransition `json:"transitions,omitempty"`
+	Pending     []PendingOperation           `json:"pending,omitempty"`
+}
+
+// SnapshotStore is the local copy of everything fjira has seen online, kept
+// so OfflineApi can serve it back, plus the changes queued while offline.
+// It's one json file in dir; an empty dir keeps it in memory only.
+type SnapshotStore struct {
+	mu   sync.Mutex
+	dir  string
+	data snapshotData
+	// maxIssues is MaxSnapshotIssues, see prune
+	maxIssues int
+	now       func() time.Time
+}
+
+// NewSnapshotStore loads the snapshot from dir, if there is one. A missing or
+// unreadable file just means starting from an empty snapshot.
+func NewSnapshotStore(dir string) *SnapshotStore {
+	s := &SnapshotStore{dir: dir, maxIssues: MaxSnapshotIssues, now: time.Now}
+	if dir != "" {
+		if raw, err := os.ReadFile(filepath.Join(dir, SnapshotFilename)); err == nil {
+			_ = json.Unmarshal(raw, &s.data)
+		}
+	}
+	s.init()
+	return s
+}
+
+func (s *SnapshotStore) init() {
+	if s.data.Issues == nil {
+		s.data.Issues = make(map[string]snapshotIssue)
+	}
+	if s.data.Searches == nil {
+		s.data.Searches = make(map[string][]string)
+	}
+	if s.data.Statuses == nil {
+		s.data.Statuses = make(map[string][]IssueStatus)
+	}
+	if s.data.Transitions == nil {
+		s.data.Transitions = make(map[string][]IssueTransition)
+	}
+}
+
+// Save writes the snapshot to disk, readable by the owner only - it holds
+// issue contents.
+func (s *SnapshotStore) Save() error {
+	if s.dir == "" {
+		return nil
+	}
+	s.mu.Lock()
+	raw, err := json.Marshal(s.data)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, SnapshotFilename), raw, 0600)
+}
+
+// Pending returns a copy of the queued offline changes, oldest first.
+func (s *SnapshotStore) Pending() []PendingOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]PendingOperation(nil), s.data.Pending...)
+}
+
+// keyOf resolves an issue id to its key; Api calls take either.
+func (s *SnapshotStore) keyOf(issueIdOrKey string) string {
+	if _, ok := s.data.Issues[issueIdOrKey]; ok {
+		return issueIdOrKey
+	}
+	for key, issue := range s.data.Issues {
+		if issue.Issue.Id == issueIdOrKey {
+			return key
+		}
+	}
+	return issueIdOrKey
+}
+
+func (s *SnapshotStore) putIssues(issues []Issue, detailed bool) {
+	seen := s.now()
+	for _, issue := range issues {
+		stored, ok := s.data.Issues[issue.Key]
+		if !detailed && ok && stored.Detailed && stored.Issue.Fields.Updated == issue.Fields.Updated {
+			// a search result would drop the comments etc. of an up-to-date detailed copy
+			stored.Seen = seen
+			s.data.Issues[issue.Key] = stored
+			continue
+		}
+		s.data.Issues[issue.Key] = snapshotIssue{Issue: issue, Detailed: detailed, Seen: seen}
+	}
+	s.prune()
+}
+
+// prune keeps the snapshot from growing without end: past maxIssues, the
+// issues seen longest ago are dropped, with their transitions and the
+// searches left without issues.
+func (s *SnapshotStore) prune() {
+	if len(s.data.Issues) <= s.maxIssues {
+		return
+	}
+	keys := slices.SortedFunc(maps.Keys(s.data.Issues), func(a, b string) int {
+		return s.data.Issues[a].Seen.Compare(s.data.Issues[b].Seen)
+	})
+	for _, key := range keys[:len(keys)-s.maxIssues] {
+		delete(s.data.Issues, key)
+		delete(s.data.Transitions, key)
+	}
+	for jql, keys := range s.data.Searches {
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			_, ok := s.data.Issues[key]
+			return !ok
+		})
+		if len(keys) == 0 {
+			delete(s.data.Searches, jql)
+			continue
+		}
+		s.data.Searches[jql] = keys
+	}
+}
+
+func (s *SnapshotStore) recordIssues(issues []Issue, detailed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putIssues(issues, detailed)
+}
+
+// recordSearch stores a page of jql results; the first page replaces what the
+// jql returned before, the next ones are appended.
+func (s *SnapshotStore) recordSearch(jql string, issues []Issue, firstPage bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putIssues(issues, false)
+	keys := s.data.Searches[jql]
+	if firstPage {
+		keys = nil
+	}
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	s.data.Searches[jql] = keys
+}
+
+func (s *SnapshotStore) recordProjects(projects []Project, all bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if all {
+		s.data.Projects = append([]Project(nil), projects...)
+		return
+	}
+	for _, project := range projects {
+		found := false
+		for i := range s.data.Projects {
+			if s.data.Projects[i].Key == project.Key {
+				s.data.Projects[i] = project
+				found = true
+			}
+		}
+		if !found {
+			s.data.Projects = append(s.data.Projects, project)
+		}
+	}
+}
+
+func (s *SnapshotStore) recordFields(fields []Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Fields = append([]Field(nil), fields...)
+}
+
+func (s *SnapshotStore) recordStatuses(projectId string, statuses []IssueStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Statuses[projectId] = statuses
+}
+
+func (s *SnapshotStore) recordTransitions(issueId string, transitions []IssueTransition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Transitions[s.keyOf(issueId)] = transitions
+}
+
+// SnapshotApi decorates an Api so that what it reads - search results,
+// issue details, projects, statuses and transitions - is recorded in a
+// SnapshotStore for offline use. The store is saved on Close.
+type SnapshotApi struct {
+	Api
+	store *SnapshotStore
+}
+
+func NewSnapshotApi(api Api, store *SnapshotStore) *SnapshotApi {
+	return &SnapshotApi{Api: api, store: store}
+}
+
+func (s *SnapshotApi) WithContext(ctx context.Context) Api {
+	return &SnapshotApi{Api: s.Api.WithContext(ctx), store: s.store}
+}
+
+func (s *SnapshotApi) Search(query string) ([]Issue, int32, error) {
+	issues, total, err := s.Api.Search(query)
+	if err == nil {
+		s.store.recordIssues(issues, false)
+	}
+	return issues, total, err
+}
+
+func (s *SnapshotApi) SearchJql(query string) ([]Issue, error) {
+	issues, err := s.Api.SearchJql(query)
+	if err == nil {
+		s.store.recordSearch(query, issues, true)
+	}
+	return issues, err
+}
+
+func (s *SnapshotApi) SearchJqlPageable(query string, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	issues, total, pageSize, err := s.Api.SearchJqlPageable(query, page, pageSize)
+	if err == nil {
+		s.store.recordSearch(query, issues, page == 0)
+	}
+	return issues, total, pageSize, err
+}
+
+func (s *SnapshotApi) SearchJqlPages(query string, pageSize int32) iter.Seq2[[]Issue, error] {
+	return func(yield func([]Issue, error) bool) {
+		first := true
+		for issues, err := range s.Api.SearchJqlPages(query, pageSize) {
+			if err == nil {
+				s.store.recordSearch(query, issues, first)
+				first = false
+			}
+			if !yield(issues, err) {
+				return
+			}
+		}
+	}
+}
+
+func (s *SnapshotApi) GetBoardSprintIssues(boardId int, sprintId int, page int32, pageSize int32) ([]Issue, int32, int32, error) {
+	issues, total, pageSize, err := s.Api.GetBoardSprintIssues(boardId, sprintId, page, pageSize)
+	if err == nil {
+		s.store.recordIssues(issues, false)
+	}
+	return issues, total, pageSize, err
+}
+
+func (s *SnapshotApi) GetIssueDetailed(issueId string) (*Issue, error) {
+	issue, err := s.Api.GetIssueDetailed(issueId)
+	if err == nil && issue != nil {
+		s.store.recordIssues([]Issue{*issue}, true)
+	}
+	return issue, err
+}
+
+func (s *SnapshotApi) FindProjects() ([]Project, error) {
+	projects, err := s.Api.FindProjects()
+	if err == nil {
+		s.store.recordProjects(projects, true)
+	}
+	return projects, err
+}
+
+func (s *SnapshotApi) FindFields() ([]Field, error) {
+	fields, err := s.Api.FindFields()
+	if err == nil {
+		s.store.recordFields(fields)
+	}
+	return fields, err
+}
+
+func (s *SnapshotApi) FindProject(projectKey string) (*Project, error) {
+	project, err := s.Api.FindProject(projectKey)
+	if err == nil && project != nil {
+		s.store.recordProjects([]Project{*project}, false)
+	}
+	return project, err
+}
+
+func (s *SnapshotApi) FindProjectStatuses(projectId string) ([]IssueStatus, error) {
+	statuses, err := s.Api.FindProjectStatuses(projectId)
+	if err == nil {
+		s.store.recordStatuses(projectId, statuses)
+	}
+	return statuses, err
+}
+
+func (s *SnapshotApi) FindTransitions(issueId string) ([]IssueTransition, error) {
+	transitions, err := s.Api.FindTransitions(issueId)
+	if err == nil {
+		s.store.recordTransitions(issueId, transitions)
+	}
+	return transitions, err
+}
+
+func (s *SnapshotApi) Close() {
+	_ = s.store.Save()
+	s.Api.Close()
+}
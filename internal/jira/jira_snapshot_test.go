@@ -0,0 +1,117 @@
+package jira
+
+import (
+	"github.com/stretchr/testify/assert"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newSnapshotApiMock(store *SnapshotStore) *SnapshotApi {
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/api/3/search/jql":
+			_, _ = w.Write([]byte(`{"issues":[
+				{"key":"ABC-1","id":"1","fields":{"summary":"First","updated":"2024-01-02","project":{"id":"100","key":"ABC"}}},
+				{"key":"ABC-2","id":"2","fields":{"summary":"Second","updated":"2024-01-01","project":{"id":"100","key":"ABC"}}}
+			],"isLast":true}`))
+		case r.URL.Path == "/rest/api/2/issue/ABC-1":
+			_, _ = w.Write([]byte(`{"key":"ABC-1","id":"1","fields":{"summary":"First","updated":"2024-01-02","description":"details",
+				"comment":{"comments":[{"body":"hello"}],"total":1}}}`))
+		case r.URL.Path == "/rest/api/2/issue/1/transitions":
+			_, _ = w.Write([]byte(`{"transitions":[{"id":"31","name":"Done","to":{"id":"3","name":"Done"}}]}`))
+		case r.URL.Path == ProjectsJira:
+			_, _ = w.Write([]byte(`{"values":[{"id":"100","key":"ABC","name":"Abc"}],"isLast":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return NewSnapshotApi(api, store)
+}
+
+func Test_SnapshotApi_should_record_reads_for_offline_use(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	api := newSnapshotApiMock(NewSnapshotStore(dir))
+	jql := "project=100 ORDER BY updated DESC"
+	for range api.SearchJqlPages(jql, 50) {
+	}
+	_, _ = api.GetIssueDetailed("ABC-1")
+	_, _ = api.FindTransitions("1")
+	_, _ = api.FindProjects()
+
+	// when
+	api.Close()
+	offline := NewOfflineApi(NewSnapshotStore(dir), ApiConfig{ApiUrl: "https://jira.example.com"})
+
+	// then
+	issues, err := offline.SearchJql(jql)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ABC-1", "ABC-2"}, []string{issues[0].Key, issues[1].Key})
+	issue, err := offline.GetIssueDetailed("ABC-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "details", issue.Fields.Description)
+	assert.Equal(t, "hello", issue.Fields.Comment.Comments[0].Body)
+	transitions, err := offline.FindTransitions("ABC-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "31", transitions[0].Id)
+	projects, err := offline.FindProjects()
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC", projects[0].Key)
+}
+
+func Test_SnapshotApi_should_not_replace_detailed_issue_with_search_result(t *testing.T) {
+	// given
+	api := newSnapshotApiMock(NewSnapshotStore(""))
+	_, _ = api.GetIssueDetailed("ABC-1")
+
+	// when
+	_, _ = api.SearchJql("project=100")
+
+	// then
+	issue, _ := NewOfflineApi(api.store, ApiConfig{}).GetIssueDetailed("ABC-1")
+	assert.Equal(t, "details", issue.Fields.Description)
+}
+
+func Test_SnapshotStore_Save_should_be_readable_by_owner_only(t *testing.T) {
+	// given
+	dir := filepath.Join(t.TempDir(), "offline", "default")
+	store := NewSnapshotStore(dir)
+	store.recordIssues([]Issue{{Key: "ABC-1"}}, true)
+
+	// when
+	err := store.Save()
+
+	// then
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(dir, SnapshotFilename))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_SnapshotStore_should_drop_issues_seen_longest_ago(t *testing.T) {
+	// given
+	store := NewSnapshotStore("")
+	store.maxIssues = 2
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	store.recordSearch("project=100", []Issue{{Key: "ABC-1"}}, true)
+	store.recordSearch("project=200", []Issue{{Key: "XYZ-1"}}, true)
+	store.recordTransitions("ABC-1", []IssueTransition{{Id: "31"}})
+	store.recordIssues([]Issue{{Key: "XYZ-1"}}, true)
+
+	// when
+	store.recordIssues([]Issue{{Key: "XYZ-2"}}, true)
+
+	// then
+	assert.ElementsMatch(t, []string{"XYZ-1", "XYZ-2"}, slices.Collect(maps.Keys(store.data.Issues)))
+	assert.NotContains(t, store.data.Transitions, "ABC-1")
+	assert.Equal(t, map[string][]string{"project=200": {"XYZ-1"}}, store.data.Searches)
+}
@@ -35,6 +35,29 @@ type IssueTransition struct {
 		StatusId  string `json:"id"`
 		Name      string `json:"name"`
 	} `json:"to"`
+	// HasScreen is set for transitions that ask for input - the Fields, and a
+	// comment.
+	HasScreen bool                 `json:"hasScreen,omitempty"`
+	Fields    map[string]FieldMeta `json:"fields,omitempty"`
+}
+
+// RequiresInput tells whether the transition has required fields Jira has no
+// default for, like the resolution on "Done" - it fails without them.
+func (t *IssueTransition) RequiresInput() bool {
+	for _, f := range t.Fields {
+		if f.Required && !f.HasDefaultValue {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionInput is what's filled in on a transition's screen: Fields are set,
+// like the resolution, and Update are operations, like adding a comment:
+// {"comment": [{"add": {"body": "..."}}]}.
+type TransitionInput struct {
+	Fields map[string]any `json:"fields,omitempty"`
+	Update map[string]any `json:"update,omitempty"`
 }
 
 type IssueStatus struct {
@@ -49,16 +72,31 @@ const (
 
 type nilParams struct{}
 
+type findTransitionsQueryParams struct {
+	Expand string `url:"expand"`
+}
+
 type transitionsResponse struct {
 	Transitions []IssueTransition `json:"transitions"`
 }
 
 type doTransitionRequest struct {
-	Transition string `json:"transition"`
+	Transition struct {
+		Id string `json:"id"`
+	} `json:"transition"`
+	Fields map[string]any `json:"fields,omitempty"`
+	Update map[string]any `json:"update,omitempty"`
 }
 
-func (a *httpApi) DoTransition(issueId string, transition *IssueTransition) error {
-	request := doTransitionRequest{Transition: transition.Id}
+// DoTransition moves the issue with the transition; input is what its screen
+// asks for, or nil.
+func (a *httpApi) DoTransition(issueId string, transition *IssueTransition, input *TransitionInput) error {
+	request := doTransitionRequest{}
+	request.Transition.Id = transition.Id
+	if input != nil {
+		request.Fields = input.Fields
+		request.Update = input.Update
+	}
 	requestBody, _ := json.Marshal(request)
 	_, err := a.jiraRequest("POST", strings.Replace(GetTransitions, "{issue}", issueId, 1), &nilParams{}, strings.NewReader(string(requestBody)))
 	if err != nil {
@@ -67,8 +105,13 @@ func (a *httpApi) DoTransition(issueId string, transition *IssueTransition) erro
 	return nil
 }
 
+// FindTransitions returns the transitions of the issue, with the fields of
+// their screens.
 func (a *httpApi) FindTransitions(issueId string) ([]IssueTransition, error) {
-	responseBody, _ := a.jiraRequest("GET", strings.Replace(GetTransitions, "{issue}", issueId, 1), &nilParams{}, nil)
+	responseBody, err := a.jiraRequest("GET", strings.Replace(GetTransitions, "{issue}", issueId, 1), &findTransitionsQueryParams{Expand: "transitions.fields"}, nil)
+	if err != nil {
+		return nil, err
+	}
 	var sResponse transitionsResponse
 	if err := json.Unmarshal(responseBody, &sResponse); err != nil {
 		app.Error(err.Error())
@@ -1,9 +1,12 @@
 package jira
 
 import (
+	"io"
 	"net/http"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_httpJiraApi_DoTransition(t *testing.T) {
@@ -39,7 +42,7 @@ func Test_httpJiraApi_DoTransition(t *testing.T) {
 				w.WriteHeader(200)
 				w.Write([]byte(``)) //nolint:errcheck
 			})
-			if err := api.DoTransition(tt.args.issueId, tt.args.transition); (err != nil) != tt.wantErr {
+			if err := api.DoTransition(tt.args.issueId, tt.args.transition, nil); (err != nil) != tt.wantErr {
 				t.Errorf("DoTransition() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -93,3 +96,55 @@ func Test_httpJiraApi_FindTransitions(t *testing.T) {
 		})
 	}
 }
+
+func Test_httpJiraApi_FindTransitions_withScreenFields(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "transitions.fields", r.URL.Query().Get("expand"))
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+{
+    "transitions": [
+        {
+            "id": "41", "name": "Done", "hasScreen": true,
+            "to": {"id": "5", "name": "Done"},
+            "fields": {
+                "resolution": {"required": true, "name": "Resolution", "schema": {"type": "resolution", "system": "resolution"},
+                    "operations": ["set"], "allowedValues": [{"id": "1", "name": "Done"}]}
+            }
+        },
+        {"id": "21", "name": "Start progress", "hasScreen": false, "to": {"id": "3", "name": "In Progress"}}
+    ]
+}`)) //nolint:errcheck
+	})
+
+	// when
+	transitions, err := api.FindTransitions("ABC-123")
+
+	// then
+	assert.Nil(t, err)
+	assert.True(t, transitions[0].HasScreen)
+	assert.True(t, transitions[0].RequiresInput())
+	assert.Equal(t, "Done", transitions[0].Fields["resolution"].AllowedValues[0].Label())
+	assert.False(t, transitions[1].RequiresInput())
+}
+
+func Test_httpJiraApi_DoTransition_withInput(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(204)
+	})
+
+	// when
+	err := api.DoTransition("ABC-123", &IssueTransition{Id: "41"}, &TransitionInput{
+		Fields: map[string]any{"resolution": map[string]string{"id": "1"}},
+		Update: map[string]any{"comment": []any{map[string]any{"add": map[string]any{"body": "done"}}}},
+	})
+
+	// then
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"transition": {"id": "41"}, "fields": {"resolution": {"id": "1"}}, "update": {"comment": [{"add": {"body": "done"}}]}}`, body)
+}
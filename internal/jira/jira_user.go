@@ -21,7 +21,8 @@ type User struct {
 }
 
 const (
-	FindUser = "/rest/api/2/user/assignable/search"
+	FindUser   = "/rest/api/2/user/assignable/search"
+	MyselfPath = "/rest/api/2/myself"
 )
 
 var ErrUserSearchDeserialize = errors.New("cannot deserialize jira user search response")
@@ -42,10 +43,10 @@ func (api *httpApi) FindUsersWithQuery(project string, query string) ([]User, er
 		Project:    project,
 		MaxResults: 10000,
 	}
-	if query != "" && !api.IsJiraServer() {
+	if query != "" && api.Capabilities().SupportsAccountId() {
 		queryParams.Query = &query
 	}
-	if query != "" && api.IsJiraServer() {
+	if query != "" && !api.Capabilities().SupportsAccountId() {
 		queryParams.Username = &query
 	}
 	response, err := api.jiraRequest("GET", FindUser, queryParams, nil)
@@ -59,3 +60,30 @@ func (api *httpApi) FindUsersWithQuery(project string, query string) ([]User, er
 	}
 	return users, nil
 }
+
+// GetMyself returns the user the api is authenticated as.
+func (api *httpApi) GetMyself() (*User, error) {
+	response, err := api.jiraRequest("GET", MyselfPath, &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if err := json.Unmarshal(response, &user); err != nil {
+		return nil, ErrUserSearchDeserialize
+	}
+	return &user, nil
+}
+
+// SameAs tells whether both are the same user: by account id on Cloud, by
+// key or name on-premise.
+func (u *User) SameAs(other *User) bool {
+	switch {
+	case u == nil || other == nil:
+		return false
+	case u.AccountId != "" || other.AccountId != "":
+		return u.AccountId == other.AccountId
+	case u.Key != "" || other.Key != "":
+		return u.Key == other.Key
+	}
+	return u.Name != "" && u.Name == other.Name
+}
@@ -120,3 +120,41 @@ func Test_httpJiraApi_FindUsers_returnError(t *testing.T) {
 		})
 	}
 }
+
+func Test_httpJiraApi_GetMyself(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/myself", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"accountId": "712020:alice", "displayName": "Alice Andersen", "active": true}`)) //nolint:errcheck
+	})
+
+	// when
+	user, err := api.GetMyself()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "712020:alice", user.AccountId)
+	assert.Equal(t, "Alice Andersen", user.DisplayName)
+}
+
+func Test_User_SameAs(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  *User
+		other *User
+		want  bool
+	}{
+		{"should compare account ids", &User{AccountId: "1", Name: "alice"}, &User{AccountId: "1"}, true},
+		{"should not match other account ids", &User{AccountId: "1", Name: "alice"}, &User{AccountId: "2", Name: "alice"}, false},
+		{"should compare keys on-premise", &User{Key: "JIRAUSER1", Name: "alice"}, &User{Key: "JIRAUSER1", Name: "alice.renamed"}, true},
+		{"should compare names without keys", &User{Name: "alice"}, &User{Name: "alice"}, true},
+		{"should not match unknown users", &User{}, &User{}, false},
+		{"should not match nil", &User{AccountId: "1"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.user.SameAs(tt.other))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mk-5/fjira/internal/app"
+)
+
+//
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-worklogs/
+//
+
+const (
+	WorklogPath = "/rest/api/2/issue/%s/worklog"
+)
+
+// ErrInvalidTimeSpent is returned for time spent that isn't like "1h 30m".
+var ErrInvalidTimeSpent = errors.New("time spent should be like 1w 2d 3h 45m")
+
+var (
+	timeSpentRegexp     = regexp.MustCompile(`^(\d+[wdhm]\s*)+$`)
+	timeSpentPartRegexp = regexp.MustCompile(`\d+[wdhm]`)
+)
+
+// Worklog is time logged on an issue. TimeSpent reads like "1h 30m";
+// Started is a timestamp like Created of a Comment.
+type Worklog struct {
+	Id               string `json:"id"`
+	Author           User   `json:"author"`
+	Comment          string `json:"comment"`
+	Started          string `json:"started"`
+	TimeSpent        string `json:"timeSpent"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds"`
+}
+
+type worklogsResponse struct {
+	Worklogs []Worklog `json:"worklogs"`
+}
+
+type addWorklogRequestBody struct {
+	TimeSpent string `json:"timeSpent"`
+	Started   string `json:"started"`
+}
+
+// FindWorklogs returns the time logged on the issue, oldest first.
+func (api *httpApi) FindWorklogs(issueKey string) ([]Worklog, error) {
+	body, err := api.jiraRequest("GET", fmt.Sprintf(WorklogPath, issueKey), &nilParams{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response worklogsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, ErrSearchDeserialize
+	}
+	return response.Worklogs, nil
+}
+
+// AddWorklog logs timeSpent - like "1h 30m", Jira counts days and weeks as
+// configured for the instance - on the issue, as work started at started.
+func (api *httpApi) AddWorklog(issueKey string, timeSpent string, started time.Time) error {
+	timeSpent, err := NormalizeTimeSpent(timeSpent)
+	if err != nil {
+		return err
+	}
+	jsonBody, err := json.Marshal(&addWorklogRequestBody{
+		TimeSpent: timeSpent,
+		Started:   started.Format(app.JiraTimestampLayout),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = api.jiraRequest("POST", fmt.Sprintf(WorklogPath, issueKey), &nilParams{}, strings.NewReader(string(jsonBody)))
+	return err
+}
+
+// NormalizeTimeSpent checks time spent is like "1h 30m", and tidies it up:
+// "1h30m " is "1h 30m".
+func NormalizeTimeSpent(timeSpent string) (string, error) {
+	timeSpent = strings.ToLower(strings.TrimSpace(timeSpent))
+	if !timeSpentRegexp.MatchString(timeSpent) {
+		return "", ErrInvalidTimeSpent
+	}
+	return strings.Join(timeSpentPartRegexp.FindAllString(timeSpent, -1), " "), nil
+}
+
+// FormatTimeSpent formats a duration the way Jira reads time spent, rounded up
+// to a minute: 90m30s is "1h 31m".
+func FormatTimeSpent(d time.Duration) string {
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	minutes = max(minutes, 1)
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,97 @@
+package jira
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_httpJiraApi_FindWorklogs(t *testing.T) {
+	// given
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue/ABC-1/worklog", r.URL.Path)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`
+{
+    "startAt": 0, "maxResults": 1, "total": 1,
+    "worklogs": [
+        {"id": "100", "author": {"accountId": "acc-1", "displayName": "Jane Doe"}, "comment": "review",
+         "started": "2024-03-04T09:30:00.000+0000", "timeSpent": "1h 30m", "timeSpentSeconds": 5400}
+    ]
+}`)) //nolint:errcheck
+	})
+
+	// when
+	worklogs, err := api.FindWorklogs("ABC-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []Worklog{{Id: "100", Author: User{AccountId: "acc-1", DisplayName: "Jane Doe"}, Comment: "review",
+		Started: "2024-03-04T09:30:00.000+0000", TimeSpent: "1h 30m", TimeSpentSeconds: 5400}}, worklogs)
+}
+
+func Test_httpJiraApi_AddWorklog(t *testing.T) {
+	// given
+	var body string
+	api := NewJiraApiMock(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/rest/api/2/issue/ABC-1/worklog", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+		_, _ = w.Write([]byte(`{"id": "101"}`)) //nolint:errcheck
+	})
+	started := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	// when
+	err := api.AddWorklog("ABC-1", " 1H30m", started)
+	invalidErr := api.AddWorklog("ABC-1", "90 minutes", started)
+
+	// then
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"timeSpent": "1h 30m", "started": "2024-03-04T09:30:00.000+0000"}`, body)
+	assert.Equal(t, ErrInvalidTimeSpent, invalidErr)
+}
+
+func Test_NormalizeTimeSpent(t *testing.T) {
+	tests := []struct {
+		timeSpent string
+		want      string
+		wantErr   error
+	}{
+		{"1h 30m", "1h 30m", nil},
+		{"1w2d 4h", "1w 2d 4h", nil},
+		{" 45M ", "45m", nil},
+		{"", "", ErrInvalidTimeSpent},
+		{"1.5h", "", ErrInvalidTimeSpent},
+		{"30", "", ErrInvalidTimeSpent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timeSpent, func(t *testing.T) {
+			got, err := NormalizeTimeSpent(tt.timeSpent)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_FormatTimeSpent(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "1m"},
+		{20 * time.Second, "1m"},
+		{45 * time.Minute, "45m"},
+		{2 * time.Hour, "2h"},
+		{90*time.Minute + 30*time.Second, "1h 31m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatTimeSpent(tt.d))
+		})
+	}
+}
@@ -3,20 +3,104 @@ package jira
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/mk-5/fjira/internal/app"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 )
 
 var (
 	defaultHttpTransport = createHttpTransport()
+	ErrCaFileInvalid     = errors.New("no certificates found in the CA file")
+	ErrClientCertInvalid = errors.New("certFile and keyFile need to be set together")
 )
 
+// ConnectionConfig tunes how one workspace connects to Jira, e.g. a Data
+// Center behind a mutual-TLS gateway or a proxy. The zero value is the
+// default transport.
+type ConnectionConfig struct {
+	// CaFile is a PEM bundle trusted next to the system CAs (and
+	// SSL_CERT_FILE, which still applies to every workspace).
+	CaFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	// CertFile and KeyFile are the PEM client certificate and its key, sent
+	// when the server asks for one.
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	// InsecureSkipVerify accepts any server certificate. For debugging only.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	// ProxyUrl replaces the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment, e.g.
+	// http://proxy.corp:3128.
+	ProxyUrl string `json:"proxyUrl,omitempty" yaml:"proxyUrl,omitempty"`
+}
+
 func createHttpTransport() *http.Transport {
-	t := &http.Transport{
+	t, err := NewHttpTransport(ConnectionConfig{})
+	if err != nil {
+		app.Error(fmt.Sprintf("Cannot read file for SSL_CERT_FILE. %s", err.Error()))
+		app.GetApp().Quit()
+		return newBaseTransport()
+	}
+	return t
+}
+
+// NewHttpTransport builds a transport of its own for one workspace, so its
+// certificates and proxy don't leak into others.
+func NewHttpTransport(cfg ConnectionConfig) (*http.Transport, error) {
+	t := newBaseTransport()
+	if cfg.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(cfg.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxyUrl: %w", err)
+		}
+		t.Proxy = http.ProxyURL(proxyUrl)
+	}
+	envCaFile := os.Getenv("SSL_CERT_FILE")
+	if envCaFile == "" && cfg.CaFile == "" && cfg.CertFile == "" && cfg.KeyFile == "" && !cfg.InsecureSkipVerify {
+		return t, nil
+	}
+	t.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec
+	}
+	if envCaFile != "" || cfg.CaFile != "" {
+		rootCAs := systemCertPool()
+		if envCaFile != "" {
+			data, err := os.ReadFile(envCaFile)
+			if err != nil {
+				return nil, err
+			}
+			rootCAs.AppendCertsFromPEM(data)
+		}
+		if cfg.CaFile != "" {
+			data, err := os.ReadFile(cfg.CaFile)
+			if err != nil {
+				return nil, err
+			}
+			if !rootCAs.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("%w: %s", ErrCaFileInvalid, cfg.CaFile)
+			}
+		}
+		t.TLSClientConfig.RootCAs = rootCAs
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, ErrClientCertInvalid
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	return t, nil
+}
+
+func newBaseTransport() *http.Transport {
+	return &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second,
@@ -28,21 +112,6 @@ func createHttpTransport() *http.Transport {
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
-	if f := os.Getenv("SSL_CERT_FILE"); f != "" {
-		t.TLSClientConfig = &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		}
-		data, err := os.ReadFile(f)
-		if err != nil {
-			app.Error(fmt.Sprintf("Cannot read file for SSL_CERT_FILE. %s", err.Error()))
-			app.GetApp().Quit()
-			return t
-		}
-		rootCAs := systemCertPool()
-		rootCAs.AppendCertsFromPEM(data)
-		t.TLSClientConfig.RootCAs = rootCAs
-	}
-	return t
 }
 
 func systemCertPool() *x509.CertPool {
@@ -0,0 +1,154 @@
+package jira
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeClientCert creates a self-signed client certificate and returns the
+// paths of its PEM files together with a pool trusting it.
+func writeClientCert(t *testing.T) (string, string, *x509.CertPool) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fjira"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+// writeServerCa saves the certificate of a tls httptest server as a CA bundle.
+func writeServerCa(t *testing.T, server *httptest.Server) string {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	_ = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+	return caFile
+}
+
+func newServerInfoServer(clientCAs *x509.CertPool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"deploymentType":"Server","version":"9.12.0"}`))
+	}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	}
+	server.StartTLS()
+	return server
+}
+
+func Test_NewApiWithConfig_should_connect_with_workspace_tls(t *testing.T) {
+	certFile, keyFile, clientCAs := writeClientCert(t)
+	plain := newServerInfoServer(nil)
+	defer plain.Close()
+	mutual := newServerInfoServer(clientCAs)
+	defer mutual.Close()
+	tests := []struct {
+		name       string
+		server     *httptest.Server
+		connection ConnectionConfig
+		wantErr    bool
+	}{
+		{"should not trust unknown CA", plain, ConnectionConfig{}, true},
+		{"should trust workspace CA", plain, ConnectionConfig{CaFile: writeServerCa(t, plain)}, false},
+		{"should skip verify when asked to", plain, ConnectionConfig{InsecureSkipVerify: true}, false},
+		{"should fail mutual tls without client cert", mutual, ConnectionConfig{CaFile: writeServerCa(t, mutual)}, true},
+		{"should send client cert", mutual, ConnectionConfig{CaFile: writeServerCa(t, mutual), CertFile: certFile, KeyFile: keyFile}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			api, err := NewApiWithConfig(ApiConfig{ApiUrl: tt.server.URL, Username: "u", Token: "t", Connection: tt.connection})
+			assert.Nil(t, err)
+
+			// when
+			info, err := api.GetServerInfo()
+
+			// then
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, DeploymentServer, info.DeploymentType)
+		})
+	}
+}
+
+func Test_NewHttpTransport_should_use_workspace_proxy(t *testing.T) {
+	// given
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`{"deploymentType":"Server","version":"9.12.0"}`))
+	}))
+	defer proxy.Close()
+	api, _ := NewApiWithConfig(ApiConfig{ApiUrl: "http://jira.internal", Username: "u", Token: "t", Connection: ConnectionConfig{ProxyUrl: proxy.URL}})
+
+	// when
+	_, err := api.GetServerInfo()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, "http://jira.internal/rest/api/2/serverInfo", proxied)
+}
+
+func Test_NewHttpTransport_should_reject_invalid_config(t *testing.T) {
+	certFile, _, _ := writeClientCert(t)
+	notPem := filepath.Join(t.TempDir(), "ca.pem")
+	_ = os.WriteFile(notPem, []byte("not a certificate"), 0600)
+	tests := []struct {
+		name       string
+		connection ConnectionConfig
+		wantErr    error
+	}{
+		{"should require key with cert", ConnectionConfig{CertFile: certFile}, ErrClientCertInvalid},
+		{"should require certificates in CA file", ConnectionConfig{CaFile: notPem}, ErrCaFileInvalid},
+		{"should fail for missing CA file", ConnectionConfig{CaFile: notPem + ".missing"}, os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHttpTransport(tt.connection)
+
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
+
+func Test_NewApiWithConfig_should_not_build_the_connection_for_a_given_transport(t *testing.T) {
+	// given
+	certFile, _, _ := writeClientCert(t)
+
+	// when
+	_, err := NewApiWithConfig(ApiConfig{ApiUrl: "http://jira.internal", Username: "u", Token: "t", Connection: ConnectionConfig{CertFile: certFile}, Transport: http.DefaultTransport})
+
+	// then
+	assert.Nil(t, err)
+}
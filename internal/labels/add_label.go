@@ -1,6 +1,8 @@
 package labels
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
@@ -68,7 +70,7 @@ func (view *addLabelView) HandleKeyEvent(ev *tcell.EventKey) {
 func (view *addLabelView) startLabelSearching() {
 	app.GetApp().ClearNow()
 	app.GetApp().Loading(true)
-	view.fuzzyFind = app.NewFuzzyFindWithProvider(ui.MessageLabelFuzzyFind, view.findLabels)
+	view.fuzzyFind = app.NewFuzzyFindWithContextProvider(ui.MessageLabelFuzzyFind, view.findLabels)
 	view.fuzzyFind.MarginBottom = 0
 	app.GetApp().Loading(false)
 	if match := <-view.fuzzyFind.Complete; true {
@@ -82,10 +84,12 @@ func (view *addLabelView) startLabelSearching() {
 	}
 }
 
-func (view *addLabelView) findLabels(query string) []string {
+func (view *addLabelView) findLabels(ctx context.Context, query string) []string {
 	app.GetApp().LoadingWithText(true, ui.MessageSearchLabelsLoading)
-	labels, err := view.api.FindLabels(view.issue, query)
-	if err != nil {
+	loadingCtx, cancel := app.GetApp().LoadingContextFrom(ctx)
+	labels, err := view.api.WithContext(loadingCtx).FindLabels(view.issue, query)
+	cancel()
+	if err != nil && !errors.Is(err, context.Canceled) {
 		app.Error(err.Error())
 	}
 	app.GetApp().Loading(false)
@@ -107,7 +111,7 @@ func (view *addLabelView) doAddLabel(issue *jira.Issue, label string) {
 	err := view.api.AddLabel(issue.Key, label)
 	app.GetApp().Loading(false)
 	if err != nil {
-		app.Error(fmt.Sprintf(ui.MessageCannotAddLabel, label, issue.Key, err))
+		app.Error(fmt.Sprintf(ui.MessageCannotAddLabel, label, issue.Key, ui.JiraErrorReason(err)))
 		return
 	}
 	app.Success(fmt.Sprintf(ui.MessageAddLabelSuccess, label, issue.Key))
@@ -0,0 +1,67 @@
+package markdown
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+// adfPanelTypes maps the callout names - GitHub's and the wiki panel macros -
+// to ADF panel types. Others are "info".
+var adfPanelTypes = map[string]string{
+	"info": "info", "note": "note", "warning": "warning", "success": "success", "error": "error",
+	"tip": "success", "caution": "error", "important": "note",
+}
+
+// ToAdf converts Markdown to an Atlassian Document Format document, the
+// body of a comment or description for Jira Cloud's v3 api.
+func ToAdf(md string) (json.RawMessage, error) {
+	doc := parser{}.parse(md)
+	ids := 0
+	toAdf(doc, &ids)
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		*adf.Node
+	}{Version: 1, Node: doc})
+}
+
+// toAdf turns the parser's own nodes into ADF ones, and fills in what ADF
+// requires: task ids, the content of empty list items, ...
+func toAdf(n *adf.Node, ids *int) {
+	switch n.Type {
+	case "image":
+		src, _ := n.Attrs["src"].(string)
+		text, _ := n.Attrs["alt"].(string)
+		if text == "" {
+			text = src
+		}
+		*n = adf.Node{Type: "text", Text: text, Marks: with(n.Marks, adf.Mark{Type: "link", Attrs: map[string]any{"href": src}})}
+	case "panel":
+		callout, _ := n.Attrs["callout"].(string)
+		panelType, ok := adfPanelTypes[callout]
+		if !ok {
+			panelType = "info"
+		}
+		if title, _ := n.Attrs["title"].(string); title != "" {
+			heading := &adf.Node{Type: "paragraph", Content: []*adf.Node{{Type: "text", Text: title, Marks: []adf.Mark{{Type: "strong"}}}}}
+			n.Content = append([]*adf.Node{heading}, n.Content...)
+		}
+		n.Attrs = map[string]any{"panelType": panelType}
+	case "taskList", "taskItem":
+		*ids++
+		if n.Attrs == nil {
+			n.Attrs = map[string]any{}
+		}
+		n.Attrs["localId"] = "task-" + strconv.Itoa(*ids)
+	}
+	switch n.Type {
+	case "listItem", "blockquote", "panel", "tableCell", "tableHeader":
+		if len(n.Content) == 0 {
+			n.Content = []*adf.Node{{Type: "paragraph"}}
+		}
+	}
+	for _, c := range n.Content {
+		toAdf(c, ids)
+	}
+}
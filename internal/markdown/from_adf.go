@@ -0,0 +1,251 @@
+package markdown
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+// markdownMarks are the marks Markdown has a syntax for; text with others,
+// e.g. a textColor, is kept as raw ADF.
+var markdownMarks = map[string]bool{"strong": true, "em": true, "strike": true, "underline": true, "code": true, "link": true}
+
+// FromAdf converts a document to Markdown that ToAdf converts back: a
+// description opens for editing as Markdown. Nodes Markdown has no syntax
+// for - media, expands, statuses, ... - are kept as "<!-- adf:{...} -->"
+// comments.
+func FromAdf(doc *adf.Node) string {
+	if doc == nil {
+		return ""
+	}
+	if doc.Type != "doc" {
+		return mdBlock(doc)
+	}
+	return mdBlocks(doc.Content, "\n\n")
+}
+
+func mdBlocks(nodes []*adf.Node, separator string) string {
+	blocks := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		blocks = append(blocks, mdBlock(n))
+	}
+	return strings.Join(blocks, separator)
+}
+
+func mdBlock(n *adf.Node) string {
+	switch n.Type {
+	case "paragraph":
+		return escapeLineStarts(mdInline(n.Content, "\n", false))
+	case "heading":
+		level, _ := strconv.Atoi(attr(n, "level"))
+		return strings.Repeat("#", min(max(level, 1), 6)) + " " + mdInline(n.Content, " ", false)
+	case "bulletList", "orderedList", "taskList":
+		return mdList(n)
+	case "codeBlock":
+		code := textOf(n)
+		fence := codeFence(code, 3)
+		return fence + attr(n, "language") + "\n" + code + "\n" + fence
+	case "blockquote":
+		return prefixLines(mdBlocks(n.Content, "\n\n"), "> ")
+	case "panel":
+		callout := "[!" + attr(n, "panelType") + "]"
+		if _, ok := adfPanelTypes[attr(n, "panelType")]; !ok {
+			callout = "[!info]"
+		}
+		if len(n.Content) == 0 {
+			return "> " + callout
+		}
+		return prefixLines(callout+"\n"+mdBlocks(n.Content, "\n\n"), "> ")
+	case "rule":
+		return "---"
+	case "table":
+		if isSimpleTable(n) {
+			return mdTable(n)
+		}
+	}
+	return raw(n)
+}
+
+// mdList writes the items of a list, with their content indented under them.
+func mdList(list *adf.Node) string {
+	number, _ := strconv.Atoi(attr(list, "order"))
+	number = max(number, 1)
+	items := make([]string, 0, len(list.Content))
+	for _, item := range list.Content {
+		switch {
+		case item.Type == "taskItem":
+			marker := "- [ ] "
+			if attr(item, "state") == "DONE" {
+				marker = "- [x] "
+			}
+			items = append(items, marker+escapeLineStarts(mdInline(item.Content, "\n", false)))
+		case item.Type == "taskList":
+			// a task list nested in the task above
+			items = append(items, prefixLines(mdList(item), "  "))
+		case list.Type == "orderedList":
+			marker := strconv.Itoa(number) + ". "
+			number++
+			items = append(items, hangLines(mdItem(item), marker))
+		default:
+			items = append(items, hangLines(mdItem(item), "- "))
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// mdItem writes the content of a list item: a nested list right below the
+// text, other blocks a blank line apart.
+func mdItem(item *adf.Node) string {
+	var b strings.Builder
+	for i, block := range item.Content {
+		if i > 0 {
+			switch block.Type {
+			case "bulletList", "orderedList", "taskList":
+				b.WriteString("\n")
+			default:
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(mdBlock(block))
+	}
+	return b.String()
+}
+
+// isSimpleTable tells whether a table can be written in Markdown: a heading
+// row, and cells of a paragraph each.
+func isSimpleTable(table *adf.Node) bool {
+	if len(table.Content) == 0 {
+		return false
+	}
+	columns := len(table.Content[0].Content)
+	for i, row := range table.Content {
+		if len(row.Content) != columns {
+			return false
+		}
+		for _, cell := range row.Content {
+			if (cell.Type == "tableHeader") != (i == 0) || len(cell.Content) > 1 ||
+				len(cell.Content) == 1 && cell.Content[0].Type != "paragraph" ||
+				attr(cell, "colspan") != "" && attr(cell, "colspan") != "1" ||
+				attr(cell, "rowspan") != "" && attr(cell, "rowspan") != "1" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func mdTable(table *adf.Node) string {
+	lines := make([]string, 0, len(table.Content)+1)
+	for i, row := range table.Content {
+		var b strings.Builder
+		for _, cell := range row.Content {
+			text := ""
+			if len(cell.Content) == 1 {
+				text = mdInline(cell.Content[0].Content, "<br>", true)
+			}
+			b.WriteString("| " + text + " ")
+		}
+		lines = append(lines, b.String()+"|")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(row.Content)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mdInline writes the inline nodes of a paragraph, heading, ... cell escapes
+// the pipes of table cells.
+func mdInline(nodes []*adf.Node, lineBreak string, cell bool) string {
+	kept := make([]*adf.Node, len(nodes))
+	for i, n := range nodes {
+		kept[i] = n
+		for _, m := range n.Marks {
+			if !markdownMarks[m.Type] {
+				kept[i] = &adf.Node{Type: "raw", Content: []*adf.Node{n}}
+				break
+			}
+		}
+	}
+	return writeInline(kept, nil, func(n *adf.Node) string {
+		switch n.Type {
+		case "text":
+			if containsMark(n.Marks, adf.Mark{Type: "code"}) {
+				return n.Text
+			}
+			text := escapeText(n.Text)
+			if cell {
+				text = strings.ReplaceAll(text, "|", `\|`)
+			}
+			return text
+		case "hardBreak":
+			return lineBreak
+		case "mention":
+			text := attr(n, "text")
+			if text == "" {
+				text = "@" + attr(n, "id")
+			}
+			return "[" + escapeText(text) + "](accountid:" + attr(n, "id") + ")"
+		case "emoji":
+			if text := attr(n, "text"); text != "" {
+				return text
+			}
+			return attr(n, "shortName")
+		case "inlineCard":
+			if url := attr(n, "url"); url != "" {
+				return "<" + url + ">"
+			}
+		case "raw":
+			return raw(n.Content[0])
+		}
+		return raw(n)
+	}, func(m adf.Mark, text string) string {
+		switch m.Type {
+		case "strong":
+			return "**" + text + "**"
+		case "em":
+			return "_" + text + "_"
+		case "strike":
+			return "~~" + text + "~~"
+		case "underline":
+			return "<u>" + text + "</u>"
+		case "code":
+			return codeSpan(text)
+		case "link":
+			href, _ := m.Attrs["href"].(string)
+			if text == escapeText(href) && urlRe.MatchString(href) {
+				return href
+			}
+			return "[" + text + "](" + href + ")"
+		}
+		return text
+	})
+}
+
+// escapeText escapes what Markdown would read as markup in text.
+func escapeText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && isPunct(text[i+1]),
+			c == '`', c == '*', c == '[',
+			c == '~' && i+1 < len(text) && text[i+1] == '~',
+			c == '<' && i+1 < len(text) && (isLetter(text[i+1]) || strings.IndexByte("/!", text[i+1]) >= 0),
+			c == '_' && !(wordBefore(text, i) && wordAfter(text, i+1)):
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// raw writes n as a comment ToAdf reads back as it is.
+func raw(n *adf.Node) string {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return adf.PlainText(n)
+	}
+	return "<!-- adf:" + string(data) + " -->"
+}
@@ -0,0 +1,296 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mk-5/fjira/internal/wiki"
+)
+
+var (
+	wikiHeadingRe = regexp.MustCompile(`^\s*h([1-6])\.\s+(.*)$`)
+	wikiQuoteRe   = regexp.MustCompile(`^\s*bq\.\s+(.*)$`)
+	wikiListRe    = regexp.MustCompile(`^\s*([*#]+|-)\s+(.*)$`)
+	wikiRuleRe    = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	wikiMacroRe   = regexp.MustCompile(`^[ \t]*\{(code|noformat|quote|panel|info|note|warning|tip)(?::([^}]*))?\}`)
+	wikiImageRe   = regexp.MustCompile(`^!([^\s!|][^!|\n]*)(\|[^!\n]*)?!`)
+	wikiColorRe   = regexp.MustCompile(`^\{color:[^}]+\}`)
+	wikiUrlRe     = regexp.MustCompile(`^(?:https?|ftp)://[^\s\]|<>"]+`)
+)
+
+// FromWiki converts Jira wiki markup to Markdown that ToWiki converts back:
+// a description opens for editing as Markdown. Markup Markdown has no syntax
+// for - [~user], {color}, ^superscript^, ... - is left as it is.
+func FromWiki(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var blocks []string
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		if strings.TrimSpace(line) == "" {
+			text = rest
+			continue
+		}
+		if m := wikiMacroRe.FindStringSubmatch(text); m != nil {
+			var block string
+			block, text = fromWikiMacro(m[1], m[2], text[len(m[0]):])
+			blocks = append(blocks, block)
+			continue
+		}
+		switch {
+		case wikiHeadingRe.MatchString(line):
+			m := wikiHeadingRe.FindStringSubmatch(line)
+			level, _ := strconv.Atoi(m[1])
+			blocks = append(blocks, strings.Repeat("#", level)+" "+fromWikiInline(m[2]))
+			text = rest
+		case wikiQuoteRe.MatchString(line):
+			blocks = append(blocks, "> "+fromWikiInline(wikiQuoteRe.FindStringSubmatch(line)[1]))
+			text = rest
+		case wikiRuleRe.MatchString(line):
+			blocks = append(blocks, "---")
+			text = rest
+		case wikiListRe.MatchString(line):
+			var items []string
+			items, text = wikiLines(text, wikiListRe.MatchString)
+			blocks = append(blocks, fromWikiList(items))
+		case strings.HasPrefix(strings.TrimSpace(line), "|"):
+			var rows []string
+			rows, text = wikiLines(text, func(l string) bool { return strings.HasPrefix(strings.TrimSpace(l), "|") })
+			blocks = append(blocks, fromWikiTable(rows))
+		default:
+			var paragraph []string
+			paragraph, text = wikiLines(text, func(l string) bool {
+				return strings.TrimSpace(l) != "" && !startsWikiBlock(l)
+			})
+			for i, l := range paragraph {
+				paragraph[i] = escapeLineStarts(fromWikiInline(strings.TrimSpace(l)))
+			}
+			blocks = append(blocks, strings.Join(paragraph, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// fromWikiMacro converts a {code}, {quote}, {panel}, ... macro whose opening
+// tag has been read, and returns the text after its closing tag.
+func fromWikiMacro(name, params, text string) (string, string) {
+	body, rest, found := strings.Cut(text, "{"+name+"}")
+	if !found {
+		body, rest = text, ""
+	}
+	switch name {
+	case "code", "noformat":
+		body = strings.TrimSuffix(strings.TrimPrefix(body, "\n"), "\n")
+		fence := codeFence(body, 3)
+		language := ""
+		if name == "code" {
+			language = wiki.CodeLanguage(params)
+		}
+		return fence + language + "\n" + body + "\n" + fence, rest
+	case "quote":
+		return prefixLines(FromWiki(body), "> "), rest
+	}
+	callout := "> [!" + name + "]"
+	if title := wiki.MacroParam(params, "title"); title != "" {
+		callout += " " + title
+	}
+	if body = FromWiki(body); body != "" {
+		callout += "\n" + prefixLines(body, "> ")
+	}
+	return callout, rest
+}
+
+// fromWikiList converts "* a", "*# nested" items to Markdown list items,
+// indented under the items they're nested in.
+func fromWikiList(items []string) string {
+	lines := make([]string, 0, len(items))
+	// indents are the marker widths of the items the current one is nested
+	// in, numbers the counters of the numbered levels
+	var indents, numbers []int
+	previous := ""
+	for _, item := range items {
+		m := wikiListRe.FindStringSubmatch(item)
+		marks, text := m[1], m[2]
+		depth := len(marks)
+		for len(indents) < depth-1 {
+			indents = append(indents, 2)
+		}
+		indents = indents[:depth-1]
+		for len(numbers) < depth {
+			numbers = append(numbers, 0)
+		}
+		numbers = numbers[:depth]
+		if len(previous) < depth || previous[:depth] != marks {
+			numbers[depth-1] = 0
+		}
+		numbers[depth-1]++
+		previous = marks
+
+		marker := "- "
+		if marks[depth-1] == '#' {
+			marker = strconv.Itoa(numbers[depth-1]) + ". "
+		}
+		indent := 0
+		for _, w := range indents {
+			indent += w
+		}
+		indents = append(indents, len(marker))
+		lines = append(lines, strings.Repeat(" ", indent)+marker+fromWikiInline(text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fromWikiTable converts a table with a heading row to a Markdown one. Other
+// tables can't be written in Markdown, and keep their wiki rows.
+func fromWikiTable(rows []string) string {
+	cells := make([][]wiki.TableCell, len(rows))
+	simple := true
+	for i, row := range rows {
+		cells[i] = wiki.SplitRow(strings.TrimSpace(row))
+		for _, c := range cells[i] {
+			simple = simple && c.Header == (i == 0)
+		}
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range cells {
+		var b strings.Builder
+		for _, c := range row {
+			text := strings.ReplaceAll(fromWikiInline(c.Text), "\n", "<br>")
+			switch {
+			case !simple && c.Header:
+				b.WriteString("||" + text)
+			case !simple:
+				b.WriteString("|" + text)
+			default:
+				b.WriteString("| " + escapePipes(text) + " ")
+			}
+		}
+		if !simple && len(row) > 0 && row[len(row)-1].Header {
+			b.WriteString("||")
+		} else {
+			b.WriteString("|")
+		}
+		lines = append(lines, b.String())
+		if simple && i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(row)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fromWikiInline converts the markup of a paragraph, a heading, a cell, ...
+func fromWikiInline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if md, n := fromWikiToken(text, i); n > 0 {
+			b.WriteString(md)
+			i += n
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case c == '`', c == '*' && i+1 < len(text) && text[i+1] != ' ',
+			c == '~' && strings.HasPrefix(text[i+1:], "~"),
+			c == '<' && i+1 < len(text) && (isLetter(text[i+1]) || strings.IndexByte("/!", text[i+1]) >= 0),
+			c == '_' && !(wordBefore(text, i) && wordAfter(text, i+1)):
+			// Markdown would read it as markup
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+		i += size
+	}
+	return b.String()
+}
+
+// fromWikiToken converts the markup starting at text[i], and returns its
+// length - 0 when it's plain text.
+func fromWikiToken(text string, i int) (string, int) {
+	rest := text[i:]
+	switch rest[0] {
+	case '\\':
+		if strings.HasPrefix(rest, `\\`) {
+			return "<br>", 2
+		}
+		if len(rest) > 1 {
+			_, size := utf8.DecodeRuneInString(rest[1:])
+			return rest[:1+size], 1 + size
+		}
+	case '{':
+		if strings.HasPrefix(rest, "{{") {
+			if end := strings.Index(rest[2:], "}}"); end > 0 {
+				return codeSpan(rest[2 : 2+end]), end + 4
+			}
+		}
+		if m := wikiColorRe.FindString(rest); m != "" {
+			if body, _, found := strings.Cut(rest[len(m):], "{color}"); found {
+				return m + fromWikiInline(body) + "{color}", len(m) + len(body) + len("{color}")
+			}
+		}
+	case '[':
+		if end := strings.IndexByte(rest, ']'); end > 1 && !strings.Contains(rest[:end], "\n") {
+			label, target, found := strings.Cut(rest[1:end], "|")
+			if !found {
+				target = label
+			}
+			target = strings.TrimSpace(target)
+			if !strings.Contains(target, "://") && !strings.HasPrefix(target, "mailto:") {
+				// mentions, attachments, issues, anchors
+				return rest[:end+1], end + 1
+			}
+			if !found {
+				return "<" + target + ">", end + 1
+			}
+			return "[" + fromWikiInline(label) + "](" + target + ")", end + 1
+		}
+	case '!':
+		if m := wikiImageRe.FindStringSubmatch(rest); m != nil && strings.Contains(m[1], ".") {
+			if m[2] != "" || strings.Contains(m[1], " ") {
+				return m[0], len(m[0])
+			}
+			return "![](" + m[1] + ")", len(m[0])
+		}
+	}
+	if m := wikiUrlRe.FindString(rest); m != "" && !wordBefore(text, i) {
+		url := strings.TrimRight(m, ".,;:!?)")
+		return url, len(url)
+	}
+	if marker, ok := fromWikiMarkers[rest[0]]; ok {
+		if body, n := wiki.Emphasized(text, i); n > 0 {
+			return marker[0] + fromWikiInline(body) + marker[1], n
+		}
+	}
+	return "", 0
+}
+
+// fromWikiMarkers are the Markdown markers of *strong*, _emphasis_, -deleted-
+// and +inserted+.
+var fromWikiMarkers = map[byte][2]string{
+	'*': {"**", "**"},
+	'_': {"_", "_"},
+	'-': {"~~", "~~"},
+	'+': {"<u>", "</u>"},
+}
+
+func startsWikiBlock(line string) bool {
+	return wikiHeadingRe.MatchString(line) || wikiQuoteRe.MatchString(line) || wikiRuleRe.MatchString(line) ||
+		wikiListRe.MatchString(line) || strings.HasPrefix(strings.TrimSpace(line), "|") || wikiMacroRe.MatchString(line)
+}
+
+func wikiLines(text string, match func(string) bool) ([]string, string) {
+	var lines []string
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		if !match(line) {
+			break
+		}
+		lines = append(lines, line)
+		text = rest
+	}
+	return lines, text
+}
+
+func isLetter(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsLetter(rune(c))
+}
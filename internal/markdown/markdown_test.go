@@ -0,0 +1,221 @@
+package markdown
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mk-5/fjira/internal/adf"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ToWiki(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"should keep plain text and line breaks", "one\ntwo\n\n\nthree", "one\ntwo\n\nthree"},
+		{"should convert emphasis",
+			"**bold** _em_ *em* ~~gone~~ <u>under</u> `code`",
+			"*bold* _em_ _em_ -gone- +under+ {{code}}"},
+		{"should nest emphasis", "**bold _both_** and ***all***", "*bold _both_* and *_all_*"},
+		{"should convert links",
+			"[docs](https://example.com/docs) <https://example.com/card> https://example.com/x [@Alice](accountid:5b10) ![shot](screen.png)",
+			"[docs|https://example.com/docs] [https://example.com/card] https://example.com/x [~accountid:5b10] !screen.png!"},
+		{"should convert headings", "## Steps to reproduce", "h2. Steps to reproduce"},
+		{"should convert nested lists",
+			"- one\n  - nested\n  1. first\n  2. second\n- two",
+			"* one\n** nested\n*# first\n*# second\n* two"},
+		{"should convert numbered lists", "1. a\n2. b", "# a\n# b"},
+		{"should convert task lists", "- [ ] todo\n- [x] done", "* todo\n* (/) done"},
+		{"should convert code blocks",
+			"```go\nfunc main() {}\n```\n\n```\n**plain**\n```",
+			"{code:go}\nfunc main() {}\n{code}\n\n{noformat}\n**plain**\n{noformat}"},
+		{"should convert quotes", "> quoted\n\n> a\n>\n> b", "bq. quoted\n\n{quote}\na\n\nb\n{quote}"},
+		{"should convert callouts", "> [!warning] Careful\n> don't", "{warning:title=Careful}\ndon't\n{warning}"},
+		{"should convert rules", "a\n\n---\n\nb", "a\n\n----\n\nb"},
+		{"should convert tables",
+			"| Key | Status |\n| --- | :---: |\n| FJ-1 | **Done** |",
+			"||Key||Status||\n|FJ-1|*Done*|"},
+		{"should keep the escapes wiki markup needs", `1\. not a list, \*stars\* and \<tags>`, `1. not a list, \*stars\* and <tags>`},
+		{"should keep wiki markup", "{color:red}red{color} [~bob] snake_case a * b", "{color:red}red{color} [~bob] snake_case a * b"},
+		{"should keep code as it is", "`a **b** \\*`", `{{a **b** \*}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ToWiki(tt.md))
+		})
+	}
+}
+
+func Test_FromWiki(t *testing.T) {
+	tests := []struct {
+		name string
+		wiki string
+		want string
+	}{
+		{"should convert emphasis", "*bold* _em_ -gone- +under+ {{code}}", "**bold** _em_ ~~gone~~ <u>under</u> `code`"},
+		{"should keep what isn't emphasis", "well-known snake_case a * b", "well-known snake_case a * b"},
+		{"should convert links",
+			"[docs|https://example.com/docs] [https://example.com/card] https://example.com/x !screen.png!",
+			"[docs](https://example.com/docs) <https://example.com/card> https://example.com/x ![](screen.png)"},
+		{"should keep links within jira", "[~bob] [FJ-1] [^log.txt] !screen.png|thumbnail!", "[~bob] [FJ-1] [^log.txt] !screen.png|thumbnail!"},
+		{"should convert headings", "h3. Notes", "### Notes"},
+		{"should convert lists",
+			"* one\n** nested\n*# first\n*# second\n* two",
+			"- one\n  - nested\n  1. first\n  2. second\n- two"},
+		{"should convert code", "{code:java}\nclass A {}\n{code}", "```java\nclass A {}\n```"},
+		{"should convert quotes", "bq. quoted\n{quote}\na\n\nb\n{quote}", "> quoted\n\n> a\n>\n> b"},
+		{"should convert panels", "{info:title=Heads up}\n*read* me\n{info}", "> [!info] Heads up\n> **read** me"},
+		{"should convert tables", "||Key||Status||\n|FJ-1|{{a|b}}|", "| Key | Status |\n| --- | --- |\n| FJ-1 | `a|b` |"},
+		{"should keep tables without a heading row", "|a|*b*|", "|a|**b**|"},
+		{"should escape Markdown", "1. not a list with `ticks` and <b>", "1\\. not a list with \\`ticks\\` and \\<b>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FromWiki(tt.wiki))
+		})
+	}
+}
+
+func Test_FromWiki_should_round_trip(t *testing.T) {
+	// given
+	wiki := "h2. Steps\n\n" +
+		"Run *fjira* with _--offline_, see [the docs|https://example.com/docs].\nThen -wait- {{30s}}.\n\n" +
+		"# first\n## nested\n# second\n\n" +
+		"* [~bob] and [FJ-1]\n*# one\n\n" +
+		"{code:go}\nfmt.Println(\"*\")\n{code}\n\n" +
+		"bq. quoted\n\n" +
+		"{warning:title=Careful}\ndon't\n{warning}\n\n" +
+		"||Key||Status||\n|FJ-1|Done|\n\n" +
+		"----\n\n" +
+		"{color:red}red{color} ^sup^ ??cite??"
+
+	// when
+	got := ToWiki(FromWiki(wiki))
+
+	// then
+	assert.Equal(t, wiki, got)
+}
+
+func Test_ToAdf(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"should convert paragraphs with marks",
+			"**bold** _em_\n`code` [docs](https://example.com)",
+			`[{"type":"paragraph","content":[
+				{"type":"text","text":"bold","marks":[{"type":"strong"}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"em","marks":[{"type":"em"}]},
+				{"type":"hardBreak"},
+				{"type":"text","text":"code","marks":[{"type":"code"}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]}]}]`},
+		{"should unescape", `\*a\* \_b\_`, `[{"type":"paragraph","content":[{"type":"text","text":"*a* _b_"}]}]`},
+		{"should convert mentions and cards",
+			"[@Alice](accountid:5b10) <https://example.com>",
+			`[{"type":"paragraph","content":[
+				{"type":"mention","attrs":{"id":"5b10","text":"@Alice"}},
+				{"type":"text","text":" "},
+				{"type":"inlineCard","attrs":{"url":"https://example.com"}}]}]`},
+		{"should convert headings and rules", "# Title\n\n***",
+			`[{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"Title"}]},{"type":"rule"}]`},
+		{"should convert lists",
+			"3. a\n   - b",
+			`[{"type":"orderedList","attrs":{"order":3},"content":[{"type":"listItem","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"a"}]},
+				{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}]}]}]}]`},
+		{"should convert task lists",
+			"- [x] done\n- [ ] todo",
+			`[{"type":"taskList","attrs":{"localId":"task-1"},"content":[
+				{"type":"taskItem","attrs":{"localId":"task-2","state":"DONE"},"content":[{"type":"text","text":"done"}]},
+				{"type":"taskItem","attrs":{"localId":"task-3","state":"TODO"},"content":[{"type":"text","text":"todo"}]}]}]`},
+		{"should convert code blocks", "```go\na := `b`\n```",
+			"[{\"type\":\"codeBlock\",\"attrs\":{\"language\":\"go\"},\"content\":[{\"type\":\"text\",\"text\":\"a := `b`\"}]}]"},
+		{"should convert callouts", "> [!TIP] Hint\n> text",
+			`[{"type":"panel","attrs":{"panelType":"success"},"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"Hint","marks":[{"type":"strong"}]}]},
+				{"type":"paragraph","content":[{"type":"text","text":"text"}]}]}]`},
+		{"should convert tables", "| a |\n| - |\n| b<br>c |",
+			`[{"type":"table","content":[
+				{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]}]},
+				{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"b"},{"type":"hardBreak"},{"type":"text","text":"c"}]}]}]}]}]`},
+		{"should restore raw nodes", `<!-- adf:{"type":"rule"} -->`, `[{"type":"rule"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToAdf(tt.md)
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"version":1,"type":"doc","content":`+tt.want+`}`, string(got))
+		})
+	}
+}
+
+func Test_FromAdf(t *testing.T) {
+	// given
+	doc := `{"version":1,"type":"doc","content":[
+		{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Steps"}]},
+		{"type":"paragraph","content":[
+			{"type":"text","text":"Run "},
+			{"type":"text","text":"fjira ","marks":[{"type":"strong"}]},
+			{"type":"text","text":"offline","marks":[{"type":"strong"},{"type":"em"}]},
+			{"type":"text","text":" with "},
+			{"type":"text","text":"--offline","marks":[{"type":"code"}]},
+			{"type":"text","text":", ask "},
+			{"type":"mention","attrs":{"id":"5b10","text":"@Alice"}},
+			{"type":"hardBreak"},
+			{"type":"text","text":"1. *not* a list"}]},
+		{"type":"bulletList","content":[
+			{"type":"listItem","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"one"}]},
+				{"type":"orderedList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"nested"}]}]}]}]}]},
+		{"type":"panel","attrs":{"panelType":"warning"},"content":[{"type":"paragraph","content":[{"type":"text","text":"careful"}]}]},
+		{"type":"paragraph","content":[{"type":"text","text":"red","marks":[{"type":"textColor","attrs":{"color":"#ff0000"}}]}]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"id":"abc","type":"file"}}]}]}`
+	node, _ := adf.Parse([]byte(doc))
+
+	// when
+	md := FromAdf(node)
+
+	// then
+	assert.Equal(t, "## Steps\n\n"+
+		"Run **fjira _offline_** with `--offline`, ask [@Alice](accountid:5b10)\n1\\. \\*not\\* a list\n\n"+
+		"- one\n  1. nested\n\n"+
+		"> [!warning]\n> careful\n\n"+
+		`<!-- adf:{"type":"text","text":"red","marks":[{"type":"textColor","attrs":{"color":"#ff0000"}}]} -->`+"\n\n"+
+		`<!-- adf:{"type":"mediaSingle","content":[{"type":"media","attrs":{"id":"abc","type":"file"}}]} -->`, md)
+	back, err := ToAdf(md)
+	assert.NoError(t, err)
+	assert.JSONEq(t, doc, string(back))
+}
+
+func Test_FromAdf_should_write_tables(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		want  string
+	}{
+		{"should write tables with a heading row",
+			`{"type":"table","content":[
+				{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b"}]}]}]},
+				{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"c"},{"type":"hardBreak"},{"type":"text","text":"d"}]}]}]}]}`,
+			"| a\\|b |\n| --- |\n| c<br>d |"},
+		{"should keep other tables as they are",
+			`{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph"}]}]}]}`,
+			`<!-- adf:{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph"}]}]}]} -->`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table adf.Node
+			assert.NoError(t, json.Unmarshal([]byte(tt.table), &table))
+
+			got := FromAdf(&adf.Node{Type: "doc", Content: []*adf.Node{&table}})
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
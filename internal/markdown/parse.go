@@ -0,0 +1,559 @@
+// Package markdown converts between the Markdown comments and descriptions
+// are typed in, and Jira's formats: wiki markup (Server/Data Center) and
+// Atlassian Document Format (Cloud).
+//
+// Markdown is read into an adf.Node tree, which is written out as either
+// format; the other way round, FromWiki and FromAdf write Markdown that reads
+// back into the same markup. Jira constructs Markdown has no syntax for are
+// kept as they are: wiki markup is passed through, and ADF nodes are written
+// as "<!-- adf:{...} -->" comments.
+package markdown
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+var (
+	headingRe   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	ruleRe      = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	quoteRe     = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	calloutRe   = regexp.MustCompile(`^\[!(\w+)\][ \t]*(.*)$`)
+	listRe      = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])( +|$)(.*)$`)
+	taskRe      = regexp.MustCompile(`^\[([ xX])\][ \t]+(.*)$`)
+	separatorRe = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	rawRe       = regexp.MustCompile(`^<!-- adf:(.*?) -->`)
+	autolinkRe  = regexp.MustCompile(`^<((?:https?|ftp|mailto):[^>\s]+)>`)
+	urlRe       = regexp.MustCompile(`^(?:https?|ftp)://[^\s<>"]+`)
+	breakRe     = regexp.MustCompile(`^<br\s*/?>`)
+
+	inlineTypes = map[string]bool{
+		"text": true, "hardBreak": true, "mention": true, "emoji": true, "inlineCard": true,
+		"status": true, "date": true, "mediaInline": true, "placeholder": true,
+	}
+)
+
+// parser reads Markdown into a tree of ADF nodes, plus two node types of its
+// own that ToAdf and ToWiki write differently: "image" (attrs src, alt) and
+// "panel" with the callout name and title as attrs.
+type parser struct {
+	// keepEscapes leaves "\*" as it is instead of reading it as "*", for
+	// wiki markup, which escapes the same way.
+	keepEscapes bool
+}
+
+func (p parser) parse(md string) *adf.Node {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	md = strings.ReplaceAll(md, "\t", "    ")
+	return &adf.Node{Type: "doc", Content: p.blocks(strings.Split(md, "\n"))}
+}
+
+func (p parser) blocks(lines []string) []*adf.Node {
+	var nodes []*adf.Node
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+		var node *adf.Node
+		switch {
+		case rawBlock(line) != nil:
+			node = rawBlock(line)
+			i++
+		case fenceRe.MatchString(line):
+			node, i = p.codeBlock(lines, i)
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			node = &adf.Node{Type: "heading", Attrs: map[string]any{"level": len(m[1])}, Content: p.inline(m[2])}
+			i++
+		case ruleRe.MatchString(line):
+			node = &adf.Node{Type: "rule"}
+			i++
+		case quoteRe.MatchString(line):
+			node, i = p.quote(lines, i)
+		case isTableRow(line) && i+1 < len(lines) && separatorRe.MatchString(lines[i+1]):
+			node, i = p.table(lines, i)
+		case listRe.MatchString(line):
+			node, i = p.list(lines, i)
+		default:
+			start := i
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && (i == start || !startsBlock(lines[i])) {
+				i++
+			}
+			node = p.paragraph(lines[start:i])
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// paragraph keeps the line breaks: Jira shows them, so that's how comments
+// are typed.
+func (p parser) paragraph(lines []string) *adf.Node {
+	trimmed := make([]string, len(lines))
+	for i, l := range lines {
+		trimmed[i] = strings.TrimSpace(l)
+	}
+	return &adf.Node{Type: "paragraph", Content: p.inline(strings.Join(trimmed, "\n"))}
+}
+
+func (p parser) codeBlock(lines []string, i int) (*adf.Node, int) {
+	m := fenceRe.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, trimLeading(lines[i], indent))
+	}
+	node := &adf.Node{Type: "codeBlock"}
+	if m[3] != "" {
+		node.Attrs = map[string]any{"language": m[3]}
+	}
+	if text := strings.Join(code, "\n"); text != "" {
+		node.Content = []*adf.Node{{Type: "text", Text: text}}
+	}
+	return node, i
+}
+
+// quote reads "> " lines: a blockquote, or a "> [!note] Title" callout.
+func (p parser) quote(lines []string, i int) (*adf.Node, int) {
+	var inner []string
+	for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+		inner = append(inner, quoteRe.FindStringSubmatch(lines[i])[1])
+	}
+	if m := calloutRe.FindStringSubmatch(inner[0]); m != nil {
+		attrs := map[string]any{"callout": strings.ToLower(m[1])}
+		if title := strings.TrimSpace(m[2]); title != "" {
+			attrs["title"] = title
+		}
+		return &adf.Node{Type: "panel", Attrs: attrs, Content: p.blocks(inner[1:])}, i
+	}
+	return &adf.Node{Type: "blockquote", Content: p.blocks(inner)}, i
+}
+
+// table reads a table with a header row, the only kind Markdown has.
+func (p parser) table(lines []string, i int) (*adf.Node, int) {
+	header := splitRow(lines[i])
+	table := &adf.Node{Type: "table", Content: []*adf.Node{p.tableRow(header, "tableHeader", len(header))}}
+	for i += 2; i < len(lines) && isTableRow(lines[i]); i++ {
+		table.Content = append(table.Content, p.tableRow(splitRow(lines[i]), "tableCell", len(header)))
+	}
+	return table, i
+}
+
+func (p parser) tableRow(cells []string, cellType string, columns int) *adf.Node {
+	row := &adf.Node{Type: "tableRow"}
+	for c := 0; c < columns; c++ {
+		paragraph := &adf.Node{Type: "paragraph"}
+		if c < len(cells) {
+			paragraph.Content = p.inline(cells[c])
+		}
+		row.Content = append(row.Content, &adf.Node{Type: cellType, Content: []*adf.Node{paragraph}})
+	}
+	return row
+}
+
+// list reads the items of a list, each with the lines indented under it.
+// "- [ ] " items make a task list.
+func (p parser) list(lines []string, i int) (*adf.Node, int) {
+	first := listRe.FindStringSubmatch(lines[i])
+	indent, ordered := len(first[1]), isOrdered(first[2])
+	list := &adf.Node{Type: "bulletList"}
+	if ordered {
+		list.Type = "orderedList"
+		if start, _ := strconv.Atoi(strings.TrimRight(first[2], ".)")); start != 1 {
+			list.Attrs = map[string]any{"order": start}
+		}
+	} else if taskRe.MatchString(first[4]) {
+		list.Type = "taskList"
+	}
+	for i < len(lines) {
+		m := listRe.FindStringSubmatch(lines[i])
+		if m == nil || len(m[1]) != indent || isOrdered(m[2]) != ordered {
+			break
+		}
+		column := indent + len(m[2]) + len(m[3])
+		if len(m[3]) > 4 || m[4] == "" {
+			column = indent + len(m[2]) + 1
+		}
+		item := []string{m[4]}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			lead := leadingSpaces(line)
+			if strings.TrimSpace(line) == "" {
+				next := i + 1
+				for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+					next++
+				}
+				if next < len(lines) && leadingSpaces(lines[next]) >= column {
+					item = append(item, "")
+					continue
+				}
+				break
+			}
+			if lead >= column || (lead > indent && listRe.MatchString(line)) {
+				item = append(item, trimLeading(line, column))
+				continue
+			}
+			if !startsBlock(line) && strings.TrimSpace(item[len(item)-1]) != "" {
+				// a lazy continuation of the item's paragraph
+				item = append(item, line)
+				continue
+			}
+			break
+		}
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			i++
+		}
+		if node := p.listItem(list.Type, item); node.Type == "taskItems" {
+			list.Content = append(list.Content, node.Content...)
+		} else {
+			list.Content = append(list.Content, node)
+		}
+	}
+	return list, i
+}
+
+func (p parser) listItem(listType string, lines []string) *adf.Node {
+	if listType != "taskList" {
+		return &adf.Node{Type: "listItem", Content: p.blocks(lines)}
+	}
+	state := "TODO"
+	if m := taskRe.FindStringSubmatch(lines[0]); m != nil {
+		lines[0] = m[2]
+		if m[1] != " " {
+			state = "DONE"
+		}
+	}
+	// task items hold text, not paragraphs
+	item := &adf.Node{Type: "taskItem", Attrs: map[string]any{"state": state}}
+	var nested []*adf.Node
+	for _, block := range p.blocks(lines) {
+		switch {
+		case block.Type == "taskList":
+			nested = append(nested, block)
+		case len(item.Content) > 0:
+			item.Content = append(item.Content, &adf.Node{Type: "hardBreak"})
+			fallthrough
+		default:
+			if block.Type == "paragraph" {
+				item.Content = append(item.Content, block.Content...)
+			} else if text := adf.PlainText(block); text != "" {
+				item.Content = append(item.Content, &adf.Node{Type: "text", Text: text})
+			}
+		}
+	}
+	if len(nested) > 0 {
+		// a nested task list is a sibling of the item it's under
+		return &adf.Node{Type: "taskItems", Content: append([]*adf.Node{item}, nested...)}
+	}
+	return item
+}
+
+// inline reads the text of a paragraph, heading or cell into text nodes
+// with marks, hard breaks, mentions, ...
+func (p parser) inline(text string) []*adf.Node {
+	return p.marked(text, nil)
+}
+
+func (p parser) marked(text string, marks []adf.Mark) []*adf.Node {
+	var nodes []*adf.Node
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			nodes = append(nodes, &adf.Node{Type: "text", Text: plain.String(), Marks: marks})
+			plain.Reset()
+		}
+	}
+	for i := 0; i < len(text); {
+		if text[i] == '\\' && i+1 < len(text) && isPunct(text[i+1]) {
+			if p.keepEscapes {
+				plain.WriteString(text[i : i+2])
+			} else {
+				plain.WriteByte(text[i+1])
+			}
+			i += 2
+			continue
+		}
+		if token, n := p.token(text, i, marks); n > 0 {
+			flush()
+			nodes = append(nodes, token...)
+			i += n
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(text[i:])
+		plain.WriteRune(c)
+		i += size
+	}
+	flush()
+	return nodes
+}
+
+// token reads the markup starting at text[i], and returns its nodes and
+// length - 0 when it's plain text.
+func (p parser) token(text string, i int, marks []adf.Mark) ([]*adf.Node, int) {
+	rest := text[i:]
+	switch rest[0] {
+	case '\n':
+		return []*adf.Node{{Type: "hardBreak"}}, 1
+	case '`':
+		ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+		fence := rest[:ticks]
+		if end := strings.Index(rest[ticks:], fence); end >= 0 && !strings.HasPrefix(rest[ticks+end+ticks:], "`") {
+			code := rest[ticks : ticks+end]
+			if strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return []*adf.Node{{Type: "text", Text: code, Marks: with(linkOnly(marks), adf.Mark{Type: "code"})}}, 2*ticks + end
+		}
+		return []*adf.Node{{Type: "text", Text: fence, Marks: marks}}, ticks
+	case '<':
+		if m := rawRe.FindStringSubmatch(rest); m != nil {
+			if node, err := adf.Parse([]byte(m[1])); err == nil {
+				return []*adf.Node{node}, len(m[0])
+			}
+		}
+		if m := breakRe.FindString(rest); m != "" {
+			return []*adf.Node{{Type: "hardBreak"}}, len(m)
+		}
+		if m := autolinkRe.FindStringSubmatch(rest); m != nil {
+			return []*adf.Node{{Type: "inlineCard", Attrs: map[string]any{"url": m[1]}}}, len(m[0])
+		}
+		if strings.HasPrefix(rest, "<u>") {
+			if end := strings.Index(rest, "</u>"); end > 3 {
+				return p.marked(rest[3:end], with(marks, adf.Mark{Type: "underline"})), end + len("</u>")
+			}
+		}
+	case '!':
+		if label, href, n := linkAt(rest[1:]); n > 0 {
+			return []*adf.Node{{Type: "image", Attrs: map[string]any{"src": href, "alt": label}, Marks: marks}}, n + 1
+		}
+	case '[':
+		if label, href, n := linkAt(rest); n > 0 {
+			if id, ok := strings.CutPrefix(href, "accountid:"); ok {
+				if !strings.HasPrefix(label, "@") {
+					label = "@" + label
+				}
+				return []*adf.Node{{Type: "mention", Attrs: map[string]any{"id": id, "text": label}}}, n
+			}
+			return p.marked(label, with(marks, adf.Mark{Type: "link", Attrs: map[string]any{"href": href}})), n
+		}
+	case '*', '_', '~':
+		if body, mark, n := emphasized(text, i); n > 0 {
+			inner := marks
+			for _, m := range strings.Split(mark, "+") {
+				inner = with(inner, adf.Mark{Type: m})
+			}
+			return p.marked(body, inner), n
+		}
+	}
+	if m := urlRe.FindString(rest); m != "" && !wordBefore(text, i) && !hasMark(marks, "link") {
+		url := strings.TrimRight(m, ".,;:!?)")
+		return []*adf.Node{{Type: "text", Text: url, Marks: with(marks, adf.Mark{Type: "link", Attrs: map[string]any{"href": url}})}}, len(url)
+	}
+	return nil, 0
+}
+
+// emphasized reads **strong**, *em*, ***both***, __strong__, _em_ or
+// ~~strike~~ at text[i], returning its text and marks ("strong+em").
+func emphasized(text string, i int) (string, string, int) {
+	c := text[i]
+	run := runLength(text, i)
+	if c == '~' && run != 2 || run > 3 {
+		return "", "", 0
+	}
+	open := i + run
+	if open >= len(text) || unicode.IsSpace(rune(text[open])) || c == '_' && wordBefore(text, i) {
+		return "", "", 0
+	}
+	for j := open + 1; j < len(text); {
+		if text[j] == '`' {
+			// code spans take precedence
+			ticks := runLength(text, j)
+			if end := strings.Index(text[j+ticks:], text[j:j+ticks]); end >= 0 {
+				j += 2*ticks + end
+				continue
+			}
+		}
+		if text[j] == '\\' {
+			j += 2
+			continue
+		}
+		if text[j] != c {
+			j++
+			continue
+		}
+		n := runLength(text, j)
+		if n == run && !unicode.IsSpace(rune(text[j-1])) && !(c == '_' && wordAfter(text, j+n)) {
+			marks := map[int]string{1: "em", 2: "strong", 3: "strong+em"}[run]
+			if c == '~' {
+				marks = "strike"
+			}
+			return text[open:j], marks, j + n - i
+		}
+		j += n
+	}
+	return "", "", 0
+}
+
+// linkAt reads [label](href) or [label](href "title") at the start of text.
+func linkAt(text string) (string, string, int) {
+	if !strings.HasPrefix(text, "[") {
+		return "", "", 0
+	}
+	depth := 0
+	for j := 0; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if !strings.HasPrefix(text[j+1:], "(") {
+				return "", "", 0
+			}
+			end := strings.IndexByte(text[j+2:], ')')
+			if end < 0 {
+				return "", "", 0
+			}
+			target := strings.TrimSpace(text[j+2 : j+2+end])
+			href, _, _ := strings.Cut(target, " ")
+			href = strings.Trim(href, "<>")
+			if href == "" {
+				return "", "", 0
+			}
+			return text[1:j], href, j + 3 + end
+		case '\n':
+			return "", "", 0
+		}
+	}
+	return "", "", 0
+}
+
+// rawBlock reads a line that is just an "<!-- adf:{...} -->" comment of a
+// block node; an inline one is the content of a paragraph.
+func rawBlock(line string) *adf.Node {
+	trimmed := strings.TrimSpace(line)
+	m := rawRe.FindStringSubmatch(trimmed)
+	if m == nil || len(m[0]) != len(trimmed) {
+		return nil
+	}
+	var node adf.Node
+	if err := json.Unmarshal([]byte(m[1]), &node); err != nil || node.Type == "" || inlineTypes[node.Type] {
+		return nil
+	}
+	return &node
+}
+
+// splitRow splits "| a | b |" at the pipes that aren't escaped or in code.
+func splitRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+	var cells []string
+	start, code := 0, false
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			if i+1 < len(row) && row[i+1] == '|' {
+				i++
+			}
+		case '`':
+			code = !code
+		case '|':
+			if !code {
+				cells = append(cells, strings.TrimSpace(row[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(cells, strings.TrimSpace(row[start:]))
+}
+
+// startsBlock tells whether line ends a paragraph by starting another block.
+func startsBlock(line string) bool {
+	return headingRe.MatchString(line) || fenceRe.MatchString(line) || ruleRe.MatchString(line) ||
+		quoteRe.MatchString(line) || listRe.MatchString(line) || rawBlock(line) != nil
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+func isOrdered(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+func isPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimLeading removes up to n leading spaces.
+func trimLeading(line string, n int) string {
+	return line[min(n, leadingSpaces(line)):]
+}
+
+func runLength(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == text[i] {
+		n++
+	}
+	return n
+}
+
+func wordBefore(text string, i int) bool {
+	c, _ := utf8.DecodeLastRuneInString(text[:i])
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func wordAfter(text string, i int) bool {
+	c, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// with returns marks plus mark, leaving marks (shared by sibling nodes) as is.
+func with(marks []adf.Mark, mark adf.Mark) []adf.Mark {
+	return append(append([]adf.Mark{}, marks...), mark)
+}
+
+// linkOnly keeps the link of marks: code only goes with links in ADF.
+func linkOnly(marks []adf.Mark) []adf.Mark {
+	var links []adf.Mark
+	for _, m := range marks {
+		if m.Type == "link" {
+			links = append(links, m)
+		}
+	}
+	return links
+}
+
+func hasMark(marks []adf.Mark, markType string) bool {
+	for _, m := range marks {
+		if m.Type == markType {
+			return true
+		}
+	}
+	return false
+}
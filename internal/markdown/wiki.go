@@ -0,0 +1,234 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+// wikiMacros maps the callout names, and ADF panel types, to the panel
+// macros of wiki markup. Others are {info}.
+var wikiMacros = map[string]string{
+	"info": "info", "note": "note", "warning": "warning", "tip": "tip", "panel": "panel",
+	"success": "tip", "error": "warning", "caution": "warning", "important": "note",
+}
+
+// wikiSpecial are the characters whose Markdown escapes are kept, as wiki
+// markup would read them as markup too.
+const wikiSpecial = `\*_-+^~?{}[]!|#`
+
+// ToWiki converts Markdown to Jira wiki markup, the format of comments and
+// descriptions on Server/Data Center. Text is written as is, so wiki markup
+// typed in along with the Markdown - {color}, [~user], ... - still works.
+func ToWiki(md string) string {
+	doc := parser{keepEscapes: true}.parse(md)
+	return wikiBlocks(doc.Content)
+}
+
+func wikiBlocks(nodes []*adf.Node) string {
+	blocks := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if block := wikiBlock(n); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func wikiBlock(n *adf.Node) string {
+	switch n.Type {
+	case "paragraph":
+		return wikiInline(n.Content, "\n")
+	case "heading":
+		return fmt.Sprintf("h%s. %s", attr(n, "level"), wikiInline(n.Content, " "))
+	case "bulletList", "orderedList", "taskList":
+		return strings.Join(wikiList(n, ""), "\n")
+	case "codeBlock":
+		code := strings.TrimSuffix(textOf(n), "\n")
+		if language := attr(n, "language"); language != "" {
+			return fmt.Sprintf("{code:%s}\n%s\n{code}", language, code)
+		}
+		return fmt.Sprintf("{noformat}\n%s\n{noformat}", code)
+	case "blockquote":
+		if len(n.Content) == 1 && n.Content[0].Type == "paragraph" {
+			if text := wikiInline(n.Content[0].Content, "\n"); !strings.Contains(text, "\n") {
+				return "bq. " + text
+			}
+		}
+		return fmt.Sprintf("{quote}\n%s\n{quote}", wikiBlocks(n.Content))
+	case "panel":
+		name := attr(n, "callout")
+		if name == "" {
+			name = attr(n, "panelType")
+		}
+		macro, ok := wikiMacros[name]
+		if !ok {
+			macro = "info"
+		}
+		params := ""
+		if title := attr(n, "title"); title != "" {
+			params = ":title=" + title
+		}
+		return fmt.Sprintf("{%s%s}\n%s\n{%s}", macro, params, wikiBlocks(n.Content), macro)
+	case "rule":
+		return "----"
+	case "table":
+		rows := make([]string, 0, len(n.Content))
+		for _, row := range n.Content {
+			var b strings.Builder
+			separator := "|"
+			for _, cell := range row.Content {
+				separator = "|"
+				if cell.Type == "tableHeader" {
+					separator = "||"
+				}
+				b.WriteString(separator + wikiCell(cell))
+			}
+			rows = append(rows, b.String()+separator)
+		}
+		return strings.Join(rows, "\n")
+	}
+	return adf.PlainText(n)
+}
+
+// wikiList writes the items of a list, "* a", "*# nested", under the marks
+// of the lists it's in.
+func wikiList(list *adf.Node, marks string) []string {
+	marks += "*"
+	if list.Type == "orderedList" {
+		marks = marks[:len(marks)-1] + "#"
+	}
+	var lines []string
+	for _, item := range list.Content {
+		switch item.Type {
+		case "taskItem":
+			text := wikiInline(item.Content, ` \\ `)
+			if attr(item, "state") == "DONE" {
+				text = "(/) " + text
+			}
+			lines = append(lines, marks+" "+text)
+			continue
+		case "taskList":
+			lines = append(lines, wikiList(item, marks)...)
+			continue
+		}
+		// wiki list items are a line: paragraphs are joined with line
+		// breaks, and nested lists follow
+		var texts, nested []string
+		for _, block := range item.Content {
+			switch block.Type {
+			case "bulletList", "orderedList", "taskList":
+				nested = append(nested, wikiList(block, marks)...)
+			case "paragraph":
+				texts = append(texts, wikiInline(block.Content, ` \\ `))
+			default:
+				nested = append(nested, wikiBlock(block))
+			}
+		}
+		lines = append(lines, marks+" "+strings.Join(texts, ` \\ `))
+		lines = append(lines, nested...)
+	}
+	return lines
+}
+
+func wikiCell(cell *adf.Node) string {
+	texts := make([]string, 0, len(cell.Content))
+	for _, block := range cell.Content {
+		if block.Type == "paragraph" {
+			texts = append(texts, wikiInline(block.Content, `\\`))
+		} else {
+			texts = append(texts, adf.PlainText(block))
+		}
+	}
+	return strings.Join(texts, `\\`)
+}
+
+func wikiInline(nodes []*adf.Node, lineBreak string) string {
+	return writeInline(nodes, nil, func(n *adf.Node) string {
+		switch n.Type {
+		case "text":
+			if containsMark(n.Marks, adf.Mark{Type: "code"}) {
+				return n.Text
+			}
+			return wikiText(n.Text)
+		case "hardBreak":
+			return lineBreak
+		case "mention":
+			return "[~accountid:" + attr(n, "id") + "]"
+		case "inlineCard":
+			return "[" + attr(n, "url") + "]"
+		case "image":
+			return "!" + attr(n, "src") + "!"
+		case "emoji":
+			if text := attr(n, "text"); text != "" {
+				return text
+			}
+			return attr(n, "shortName")
+		}
+		return adf.PlainText(&adf.Node{Type: "paragraph", Content: []*adf.Node{n}})
+	}, func(m adf.Mark, text string) string {
+		switch m.Type {
+		case "strong":
+			return "*" + text + "*"
+		case "em":
+			return "_" + text + "_"
+		case "strike":
+			return "-" + text + "-"
+		case "underline":
+			return "+" + text + "+"
+		case "code":
+			return "{{" + text + "}}"
+		case "link":
+			href, _ := m.Attrs["href"].(string)
+			if text == href {
+				return href
+			}
+			return "[" + text + "|" + href + "]"
+		case "textColor":
+			color, _ := m.Attrs["color"].(string)
+			return "{color:" + color + "}" + text + "{color}"
+		}
+		return text
+	})
+}
+
+// wikiText drops the Markdown escapes wiki markup doesn't need, "1\." and
+// the like, and keeps the ones it does.
+func wikiText(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && isPunct(text[i+1]) {
+			if strings.IndexByte(wikiSpecial, text[i+1]) >= 0 {
+				b.WriteByte('\\')
+			}
+			i++
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// attr reads a string (or number) attribute.
+func attr(n *adf.Node, key string) string {
+	switch v := n.Attrs[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// textOf joins the text of n's children, e.g. the code of a codeBlock.
+func textOf(n *adf.Node) string {
+	var b strings.Builder
+	for _, c := range n.Content {
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
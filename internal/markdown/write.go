@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/adf"
+)
+
+// lineStartRe matches the lines that would start a block if a paragraph line
+// began with them: the marker to escape is the 2nd group.
+var lineStartRe = regexp.MustCompile(`^( {0,3})(#{1,6}(?:[ \t]|$)|>|[-*+](?:[ \t]|$)|\d{1,9}[.)](?:[ \t]|$)|` + "```" + `|~~~|\||[-*_](?:[ \t]*[-*_]){2,}[ \t]*$)`)
+
+// writeInline writes text nodes with their marks, each mark opened once around
+// the run of nodes it's on - "*bold _both_*", not "*bold *_*both*_". The
+// spaces at the ends of a run go outside of its markers, as both wiki markup
+// and Markdown want the markers next to the text.
+func writeInline(nodes []*adf.Node, open []adf.Mark, node func(*adf.Node) string, wrap func(adf.Mark, string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(nodes); {
+		var mark *adf.Mark
+		end := i + 1
+		for _, m := range nodes[i].Marks {
+			if containsMark(open, m) {
+				continue
+			}
+			j := i + 1
+			for j < len(nodes) && containsMark(nodes[j].Marks, m) {
+				j++
+			}
+			if mark == nil || j > end {
+				mark, end = &m, j
+			}
+		}
+		if mark == nil {
+			b.WriteString(node(nodes[i]))
+			i++
+			continue
+		}
+		inner := writeInline(nodes[i:end], with(open, *mark), node, wrap)
+		body := strings.TrimSpace(inner)
+		if body == "" || mark.Type == "code" {
+			b.WriteString(wrap(*mark, inner))
+		} else {
+			lead := inner[:strings.Index(inner, body)]
+			b.WriteString(lead + wrap(*mark, body) + inner[len(lead)+len(body):])
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func containsMark(marks []adf.Mark, mark adf.Mark) bool {
+	for _, m := range marks {
+		if m.Type == mark.Type && (len(m.Attrs) == 0 && len(mark.Attrs) == 0 || reflect.DeepEqual(m.Attrs, mark.Attrs)) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLineStarts escapes what would make the lines of a paragraph a
+// heading, a list item, a quote, ... e.g. "1\. not a list".
+func escapeLineStarts(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		m := lineStartRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		at := m[4]
+		if c := line[at]; c >= '0' && c <= '9' {
+			// the . or ) after the number
+			at += strings.IndexAny(line[at:], ".)")
+		}
+		lines[i] = line[:at] + `\` + line[at:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapePipes escapes the pipes of a table cell, but not the ones in code
+// spans, which don't split cells.
+func escapePipes(text string) string {
+	var b strings.Builder
+	code := false
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && i+1 < len(text):
+			b.WriteByte(text[i])
+			i++
+		case text[i] == '`':
+			code = !code
+		case text[i] == '|' && !code:
+			b.WriteByte('\\')
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// prefixLines puts prefix in front of each line of text, e.g. "> " to quote
+// it; empty lines get it without trailing spaces.
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hangLines puts marker in front of the first line of text, and indents the
+// others under it - a list item.
+func hangLines(text, marker string) string {
+	lines := strings.Split(text, "\n")
+	indent := strings.Repeat(" ", len(marker))
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = marker + line
+		case line != "":
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeFence is a run of backticks longer than any in code.
+func codeFence(code string, min int) string {
+	longest := 0
+	for i := 0; i < len(code); i++ {
+		if code[i] == '`' {
+			n := runLength(code, i)
+			longest = max(longest, n)
+			i += n - 1
+		}
+	}
+	return strings.Repeat("`", max(min, longest+1))
+}
+
+// codeSpan writes code in backticks, padded when it starts or ends with one.
+func codeSpan(code string) string {
+	fence := codeFence(code, 1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
@@ -78,6 +78,10 @@ func isAtlassianCloud(apiUrl string) bool {
 	return strings.Contains(apiUrl, ".atlassian.net")
 }
 
+func (view *searchProjectsView) Reload() {
+	view.reopen()
+}
+
 func (view *searchProjectsView) reopen() {
 	app.GoTo("projects", view.api)
 }
@@ -0,0 +1,179 @@
+package richtext
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	// QuoteInset and PanelInset are how much narrower the content of a quote
+	// or a panel has to be laid out than the block itself.
+	QuoteInset = 2
+	PanelInset = 4
+
+	minTableColumnWidth = 3
+	tableColumnGap      = " │ "
+)
+
+var panelIcons = map[string]string{"info": "ℹ", "note": "✎", "success": "✔", "warning": "⚠", "error": "✖"}
+
+// Cell is a table cell. Render lays its content out for a width, 0 meaning
+// its natural width.
+type Cell struct {
+	Header bool
+	Render func(width int) []Line
+}
+
+// CodeBlock keeps the code's own line breaks and indentation, on the code
+// background, with the language (if any) in the corner.
+func CodeBlock(code, language string, width int, theme Theme) []Line {
+	var spans []Span
+	for i, line := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+		if i > 0 {
+			spans = append(spans, Span{Text: "\n", Style: theme.Code})
+		}
+		spans = append(spans, Span{Text: " " + line, Style: theme.Code})
+	}
+	lines := Wrap(spans, width)
+	if language != "" {
+		lines = append([]Line{{{Text: " " + language, Style: theme.Code.Italic(true)}}}, lines...)
+	}
+	blockWidth := width
+	if blockWidth <= 0 {
+		for _, l := range lines {
+			blockWidth = max(blockWidth, l.Width())
+		}
+	}
+	return Pad(lines, blockWidth, theme.Code)
+}
+
+// Quote puts a bar in front of lines laid out QuoteInset narrower.
+func Quote(lines []Line, theme Theme) []Line {
+	bar := Span{Text: "│ ", Style: theme.Quote}
+	return Indent(lines, bar, bar)
+}
+
+// Panel puts a bar in the panel type's color in front of lines laid out
+// PanelInset narrower, led by the type's icon. An empty title is left out.
+func Panel(lines []Line, panelType, title string, theme Theme) []Line {
+	style := theme.Panel(panelType)
+	icon, ok := panelIcons[panelType]
+	if !ok {
+		icon = panelIcons["info"]
+	}
+	if title != "" {
+		lines = append([]Line{{{Text: title, Style: theme.Text.Bold(true)}}}, lines...)
+	}
+	if len(lines) == 0 {
+		lines = []Line{{}}
+	}
+	return Indent(lines,
+		Span{Text: "▌" + icon + "  ", Style: style.Bold(true)},
+		Span{Text: "▌   ", Style: style})
+}
+
+// Rule is a horizontal line across width.
+func Rule(width int, theme Theme) []Line {
+	return []Line{{{Text: strings.Repeat("─", max(width, 3)), Style: theme.Dim}}}
+}
+
+// Table sizes the columns to their content, shrinking the widest ones when
+// the table doesn't fit, and wraps the cells within them. Header cells are
+// bold, and a row of them is underlined by a rule.
+func Table(rows [][]Cell, width int, theme Theme) []Line {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return nil
+	}
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for c, cell := range row {
+			for _, l := range cell.Render(0) {
+				widths[c] = max(widths[c], l.Width())
+			}
+		}
+	}
+	fitColumns(widths, width-(columns-1)*uniseg.StringWidth(tableColumnGap))
+
+	var lines []Line
+	for i, row := range rows {
+		cells := make([][]Line, columns)
+		height := 1
+		header := false
+		for c, cell := range row {
+			cells[c] = cell.Render(widths[c])
+			if cell.Header {
+				header = true
+				cells[c] = Restyle(cells[c], func(s tcell.Style) tcell.Style { return s.Bold(true) })
+			}
+			height = max(height, len(cells[c]))
+		}
+		for y := 0; y < height; y++ {
+			var line Line
+			for c := 0; c < columns; c++ {
+				if c > 0 {
+					line = append(line, Span{Text: tableColumnGap, Style: theme.Dim})
+				}
+				var cellLine Line
+				if y < len(cells[c]) {
+					cellLine = cells[c][y]
+				}
+				line = append(line, cellLine...)
+				if pad := widths[c] - cellLine.Width(); pad > 0 && c < columns-1 {
+					line = append(line, Span{Text: strings.Repeat(" ", pad), Style: theme.Text})
+				}
+			}
+			lines = append(lines, line)
+		}
+		if header && i < len(rows)-1 {
+			var rule Line
+			for c, w := range widths {
+				if c > 0 {
+					rule = append(rule, Span{Text: "─┼─", Style: theme.Dim})
+				}
+				rule = append(rule, Span{Text: strings.Repeat("─", w), Style: theme.Dim})
+			}
+			lines = append(lines, rule)
+		}
+	}
+	return lines
+}
+
+// fitColumns takes cells off the widest columns until they fit in width.
+func fitColumns(widths []int, width int) {
+	if width <= 0 {
+		return
+	}
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for c, w := range widths {
+			if w > widths[widest] {
+				widest = c
+			}
+		}
+		if widths[widest] <= minTableColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// Restyle changes the style of every span, in place.
+func Restyle(lines []Line, change func(tcell.Style) tcell.Style) []Line {
+	for _, l := range lines {
+		for i := range l {
+			l[i].Style = change(l[i].Style)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,193 @@
+// Package richtext lays out styled text for the terminal: spans of text in
+// one style, word-wrapped into lines that can be indented, padded and drawn.
+// The document renderers (adf) produce it; issueView draws it.
+package richtext
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// Span is a run of text drawn in one style. Text never contains '\n' once
+// it's part of a Line.
+type Span struct {
+	Text  string
+	Style tcell.Style
+}
+
+// Line is one row on the screen.
+type Line []Span
+
+// Width is the number of cells the line takes.
+func (l Line) Width() int {
+	w := 0
+	for _, s := range l {
+		w += uniseg.StringWidth(s.Text)
+	}
+	return w
+}
+
+func (l Line) String() string {
+	var b strings.Builder
+	for _, s := range l {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// String joins the lines as plain text, e.g. for tests and clipboard-ish uses.
+func String(lines []Line) string {
+	texts := make([]string, 0, len(lines))
+	for _, l := range lines {
+		texts = append(texts, strings.TrimRight(l.String(), " "))
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Wrap lays the spans out into lines at most width cells wide. Lines break at
+// '\n' and between words; a word longer than a whole line is split. Spaces at
+// a break are dropped. A width <= 0 only breaks at '\n'.
+func Wrap(spans []Span, width int) []Line {
+	lines := []Line{{}}
+	col := 0
+	newLine := func() {
+		lines = append(lines, Line{})
+		col = 0
+	}
+	appendText := func(text string, style tcell.Style) {
+		last := &lines[len(lines)-1]
+		if n := len(*last); n > 0 && (*last)[n-1].Style == style {
+			(*last)[n-1].Text += text
+		} else {
+			*last = append(*last, Span{Text: text, Style: style})
+		}
+		col += uniseg.StringWidth(text)
+	}
+	for _, span := range spans {
+		for i, paragraph := range strings.Split(span.Text, "\n") {
+			if i > 0 {
+				newLine()
+			}
+			for _, word := range splitWords(paragraph) {
+				w := uniseg.StringWidth(word)
+				isSpace := strings.TrimSpace(word) == ""
+				switch {
+				case width <= 0 || col+w <= width:
+					appendText(word, span.Style)
+				case isSpace:
+					newLine()
+				case w <= width:
+					newLine()
+					appendText(word, span.Style)
+				default:
+					for _, part := range splitAt(word, width-col, width) {
+						if col > 0 && col+uniseg.StringWidth(part) > width {
+							newLine()
+						}
+						appendText(part, span.Style)
+					}
+				}
+			}
+		}
+	}
+	for i := range lines {
+		lines[i] = trimRight(lines[i])
+	}
+	return lines
+}
+
+// Indent prefixes the first line with first and every other one with rest,
+// e.g. a list bullet and the blank it hangs over.
+func Indent(lines []Line, first Span, rest Span) []Line {
+	indented := make([]Line, 0, len(lines))
+	for i, l := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		indented = append(indented, append(Line{prefix}, l...))
+	}
+	return indented
+}
+
+// Pad fills every line up to width with spaces in style, so a background
+// color makes a block.
+func Pad(lines []Line, width int, style tcell.Style) []Line {
+	for i, l := range lines {
+		if w := l.Width(); w < width {
+			lines[i] = append(l, Span{Text: strings.Repeat(" ", width-w), Style: style})
+		}
+	}
+	return lines
+}
+
+// Draw draws the lines from (x, y) down, and returns how many rows it took.
+func Draw(screen tcell.Screen, x, y int, lines []Line) int {
+	for row, l := range lines {
+		col := x
+		for _, s := range l {
+			g := uniseg.NewGraphemes(s.Text)
+			for g.Next() {
+				runes := g.Runes()
+				screen.SetContent(col, y+row, runes[0], runes[1:], s.Style)
+				col += g.Width()
+			}
+		}
+	}
+	return len(lines)
+}
+
+// splitWords cuts text into alternating runs of spaces and non-spaces.
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	for i, r := range text {
+		if i > start && (r == ' ') != (text[i-1] == ' ') {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// splitAt splits a word into parts fitting the rest of the current line,
+// then whole lines.
+func splitAt(word string, first int, width int) []string {
+	var parts []string
+	var part strings.Builder
+	limit, w := first, 0
+	if limit <= 0 {
+		limit = width
+	}
+	g := uniseg.NewGraphemes(word)
+	for g.Next() {
+		if w+g.Width() > limit && part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+			limit, w = width, 0
+		}
+		part.WriteString(g.Str())
+		w += g.Width()
+	}
+	if part.Len() > 0 {
+		parts = append(parts, part.String())
+	}
+	return parts
+}
+
+func trimRight(l Line) Line {
+	for len(l) > 0 {
+		last := &l[len(l)-1]
+		last.Text = strings.TrimRight(last.Text, " ")
+		if last.Text != "" {
+			break
+		}
+		l = l[:len(l)-1]
+	}
+	return l
+}
@@ -0,0 +1,84 @@
+package richtext
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wrap(t *testing.T) {
+	bold := tcell.StyleDefault.Bold(true)
+	tests := []struct {
+		name  string
+		spans []Span
+		width int
+		want  string
+	}{
+		{"should keep short text", []Span{{Text: "hello world"}}, 20, "hello world"},
+		{"should break between words", []Span{{Text: "hello big world"}}, 10, "hello big\nworld"},
+		{"should break across spans", []Span{{Text: "hello "}, {Text: "bold", Style: bold}, {Text: " world"}}, 10, "hello bold\nworld"},
+		{"should split words longer than a line", []Span{{Text: "abcdefghij"}}, 4, "abcd\nefgh\nij"},
+		{"should break at newlines", []Span{{Text: "one\ntwo"}}, 20, "one\ntwo"},
+		{"should keep leading spaces", []Span{{Text: "  indented"}}, 20, "  indented"},
+		{"should not wrap without width", []Span{{Text: "hello big world"}}, 0, "hello big world"},
+		{"should count wide characters twice", []Span{{Text: "🚀🚀 go"}}, 5, "🚀🚀\ngo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.spans, tt.width)
+
+			assert.Equal(t, tt.want, String(got))
+			for _, l := range got {
+				if tt.width > 0 {
+					assert.LessOrEqual(t, l.Width(), tt.width)
+				}
+			}
+		})
+	}
+}
+
+func Test_Wrap_should_keep_styles(t *testing.T) {
+	// given
+	bold := tcell.StyleDefault.Bold(true)
+
+	// when
+	lines := Wrap([]Span{{Text: "a "}, {Text: "bc d", Style: bold}}, 4)
+
+	// then
+	assert.Equal(t, []Line{{{Text: "a "}, {Text: "bc", Style: bold}}, {{Text: "d", Style: bold}}}, lines)
+}
+
+func Test_Indent_and_Pad(t *testing.T) {
+	// given
+	lines := Wrap([]Span{{Text: "one two"}}, 3)
+
+	// when
+	lines = Pad(Indent(lines, Span{Text: "- "}, Span{Text: "  "}), 6, tcell.StyleDefault)
+
+	// then
+	assert.Equal(t, "- one\n  two", String(lines))
+	for _, l := range lines {
+		assert.Equal(t, 6, l.Width())
+	}
+}
+
+func Test_Draw(t *testing.T) {
+	// given
+	screen := tcell.NewSimulationScreen("utf-8")
+	_ = screen.Init()
+	defer screen.Fini()
+	bold := tcell.StyleDefault.Bold(true)
+	lines := []Line{{{Text: "🚀"}, {Text: "go", Style: bold}}, {{Text: "x"}}}
+
+	// when
+	rows := Draw(screen, 1, 2, lines)
+
+	// then
+	assert.Equal(t, 2, rows)
+	r, _, style, _ := screen.GetContent(3, 2)
+	assert.Equal(t, 'g', r)
+	assert.Equal(t, bold, style)
+	r, _, _, _ = screen.GetContent(1, 3)
+	assert.Equal(t, 'x', r)
+}
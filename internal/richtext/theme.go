@@ -0,0 +1,55 @@
+package richtext
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/app"
+)
+
+// Theme holds the styles documents are drawn with. Emphasis (bold, italic,
+// ...) is added on top of whatever style the text is in.
+type Theme struct {
+	Text    tcell.Style
+	Heading tcell.Style
+	Code    tcell.Style
+	Link    tcell.Style
+	Mention tcell.Style
+	Quote   tcell.Style
+	Dim     tcell.Style
+	// Panels are the colors of info, note, success, warning and error panels.
+	Panels map[string]tcell.Style
+}
+
+// DefaultTheme takes the richtext.* colors of the color scheme.
+func DefaultTheme() Theme {
+	text := app.DefaultStyle()
+	return Theme{
+		Text:    text,
+		Heading: text.Foreground(app.Color("richtext.heading")).Bold(true),
+		Code:    text.Foreground(app.Color("richtext.code.foreground")).Background(app.Color("richtext.code.background")),
+		Link:    text.Foreground(app.Color("richtext.link")).Underline(true),
+		Mention: text.Foreground(app.Color("richtext.mention")).Bold(true),
+		Quote:   text.Foreground(app.Color("richtext.quote")),
+		Dim:     text.Foreground(app.Color("details.foreground")),
+		Panels: map[string]tcell.Style{
+			"info":    text.Foreground(app.Color("richtext.panel.info")),
+			"note":    text.Foreground(app.Color("richtext.panel.note")),
+			"success": text.Foreground(app.Color("richtext.panel.success")),
+			"warning": text.Foreground(app.Color("richtext.panel.warning")),
+			"error":   text.Foreground(app.Color("richtext.panel.error")),
+		},
+	}
+}
+
+// Panel is the style of a panel type, the info one for unknown types.
+func (t Theme) Panel(panelType string) tcell.Style {
+	if s, ok := t.Panels[panelType]; ok {
+		return s
+	}
+	return t.Panels["info"]
+}
+
+// Linked is s in the link color, underlined, keeping the rest of s (bold, ...).
+func (t Theme) Linked(s tcell.Style) tcell.Style {
+	fg, _, _ := t.Link.Decompose()
+	return s.Foreground(fg).Underline(true)
+}
@@ -1,6 +1,8 @@
 package statuses
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mk-5/fjira/internal/app"
@@ -69,7 +71,17 @@ func (view *statusChangeView) HandleKeyEvent(ev *tcell.EventKey) {
 func (view *statusChangeView) startStatusSearching() {
 	app.GetApp().ClearNow()
 	app.GetApp().Loading(true)
-	statuses := view.transitions(view.issue.Id)
+	statuses, err := view.transitions(view.issue.Id)
+	if err != nil {
+		app.GetApp().Loading(false)
+		if !errors.Is(err, context.Canceled) {
+			app.Error(fmt.Sprintf(ui.MessageCannotFetchTransitions, view.issue.Key, ui.JiraErrorReason(err)))
+		}
+		if view.goBackFn != nil {
+			view.goBackFn()
+		}
+		return
+	}
 	statusesStrings := FormatJiraTransitions(statuses)
 	view.fuzzyFind = app.NewFuzzyFind(ui.MessageStatusFuzzyFind, statusesStrings)
 	view.fuzzyFind.MarginBottom = 0
@@ -83,6 +95,11 @@ func (view *statusChangeView) startStatusSearching() {
 			return
 		}
 		view.fuzzyFind = nil
+		if transition := &statuses[status.Index]; transition.HasScreen || transition.RequiresInput() {
+			// the resolution, comment, ... are filled in on the transition's screen
+			app.GoTo("issue-transition", view.issue, transition, view.goBackFn, view.api)
+			return
+		}
 		view.changeStatusTo(&statuses[status.Index])
 	}
 }
@@ -102,18 +119,17 @@ func (view *statusChangeView) changeStatusTo(status *jira.IssueTransition) {
 	}
 }
 
-func (view *statusChangeView) transitions(issueId string) []jira.IssueTransition {
-	transitions, _ := view.api.FindTransitions(issueId)
-	return transitions
+func (view *statusChangeView) transitions(issueId string) ([]jira.IssueTransition, error) {
+	return view.api.WithContext(app.GetApp().LoadingContext()).FindTransitions(issueId)
 }
 
 func (view *statusChangeView) changeStatusForTicket(issue *jira.Issue, status *jira.IssueTransition) {
 	app.GetApp().ClearNow()
 	app.GetApp().LoadingWithText(true, ui.MessageChangingStatus)
-	err := view.api.DoTransition(issue.Key, status)
+	err := view.api.DoTransition(issue.Key, status, nil)
 	app.GetApp().Loading(false)
 	if err != nil {
-		app.Error(err.Error())
+		app.Error(fmt.Sprintf(ui.MessageCannotChangeStatus, issue.Key, status.Name, ui.JiraErrorReason(err)))
 		return
 	}
 	app.Success(fmt.Sprintf(ui.MessageChangeStatusSuccess, issue.Key, status.Name))
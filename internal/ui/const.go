@@ -6,4 +6,5 @@ const (
 	MaxStatusColWidth   = 12
 	MaxTypeColWidth     = 10
 	MaxAssigneeColWidth = 20
+	MaxCustomColWidth   = 15
 )
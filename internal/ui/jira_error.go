@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/mk-5/fjira/internal/jira"
+)
+
+// JiraErrorReason turns an error returned by jira.Api into the "Reason: ..."
+// part of a flash. Auth and permission problems get a fixed hint, everything
+// else shows what Jira itself said (e.g. "resolution: Field 'resolution' is
+// required"), falling back to the plain error.
+func JiraErrorReason(err error) string {
+	if errors.Is(err, jira.ErrOffline) {
+		return MessageJiraOffline
+	}
+	if errors.Is(err, jira.ErrOAuthRefresh) {
+		return MessageOAuthSessionExpired
+	}
+	var jiraErr *jira.Error
+	if !errors.As(err, &jiraErr) {
+		return err.Error()
+	}
+	details := jiraErr.Details()
+	switch {
+	case jira.IsUnauthorized(err):
+		return MessageJiraUnauthorized
+	case jira.IsRateLimited(err):
+		return MessageJiraRateLimited
+	case jira.IsForbidden(err) && details == "":
+		return MessageJiraForbidden
+	case jira.IsNotFound(err) && details == "":
+		return MessageJiraNotFound
+	}
+	if details != "" {
+		return details
+	}
+	return jiraErr.Status
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mk-5/fjira/internal/jira"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJiraErrorReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"should hint about expired token", &jira.Error{StatusCode: 401, Messages: []string{"Unauthorized"}}, MessageJiraUnauthorized},
+		{"should hint about rate limit", &jira.Error{StatusCode: 429}, MessageJiraRateLimited},
+		{"should hint about permissions", &jira.Error{StatusCode: 403}, MessageJiraForbidden},
+		{"should prefer server message for forbidden", &jira.Error{StatusCode: 403, Messages: []string{"No transition permission"}}, "No transition permission"},
+		{"should hint about missing resource", &jira.Error{StatusCode: 404}, MessageJiraNotFound},
+		{"should show required field", &jira.Error{StatusCode: 400, FieldErrors: map[string]string{"resolution": "Field 'resolution' is required"}}, "resolution: Field 'resolution' is required"},
+		{"should fall back to status", &jira.Error{StatusCode: 500, Status: "500 Internal Server Error"}, "500 Internal Server Error"},
+		{"should explain offline mode", jira.ErrOffline, MessageJiraOffline},
+		{"should ask to sign in again when oauth refresh fails", fmt.Errorf("%w: invalid_grant", jira.ErrOAuthRefresh), MessageOAuthSessionExpired},
+		{"should fall back to plain error", errors.New("connection refused"), "connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, JiraErrorReason(tt.err))
+		})
+	}
+}
@@ -9,9 +9,19 @@ const (
 	MessageSelectWorkspace           = "Select workspace or ESC to cancel"
 	MessageSelectWorkspaceSuccess    = "Workspace has been successfully switched to %s"
 	MessageQuestionMark              = "? "
-	MessageEnterJiraApiToken         = "Jira Api Token: "
+	MessageEnterJiraApiToken         = "Jira Api Token (leave empty for OAuth): "
+	MessageEnterOAuthClientId        = "OAuth client id: "
+	MessageEnterOAuthClientSecret    = "OAuth client secret: "
+	MessageInsecureSkipVerify        = "TLS certificate verification is OFF for this workspace (insecureSkipVerify) - anyone on the network can read your token."
+	MessageOAuthOpenBrowser          = "Sign in to Jira in your browser. If it didn't open, go to:"
 	MessageEnterJiraTokenType        = "Jira Token Type: "
 	MessageEnterJiraTokenNumber      = "Enter a number (Default is 1): "
+	MessageEnterTokenStorage         = "Keep the token in: "
+	MessageEnterTokenStorageNumber   = "Enter a number (Default is %d): "
+	MessageEnterTokenCommand         = "Command printing the token: "
+	MessageEnterTokenEnv             = "Environment variable with the token: "
+	MessageEnterTokenFile            = "Encrypted token file: "
+	MessageEnterTokenPassphrase      = "Passphrase for the token file: "
 	MessageProjectLabel              = "Project: "
 	MessageIssueLabel                = "Issue: "
 	MessageLabelStatus               = "Status: "
@@ -55,8 +65,8 @@ const (
 	MessageChangingStatus            = "Changing status"
 	MessageChangeStatusSuccess       = "Status for issue %s has been successfully changed to %s."
 	MessageAll                       = "All"
-	MessageTypeCommentAndSave        = "Type new comment, and press F2 to save:"
-	MessageTypeDescriptionAndSave    = "Edit description, and press F2 to save:"
+	MessageTypeCommentAndSave        = "Type new comment (Markdown), and press F2 to save:"
+	MessageTypeDescriptionAndSave    = "Edit description (Markdown), and press F2 to save:"
 	MessageTypeJqlAndSave            = "Type new JQL, and press F2 to save:"
 	MessageSummary                   = "Summary"
 	MessageDescription               = "Description"
@@ -72,6 +82,10 @@ const (
 	MessageJumpToRelated             = "Jump "
 	MessageJumpToRelatedFuzzyFind    = "Jump to a related issue or ESC to cancel"
 	MessageNoRelatedToJump           = "no related, parent, epic or child tickets to jump to"
+	MessageOpenLink                  = "Links "
+	MessageOpenLinkFuzzyFind         = "Open a link from the description or comments, or ESC to cancel"
+	MessageNoLinksToOpen             = "no links in the description or comments"
+	MessageCannotOpenLink            = "Cannot open %s. Reason: %s"
 	MessageChangeStatus              = "Change status "
 	MessageByStatus                  = "by status "
 	MessageByAssignee                = "by assignee "
@@ -104,4 +118,116 @@ const (
 	MessageJqlAddSuccess             = "New JQL has been successfully added to your workspace."
 	MessageJqlRemoveSuccess          = "JQL has been successfully removed from your workspace."
 	MessageCustomJql                 = "Custom JQL"
+	MessageCannotChangeStatus        = "Cannot change status of %s to %s. Reason: %s"
+	MessageCannotFetchTransitions    = "Cannot fetch transitions for %s. Reason: %s"
+	MessageJiraUnauthorized          = "Jira rejected your credentials - the token may have expired or been revoked. Update it with: fjira workspace --edit <workspace>"
+	MessageJiraForbidden             = "You don't have permission to do this in Jira"
+	MessageJiraNotFound              = "Not found in Jira - it may have been deleted, or you can't see it"
+	MessageJiraRateLimited           = "Jira is rate limiting requests, try again in a moment"
+	MessageJiraOffline               = "Not available offline - only what was opened while online is kept"
+	MessageOAuthSessionExpired       = "Your Jira sign-in has expired or was revoked. Sign in again with: fjira workspace --edit <workspace>"
+	MessageOfflineMode               = "Offline mode - changes are queued and sent to Jira on the next online start."
+	MessageOfflineReplaySuccess      = "%d offline change(s) sent to Jira."
+	MessageOfflineReplayConflicts    = "%d offline change(s) not sent, e.g. %s: %s. All of them are kept in %s"
+	MessageOfflineReplayRemaining    = "%d offline change(s) still queued, Jira is unreachable"
+	MessageSelectIssueType           = "Select issue type or ESC to cancel"
+	MessageCreateIssueTitle          = "New %s in %s"
+	MessageCreate                    = "Create "
+	MessageEditField                 = "Edit "
+	MessageCreatingIssue             = "Creating issue"
+	MessageCreateIssueSuccess        = "Issue %s has been successfully created."
+	MessageCannotCreateIssue         = "Cannot create issue in %s. Reason: %s"
+	MessageCannotFetchCreateMeta     = "Cannot fetch issue types of %s. Reason: %s"
+	MessageNoIssueTypes              = "You can't create issues in %s"
+	MessageMissingRequiredFields     = "Fill in the required fields: %s"
+	MessageTypeFieldAndSave          = "Type %s, and press F2 to save:"
+	MessageTypeMarkdownFieldAndSave  = "Type %s (Markdown), and press F2 to save:"
+	MessageSelectFieldValue          = "Select %s or ESC to cancel"
+	MessageToggleFieldValue          = "Select %s to add or remove, or ESC to cancel"
+	MessageFieldEmpty                = "-"
+	MessageFieldNone                 = "None"
+	MessageFieldNotSupported         = "not supported in fjira, set it in the browser"
+	MessageCannotEditField           = "%s can't be edited in fjira"
+	MessageTypeDateFieldAndSave      = "Type %s (YYYY-MM-DD), and press F2 to save:"
+	MessageInvalidFieldValue         = "%s isn't a valid %s"
+	MessageEditFields                = "Edit fields "
+	MessageEditFieldsTitle           = "Fields of %s"
+	MessageCannotFetchEditMeta       = "Cannot fetch fields of %s. Reason: %s"
+	MessageNoEditableFields          = "You can't edit fields of %s"
+	MessageUpdatingField             = "Updating %s"
+	MessageUpdateFieldSuccess        = "%s updated successfully for %s"
+	MessageCannotUpdateField         = "Cannot update %s for %s. Reason: %s"
+	MessageCannotFetchFields         = "Cannot fetch custom fields, only the ones given by id are shown. Reason: %s"
+	MessageByField                   = "by field "
+	MessageSelectFilterField         = "Select field to filter by or ESC to cancel"
+	MessageSelectFilterValue         = "Select %s, type it, or ESC to cancel"
+	MessageTransitionTitle           = "Move %s to %s"
+	MessageFieldComment              = "Comment"
+	MessageWorklog                   = "Log time "
+	MessageWorklogTitle              = "Worklog (%s)"
+	MessageWorklogFuzzyFind          = "Type time spent like 1h 30m, select an option, or ESC to cancel"
+	MessageWorklogStartTimer         = "Start timer"
+	MessageWorklogStopTimer          = "Stop timer (%s) and log it"
+	MessageWorklogDiscardTimer       = "Discard timer"
+	MessageWorklogTimerRunning       = "Timer running"
+	MessageWorklogTimerStarted       = "Timer started for %s"
+	MessageWorklogTimerDiscarded     = "Timer of %s discarded"
+	MessageCannotSaveTimer           = "Cannot save the timer of %s. Reason: %s"
+	MessageLoggingWork               = "Logging work"
+	MessageWorklogSuccess            = "%s logged on %s"
+	MessageCannotLogWork             = "Cannot log work on %s. Reason: %s"
+	MessageAttachments               = "Files "
+	MessageAttachmentsTitle          = "Attachments"
+	MessageAttachmentsFuzzyFind      = "Select an attachment, or ESC to cancel"
+	MessageUploadAttachment          = "Upload a file..."
+	MessageAttachmentFuzzyFind       = "Download or open %s, or ESC to cancel"
+	MessageDownloadAttachment        = "Download to %s"
+	MessageOpenAttachment            = "Open"
+	MessageSelectFileToUpload        = "Upload from %s - select or type a path, or ESC to cancel"
+	MessageCannotOpenFile            = "Cannot open %s. Reason: %s"
+	MessageDownloadingAttachment     = "Downloading %s"
+	MessageAttachmentDownloaded      = "%s downloaded to %s"
+	MessageCannotDownloadAttachment  = "Cannot download %s. Reason: %s"
+	MessageUploadingAttachment       = "Uploading %s"
+	MessageAttachmentUploaded        = "%s attached to %s"
+	MessageCannotUploadAttachment    = "Cannot attach %s to %s. Reason: %s"
+	MessageLinks                     = "Link "
+	MessageSearchLinkTypesLoading    = "Fetching link types"
+	MessageCannotFetchLinkTypes      = "Cannot fetch link types. Reason: %s"
+	MessageLinksFuzzyFind            = "Link %s to another issue, remove a link, or ESC to cancel"
+	MessageLinkDirection             = "%s... (%s)"
+	MessageRemoveLink                = "Remove link: %s"
+	MessageSelectIssueToLink         = "%s %s... - select issue or ESC to cancel"
+	MessageLinkingIssues             = "Linking issues"
+	MessageLinkSuccess               = "%s %s %s now"
+	MessageCannotLinkIssues          = "Cannot link %s with %s. Reason: %s"
+	MessageRemoveLinkConfirm         = "Are you sure about removing the link: %s [%s]?"
+	MessageRemovingLink              = "Removing link"
+	MessageRemoveLinkSuccess         = "Link removed: %s %s"
+	MessageCannotRemoveLink          = "Cannot remove link: %s %s. Reason: %s"
+	MessageCreateChild               = "Add child "
+	MessageNoChildIssueTypes         = "You can't add children to %s"
+	MessageTypeChildSummary          = "Type the summary of the new %s under %s and press enter, or ESC to cancel"
+	MessageCreateChildSuccess        = "Issue %s has been successfully created under %s."
+	MessageComments                  = "Comments "
+	MessageCommentsFuzzyFind         = "Select a comment of %s to edit or delete it, or ESC to cancel"
+	MessageAddRestrictedComment      = "Add a comment visible to a role or group..."
+	MessageCommentLine               = "%s: %s"
+	MessageSelectVisibility          = "Restrict the comment to a role, or type to search groups - ESC to cancel"
+	MessageVisibilityRole            = "Role: %s"
+	MessageVisibilityGroup           = "Group: %s"
+	MessageTypeRestrictedComment     = "Type new comment visible to %s only (Markdown), and press F2 to save:"
+	MessageCommentFuzzyFind          = "%s - ESC to cancel"
+	MessageEditComment               = "Edit"
+	MessageDeleteComment             = "Delete"
+	MessageCannotFetchMyself         = "Cannot fetch the current user. Reason: %s"
+	MessageNotYourComment            = "You can only edit or delete your own comments"
+	MessageTypeCommentEditAndSave    = "Edit the comment (Markdown), and press F2 to save:"
+	MessageUpdatingComment           = "Updating comment"
+	MessageCommentUpdated            = "Comment has been successfully updated in issue %s."
+	MessageCannotUpdateComment       = "Cannot update comment in ticket %s. Reason: %s"
+	MessageDeleteCommentConfirm      = "Are you sure about deleting the comment: %s [%s]?"
+	MessageDeletingComment           = "Deleting comment"
+	MessageCommentDeleted            = "Comment has been successfully deleted from issue %s."
+	MessageCannotDeleteComment       = "Cannot delete comment from ticket %s. Reason: %s"
 )
@@ -29,6 +29,14 @@ const (
 	ActionClearFilters
 	ActionToggleSort
 	ActionJumpToRelated
+	ActionOpenLink
+	ActionEditField
+	ActionSearchByField
+	ActionWorklog
+	ActionAttachments
+	ActionLinks
+	ActionCreateChild
+	ActionComments
 )
 
 type NavItemConfig struct {
@@ -280,6 +288,19 @@ func NewToggleSortBarItem(text1 string) *app.ActionBarItem {
 	}
 }
 
+// NewSearchByFieldBarItem creates the F10 item filtering issues by one of
+// the workspace's custom fields; only shown when some are configured.
+func NewSearchByFieldBarItem() *app.ActionBarItem {
+	return &app.ActionBarItem{
+		Id:         int(ActionSearchByField),
+		Text1:      MessageByField,
+		Text2:      "[F10]",
+		Text1Style: bottomBarItemDefaultStyle(),
+		Text2Style: bottomBarActionBarKeyBold(),
+		TriggerKey: tcell.KeyF10,
+	}
+}
+
 func NewMoveIssueBarItem() *app.ActionBarItem {
 	return &app.ActionBarItem{
 		Id:          int(ActionSelect),
@@ -1,6 +1,9 @@
 package users
 
 import (
+	"context"
+	"errors"
+
 	"github.com/mk-5/fjira/internal/app"
 	"github.com/mk-5/fjira/internal/jira"
 )
@@ -21,7 +24,7 @@ func NewApiRecordsProvider(api jira.Api) RecordsProvider {
 
 func (r *apiRecordsProvider) FetchUsers(projectKey string, query string) []jira.User {
 	us, err := r.api.FindUsersWithQuery(projectKey, query)
-	if err != nil {
+	if err != nil && !errors.Is(err, context.Canceled) {
 		app.Error(err.Error())
 	}
 	return us
@@ -109,7 +109,7 @@ func (view *userAssignChangeView) doAssignmentChange(issue *jira.Issue, user *ji
 	err := view.api.DoAssignee(issue.Key, user)
 	app.GetApp().Loading(false)
 	if err != nil {
-		app.Error(fmt.Sprintf(ui.MessageCannotAssignUser, user.DisplayName, issue.Key, err.Error(), user.AccountId))
+		app.Error(fmt.Sprintf(ui.MessageCannotAssignUser, user.DisplayName, issue.Key, ui.JiraErrorReason(err), user.AccountId))
 		return
 	}
 	app.Success(fmt.Sprintf(ui.MessageAssignSuccess, user.DisplayName, issue.Key))
@@ -0,0 +1,205 @@
+package wiki
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/richtext"
+)
+
+var (
+	colorRe = regexp.MustCompile(`^\{color:([^}]+)\}`)
+	imageRe = regexp.MustCompile(`^!([^\s!|][^!|\n]*)(?:\|[^!\n]*)?!`)
+	urlRe   = regexp.MustCompile(`^(?:https?|ftp)://[^\s\]|<>"]+`)
+
+	// emphasis are the markers of *strong*, _emphasis_, -deleted-, +inserted+,
+	// ^superscript^, ~subscript~ and ??citation?? (the ? is doubled).
+	emphasis = map[byte]func(tcell.Style) tcell.Style{
+		'*': func(s tcell.Style) tcell.Style { return s.Bold(true) },
+		'_': func(s tcell.Style) tcell.Style { return s.Italic(true) },
+		'-': func(s tcell.Style) tcell.Style { return s.StrikeThrough(true) },
+		'+': func(s tcell.Style) tcell.Style { return s.Underline(true) },
+		'^': func(s tcell.Style) tcell.Style { return s },
+		'~': func(s tcell.Style) tcell.Style { return s },
+		'?': func(s tcell.Style) tcell.Style { return s.Italic(true) },
+	}
+
+	// emoticons are checked in order, so longer ones come first.
+	emoticons = []struct{ text, emoji string }{
+		{"(flagoff)", "🏳"}, {"(flag)", "🚩"}, {"(off)", "💡"}, {"(on)", "💡"},
+		{"(*r)", "⭐"}, {"(*g)", "⭐"}, {"(*b)", "⭐"}, {"(*y)", "⭐"}, {"(*)", "⭐"},
+		{"(y)", "👍"}, {"(n)", "👎"}, {"(i)", "ℹ️"}, {"(/)", "✅"}, {"(x)", "❌"},
+		{"(!)", "⚠️"}, {"(+)", "➕"}, {"(-)", "➖"}, {"(?)", "❓"},
+		{":)", "🙂"}, {":(", "🙁"}, {":P", "😛"}, {":D", "😀"}, {";)", "😉"},
+	}
+)
+
+// inline turns a paragraph (or a heading, a cell, ...) into spans, base being
+// the style of the block it's in.
+func (r *Renderer) inline(text string, base tcell.Style) []richtext.Span {
+	var spans []richtext.Span
+	var plain strings.Builder
+	for i := 0; i < len(text); {
+		if token, n := r.token(text, i, base); n > 0 {
+			if plain.Len() > 0 {
+				spans = append(spans, richtext.Span{Text: plain.String(), Style: base})
+				plain.Reset()
+			}
+			spans = append(spans, token...)
+			i += n
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(text[i:])
+		plain.WriteRune(c)
+		i += size
+	}
+	if plain.Len() > 0 {
+		spans = append(spans, richtext.Span{Text: plain.String(), Style: base})
+	}
+	return spans
+}
+
+// token reads the markup starting at text[i], and returns its spans and
+// length - 0 when there's none and the character is plain text.
+func (r *Renderer) token(text string, i int, base tcell.Style) ([]richtext.Span, int) {
+	rest := text[i:]
+	switch rest[0] {
+	case '\\':
+		if strings.HasPrefix(rest, `\\`) {
+			return []richtext.Span{{Text: "\n", Style: base}}, 2
+		}
+		if len(rest) > 1 {
+			c, size := utf8.DecodeRuneInString(rest[1:])
+			return []richtext.Span{{Text: string(c), Style: base}}, 1 + size
+		}
+	case '{':
+		if strings.HasPrefix(rest, "{{") {
+			if end := strings.Index(rest[2:], "}}"); end > 0 {
+				return []richtext.Span{{Text: rest[2 : 2+end], Style: r.theme.Code}}, end + 4
+			}
+		}
+		if m := colorRe.FindStringSubmatch(rest); m != nil {
+			if body, _, found := strings.Cut(rest[len(m[0]):], "{color}"); found {
+				return r.inline(body, base.Foreground(tcell.GetColor(strings.TrimSpace(m[1])))), len(m[0]) + len(body) + len("{color}")
+			}
+		}
+	case '[':
+		if end := strings.IndexByte(rest, ']'); end > 1 && !strings.Contains(rest[:end], "\n") {
+			return r.link(rest[1:end], base), end + 1
+		}
+	case '!':
+		if m := imageRe.FindStringSubmatch(rest); m != nil && strings.Contains(m[1], ".") {
+			return r.image(m[1]), len(m[0])
+		}
+	}
+	if m := urlRe.FindString(rest); m != "" && !wordBefore(text, i) {
+		url := strings.TrimRight(m, ".,;:!?)")
+		return r.footnoted([]richtext.Span{{Text: url, Style: r.theme.Linked(base)}}, url), len(url)
+	}
+	if style, ok := emphasis[rest[0]]; ok {
+		if body, n := Emphasized(text, i); n > 0 {
+			spans := r.inline(body, style(base))
+			if rest[0] == '?' {
+				spans = append([]richtext.Span{{Text: "— ", Style: style(base)}}, spans...)
+			}
+			return spans, n
+		}
+	}
+	for _, e := range emoticons {
+		if strings.HasPrefix(rest, e.text) && !wordAfter(text, i+len(e.text)) {
+			return []richtext.Span{{Text: e.emoji, Style: base}}, len(e.text)
+		}
+	}
+	return nil, 0
+}
+
+// Emphasized reads "*text*" at text[i]: the marker has to open a word and
+// close one, on the same line, e.g. not the - in "well-known" or "a - b".
+func Emphasized(text string, i int) (string, int) {
+	marker := text[i : i+1]
+	if marker == "?" {
+		marker = "??"
+	}
+	open := i + len(marker)
+	if !strings.HasPrefix(text[i:], marker) || wordBefore(text, i) || open >= len(text) {
+		return "", 0
+	}
+	if c, _ := utf8.DecodeRuneInString(text[open:]); unicode.IsSpace(c) || strings.HasPrefix(text[open:], marker[:1]) {
+		return "", 0
+	}
+	for j := open + 1; j+len(marker) <= len(text); j++ {
+		if text[j] == '\n' {
+			return "", 0
+		}
+		if !strings.HasPrefix(text[j:], marker) {
+			continue
+		}
+		if c, _ := utf8.DecodeLastRuneInString(text[:j]); unicode.IsSpace(c) || wordAfter(text, j+len(marker)) {
+			continue
+		}
+		return text[open:j], j + len(marker) - i
+	}
+	return "", 0
+}
+
+// link renders [url], [text|url], [~user], [^attachment] and [#anchor].
+// Urls get a footnote; links within Jira (issue keys, pages) are only styled.
+func (r *Renderer) link(content string, base tcell.Style) []richtext.Span {
+	text, target, found := strings.Cut(content, "|")
+	if !found {
+		target = text
+	}
+	target = strings.TrimSpace(target)
+	switch {
+	case strings.HasPrefix(target, "~"):
+		user := strings.TrimPrefix(target[1:], "accountid:")
+		if found {
+			user = strings.TrimPrefix(text, "@")
+		}
+		return []richtext.Span{{Text: "@" + user, Style: r.theme.Mention}}
+	case strings.HasPrefix(target, "^"):
+		if !found {
+			text = target[1:]
+		}
+		return []richtext.Span{{Text: "[" + text + "]", Style: r.theme.Dim}}
+	case strings.HasPrefix(target, "#") && !found:
+		text = target[1:]
+	}
+	spans := []richtext.Span{{Text: text, Style: r.theme.Linked(base)}}
+	if found {
+		spans = r.inline(text, r.theme.Linked(base))
+	}
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return r.footnoted(spans, target)
+	}
+	return spans
+}
+
+// image renders !name.png! (and !name.png|thumbnail!) like an attachment.
+func (r *Renderer) image(name string) []richtext.Span {
+	if strings.Contains(name, "://") {
+		return r.footnoted([]richtext.Span{{Text: "[image]", Style: r.theme.Dim}}, name)
+	}
+	return []richtext.Span{{Text: "[" + name + "]", Style: r.theme.Dim}}
+}
+
+// footnoted puts the footnote number of url after spans.
+func (r *Renderer) footnoted(spans []richtext.Span, url string) []richtext.Span {
+	return append(spans, richtext.Span{Text: fmt.Sprintf("[%d]", r.footnote(url)), Style: r.theme.Dim})
+}
+
+// wordBefore tells whether text[i] is preceded by a letter or a digit.
+func wordBefore(text string, i int) bool {
+	c, _ := utf8.DecodeLastRuneInString(text[:i])
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// wordAfter tells whether text[i] is a letter or a digit.
+func wordAfter(text string, i int) bool {
+	c, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,307 @@
+// Package wiki renders Jira wiki markup - the text format of descriptions and
+// comments on Server/Data Center, and on Cloud's v2 api - as styled terminal
+// text.
+//
+// https://jira.atlassian.com/secure/WikiRendererHelpAction.jspa?section=all
+package wiki
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mk-5/fjira/internal/richtext"
+	"github.com/rivo/uniseg"
+)
+
+var (
+	headingRe = regexp.MustCompile(`^\s*h([1-6])\.\s+(.*)$`)
+	quoteRe   = regexp.MustCompile(`^\s*bq\.\s+(.*)$`)
+	listRe    = regexp.MustCompile(`^\s*([*#]+|-)\s+(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	macroRe   = regexp.MustCompile(`^[ \t]*\{(code|noformat|quote|panel|info|note|warning|tip)(?::([^}]*))?\}`)
+
+	bullets = []string{"• ", "◦ ", "▪ "}
+	// panelTypes maps the panel macros to the panel colors and icons.
+	panelTypes = map[string]string{"panel": "", "info": "info", "note": "note", "warning": "warning", "tip": "success"}
+)
+
+// Renderer lays out wiki markup. Links become footnotes - "text[1]", with the
+// numbered urls listed under the text - numbered on across Render calls, so a
+// description and its comments share one list of Links.
+type Renderer struct {
+	theme richtext.Theme
+	links []string
+}
+
+func NewRenderer(theme richtext.Theme) *Renderer {
+	return &Renderer{theme: theme}
+}
+
+// Render lays text out for a width of width cells. A width <= 0 doesn't wrap.
+func (r *Renderer) Render(text string, width int) []richtext.Line {
+	first := len(r.links)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := r.blocks(text, width)
+	if len(r.links) > first {
+		lines = append(lines, richtext.Line{})
+		for i := first; i < len(r.links); i++ {
+			lines = append(lines, richtext.Wrap([]richtext.Span{{Text: fmt.Sprintf("[%d] %s", i+1, r.links[i]), Style: r.theme.Dim}}, width)...)
+		}
+	}
+	return lines
+}
+
+// Links are the urls of the footnotes so far; footnote [n] is Links()[n-1].
+func (r *Renderer) Links() []string {
+	return r.links
+}
+
+// footnote numbers url, reusing the number of a url seen before.
+func (r *Renderer) footnote(url string) int {
+	for i, l := range r.links {
+		if l == url {
+			return i + 1
+		}
+	}
+	r.links = append(r.links, url)
+	return len(r.links)
+}
+
+// blocks renders the blocks of text one below the other, a blank line
+// between each.
+func (r *Renderer) blocks(text string, width int) []richtext.Line {
+	var lines []richtext.Line
+	add := func(block []richtext.Line) {
+		if len(block) == 0 {
+			return
+		}
+		if len(lines) > 0 {
+			lines = append(lines, richtext.Line{})
+		}
+		lines = append(lines, block...)
+	}
+	for text != "" {
+		line, rest := cutLine(text)
+		if strings.TrimSpace(line) == "" {
+			text = rest
+			continue
+		}
+		if m := macroRe.FindStringSubmatch(text); m != nil {
+			var block []richtext.Line
+			block, text = r.macro(m[1], m[2], text[len(m[0]):], width)
+			add(block)
+			continue
+		}
+		switch {
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			style := r.theme.Heading
+			if m[1] == "1" {
+				style = style.Underline(true)
+			}
+			add(richtext.Wrap(r.inline(m[2], style), width))
+			text = rest
+		case quoteRe.MatchString(line):
+			m := quoteRe.FindStringSubmatch(line)
+			add(richtext.Quote(richtext.Wrap(r.inline(m[1], r.theme.Text), width-richtext.QuoteInset), r.theme))
+			text = rest
+		case ruleRe.MatchString(line):
+			add(richtext.Rule(width, r.theme))
+			text = rest
+		case listRe.MatchString(line):
+			var items []string
+			items, text = takeLines(text, listRe.MatchString)
+			add(r.list(items, width))
+		case isTableRow(line):
+			var rows []string
+			rows, text = takeLines(text, isTableRow)
+			add(r.table(rows, width))
+		default:
+			var paragraph []string
+			paragraph, text = takeLines(text, func(l string) bool {
+				return strings.TrimSpace(l) != "" && !startsBlock(l)
+			})
+			add(richtext.Wrap(r.inline(strings.Join(paragraph, "\n"), r.theme.Text), width))
+		}
+	}
+	return lines
+}
+
+// macro renders a {code}, {quote}, {panel}, ... macro whose opening tag has
+// been read, and returns the text after its closing tag.
+func (r *Renderer) macro(name, params, text string, width int) ([]richtext.Line, string) {
+	closing := "{" + name + "}"
+	body, rest, found := strings.Cut(text, closing)
+	if !found {
+		body, rest = text, ""
+	}
+	switch name {
+	case "code", "noformat":
+		body = strings.TrimPrefix(body, "\n")
+		language := ""
+		if name == "code" {
+			language = CodeLanguage(params)
+		}
+		return richtext.CodeBlock(body, language, width, r.theme), rest
+	case "quote":
+		return richtext.Quote(r.blocks(body, width-richtext.QuoteInset), r.theme), rest
+	default:
+		content := r.blocks(body, width-richtext.PanelInset)
+		return richtext.Panel(content, panelTypes[name], MacroParam(params, "title"), r.theme), rest
+	}
+}
+
+// list renders list items - "* a", "** nested", "# numbered", "- a" - with
+// their marks hanging in front of them.
+func (r *Renderer) list(items []string, width int) []richtext.Line {
+	var lines []richtext.Line
+	// indents are the mark widths of the items the current one is nested in,
+	// numbers the counters of the numbered levels
+	var indents, numbers []int
+	previous := ""
+	for _, item := range items {
+		m := listRe.FindStringSubmatch(item)
+		marks, text := m[1], m[2]
+		depth := len(marks)
+		indents = indents[:min(len(indents), depth-1)]
+		for len(numbers) < depth {
+			numbers = append(numbers, 0)
+		}
+		numbers = numbers[:depth]
+		if len(previous) < depth || previous[:depth] != marks {
+			// a new list at this level
+			numbers[depth-1] = 0
+		}
+		numbers[depth-1]++
+		previous = marks
+
+		mark := bullets[(depth-1)%len(bullets)]
+		if marks[depth-1] == '#' {
+			mark = strconv.Itoa(numbers[depth-1]) + ". "
+		}
+		indent := 0
+		for _, w := range indents {
+			indent += w
+		}
+		markWidth := uniseg.StringWidth(mark)
+		indents = append(indents, markWidth)
+
+		content := richtext.Wrap(r.inline(text, r.theme.Text), width-indent-markWidth)
+		if len(content) == 0 {
+			content = []richtext.Line{{}}
+		}
+		lead := strings.Repeat(" ", indent)
+		lines = append(lines, richtext.Indent(content,
+			richtext.Span{Text: lead + mark, Style: r.theme.Text},
+			richtext.Span{Text: lead + strings.Repeat(" ", markWidth), Style: r.theme.Text})...)
+	}
+	return lines
+}
+
+// table renders "||heading||heading||" and "|cell|cell|" rows.
+func (r *Renderer) table(rows []string, width int) []richtext.Line {
+	cells := make([][]richtext.Cell, 0, len(rows))
+	for _, row := range rows {
+		var cols []richtext.Cell
+		for _, c := range SplitRow(strings.TrimSpace(row)) {
+			spans := r.inline(c.Text, r.theme.Text)
+			cols = append(cols, richtext.Cell{
+				Header: c.Header,
+				Render: func(width int) []richtext.Line { return richtext.Wrap(spans, width) },
+			})
+		}
+		cells = append(cells, cols)
+	}
+	return richtext.Table(cells, width, r.theme)
+}
+
+// TableCell is a cell of a table row, as SplitRow reads it.
+type TableCell struct {
+	Text   string
+	Header bool
+}
+
+// SplitRow splits a table row at the | (or || for headings) that aren't in a
+// link or a macro, e.g. "|[a|http://b]|{{c}}|".
+func SplitRow(row string) []TableCell {
+	var cells []TableCell
+	for i := 0; i < len(row); {
+		header := strings.HasPrefix(row[i:], "||")
+		switch {
+		case header:
+			i += 2
+		case row[i] == '|':
+			i++
+		}
+		start, depth := i, 0
+		for ; i < len(row) && (depth > 0 || row[i] != '|'); i++ {
+			switch row[i] {
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth = max(depth-1, 0)
+			}
+		}
+		text := strings.TrimSpace(row[start:i])
+		if i >= len(row) && text == "" {
+			// the closing |
+			break
+		}
+		cells = append(cells, TableCell{Text: text, Header: header})
+	}
+	return cells
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// startsBlock tells whether line ends a paragraph by starting another block.
+func startsBlock(line string) bool {
+	return headingRe.MatchString(line) || quoteRe.MatchString(line) || ruleRe.MatchString(line) ||
+		listRe.MatchString(line) || isTableRow(line) || macroRe.MatchString(line)
+}
+
+// takeLines takes the leading lines of text that match.
+func takeLines(text string, match func(string) bool) ([]string, string) {
+	var lines []string
+	for text != "" {
+		line, rest := cutLine(text)
+		if !match(line) {
+			break
+		}
+		lines = append(lines, line)
+		text = rest
+	}
+	return lines, text
+}
+
+func cutLine(text string) (string, string) {
+	line, rest, _ := strings.Cut(text, "\n")
+	return line, rest
+}
+
+// CodeLanguage reads the language of {code:java} or {code:language=java|...}.
+func CodeLanguage(params string) string {
+	if language := MacroParam(params, "language"); language != "" {
+		return language
+	}
+	first, _, _ := strings.Cut(params, "|")
+	if strings.Contains(first, "=") {
+		return ""
+	}
+	return strings.TrimSpace(first)
+}
+
+// MacroParam reads key from macro params like "title=Steps|borderStyle=solid".
+func MacroParam(params, key string) string {
+	for _, p := range strings.Split(params, "|") {
+		k, v, found := strings.Cut(p, "=")
+		if found && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mk-5/fjira/internal/richtext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTheme = richtext.Theme{
+	Code:    tcell.StyleDefault.Background(tcell.ColorGray),
+	Link:    tcell.StyleDefault.Foreground(tcell.ColorBlue).Underline(true),
+	Mention: tcell.StyleDefault.Foreground(tcell.ColorGreen),
+	Dim:     tcell.StyleDefault.Foreground(tcell.ColorDarkGray),
+}
+
+func Test_Render(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"should keep plain text", "Lorem ipsum", 40, "Lorem ipsum"},
+		{"should keep line breaks and separate paragraphs", "one\ntwo\n\n\nthree", 40, "one\ntwo\n\nthree"},
+		{"should wrap paragraphs", "the quick brown fox", 10, "the quick\nbrown fox"},
+		{"should drop emphasis markers", "*bold* _em_ -gone- +under+ {{code}} ??cite??", 80, "bold em gone under code — cite"},
+		{"should keep markers inside words", "well-known snake_case 2+2 a * b", 80, "well-known snake_case 2+2 a * b"},
+		{"should unescape", `\*not bold\* line\\break`, 80, "*not bold* line\nbreak"},
+		{"should render headings", "h2. Steps to reproduce\nfirst", 80, "Steps to reproduce\n\nfirst"},
+		{"should render nested lists",
+			"* one\n** nested\n*# first\n*# second\n* two\n- dash",
+			80, "• one\n  ◦ nested\n  1. first\n  2. second\n• two\n• dash"},
+		{"should wrap list items under their text", "# the quick brown fox", 12, "1. the quick\n   brown fox"},
+		{"should keep code blocks",
+			"{code:java}\nclass A {\n  int b;\n}\n{code}\nafter",
+			20, " java\n class A {\n   int b;\n }\n\nafter"},
+		{"should keep noformat blocks", "{noformat}*not bold*{noformat}", 20, " *not bold*"},
+		{"should render tables",
+			"||Key||Status||\n|FJ-1|Done|\n|[link|http://example.com/a]|{{x|y}}|",
+			80, "Key     │ Status\n────────┼───────\nFJ-1    │ Done\nlink[1] │ x|y\n\n[1] http://example.com/a"},
+		{"should render quotes", "bq. quoted\n{quote}\nmore\n{quote}", 40, "│ quoted\n\n│ more"},
+		{"should render panels", "{warning:title=Careful}\ndon't\n{warning}", 40, "▌⚠  Careful\n▌   don't"},
+		{"should render rules", "a\n----\nb", 5, "a\n\n─────\n\nb"},
+		{"should footnote links",
+			"see [the docs|https://example.com/docs], https://example.com/x. and [https://example.com/docs]",
+			80, "see the docs[1], https://example.com/x[2]. and https://example.com/docs[1]\n\n[1] https://example.com/docs\n[2] https://example.com/x"},
+		{"should not footnote links within jira", "[FJ-1] and [#anchor]", 80, "FJ-1 and anchor"},
+		{"should render mentions, attachments and images",
+			"[~alice] [~accountid:5b10] [^log.txt] !screen.png|thumbnail! hello!",
+			80, "@alice @5b10 [log.txt] [screen.png] hello!"},
+		{"should render emoticons", "(/) done :) (x)", 80, "✅ done 🙂 ❌"},
+		{"should render colors", "{color:red}red{color} text", 80, "red text"},
+		{"should read unclosed macros to the end", "{code}\nno end", 20, " no end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRenderer(testTheme).Render(tt.text, tt.width)
+
+			assert.Equal(t, tt.want, richtext.String(got))
+		})
+	}
+}
+
+func Test_Render_should_style_markup(t *testing.T) {
+	// given
+	r := NewRenderer(testTheme)
+
+	// when
+	lines := r.Render("*b* _i_ {{c}} [~bob] {color:#ff0000}red{color}", 80)
+
+	// then
+	assert.Len(t, lines, 1)
+	spans := lines[0]
+	assert.Equal(t, tcell.StyleDefault.Bold(true), spans[0].Style)
+	assert.Equal(t, tcell.StyleDefault.Italic(true), spans[2].Style)
+	assert.Equal(t, testTheme.Code, spans[4].Style)
+	assert.Equal(t, testTheme.Mention, spans[6].Style)
+	assert.Equal(t, tcell.StyleDefault.Foreground(tcell.NewHexColor(0xff0000)), spans[8].Style)
+}
+
+func Test_Renderer_should_number_links_across_renders(t *testing.T) {
+	// given
+	r := NewRenderer(testTheme)
+
+	// when
+	description := r.Render("[a|http://a]", 80)
+	comment := r.Render("[b|http://b] [again|http://a]", 80)
+
+	// then
+	assert.Equal(t, "a[1]\n\n[1] http://a", richtext.String(description))
+	assert.Equal(t, "b[2] again[1]\n\n[2] http://b", richtext.String(comment))
+	assert.Equal(t, []string{"http://a", "http://b"}, r.Links())
+}
@@ -0,0 +1,246 @@
+package workspaces
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// PassphraseEnv unlocks jiraTokenFile without a prompt, e.g. in scripts.
+	PassphraseEnv = "FJIRA_PASSPHRASE"
+	// TokensDirname is where encrypted token files go by default, under the
+	// config dir.
+	TokensDirname        = "tokens"
+	encryptedTokenKdf    = "pbkdf2-sha256"
+	encryptedTokenRounds = 600_000
+)
+
+var (
+	ErrTokenCommandFailed   = errors.New("jiraTokenCommand failed")
+	ErrTokenEnvMissing      = errors.New("the environment variable from jiraTokenEnv is not set")
+	ErrTokenFileUnreadable  = errors.New("cannot decrypt jiraTokenFile - wrong passphrase, or the file is damaged")
+	ErrPassphraseMissing    = errors.New("a passphrase is needed to decrypt jiraTokenFile, set " + PassphraseEnv + " or run fjira in a terminal")
+	ErrCredentialsAmbiguous = errors.New("only one of jiraToken, jiraTokenCommand, jiraTokenEnv and jiraTokenFile can be set")
+)
+
+// ReadPassphrase asks for the passphrase of an encrypted token file: from
+// FJIRA_PASSPHRASE, or typed in the terminal. Swapped in tests.
+var ReadPassphrase = func(prompt string) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", ErrPassphraseMissing
+	}
+	fmt.Print(prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(passphrase), nil
+}
+
+// CredentialSource is where the token of a workspace comes from. Anything
+// but the plain jiraToken keeps the secret out of fjira.yaml.
+type CredentialSource interface {
+	Token() (string, error)
+}
+
+// plainToken is jiraToken, stored as is in fjira.yaml.
+type plainToken string
+
+func (t plainToken) Token() (string, error) {
+	return string(t), nil
+}
+
+// tokenCommand runs a command printing the token, like `pass show jira` or
+// `op read op://Private/jira/token`, through the shell.
+type tokenCommand string
+
+func (c tokenCommand) Token() (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(shell, flag, string(c))
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v %s", ErrTokenCommandFailed, c, err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s printed nothing", ErrTokenCommandFailed, c)
+	}
+	return token, nil
+}
+
+// tokenEnv names an environment variable holding the token.
+type tokenEnv string
+
+func (e tokenEnv) Token() (string, error) {
+	token := os.Getenv(string(e))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s", ErrTokenEnvMissing, e)
+	}
+	return token, nil
+}
+
+// encryptedTokenFile is a token encrypted with a passphrase, see
+// WriteEncryptedToken.
+type encryptedTokenFile string
+
+func (f encryptedTokenFile) Token() (string, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return "", err
+	}
+	passphrase, err := ReadPassphrase(fmt.Sprintf("Passphrase for %s: ", f))
+	if err != nil {
+		return "", err
+	}
+	return DecryptToken(data, passphrase)
+}
+
+// CredentialSource returns the configured source of the token; a plain
+// (maybe empty) jiraToken when nothing else is set.
+func (s *WorkspaceSettings) CredentialSource() (CredentialSource, error) {
+	var sources []CredentialSource
+	if s.JiraToken != "" {
+		sources = append(sources, plainToken(s.JiraToken))
+	}
+	if s.JiraTokenCommand != "" {
+		sources = append(sources, tokenCommand(s.JiraTokenCommand))
+	}
+	if s.JiraTokenEnv != "" {
+		sources = append(sources, tokenEnv(s.JiraTokenEnv))
+	}
+	if s.JiraTokenFile != "" {
+		sources = append(sources, encryptedTokenFile(expandHome(s.JiraTokenFile)))
+	}
+	switch len(sources) {
+	case 0:
+		return plainToken(""), nil
+	case 1:
+		return sources[0], nil
+	}
+	return nil, ErrCredentialsAmbiguous
+}
+
+// ResolveToken reads the token from the credential source. It's meant to be
+// passed on to the api only - never stored back into JiraToken, or the next
+// settings write would put it into fjira.yaml.
+func (s *WorkspaceSettings) ResolveToken() (string, error) {
+	source, err := s.CredentialSource()
+	if err != nil {
+		return "", err
+	}
+	return source.Token()
+}
+
+type encryptedToken struct {
+	Kdf        string `json:"kdf"`
+	Rounds     int    `json:"rounds"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptToken seals the token with AES-256-GCM, under a key derived from
+// the passphrase with PBKDF2.
+func EncryptToken(token string, passphrase string) ([]byte, error) {
+	sealed := encryptedToken{Kdf: encryptedTokenKdf, Rounds: encryptedTokenRounds, Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return nil, err
+	}
+	gcm, err := tokenCipher(passphrase, sealed)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, err
+	}
+	sealed.Ciphertext = gcm.Seal(nil, sealed.Nonce, []byte(token), nil)
+	return json.MarshalIndent(sealed, "", "  ")
+}
+
+func DecryptToken(data []byte, passphrase string) (string, error) {
+	var sealed encryptedToken
+	if err := json.Unmarshal(data, &sealed); err != nil || sealed.Kdf != encryptedTokenKdf {
+		return "", ErrTokenFileUnreadable
+	}
+	gcm, err := tokenCipher(passphrase, sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return "", ErrTokenFileUnreadable
+	}
+	token, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return "", ErrTokenFileUnreadable
+	}
+	return string(token), nil
+}
+
+func tokenCipher(passphrase string, sealed encryptedToken) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, sealed.Salt, sealed.Rounds, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteEncryptedToken encrypts the token into path, readable by the owner only.
+func WriteEncryptedToken(path string, token string, passphrase string) error {
+	data, err := EncryptToken(token, passphrase)
+	if err != nil {
+		return err
+	}
+	path = expandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return writePrivateFile(path, data)
+}
+
+// writePrivateFile writes with 0600, also tightening a file that already
+// exists with wider permissions - os.WriteFile keeps those.
+func writePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,69 @@
+package workspaces
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WorkspaceSettings_ResolveToken(t *testing.T) {
+	t.Setenv("FJIRA_TEST_TOKEN", "from-env")
+	tokenFile := filepath.Join(t.TempDir(), "jira.token")
+	_ = WriteEncryptedToken(tokenFile, "from-file", "secret")
+	ReadPassphrase = func(string) (string, error) { return "secret", nil }
+	tests := []struct {
+		name     string
+		settings WorkspaceSettings
+		want     string
+		wantErr  error
+	}{
+		{"should use plain token", WorkspaceSettings{JiraToken: "plain"}, "plain", nil},
+		{"should run token command", WorkspaceSettings{JiraTokenCommand: "echo '  from-command '"}, "from-command", nil},
+		{"should read token env", WorkspaceSettings{JiraTokenEnv: "FJIRA_TEST_TOKEN"}, "from-env", nil},
+		{"should decrypt token file", WorkspaceSettings{JiraTokenFile: tokenFile}, "from-file", nil},
+		{"should allow no token", WorkspaceSettings{}, "", nil},
+		{"should fail when command fails", WorkspaceSettings{JiraTokenCommand: "exit 3"}, "", ErrTokenCommandFailed},
+		{"should fail when command prints nothing", WorkspaceSettings{JiraTokenCommand: "true"}, "", ErrTokenCommandFailed},
+		{"should fail when env is not set", WorkspaceSettings{JiraTokenEnv: "FJIRA_TEST_MISSING"}, "", ErrTokenEnvMissing},
+		{"should not guess between sources", WorkspaceSettings{JiraToken: "plain", JiraTokenEnv: "FJIRA_TEST_TOKEN"}, "", ErrCredentialsAmbiguous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got, err := tt.settings.ResolveToken()
+
+			// then
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_WriteEncryptedToken(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), TokensDirname, "default.token")
+
+	// when
+	err := WriteEncryptedToken(path, "jira-token", "secret")
+
+	// then
+	assert.Nil(t, err)
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, _ := os.ReadFile(path)
+	assert.NotContains(t, string(data), "jira-token")
+	token, err := DecryptToken(data, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "jira-token", token)
+	_, err = DecryptToken(data, "wrong")
+	assert.Equal(t, ErrTokenFileUnreadable, err)
+	_, err = DecryptToken([]byte("jira-token"), "secret")
+	assert.Equal(t, ErrTokenFileUnreadable, err)
+}
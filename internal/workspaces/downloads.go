@@ -0,0 +1,36 @@
+package workspaces
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	os2 "github.com/mk-5/fjira/internal/os"
+)
+
+// DownloadDir is where attachments are downloaded to: the downloadDir of the
+// session's workspace settings, or ~/Downloads - the home directory when
+// there's no such directory. settings may be nil.
+func DownloadDir(settings *WorkspaceSettings) string {
+	home := os2.MustGetUserHomeDir()
+	if settings != nil && settings.DownloadDir != "" {
+		return ExpandHomeDir(settings.DownloadDir)
+	}
+	downloads := filepath.Join(home, "Downloads")
+	if stat, err := os.Stat(downloads); err == nil && stat.IsDir() {
+		return downloads
+	}
+	return home
+}
+
+// ExpandHomeDir replaces a leading "~" of the path with the home directory.
+func ExpandHomeDir(path string) string {
+	home := os2.MustGetUserHomeDir()
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
@@ -0,0 +1,60 @@
+package workspaces
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// sessionTimers are the timers of sessions without a workspace - settings
+	// from env variables, --demo - which aren't kept between runs
+	sessionTimers      = map[string]time.Time{}
+	sessionTimersMutex sync.Mutex
+)
+
+// IssueTimerKey builds the storage key for an issue's worklog timer, scoped
+// per connection (workspace).
+func IssueTimerKey(workspace string, issueKey string) string {
+	return fmt.Sprintf("%s/%s", workspace, issueKey)
+}
+
+// StartIssueTimer starts a worklog timer on the issue in the given workspace
+// - the session's, see WorkspaceSettings.Workspace. The timer lives in
+// fjira.yaml, so it keeps running across restarts until it's stopped.
+func StartIssueTimer(workspace string, issueKey string, started time.Time) error {
+	if workspace == EmptyWorkspace {
+		sessionTimersMutex.Lock()
+		defer sessionTimersMutex.Unlock()
+		sessionTimers[issueKey] = started
+		return nil
+	}
+	s := NewUserHomeSettingsStorage().(*userHomeSettingsStorage)
+	return s.WriteIssueTimer(IssueTimerKey(workspace, issueKey), started)
+}
+
+// LoadIssueTimer returns when the issue's timer was started; found=false
+// means there's no timer running.
+func LoadIssueTimer(workspace string, issueKey string) (time.Time, bool, error) {
+	if workspace == EmptyWorkspace {
+		sessionTimersMutex.Lock()
+		defer sessionTimersMutex.Unlock()
+		started, ok := sessionTimers[issueKey]
+		return started, ok, nil
+	}
+	s := NewUserHomeSettingsStorage().(*userHomeSettingsStorage)
+	return s.ReadIssueTimer(IssueTimerKey(workspace, issueKey))
+}
+
+// StopIssueTimer removes the issue's timer, if there is one. Logging the time
+// is up to the caller.
+func StopIssueTimer(workspace string, issueKey string) error {
+	if workspace == EmptyWorkspace {
+		sessionTimersMutex.Lock()
+		defer sessionTimersMutex.Unlock()
+		delete(sessionTimers, issueKey)
+		return nil
+	}
+	s := NewUserHomeSettingsStorage().(*userHomeSettingsStorage)
+	return s.DeleteIssueTimer(IssueTimerKey(workspace, issueKey))
+}
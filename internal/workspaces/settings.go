@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/mk-5/fjira/internal/jira"
 	os2 "github.com/mk-5/fjira/internal/os"
@@ -17,6 +18,9 @@ type Settings struct {
 	// label, excluded statuses) the user last viewed, keyed per connection and
 	// project so they're restored on the next launch. Key: "<workspace>/<projectId>".
 	IssueFilters map[string]ProjectIssueFilters `json:"issueFilters,omitempty" yaml:"issueFilters,omitempty"`
+	// IssueTimers are the running worklog timers - when each was started - so
+	// they keep running while fjira is closed. Key: "<workspace>/<issueKey>".
+	IssueTimers map[string]time.Time `json:"issueTimers,omitempty" yaml:"issueTimers,omitempty"`
 }
 
 // ProjectIssueFilters is the persisted, network-free snapshot of the issue
@@ -36,6 +40,8 @@ type ProjectIssueFilters struct {
 	// SortByUpdated is the F9 sort toggle: true = ORDER BY updated, false =
 	// ORDER BY status (the default). Absent in older config unmarshals to false.
 	SortByUpdated bool `json:"sortByUpdated,omitempty" yaml:"sortByUpdated,omitempty"`
+	// FieldFilters are the custom field filters, value by field id.
+	FieldFilters map[string]string `json:"fieldFilters,omitempty" yaml:"fieldFilters,omitempty"`
 }
 
 type WorkspaceSettings struct {
@@ -43,7 +49,45 @@ type WorkspaceSettings struct {
 	JiraToken     string             `json:"jiraToken" yaml:"jiraToken"`
 	JiraUsername  string             `json:"jiraUsername" yaml:"jiraUsername"`
 	JiraTokenType jira.JiraTokenType `json:"jiraTokenType" yaml:"jiraTokenType"`
-	Workspace     string             `json:"-" yaml:"-"`
+	// JiraTokenCommand, JiraTokenEnv and JiraTokenFile keep the token out of
+	// fjira.yaml: a command printing it (e.g. `pass show jira`), the name of an
+	// environment variable holding it, or a passphrase-encrypted file. At most
+	// one of them, or JiraToken, is set - see CredentialSource.
+	JiraTokenCommand string `json:"jiraTokenCommand,omitempty" yaml:"jiraTokenCommand,omitempty"`
+	JiraTokenEnv     string `json:"jiraTokenEnv,omitempty" yaml:"jiraTokenEnv,omitempty"`
+	JiraTokenFile    string `json:"jiraTokenFile,omitempty" yaml:"jiraTokenFile,omitempty"`
+	// Retry overrides jira.DefaultRetryPolicy for this workspace, e.g. to back
+	// off longer on a heavily rate-limited Cloud site. Nil means the default;
+	// delays left unset fall back to the default ones.
+	Retry *jira.RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// Cache tunes the response cache; nil means in-memory caching with the
+	// default TTLs.
+	Cache *jira.CacheConfig `json:"cache,omitempty" yaml:"cache,omitempty"`
+	// Connection holds the CA bundle, client certificate and proxy of this
+	// workspace; nil means the system defaults.
+	Connection *jira.ConnectionConfig `json:"connection,omitempty" yaml:"connection,omitempty"`
+	// CustomFields lists the custom fields shown in the issue view, as
+	// columns of issue lists, and as issue search filters.
+	CustomFields *jira.CustomFieldsConfig `json:"customFields,omitempty" yaml:"customFields,omitempty"`
+	// DownloadDir is where attachments are downloaded to; "~/" is the home
+	// directory. Empty means ~/Downloads, see DownloadDir.
+	DownloadDir string `json:"downloadDir,omitempty" yaml:"downloadDir,omitempty"`
+	// DeploymentType and ServerVersion cache the serverInfo probe made on the
+	// first launch against this workspace; cleared when the URL changes.
+	DeploymentType jira.DeploymentType `json:"deploymentType,omitempty" yaml:"deploymentType,omitempty"`
+	ServerVersion  string              `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+	// OAuth and OAuthTokens replace JiraToken for the "oauth" token type. The
+	// tokens are rewritten after every refresh.
+	OAuth       *jira.OAuthConfig `json:"oauth,omitempty" yaml:"oauth,omitempty"`
+	OAuthTokens *jira.OAuthTokens `json:"oauthTokens,omitempty" yaml:"oauthTokens,omitempty"`
+	Workspace   string            `json:"-" yaml:"-"`
+	// Offline is set by the --offline flag: fjira works on the local snapshot
+	// of the workspace and queues changes instead of calling Jira.
+	Offline bool `json:"-" yaml:"-"`
+	// Record and Replay are set by --record/--replay: the directory the
+	// session's http traffic is recorded into, or served back from.
+	Record string `json:"-" yaml:"-"`
+	Replay string `json:"-" yaml:"-"`
 }
 
 type SettingsStorage interface { //nolint
@@ -102,8 +146,9 @@ func (s *userHomeSettingsStorage) writeSettings(settings *Settings) error {
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(settingsFilePath, settingsYml, 0644)
-	return err
+	// fjira.yaml may hold tokens, keep it private - also when an older fjira
+	// created it world-readable
+	return writePrivateFile(settingsFilePath, settingsYml)
 }
 
 func (s *userHomeSettingsStorage) createOrGetSettings() (*Settings, error) {
@@ -168,6 +213,43 @@ func (s *userHomeSettingsStorage) ReadIssueFilters(key string) (ProjectIssueFilt
 	return f, ok, nil
 }
 
+// WriteIssueTimer saves a timer started at started under the given key
+// ("<workspace>/<issueKey>").
+func (s *userHomeSettingsStorage) WriteIssueTimer(key string, started time.Time) error {
+	settings, err := s.createOrGetSettings()
+	if err != nil {
+		return err
+	}
+	if settings.IssueTimers == nil {
+		settings.IssueTimers = map[string]time.Time{}
+	}
+	settings.IssueTimers[key] = started
+	return s.writeSettings(settings)
+}
+
+// ReadIssueTimer returns when the timer under the given key was started, and
+// whether there is one.
+func (s *userHomeSettingsStorage) ReadIssueTimer(key string) (time.Time, bool, error) {
+	settings, err := s.createOrGetSettings()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	started, ok := settings.IssueTimers[key]
+	return started, ok, nil
+}
+
+func (s *userHomeSettingsStorage) DeleteIssueTimer(key string) error {
+	settings, err := s.createOrGetSettings()
+	if err != nil {
+		return err
+	}
+	if _, ok := settings.IssueTimers[key]; !ok {
+		return nil
+	}
+	delete(settings.IssueTimers, key)
+	return s.writeSettings(settings)
+}
+
 func (s *userHomeSettingsStorage) ReadCurrentWorkspace() (string, error) {
 	settings, err := s.createOrGetSettings()
 	if err != nil {
@@ -203,7 +285,7 @@ func (s *userHomeSettingsStorage) ReadAllWorkspaces() ([]string, error) {
 func (s *userHomeSettingsStorage) ConfigDir() (string, error) {
 	configDir := os2.MustGetFjiraHomeDir()
 	if _, err := os.Stat(configDir); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(configDir, os.ModePerm)
+		err := os.Mkdir(configDir, 0700)
 		if err != nil {
 			return "", err
 		}
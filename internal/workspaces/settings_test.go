@@ -2,9 +2,13 @@ package workspaces
 
 import (
 	"errors"
+	"github.com/mk-5/fjira/internal/jira"
 	os2 "github.com/mk-5/fjira/internal/os"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func Test_userHomeSettingsStorage_write(t *testing.T) {
@@ -65,3 +69,120 @@ func Test_userHomeSettingsStorage_read(t *testing.T) {
 		})
 	}
 }
+
+func Test_userHomeSettingsStorage_should_round_trip_retry_policy(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	s := &userHomeSettingsStorage{}
+	retry := &jira.RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: time.Minute}
+	settings := &WorkspaceSettings{JiraRestUrl: "http://test", JiraUsername: "test_user", JiraToken: "test_token", Retry: retry}
+
+	// when
+	err := s.Write("test", settings)
+	assert.Nil(t, err)
+	read, err := s.Read("test")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, retry, read.Retry)
+	data, _ := os.ReadFile(filepath.Join(tempDir, ".fjira", "fjira.yaml"))
+	assert.Contains(t, string(data), "baseDelay: 1s")
+}
+
+func Test_userHomeSettingsStorage_should_keep_settings_file_private(t *testing.T) {
+	// given
+	tempDir := t.TempDir()
+	_ = os2.SetUserHomeDir(tempDir)
+	s := &userHomeSettingsStorage{}
+	path, _ := s.settingsFilePath()
+	_ = os.WriteFile(path, []byte("current: default\nworkspaces: {}\n"), 0644)
+	_ = os.Chmod(path, 0644)
+
+	// when
+	err := s.Write("default", &WorkspaceSettings{JiraRestUrl: "http://test", JiraToken: "test_token"})
+
+	// then
+	assert.Nil(t, err)
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_userHomeSettingsStorage_should_keep_issue_timers(t *testing.T) {
+	// given
+	_ = os2.SetUserHomeDir(t.TempDir())
+	s := &userHomeSettingsStorage{}
+	started := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	// when
+	err := s.WriteIssueTimer("default/ABC-1", started)
+	assert.Nil(t, err)
+	read, found, err := s.ReadIssueTimer("default/ABC-1")
+
+	// then
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.True(t, started.Equal(read))
+
+	// when
+	err = s.DeleteIssueTimer("default/ABC-1")
+	assert.Nil(t, err)
+	_, found, _ = s.ReadIssueTimer("default/ABC-1")
+
+	// then
+	assert.False(t, found)
+}
+
+func Test_IssueTimers_should_be_kept_per_session_workspace(t *testing.T) {
+	// given
+	_ = os2.SetUserHomeDir(t.TempDir())
+	s := &userHomeSettingsStorage{}
+	started := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	// when
+	_ = StartIssueTimer("work", "ABC-1", started)
+	_ = StartIssueTimer(EmptyWorkspace, "ABC-2", started)
+
+	// then
+	_, found, _ := LoadIssueTimer(DefaultWorkspaceName, "ABC-1")
+	assert.False(t, found, "the current workspace isn't the session's")
+	_, found, _ = LoadIssueTimer("work", "ABC-1")
+	assert.True(t, found)
+	_, found, _ = LoadIssueTimer(EmptyWorkspace, "ABC-2")
+	assert.True(t, found)
+	_, stored, _ := s.ReadIssueTimer(IssueTimerKey(EmptyWorkspace, "ABC-2"))
+	assert.False(t, stored, "a session without workspace isn't kept in fjira.yaml")
+
+	// when
+	_ = StopIssueTimer(EmptyWorkspace, "ABC-2")
+
+	// then
+	_, found, _ = LoadIssueTimer(EmptyWorkspace, "ABC-2")
+	assert.False(t, found)
+}
+
+func Test_DownloadDir(t *testing.T) {
+	// given
+	home := t.TempDir()
+	_ = os2.SetUserHomeDir(home)
+	_ = (&userHomeSettingsStorage{}).Write(DefaultWorkspaceName, &WorkspaceSettings{JiraRestUrl: "http://test", DownloadDir: "~/current"})
+
+	// when - no session settings, no ~/Downloads
+	dir := DownloadDir(nil)
+
+	// then
+	assert.Equal(t, home, dir)
+
+	// when
+	_ = os.Mkdir(filepath.Join(home, "Downloads"), 0700)
+	dir = DownloadDir(&WorkspaceSettings{})
+
+	// then
+	assert.Equal(t, filepath.Join(home, "Downloads"), dir, "the current workspace isn't the session's")
+
+	// when
+	dir = DownloadDir(&WorkspaceSettings{Workspace: "work", DownloadDir: "~/jira"})
+
+	// then
+	assert.Equal(t, filepath.Join(home, "jira"), dir)
+}